@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/amityadav/landr/db"
+	"github.com/amityadav/landr/internal/migrate"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+  up           apply every pending migration
+  down [n]     roll back the last n migrations (default 1)
+  status       show the schema version and pending migrations
+  to <version> migrate up or down to version
+  force <version>
+               record version as current after fixing a failed migration by
+               hand, or to adopt a database that was migrated by hand`
+
+// newMigrator connects to dbURL with the migrations built into the binary.
+func newMigrator(ctx context.Context, dbURL string) (*migrate.Migrator, error) {
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(ctx, dbURL, migrations)
+}
+
+// runMigrate runs the migrate subcommand with the arguments after "migrate".
+func runMigrate(ctx context.Context, dbURL string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	m, err := newMigrator(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close(ctx)
+
+	switch args[0] {
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations: %q", args[1])
+			}
+		}
+		if err := m.Down(ctx, steps); err != nil {
+			return err
+		}
+	case "to", "force":
+		if len(args) < 2 {
+			return fmt.Errorf("%s needs a version\n%s", args[0], migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version: %q", args[1])
+		}
+		if args[0] == "to" {
+			err = m.To(ctx, version)
+		} else {
+			err = m.Force(ctx, version)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+
+	s, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	dirty := ""
+	if s.Dirty {
+		dirty = " (dirty)"
+	}
+	log.Printf("Schema version %d%s, latest %d", s.Version, dirty, s.Latest)
+	for _, mig := range s.Pending {
+		log.Printf("  pending: %d_%s", mig.Version, mig.Name)
+	}
+	return nil
+}
+
+// checkSchema makes sure every migration has been applied before the server
+// starts, applying pending ones first when autoMigrate is set.
+func checkSchema(ctx context.Context, dbURL string, autoMigrate bool) error {
+	m, err := newMigrator(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close(ctx)
+
+	if autoMigrate {
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	}
+	err = m.Check(ctx)
+	if errors.Is(err, migrate.ErrBehind) {
+		return fmt.Errorf("%w (run `server migrate up` or start with -auto-migrate)", err)
+	}
+	return err
+}
@@ -0,0 +1,18 @@
+// Package db holds the database schema, embedded so the server binary can
+// set up its database itself.
+package db
+
+import "embed"
+
+// Migrations holds the numbered migrations/*.up.sql and *.down.sql files of
+// the Postgres schema.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// SQLiteMigrations holds the sqlite/*.up.sql files that bring a SQLite
+// database up to the Postgres schema. Each is numbered after the Postgres
+// migration it matches, starting with the full schema as of migration 15.
+//
+//go:embed sqlite/*.sql
+var SQLiteMigrations embed.FS
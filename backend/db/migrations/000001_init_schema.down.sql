@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_materials_user_id;
+DROP INDEX IF EXISTS idx_flashcards_next_review;
+
+DROP TABLE IF EXISTS flashcards;
+DROP TABLE IF EXISTS materials;
+DROP TABLE IF EXISTS users;
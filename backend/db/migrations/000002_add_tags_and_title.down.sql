@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tags_user_id;
+
+DROP TABLE IF EXISTS material_tags;
+DROP TABLE IF EXISTS tags;
+
+ALTER TABLE materials DROP COLUMN IF EXISTS title;
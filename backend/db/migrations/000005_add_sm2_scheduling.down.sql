@@ -0,0 +1,3 @@
+ALTER TABLE flashcards DROP COLUMN IF EXISTS repetitions;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS interval_days;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS ease_factor;
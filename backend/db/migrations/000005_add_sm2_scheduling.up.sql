@@ -0,0 +1,3 @@
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS interval_days INT NOT NULL DEFAULT 0;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS repetitions INT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_fsrs_params;
+DROP TABLE IF EXISTS review_logs;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS last_reviewed_at;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS stability;
@@ -0,0 +1,23 @@
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS stability DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS difficulty DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS last_reviewed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS review_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    flashcard_id UUID NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    grade SMALLINT NOT NULL, -- 1=Again, 2=Hard, 3=Good, 4=Easy
+    scheduler VARCHAR(20) NOT NULL,
+    elapsed_days DOUBLE PRECISION NOT NULL DEFAULT 0, -- Days since the card's previous review
+    reviewed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_reviewed ON review_logs(user_id, reviewed_at);
+CREATE INDEX IF NOT EXISTS idx_review_logs_flashcard ON review_logs(flashcard_id, reviewed_at);
+
+CREATE TABLE IF NOT EXISTS user_fsrs_params (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    weights DOUBLE PRECISION[] NOT NULL,
+    review_count INT NOT NULL DEFAULT 0, -- Review logs the weights were fitted on
+    optimized_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
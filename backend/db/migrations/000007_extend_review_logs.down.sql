@@ -0,0 +1,5 @@
+ALTER TABLE review_logs DROP COLUMN IF EXISTS client_reviewed_at;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS next_due_at;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_due_at;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS next_stage;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_stage;
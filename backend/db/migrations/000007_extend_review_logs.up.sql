@@ -0,0 +1,5 @@
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_stage INT NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS next_stage INT NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_due_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS next_due_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS client_reviewed_at TIMESTAMP WITH TIME ZONE;
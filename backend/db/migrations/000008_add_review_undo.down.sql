@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_review_logs_user_session;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS reverted_at;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS session_id;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_last_reviewed_at;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_difficulty;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_stability;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_repetitions;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_interval_days;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_ease_factor;
@@ -0,0 +1,10 @@
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_ease_factor DOUBLE PRECISION NOT NULL DEFAULT 2.5;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_interval_days INT NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_repetitions INT NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_stability DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_difficulty DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_last_reviewed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS session_id VARCHAR(64);
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS reverted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_session ON review_logs(user_id, session_id, reviewed_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    new_cards_per_day INT NOT NULL DEFAULT 20,
+    max_reviews_per_day INT NOT NULL DEFAULT 200,
+    day_rollover_hour INT NOT NULL DEFAULT 4 CHECK (day_rollover_hour BETWEEN 0 AND 23),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE flashcards DROP COLUMN IF EXISTS flag;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS buried_until;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS suspended;
@@ -0,0 +1,3 @@
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS suspended BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS buried_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS flag INT NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_flashcards_leech;
+
+ALTER TABLE user_settings DROP COLUMN IF EXISTS leech_action;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS leech_threshold;
+
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_suspended;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_is_leech;
+ALTER TABLE review_logs DROP COLUMN IF EXISTS previous_lapses;
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS is_leech;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS lapses;
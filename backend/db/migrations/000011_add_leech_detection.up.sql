@@ -0,0 +1,11 @@
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS lapses INT NOT NULL DEFAULT 0;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS is_leech BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_lapses INT NOT NULL DEFAULT 0;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_is_leech BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE review_logs ADD COLUMN IF NOT EXISTS previous_suspended BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS leech_threshold INT NOT NULL DEFAULT 8;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS leech_action VARCHAR(16) NOT NULL DEFAULT 'TAG';
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_leech ON flashcards(material_id) WHERE is_leech;
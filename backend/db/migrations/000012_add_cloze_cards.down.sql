@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_flashcards_note_id;
+
+ALTER TABLE flashcards DROP COLUMN IF EXISTS cloze_index;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS note_id;
+ALTER TABLE flashcards DROP COLUMN IF EXISTS card_type;
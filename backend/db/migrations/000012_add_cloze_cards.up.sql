@@ -0,0 +1,5 @@
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS card_type VARCHAR(16) NOT NULL DEFAULT 'BASIC';
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS note_id UUID;
+ALTER TABLE flashcards ADD COLUMN IF NOT EXISTS cloze_index INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_note_id ON flashcards(note_id) WHERE note_id IS NOT NULL;
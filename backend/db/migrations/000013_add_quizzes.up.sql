@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS quizzes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    material_id UUID REFERENCES materials(id) ON DELETE SET NULL,
+    tag VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    flashcard_id UUID REFERENCES flashcards(id) ON DELETE SET NULL,
+    position INT NOT NULL,
+    question TEXT NOT NULL,
+    choices TEXT[] NOT NULL,
+    correct_index INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quiz_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    correct_count INT NOT NULL,
+    total_count INT NOT NULL,
+    applied_to_schedule BOOLEAN NOT NULL DEFAULT FALSE,
+    submitted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS quiz_attempt_answers (
+    attempt_id UUID NOT NULL REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    chosen_index INT NOT NULL,
+    correct BOOLEAN NOT NULL,
+    PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quizzes_user_id ON quizzes(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_quiz_id ON quiz_questions(quiz_id, position);
+CREATE INDEX IF NOT EXISTS idx_quiz_attempts_user_id ON quiz_attempts(user_id, submitted_at);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS ingestion_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    image_data TEXT NOT NULL DEFAULT '',
+    allow_cloze BOOLEAN NOT NULL DEFAULT FALSE,
+    generate_reverse BOOLEAN NOT NULL DEFAULT FALSE,
+    stage VARCHAR(20) NOT NULL DEFAULT 'QUEUED', -- QUEUED, FETCHING, EXTRACTING, GENERATING, SAVING, SUCCEEDED or FAILED
+    chunk INT NOT NULL DEFAULT 0,
+    total_chunks INT NOT NULL DEFAULT 0,
+    attempts INT NOT NULL DEFAULT 0,
+    lease_expires_at TIMESTAMP WITH TIME ZONE, -- A worker owns the job until then
+    material_id UUID REFERENCES materials(id) ON DELETE SET NULL,
+    title TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    flashcards_created INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Workers pick up unfinished jobs whose lease has run out, oldest first
+CREATE INDEX IF NOT EXISTS idx_ingestion_jobs_pending ON ingestion_jobs(created_at)
+    WHERE stage NOT IN ('SUCCEEDED', 'FAILED');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_ingestion_jobs_material_id;
+ALTER TABLE ingestion_jobs DROP COLUMN IF EXISTS failed_chunks;
+DROP TABLE IF EXISTS material_chunks;
@@ -0,0 +1,16 @@
+-- Sections of a material's content that flashcards are generated from one at a time,
+-- so failed or unfinished sections can be retried without redoing the rest
+CREATE TABLE IF NOT EXISTS material_chunks (
+    material_id UUID NOT NULL REFERENCES materials(id) ON DELETE CASCADE,
+    chunk_index INT NOT NULL,
+    content TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING', -- PENDING, DONE or FAILED
+    error TEXT NOT NULL DEFAULT '',
+    flashcards_created INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (material_id, chunk_index)
+);
+
+ALTER TABLE ingestion_jobs ADD COLUMN IF NOT EXISTS failed_chunks INT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_jobs_material_id ON ingestion_jobs(material_id);
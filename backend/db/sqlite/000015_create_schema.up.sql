@@ -0,0 +1,196 @@
+-- Schema of SQLite databases, matching the Postgres schema after migration
+-- 15 in ../migrations. Later Postgres migrations get a SQLite counterpart here
+-- with the same version, applied in order on top of this one. IDs are UUID
+-- text, times are Unix milliseconds, booleans are 0 or 1 and arrays are JSON.
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    google_id TEXT UNIQUE NOT NULL,
+    picture TEXT,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS materials (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL, -- 'TEXT', 'LINK', 'IMAGE' or 'YOUTUBE'
+    content TEXT NOT NULL,
+    title TEXT DEFAULT 'Untitled Material',
+    summary TEXT,
+    is_deleted INTEGER DEFAULT 0,
+    deleted_at INTEGER,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_materials_user_id ON materials(user_id);
+
+CREATE TABLE IF NOT EXISTS flashcards (
+    id TEXT PRIMARY KEY,
+    material_id TEXT NOT NULL REFERENCES materials(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    stage INTEGER NOT NULL DEFAULT 0,
+    next_review_at INTEGER NOT NULL,
+    ease_factor REAL NOT NULL DEFAULT 2.5,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    stability REAL NOT NULL DEFAULT 0,
+    difficulty REAL NOT NULL DEFAULT 0,
+    last_reviewed_at INTEGER,
+    suspended INTEGER NOT NULL DEFAULT 0,
+    buried_until INTEGER,
+    flag INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    is_leech INTEGER NOT NULL DEFAULT 0,
+    card_type TEXT NOT NULL DEFAULT 'BASIC',
+    note_id TEXT,
+    cloze_index INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_flashcards_material_id ON flashcards(material_id, next_review_at);
+CREATE INDEX IF NOT EXISTS idx_flashcards_note_id ON flashcards(note_id) WHERE note_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS tags (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS material_tags (
+    material_id TEXT NOT NULL REFERENCES materials(id) ON DELETE CASCADE,
+    tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (material_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS review_logs (
+    id TEXT PRIMARY KEY,
+    flashcard_id TEXT NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    grade INTEGER NOT NULL, -- 1=Again, 2=Hard, 3=Good, 4=Easy
+    scheduler TEXT NOT NULL,
+    elapsed_days REAL NOT NULL DEFAULT 0,
+    reviewed_at INTEGER NOT NULL,
+    previous_stage INTEGER NOT NULL DEFAULT 0,
+    next_stage INTEGER NOT NULL DEFAULT 0,
+    previous_due_at INTEGER,
+    next_due_at INTEGER,
+    client_reviewed_at INTEGER,
+    previous_ease_factor REAL NOT NULL DEFAULT 2.5,
+    previous_interval_days INTEGER NOT NULL DEFAULT 0,
+    previous_repetitions INTEGER NOT NULL DEFAULT 0,
+    previous_stability REAL NOT NULL DEFAULT 0,
+    previous_difficulty REAL NOT NULL DEFAULT 0,
+    previous_last_reviewed_at INTEGER,
+    session_id TEXT,
+    reverted_at INTEGER,
+    previous_lapses INTEGER NOT NULL DEFAULT 0,
+    previous_is_leech INTEGER NOT NULL DEFAULT 0,
+    previous_suspended INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_reviewed ON review_logs(user_id, reviewed_at);
+CREATE INDEX IF NOT EXISTS idx_review_logs_flashcard ON review_logs(flashcard_id, reviewed_at);
+CREATE INDEX IF NOT EXISTS idx_review_logs_user_session ON review_logs(user_id, session_id, reviewed_at);
+
+CREATE TABLE IF NOT EXISTS user_fsrs_params (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    weights TEXT NOT NULL, -- JSON array of numbers
+    review_count INTEGER NOT NULL DEFAULT 0,
+    optimized_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    new_cards_per_day INTEGER NOT NULL DEFAULT 20,
+    max_reviews_per_day INTEGER NOT NULL DEFAULT 200,
+    day_rollover_hour INTEGER NOT NULL DEFAULT 4 CHECK (day_rollover_hour BETWEEN 0 AND 23),
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    leech_threshold INTEGER NOT NULL DEFAULT 8,
+    leech_action TEXT NOT NULL DEFAULT 'TAG',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quizzes (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    material_id TEXT REFERENCES materials(id) ON DELETE SET NULL,
+    tag TEXT,
+    created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quiz_questions (
+    id TEXT PRIMARY KEY,
+    quiz_id TEXT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    flashcard_id TEXT REFERENCES flashcards(id) ON DELETE SET NULL,
+    position INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    choices TEXT NOT NULL, -- JSON array of strings
+    correct_index INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quiz_attempts (
+    id TEXT PRIMARY KEY,
+    quiz_id TEXT NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    correct_count INTEGER NOT NULL,
+    total_count INTEGER NOT NULL,
+    applied_to_schedule INTEGER NOT NULL DEFAULT 0,
+    submitted_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quiz_attempt_answers (
+    attempt_id TEXT NOT NULL REFERENCES quiz_attempts(id) ON DELETE CASCADE,
+    question_id TEXT NOT NULL REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    chosen_index INTEGER NOT NULL,
+    correct INTEGER NOT NULL,
+    PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_quiz_id ON quiz_questions(quiz_id, position);
+
+CREATE TABLE IF NOT EXISTS ingestion_jobs (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    image_data TEXT NOT NULL DEFAULT '',
+    allow_cloze INTEGER NOT NULL DEFAULT 0,
+    generate_reverse INTEGER NOT NULL DEFAULT 0,
+    stage TEXT NOT NULL DEFAULT 'QUEUED',
+    chunk INTEGER NOT NULL DEFAULT 0,
+    total_chunks INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    lease_expires_at INTEGER,
+    material_id TEXT REFERENCES materials(id) ON DELETE SET NULL,
+    title TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]', -- JSON array of strings
+    flashcards_created INTEGER NOT NULL DEFAULT 0,
+    failed_chunks TEXT NOT NULL DEFAULT '[]', -- JSON array of 1-based chunk numbers
+    error TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_jobs_pending ON ingestion_jobs(created_at)
+    WHERE stage NOT IN ('SUCCEEDED', 'FAILED');
+CREATE INDEX IF NOT EXISTS idx_ingestion_jobs_material_id ON ingestion_jobs(material_id);
+
+CREATE TABLE IF NOT EXISTS material_chunks (
+    material_id TEXT NOT NULL REFERENCES materials(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING', -- PENDING, DONE or FAILED
+    error TEXT NOT NULL DEFAULT '',
+    flashcards_created INTEGER NOT NULL DEFAULT 0,
+    updated_at INTEGER NOT NULL,
+    PRIMARY KEY (material_id, chunk_index)
+);
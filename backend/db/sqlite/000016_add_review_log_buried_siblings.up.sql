@@ -0,0 +1,8 @@
+-- Sibling cards a review buried, with when they were buried until before, so
+-- undoing the review can put them back
+CREATE TABLE IF NOT EXISTS review_log_buried_siblings (
+    review_log_id TEXT NOT NULL REFERENCES review_logs(id) ON DELETE CASCADE,
+    flashcard_id TEXT NOT NULL REFERENCES flashcards(id) ON DELETE CASCADE,
+    previous_buried_until INTEGER,
+    PRIMARY KEY (review_log_id, flashcard_id)
+);
@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropicVersion is the Messages API version requests are written against.
+const anthropicVersion = "2023-06-01"
+
+// anthropicMaxTokens caps replies; the largest, 40 flashcards, fit well within it.
+const anthropicMaxTokens = 8192
+
+// anthropicProvider talks to Anthropic's Messages API.
+type anthropicProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func newAnthropicProvider(baseURL, apiKey, model string) *anthropicProvider {
+	return &anthropicProvider{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   model,
+		client: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+	}
+}
+
+type anthropicRequest struct {
+	Model      string               `json:"model"`
+	MaxTokens  int                  `json:"max_tokens"`
+	Messages   []anthropicMessage   `json:"messages"`
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+// anthropicTool describes a tool the model can call. Forcing a call to a
+// tool whose input is the schema is how the Messages API returns structured output.
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+type anthropicContent struct {
+	Type   string           `json:"type"`
+	Text   string           `json:"text,omitempty"`
+	Source *anthropicSource `json:"source,omitempty"`
+	Input  json.RawMessage  `json:"input,omitempty"` // Arguments of a tool_use block
+}
+
+type anthropicSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContent `json:"content"`
+}
+
+func (p *anthropicProvider) Name() string {
+	return "Anthropic " + p.model
+}
+
+func (p *anthropicProvider) Complete(req Request) (string, error) {
+	log.Printf("[AI.%s] Sending request to %s...", req.Operation, p.Name())
+
+	var content []anthropicContent
+	if req.Image != "" {
+		mediaType, data := splitDataURL(req.Image)
+		content = append(content, anthropicContent{
+			Type:   "image",
+			Source: &anthropicSource{Type: "base64", MediaType: mediaType, Data: data},
+		})
+	}
+	content = append(content, anthropicContent{Type: "text", Text: req.Prompt})
+	reqBody := anthropicRequest{
+		Model:     p.model,
+		MaxTokens: anthropicMaxTokens,
+		Messages:  []anthropicMessage{{Role: "user", Content: content}},
+	}
+	if req.Schema != nil {
+		reqBody.Tools = []anthropicTool{{
+			Name:        req.Schema.Name,
+			Description: "Return the result in the required structure.",
+			InputSchema: req.Schema.Schema,
+		}}
+		reqBody.ToolChoice = &anthropicToolChoice{Type: "tool", Name: req.Schema.Name}
+	}
+
+	headers := map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+	var resp anthropicResponse
+	if err := postJSON(p.client, p.baseURL+"/v1/messages", headers, reqBody, &resp, req.Operation); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "tool_use" && req.Schema != nil {
+			log.Printf("[AI.%s] Structured response received, length: %d", req.Operation, len(block.Input))
+			return string(block.Input), nil
+		}
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	reply := strings.TrimSpace(text.String())
+	if reply == "" {
+		log.Printf("[AI.%s] No text returned in response", req.Operation)
+		return "", fmt.Errorf("no text returned")
+	}
+	log.Printf("[AI.%s] Response received, length: %d", req.Operation, len(reply))
+	return reply, nil
+}
@@ -0,0 +1,144 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string // The request body sent
+	}{
+		{
+			name: "text",
+			req:  Request{Operation: "Summary", Prompt: "Summarize"},
+			want: `{"model":"claude","max_tokens":8192,"messages":[{"role":"user","content":[{"type":"text","text":"Summarize"}]}]}`,
+		},
+		{
+			name: "image",
+			req:  Request{Operation: "OCR", Prompt: "Read this", Image: "data:image/png;base64,iVBORw0K"},
+			want: `{"model":"claude","max_tokens":8192,"messages":[{"role":"user","content":[` +
+				`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"iVBORw0K"}},` +
+				`{"type":"text","text":"Read this"}]}]}`,
+		},
+		{
+			name: "schema",
+			req:  Request{Operation: "Grade", Prompt: "Grade it", Schema: &Schema{Name: "grade", Schema: map[string]any{"type": "object"}}},
+			want: `{"model":"claude","max_tokens":8192,"messages":[{"role":"user","content":[{"type":"text","text":"Grade it"}]}],` +
+				`"tools":[{"name":"grade","description":"Return the result in the required structure.","input_schema":{"type":"object"}}],` +
+				`"tool_choice":{"type":"tool","name":"grade"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got json.RawMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != anthropicVersion {
+					t.Errorf("request to %s with key %q and version %q", r.URL.Path, r.Header.Get("x-api-key"), r.Header.Get("anthropic-version"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				w.Write([]byte(`{"content":[{"type":"text","text":"ok"}]}`))
+			}))
+			defer server.Close()
+
+			if _, err := newAnthropicProvider(server.URL, "key", "claude").Complete(tt.req); err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			if !jsonEqual(t, got, tt.want) {
+				t.Errorf("request body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnthropicResponse(t *testing.T) {
+	schema := &Schema{Name: "grade", Schema: map[string]any{"type": "object"}}
+	tests := []struct {
+		name     string
+		schema   *Schema
+		status   int
+		response string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "text",
+			response: `{"content":[{"type":"text","text":" Paris is "},{"type":"text","text":"the capital. "}]}`,
+			want:     "Paris is the capital.",
+		},
+		{
+			name:     "tool use",
+			schema:   schema,
+			response: `{"content":[{"type":"text","text":"Here you go"},{"type":"tool_use","name":"grade","input":{"score": 0.9}}]}`,
+			want:     `{"score": 0.9}`,
+		},
+		{
+			name:     "tool use ignored without a schema",
+			response: `{"content":[{"type":"tool_use","name":"grade","input":{}},{"type":"text","text":"Plain"}]}`,
+			want:     "Plain",
+		},
+		{
+			name:     "text when a tool was asked for",
+			schema:   schema,
+			response: `{"content":[{"type":"text","text":"{\"score\": 1}"}]}`,
+			want:     `{"score": 1}`,
+		},
+		{
+			name:     "empty",
+			response: `{"content":[]}`,
+			wantErr:  "no text returned",
+		},
+		{
+			name:     "error status",
+			status:   http.StatusTooManyRequests,
+			response: `{"type":"error","error":{"type":"rate_limit_error"}}`,
+			wantErr:  "api error: 429",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			reply, err := newAnthropicProvider(server.URL, "key", "claude").Complete(Request{Operation: "Test", Prompt: "Hi", Schema: tt.schema})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Complete: got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || reply != tt.want {
+				t.Errorf("Complete = %q, %v, want %q", reply, err, tt.want)
+			}
+		})
+	}
+}
+
+// jsonEqual reports whether got holds the same JSON value as want.
+func jsonEqual(t *testing.T, got json.RawMessage, want string) bool {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("decoding %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("decoding %s: %v", want, err)
+	}
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	return string(gb) == string(wb)
+}
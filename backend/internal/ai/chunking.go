@@ -17,6 +17,7 @@ const (
 	ChunkOverlap   = 300  // Overlap between chunks
 	MaxRetries     = 3
 	BaseRetryDelay = 2 * time.Second
+	ChunkDelay     = 10 * time.Second // Between chunks to respect the rate limit (8000 TPM)
 )
 
 // ChunkResult holds the result from processing a single chunk
@@ -125,103 +126,20 @@ func RetryWithBackoff(ctx context.Context, operation string, fn func() error) er
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-// ProcessChunksSequential processes chunks one at a time to respect rate limits
-func (c *Client) ProcessChunksParallel(ctx context.Context, chunks []string, existingTags []string) (string, []string, []*learning.Flashcard, error) {
-	if len(chunks) == 0 {
-		return "", nil, nil, fmt.Errorf("no chunks to process")
-	}
-
-	if len(chunks) == 1 {
-		// Single chunk - process normally
-		return c.GenerateFlashcards(chunks[0], existingTags)
-	}
-
-	log.Printf("[AI.Sequential] Processing %d chunks sequentially (rate limit: 8000 TPM)", len(chunks))
-
-	var allFlashcards []*learning.Flashcard
-	allTags := make(map[string]bool)
-	var firstTitle string
-	var errors []error
-
-	// Process chunks one at a time with delay
-	for i, chunk := range chunks {
-		if ctx.Err() != nil {
-			break
-		}
-
-		log.Printf("[AI.Sequential] Processing chunk %d/%d", i+1, len(chunks))
-
-		var title string
-		var tags []string
-		var cards []*learning.Flashcard
+// GenerateChunkFlashcards generates the flashcards for one chunk, retrying
+// with backoff. Callers processing several chunks should wait ChunkDelay
+// between them to respect the rate limit.
+func (c *Client) GenerateChunkFlashcards(ctx context.Context, index int, chunk string, existingTags []string, allowCloze bool) ChunkResult {
+	result := ChunkResult{ChunkIndex: index}
+	result.Error = RetryWithBackoff(ctx, fmt.Sprintf("Chunk_%d", index), func() error {
 		var err error
-
-		// Retry with backoff for this chunk
-		err = RetryWithBackoff(ctx, fmt.Sprintf("Chunk_%d", i), func() error {
-			title, tags, cards, err = c.GenerateFlashcards(chunk, existingTags)
-			return err
-		})
-
-		if err != nil {
-			log.Printf("[AI.Sequential] Chunk %d failed: %v", i+1, err)
-			errors = append(errors, err)
-		} else {
-			if firstTitle == "" && title != "" {
-				firstTitle = title
-			}
-			for _, tag := range tags {
-				allTags[tag] = true
-			}
-			allFlashcards = append(allFlashcards, cards...)
-			log.Printf("[AI.Sequential] Chunk %d completed: %d flashcards", i+1, len(cards))
-		}
-
-		// Wait 10 seconds between chunks to respect rate limit (8000 TPM)
-		if i < len(chunks)-1 {
-			log.Printf("[AI.Sequential] Waiting 10s for rate limit...")
-			select {
-			case <-ctx.Done():
-				break
-			case <-time.After(10 * time.Second):
-			}
-		}
-	}
-
-	// If all chunks failed, return error
-	if len(errors) == len(chunks) {
-		return "", nil, nil, fmt.Errorf("all chunks failed: %v", errors[0])
-	}
-
-	// Convert tags map to slice
-	var tags []string
-	for tag := range allTags {
-		tags = append(tags, tag)
-	}
-
-	// Deduplicate flashcards
-	dedupedCards := deduplicateFlashcards(allFlashcards)
-
-	log.Printf("[AI.Sequential] Completed: %d unique flashcards from %d chunks", len(dedupedCards), len(chunks))
-	return firstTitle, tags, dedupedCards, nil
-}
-
-// deduplicateFlashcards removes duplicate flashcards based on question similarity
-func deduplicateFlashcards(cards []*learning.Flashcard) []*learning.Flashcard {
-	seen := make(map[string]bool)
-	var unique []*learning.Flashcard
-
-	for _, card := range cards {
-		// Normalize question for comparison
-		key := strings.ToLower(strings.TrimSpace(card.Question))
-		if len(key) > 50 {
-			key = key[:50] // Use first 50 chars as key
-		}
-
-		if !seen[key] {
-			seen[key] = true
-			unique = append(unique, card)
-		}
+		result.Title, result.Tags, result.Flashcards, err = c.GenerateFlashcards(chunk, existingTags, allowCloze)
+		return err
+	})
+	if result.Error != nil {
+		log.Printf("[AI.Chunk] Chunk %d failed: %v", index+1, result.Error)
+	} else {
+		log.Printf("[AI.Chunk] Chunk %d completed: %d flashcards", index+1, len(result.Flashcards))
 	}
-
-	return unique
+	return result
 }
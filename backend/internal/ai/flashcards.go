@@ -0,0 +1,122 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amityadav/landr/internal/cloze"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// Bounds generated flashcards must stay within. The prompt asks for at least
+// requestedFlashcards, but a short text may fairly yield fewer, down to
+// minFlashcards.
+const (
+	requestedFlashcards = 6
+	minFlashcards       = 1
+	maxFlashcards       = 40
+	maxTagLength        = 50 // tags.name is VARCHAR(50)
+)
+
+// flashcardRepairs is how many times a reply that breaks the schema is sent
+// back to the model with its problems before giving up.
+const flashcardRepairs = 1
+
+// flashcardsSchema is the structure GenerateFlashcards asks for. It keeps to
+// the subset of JSON schema strict structured output accepts, so the bounds
+// above are checked by validateFlashcards instead.
+var flashcardsSchema = &Schema{
+	Name: "flashcards",
+	Schema: map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"title", "tags", "flashcards"},
+		"properties": map[string]any{
+			"title": map[string]any{"type": "string", "description": "Short, descriptive title of the material"},
+			"tags": map[string]any{
+				"type":        "array",
+				"description": "3-5 relevant categories",
+				"items":       map[string]any{"type": "string"},
+			},
+			"flashcards": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []string{"question", "answer"},
+					"properties": map[string]any{
+						"question": map[string]any{"type": "string"},
+						"answer":   map[string]any{"type": "string"},
+					},
+				},
+			},
+		},
+	},
+}
+
+type flashcardsReply struct {
+	Title      string                `json:"title"`
+	Tags       []string              `json:"tags"`
+	Flashcards []*learning.Flashcard `json:"flashcards"`
+}
+
+// parseFlashcards decodes a reply to the flashcards prompt and returns what
+// is wrong with it, if anything. Text around the JSON object, such as code
+// fences from models without structured output, is ignored.
+func parseFlashcards(raw string, allowCloze bool) (*flashcardsReply, []string) {
+	start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}")
+	if start < 0 || end < start {
+		return nil, []string{"the reply is not a JSON object"}
+	}
+	var reply flashcardsReply
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &reply); err != nil {
+		return nil, []string{fmt.Sprintf("the reply is not valid JSON: %v", err)}
+	}
+	return &reply, validateFlashcards(&reply, allowCloze)
+}
+
+// validateFlashcards checks a reply against the bounds the store and the
+// review screens rely on. Cloze cards may have an empty answer.
+func validateFlashcards(reply *flashcardsReply, allowCloze bool) []string {
+	var problems []string
+	if strings.TrimSpace(reply.Title) == "" {
+		problems = append(problems, "title is empty")
+	}
+	for _, tag := range reply.Tags {
+		switch {
+		case strings.TrimSpace(tag) == "":
+			problems = append(problems, "a tag is empty")
+		case utf8.RuneCountInString(tag) > maxTagLength:
+			problems = append(problems, fmt.Sprintf("tag %q is longer than %d characters", tag, maxTagLength))
+		}
+	}
+	if n := len(reply.Flashcards); n < minFlashcards || n > maxFlashcards {
+		problems = append(problems, fmt.Sprintf("there are %d flashcards, expected %d to %d", n, minFlashcards, maxFlashcards))
+	}
+	for i, card := range reply.Flashcards {
+		if card == nil || strings.TrimSpace(card.Question) == "" {
+			problems = append(problems, fmt.Sprintf("flashcard %d has an empty question", i+1))
+			continue
+		}
+		isCloze := allowCloze && len(cloze.Indices(card.Question)) > 0
+		if !isCloze && strings.TrimSpace(card.Answer) == "" {
+			problems = append(problems, fmt.Sprintf("flashcard %d has an empty answer", i+1))
+		}
+	}
+	return problems
+}
+
+// flashcardsRepairPrompt asks the model to fix its previous reply to prompt.
+func flashcardsRepairPrompt(prompt, reply string, problems []string) string {
+	return fmt.Sprintf(`%s
+
+Your previous reply was:
+%s
+
+It has these problems:
+- %s
+
+Return the corrected JSON object, following the same structure and rules.`, prompt, reply, strings.Join(problems, "\n- "))
+}
@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFlashcards(t *testing.T) {
+	valid := `{"title": "Go", "tags": ["go"], "flashcards": [{"question": "Who made Go?", "answer": "Google"}]}`
+	cards := func(n int) string {
+		var parts []string
+		for i := range n {
+			parts = append(parts, fmt.Sprintf(`{"question": "Q%d", "answer": "A%d"}`, i, i))
+		}
+		return `{"title": "Go", "tags": ["go"], "flashcards": [` + strings.Join(parts, ", ") + `]}`
+	}
+
+	tests := []struct {
+		name         string
+		raw          string
+		allowCloze   bool
+		wantProblems []string // Substrings, one per expected problem
+	}{
+		{name: "valid", raw: valid},
+		{name: "in a code fence", raw: "```json\n" + valid + "\n```"},
+		{name: "the most cards", raw: cards(maxFlashcards)},
+		{name: "not JSON", raw: "Sorry, I can't help with that.", wantProblems: []string{"not a JSON object"}},
+		{name: "broken JSON", raw: `{"title": "Go", "flashcards": [}`, wantProblems: []string{"not valid JSON"}},
+		{name: "no cards", raw: cards(0), wantProblems: []string{"there are 0 flashcards"}},
+		{name: "too many cards", raw: cards(maxFlashcards + 1), wantProblems: []string{fmt.Sprintf("there are %d flashcards", maxFlashcards+1)}},
+		{
+			name:         "empty title and tag",
+			raw:          `{"title": " ", "tags": [""], "flashcards": [{"question": "Q", "answer": "A"}]}`,
+			wantProblems: []string{"title is empty", "a tag is empty"},
+		},
+		{
+			name:         "long tag",
+			raw:          `{"title": "Go", "tags": ["` + strings.Repeat("é", maxTagLength+1) + `"], "flashcards": [{"question": "Q", "answer": "A"}]}`,
+			wantProblems: []string{"longer than 50 characters"},
+		},
+		{
+			name:         "empty question and answer",
+			raw:          `{"title": "Go", "tags": [], "flashcards": [{"question": "", "answer": "A"}, {"question": "Q", "answer": ""}, null]}`,
+			wantProblems: []string{"flashcard 1 has an empty question", "flashcard 2 has an empty answer", "flashcard 3 has an empty question"},
+		},
+		{
+			name:       "cloze without an answer",
+			raw:        `{"title": "Go", "tags": [], "flashcards": [{"question": "Go was made at {{c1::Google}}", "answer": ""}]}`,
+			allowCloze: true,
+		},
+		{
+			name:         "cloze when not allowed",
+			raw:          `{"title": "Go", "tags": [], "flashcards": [{"question": "Go was made at {{c1::Google}}", "answer": ""}]}`,
+			wantProblems: []string{"flashcard 1 has an empty answer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, problems := parseFlashcards(tt.raw, tt.allowCloze)
+			if len(problems) != len(tt.wantProblems) {
+				t.Fatalf("got problems %q, want %q", problems, tt.wantProblems)
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i+1, problems[i], want)
+				}
+			}
+			if len(problems) == 0 && (reply == nil || reply.Title != "Go") {
+				t.Errorf("got reply %+v, want the decoded object", reply)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ollamaProvider talks to a local Ollama server through its native chat API.
+type ollamaProvider struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func newOllamaProvider(baseURL, model string) *ollamaProvider {
+	return &ollamaProvider{
+		baseURL: baseURL,
+		model:   model,
+		client: &http.Client{
+			Timeout: 5 * time.Minute, // Local models on modest hardware are slow
+		},
+	}
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   map[string]any  `json:"format,omitempty"` // JSON schema of the reply
+}
+
+type ollamaMessage struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // Base64, without the data: prefix
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+}
+
+func (p *ollamaProvider) Name() string {
+	return "Ollama " + p.model
+}
+
+func (p *ollamaProvider) Complete(req Request) (string, error) {
+	log.Printf("[AI.%s] Sending request to %s...", req.Operation, p.Name())
+
+	msg := ollamaMessage{Role: "user", Content: req.Prompt}
+	if req.Image != "" {
+		_, data := splitDataURL(req.Image)
+		msg.Images = []string{data}
+	}
+	reqBody := ollamaRequest{Model: p.model, Messages: []ollamaMessage{msg}}
+	if req.Schema != nil {
+		reqBody.Format = req.Schema.Schema
+	}
+
+	var resp ollamaResponse
+	if err := postJSON(p.client, p.baseURL+"/api/chat", nil, reqBody, &resp, req.Operation); err != nil {
+		return "", err
+	}
+
+	content := strings.TrimSpace(resp.Message.Content)
+	if content == "" {
+		log.Printf("[AI.%s] Empty message returned in response", req.Operation)
+		return "", fmt.Errorf("no message returned")
+	}
+	log.Printf("[AI.%s] Response received, length: %d", req.Operation, len(content))
+	return content, nil
+}
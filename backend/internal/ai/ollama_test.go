@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOllamaRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want string // The request body sent
+	}{
+		{
+			name: "text",
+			req:  Request{Operation: "Summary", Prompt: "Summarize"},
+			want: `{"model":"llama","messages":[{"role":"user","content":"Summarize"}],"stream":false}`,
+		},
+		{
+			name: "image",
+			req:  Request{Operation: "OCR", Prompt: "Read this", Image: "data:image/png;base64,iVBORw0K"},
+			want: `{"model":"llama","messages":[{"role":"user","content":"Read this","images":["iVBORw0K"]}],"stream":false}`,
+		},
+		{
+			name: "schema",
+			req:  Request{Operation: "Grade", Prompt: "Grade it", Schema: &Schema{Name: "grade", Schema: map[string]any{"type": "object"}}},
+			want: `{"model":"llama","messages":[{"role":"user","content":"Grade it"}],"stream":false,"format":{"type":"object"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got json.RawMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/chat" {
+					t.Errorf("request to %s, want /api/chat", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				w.Write([]byte(`{"message":{"role":"assistant","content":"ok"}}`))
+			}))
+			defer server.Close()
+
+			if _, err := newOllamaProvider(server.URL, "llama").Complete(tt.req); err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			if !jsonEqual(t, got, tt.want) {
+				t.Errorf("request body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     string
+		wantErr  string
+	}{
+		{name: "reply", response: `{"message":{"role":"assistant","content":"  {\"score\": 1}\n"}}`, want: `{"score": 1}`},
+		{name: "empty", response: `{"message":{"role":"assistant","content":" "}}`, wantErr: "no message returned"},
+		{name: "error status", status: http.StatusNotFound, response: `{"error":"model 'llama' not found"}`, wantErr: "api error: 404"},
+		{name: "not JSON", response: `<html>`, wantErr: "failed to decode response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			reply, err := newOllamaProvider(server.URL, "llama").Complete(Request{Operation: "Test", Prompt: "Hi"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Complete: got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || reply != tt.want {
+				t.Errorf("Complete = %q, %v, want %q", reply, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// openAIProvider talks to any endpoint implementing OpenAI's chat completions
+// API, such as OpenAI itself, Groq, vLLM or LM Studio.
+type openAIProvider struct {
+	name    string
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+
+	// jsonMode is how schema requests ask for JSON. Many compatible servers
+	// reject a response_format with a 400, so a rejected request is retried
+	// in the next weaker mode, which is kept once a request succeeds in it.
+	jsonMode atomic.Int32
+}
+
+// How an openAIProvider asks for a JSON reply. Every mode also describes the
+// JSON in the prompt, and the reply is validated either way.
+const (
+	jsonModePrompt int32 = iota // Only the prompt asks for JSON
+	jsonModeObject              // A json_object response_format, any valid JSON
+	jsonModeSchema              // A strict json_schema response_format
+)
+
+func newOpenAIProvider(name, baseURL, apiKey, model string, jsonMode int32) *openAIProvider {
+	p := &openAIProvider{
+		name:    name,
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   model,
+		client: &http.Client{
+			Timeout: 60 * time.Second, // Increased for vision processing
+		},
+	}
+	p.jsonMode.Store(jsonMode)
+	return p
+}
+
+// jsonFormat returns the response_format asking for schema in mode, if any.
+func jsonFormat(mode int32, schema *Schema) *responseFormat {
+	switch mode {
+	case jsonModeSchema:
+		return &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &jsonSchema{Name: schema.Name, Schema: schema.Schema, Strict: true},
+		}
+	case jsonModeObject:
+		return &responseFormat{Type: "json_object"}
+	}
+	return nil
+}
+
+// Message types for API requests
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []interface{}   `json:"messages"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"`
+}
+
+type textMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type visionMessage struct {
+	Role    string          `json:"role"`
+	Content []visionContent `json:"content"`
+}
+
+type visionContent struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type chatResponse struct {
+	Choices []choice `json:"choices"`
+}
+
+type choice struct {
+	Message textMessage `json:"message"`
+}
+
+func (p *openAIProvider) Name() string {
+	return p.name + " " + p.model
+}
+
+func (p *openAIProvider) Complete(req Request) (string, error) {
+	log.Printf("[AI.%s] Sending request to %s...", req.Operation, p.Name())
+
+	reqBody := chatRequest{Model: p.model}
+	if req.Image != "" {
+		reqBody.Messages = []interface{}{
+			visionMessage{
+				Role: "user",
+				Content: []visionContent{
+					{Type: "text", Text: req.Prompt},
+					{Type: "image_url", ImageURL: &imageURL{URL: req.Image}},
+				},
+			},
+		}
+	} else {
+		reqBody.Messages = []interface{}{
+			textMessage{Role: "user", Content: req.Prompt},
+		}
+	}
+
+	mode := jsonModePrompt
+	if req.Schema != nil {
+		mode = p.jsonMode.Load()
+	}
+
+	headers := map[string]string{}
+	if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	var chatResp chatResponse
+	var err error
+	for {
+		reqBody.ResponseFormat = jsonFormat(mode, req.Schema)
+		err = postJSON(p.client, p.baseURL+"/chat/completions", headers, reqBody, &chatResp, req.Operation)
+
+		// Retry a rejected response_format with the next weaker one
+		var apiErr *apiError
+		if mode == jsonModePrompt || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+			break
+		}
+		mode--
+		log.Printf("[AI.%s] %s rejected the response_format, retrying with JSON mode %d", req.Operation, p.Name(), mode)
+	}
+	if err != nil {
+		return "", err
+	}
+	if req.Schema != nil && mode < p.jsonMode.Load() {
+		p.jsonMode.Store(mode)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		log.Printf("[AI.%s] No choices returned in response", req.Operation)
+		return "", fmt.Errorf("no choices returned")
+	}
+
+	content := strings.TrimSpace(chatResp.Choices[0].Message.Content)
+	log.Printf("[AI.%s] Response received, length: %d", req.Operation, len(content))
+	return content, nil
+}
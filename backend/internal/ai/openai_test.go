@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIJSONModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     int32
+		rejected []string // response_format types answered with a 400
+		status   int      // Of other requests
+		wantErr  string
+		want     []string // The response_format type of each request sent, "" for none
+	}{
+		{name: "strict schema", mode: jsonModeSchema, want: []string{"json_schema", "json_schema"}},
+		{name: "JSON mode", mode: jsonModeObject, want: []string{"json_object", "json_object"}},
+		{name: "prompt only", mode: jsonModePrompt, want: []string{"", ""}},
+		{
+			name:     "schema rejected",
+			mode:     jsonModeSchema,
+			rejected: []string{"json_schema"},
+			want:     []string{"json_schema", "json_object", "json_object"},
+		},
+		{
+			name:     "every response_format rejected",
+			mode:     jsonModeSchema,
+			rejected: []string{"json_schema", "json_object"},
+			want:     []string{"json_schema", "json_object", "", ""},
+		},
+		{
+			name:    "other errors",
+			mode:    jsonModeSchema,
+			status:  http.StatusInternalServerError,
+			wantErr: "api error: 500",
+			want:    []string{"json_schema", "json_schema"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var formats []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req chatRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				var format string
+				if req.ResponseFormat != nil {
+					format = req.ResponseFormat.Type
+				}
+				formats = append(formats, format)
+				if slices.Contains(tt.rejected, format) {
+					http.Error(w, `{"error": "response_format is not supported"}`, http.StatusBadRequest)
+					return
+				}
+				if tt.status != 0 {
+					http.Error(w, "server error", tt.status)
+					return
+				}
+				json.NewEncoder(w).Encode(chatResponse{Choices: []choice{{Message: textMessage{Role: "assistant", Content: "{}"}}}})
+			}))
+			defer server.Close()
+
+			p := newOpenAIProvider("Test", server.URL, "", "model", tt.mode)
+			for range 2 {
+				reply, err := p.Complete(Request{Operation: "Flashcards", Prompt: "Cards please", Schema: flashcardsSchema})
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("Complete: got %v, want %q", err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil || reply != "{}" {
+					t.Fatalf("Complete = %q, %v, want the reply", reply, err)
+				}
+			}
+			if !slices.Equal(formats, tt.want) {
+				t.Errorf("sent requests with response_format %q, want %q", formats, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIPlainRequests(t *testing.T) {
+	var format *responseFormat
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		format = req.ResponseFormat
+		json.NewEncoder(w).Encode(chatResponse{Choices: []choice{{Message: textMessage{Role: "assistant", Content: "A summary"}}}})
+	}))
+	defer server.Close()
+
+	p := newOpenAIProvider("Test", server.URL, "", "model", jsonModeSchema)
+	if _, err := p.Complete(Request{Operation: "Summary", Prompt: "Summarize"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if format != nil {
+		t.Errorf("request without a schema sent response_format %+v", format)
+	}
+}
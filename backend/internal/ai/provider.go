@@ -0,0 +1,200 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Providers that can be selected for an operation.
+const (
+	ProviderGroq      = "groq"
+	ProviderOpenAI    = "openai" // Any OpenAI-compatible endpoint, set with OPENAI_BASE_URL
+	ProviderOllama    = "ollama"
+	ProviderAnthropic = "anthropic"
+)
+
+// LLMProvider sends prompts to one model of an LLM vendor.
+type LLMProvider interface {
+	// Complete returns the model's reply to req.
+	Complete(req Request) (string, error)
+	// Name describes the provider and model, for logs.
+	Name() string
+}
+
+// Request is a single-turn prompt, optionally with an image.
+type Request struct {
+	Operation string // For logs, e.g. "Flashcards"
+	Prompt    string
+	Image     string  // data: URL of an image to send with the prompt, for vision models
+	Schema    *Schema // Set to ask for a JSON reply following the schema
+}
+
+// Schema is a JSON schema for a structured reply. Providers that support
+// structured output constrain the model to it; the reply still has to be
+// validated, since not every model or endpoint enforces it.
+type Schema struct {
+	Name   string
+	Schema map[string]any
+}
+
+// ProviderConfig selects the provider, model and endpoint for an operation.
+// Empty fields take the provider's defaults.
+type ProviderConfig struct {
+	Provider string
+	Model    string
+	BaseURL  string
+	APIKey   string
+}
+
+// Config selects a provider for each operation. Rewriting flashcards,
+// writing quiz distractors and grading answers use the Flashcards provider.
+type Config struct {
+	Flashcards ProviderConfig
+	Summary    ProviderConfig
+	OCR        ProviderConfig
+}
+
+// ConfigFromEnv reads the configuration from the environment. LLM_PROVIDER
+// and LLM_MODEL apply to every operation, and LLM_<OPERATION>_PROVIDER and
+// LLM_<OPERATION>_MODEL (FLASHCARDS, SUMMARY or OCR) override them for one.
+// Without any of them every operation uses Groq, as before.
+func ConfigFromEnv() Config {
+	operation := func(name string) ProviderConfig {
+		cfg := ProviderConfig{
+			Provider: firstEnv("LLM_"+name+"_PROVIDER", "LLM_PROVIDER"),
+			Model:    firstEnv("LLM_"+name+"_MODEL", "LLM_MODEL"),
+		}
+		switch strings.ToLower(cfg.Provider) {
+		case "", ProviderGroq:
+			cfg.APIKey = os.Getenv("GROQ_API_KEY")
+		case ProviderOpenAI:
+			cfg.BaseURL, cfg.APIKey = os.Getenv("OPENAI_BASE_URL"), os.Getenv("OPENAI_API_KEY")
+		case ProviderOllama:
+			cfg.BaseURL = os.Getenv("OLLAMA_URL")
+		case ProviderAnthropic:
+			cfg.BaseURL, cfg.APIKey = os.Getenv("ANTHROPIC_BASE_URL"), os.Getenv("ANTHROPIC_API_KEY")
+		}
+		return cfg
+	}
+	return Config{
+		Flashcards: operation("FLASHCARDS"),
+		Summary:    operation("SUMMARY"),
+		OCR:        operation("OCR"),
+	}
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// NewProvider returns the provider cfg selects. An empty model selects the
+// provider's default text model, or its vision model when vision is set.
+func NewProvider(cfg ProviderConfig, vision bool) (LLMProvider, error) {
+	model := func(text, image string) string {
+		if cfg.Model != "" {
+			return cfg.Model
+		}
+		if vision {
+			return image
+		}
+		return text
+	}
+	baseURL := func(def string) string {
+		if cfg.BaseURL != "" {
+			return strings.TrimSuffix(cfg.BaseURL, "/")
+		}
+		return def
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "", ProviderGroq:
+		// Groq only accepts strict JSON schemas on some models, but JSON mode on all
+		return newOpenAIProvider("Groq", baseURL("https://api.groq.com/openai/v1"), cfg.APIKey, model(TextModel, VisionModel), jsonModeObject), nil
+	case ProviderOpenAI:
+		return newOpenAIProvider("OpenAI", baseURL("https://api.openai.com/v1"), cfg.APIKey, model("gpt-4o-mini", "gpt-4o-mini"), jsonModeSchema), nil
+	case ProviderOllama:
+		return newOllamaProvider(baseURL("http://localhost:11434"), model("llama3.1", "llava")), nil
+	case ProviderAnthropic:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("anthropic provider needs an API key")
+		}
+		return newAnthropicProvider(baseURL("https://api.anthropic.com"), cfg.APIKey, model("claude-sonnet-4-5", "claude-sonnet-4-5")), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (want %s, %s, %s or %s)",
+			cfg.Provider, ProviderGroq, ProviderOpenAI, ProviderOllama, ProviderAnthropic)
+	}
+}
+
+// apiError is a response from an LLM API with a status other than 200.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("api error: %d %s", e.StatusCode, e.Body)
+}
+
+// postJSON sends body to url and decodes the JSON response into out.
+func postJSON(client *http.Client, url string, headers map[string]string, body, out any, operation string) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("[AI.%s] Failed to marshal request: %v", operation, err)
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		log.Printf("[AI.%s] Failed to create HTTP request: %v", operation, err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("[AI.%s] HTTP request failed: %v", operation, err)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	log.Printf("[AI.%s] Received response with status: %d", operation, resp.StatusCode)
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		log.Printf("[AI.%s] API error response: %s", operation, string(bodyBytes))
+		return &apiError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		log.Printf("[AI.%s] Failed to decode response: %v", operation, err)
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// splitDataURL returns the media type and base64 data of a data: URL.
+func splitDataURL(url string) (string, string) {
+	meta, data, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !ok {
+		return "image/jpeg", url
+	}
+	mediaType, _, _ := strings.Cut(meta, ";")
+	if mediaType == "" {
+		mediaType = "image/jpeg"
+	}
+	return mediaType, data
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+// llmEnv lists every variable ConfigFromEnv reads.
+var llmEnv = []string{
+	"LLM_PROVIDER", "LLM_MODEL",
+	"LLM_FLASHCARDS_PROVIDER", "LLM_FLASHCARDS_MODEL",
+	"LLM_SUMMARY_PROVIDER", "LLM_SUMMARY_MODEL",
+	"LLM_OCR_PROVIDER", "LLM_OCR_MODEL",
+	"GROQ_API_KEY", "OPENAI_BASE_URL", "OPENAI_API_KEY", "OLLAMA_URL", "ANTHROPIC_BASE_URL", "ANTHROPIC_API_KEY",
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	groq := ProviderConfig{APIKey: "groq-key"}
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Config
+	}{
+		{
+			name: "groq by default",
+			env:  map[string]string{"GROQ_API_KEY": "groq-key"},
+			want: Config{Flashcards: groq, Summary: groq, OCR: groq},
+		},
+		{
+			name: "one provider for everything",
+			env: map[string]string{
+				"LLM_PROVIDER": "openai", "LLM_MODEL": "gpt-4.1",
+				"OPENAI_BASE_URL": "http://localhost:8000/v1", "OPENAI_API_KEY": "openai-key", "GROQ_API_KEY": "groq-key",
+			},
+			want: Config{
+				Flashcards: ProviderConfig{Provider: "openai", Model: "gpt-4.1", BaseURL: "http://localhost:8000/v1", APIKey: "openai-key"},
+				Summary:    ProviderConfig{Provider: "openai", Model: "gpt-4.1", BaseURL: "http://localhost:8000/v1", APIKey: "openai-key"},
+				OCR:        ProviderConfig{Provider: "openai", Model: "gpt-4.1", BaseURL: "http://localhost:8000/v1", APIKey: "openai-key"},
+			},
+		},
+		{
+			name: "per operation",
+			env: map[string]string{
+				"LLM_PROVIDER": "groq", "GROQ_API_KEY": "groq-key",
+				"LLM_OCR_PROVIDER": "ollama", "LLM_OCR_MODEL": "llava:13b", "OLLAMA_URL": "http://gpu:11434",
+				"LLM_SUMMARY_PROVIDER": "Anthropic", "ANTHROPIC_API_KEY": "anthropic-key",
+			},
+			want: Config{
+				Flashcards: ProviderConfig{Provider: "groq", APIKey: "groq-key"},
+				Summary:    ProviderConfig{Provider: "Anthropic", APIKey: "anthropic-key"},
+				OCR:        ProviderConfig{Provider: "ollama", Model: "llava:13b", BaseURL: "http://gpu:11434"},
+			},
+		},
+		{
+			name: "operation model over the shared one",
+			env:  map[string]string{"LLM_MODEL": "shared", "LLM_FLASHCARDS_MODEL": "cards", "GROQ_API_KEY": "groq-key"},
+			want: Config{
+				Flashcards: ProviderConfig{Model: "cards", APIKey: "groq-key"},
+				Summary:    ProviderConfig{Model: "shared", APIKey: "groq-key"},
+				OCR:        ProviderConfig{Model: "shared", APIKey: "groq-key"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range llmEnv {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := ConfigFromEnv(); got != tt.want {
+				t.Errorf("ConfigFromEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ProviderConfig
+		vision      bool
+		wantName    string
+		wantBaseURL string
+		wantErr     string
+	}{
+		{name: "groq", cfg: ProviderConfig{APIKey: "key"}, wantName: "Groq " + TextModel, wantBaseURL: "https://api.groq.com/openai/v1"},
+		{name: "groq vision", cfg: ProviderConfig{Provider: "groq"}, vision: true, wantName: "Groq " + VisionModel, wantBaseURL: "https://api.groq.com/openai/v1"},
+		{name: "openai", cfg: ProviderConfig{Provider: "openai"}, wantName: "OpenAI gpt-4o-mini", wantBaseURL: "https://api.openai.com/v1"},
+		{
+			name:        "openai-compatible server",
+			cfg:         ProviderConfig{Provider: " OpenAI ", Model: "qwen", BaseURL: "http://localhost:8000/v1/"},
+			vision:      true,
+			wantName:    "OpenAI qwen",
+			wantBaseURL: "http://localhost:8000/v1",
+		},
+		{name: "ollama", cfg: ProviderConfig{Provider: "ollama"}, wantName: "Ollama llama3.1", wantBaseURL: "http://localhost:11434"},
+		{name: "ollama vision", cfg: ProviderConfig{Provider: "ollama", BaseURL: "http://gpu:11434"}, vision: true, wantName: "Ollama llava", wantBaseURL: "http://gpu:11434"},
+		{name: "anthropic", cfg: ProviderConfig{Provider: "anthropic", APIKey: "key"}, wantName: "Anthropic claude-sonnet-4-5", wantBaseURL: "https://api.anthropic.com"},
+		{name: "anthropic without a key", cfg: ProviderConfig{Provider: "anthropic"}, wantErr: "needs an API key"},
+		{name: "unknown", cfg: ProviderConfig{Provider: "mistral"}, wantErr: `unknown LLM provider "mistral"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProvider(tt.cfg, tt.vision)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewProvider: got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			var baseURL string
+			switch p := p.(type) {
+			case *openAIProvider:
+				baseURL = p.baseURL
+			case *ollamaProvider:
+				baseURL = p.baseURL
+			case *anthropicProvider:
+				baseURL = p.baseURL
+			}
+			if p.Name() != tt.wantName || baseURL != tt.wantBaseURL {
+				t.Errorf("NewProvider = %s at %s, want %s at %s", p.Name(), baseURL, tt.wantName, tt.wantBaseURL)
+			}
+		})
+	}
+}
+
+func TestNewProviderJSONModes(t *testing.T) {
+	for provider, want := range map[string]int32{"groq": jsonModeObject, "openai": jsonModeSchema} {
+		p, err := NewProvider(ProviderConfig{Provider: provider}, false)
+		if err != nil {
+			t.Fatalf("NewProvider(%s): %v", provider, err)
+		}
+		if mode := p.(*openAIProvider).jsonMode.Load(); mode != want {
+			t.Errorf("%s starts in JSON mode %d, want %d", provider, mode, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package cloze parses and renders cloze-deletion text such as
+// "{{c1::Paris}} is the capital of {{c2::France::country}}".
+package cloze
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// deletionPattern matches {{cN::text}} and {{cN::text::hint}}. Neither the
+// text nor the hint may contain braces, so an unclosed or nested marker is
+// left as it is rather than swallowing the deletions after it.
+var deletionPattern = regexp.MustCompile(`\{\{c(\d+)::([^{}]*?)(?:::([^{}]*?))?\}\}`)
+
+// Indices returns the distinct cloze numbers used in text, in ascending order.
+// Text without any cloze deletions returns nil.
+func Indices(text string) []int32 {
+	seen := make(map[int32]bool)
+	var indices []int32
+	for _, m := range deletionPattern.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || seen[int32(n)] {
+			continue
+		}
+		seen[int32(n)] = true
+		indices = append(indices, int32(n))
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return indices
+}
+
+// Render returns the front and back of the card for one cloze number. The
+// front hides that number's deletions behind "[...]" (or "[hint]") and shows
+// every other deletion; the back shows the full text.
+func Render(text string, index int32) (front, back string) {
+	front = deletionPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := deletionPattern.FindStringSubmatch(s)
+		if m[1] != strconv.Itoa(int(index)) {
+			return m[2]
+		}
+		if hint := strings.TrimSpace(m[3]); hint != "" {
+			return "[" + hint + "]"
+		}
+		return "[...]"
+	})
+	back = deletionPattern.ReplaceAllString(text, "$2")
+	return front, back
+}
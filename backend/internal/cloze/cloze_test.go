@@ -0,0 +1,119 @@
+package cloze
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIndices(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []int32
+	}{
+		{name: "none", text: "Paris is the capital of France", want: nil},
+		{name: "one", text: "{{c1::Paris}} is the capital of France", want: []int32{1}},
+		{name: "sorted", text: "{{c3::Paris}} is the capital of {{c1::France}}", want: []int32{1, 3}},
+		{name: "repeated", text: "{{c1::Paris}}, {{c2::France}} and {{c1::Europe}}", want: []int32{1, 2}},
+		{name: "hint", text: "{{c2::Paris::city}} is in {{c1::France}}", want: []int32{1, 2}},
+		{name: "multi-digit", text: "{{c12::Paris}}", want: []int32{12}},
+		{name: "zero", text: "{{c0::Paris}} and {{c1::France}}", want: []int32{1}},
+		{name: "unclosed", text: "{{c1::Paris is the capital", want: nil},
+		{name: "unclosed before another", text: "{{c1::Paris is the capital of {{c2::France}}", want: []int32{2}},
+		{name: "no number", text: "{{c::Paris}}", want: nil},
+		{name: "single braces", text: "{c1::Paris}", want: nil},
+		{name: "empty deletion", text: "{{c1::}}", want: []int32{1}},
+		{name: "nested", text: "{{c1::capital of {{c2::France}}}}", want: []int32{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Indices(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Indices(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		index     int32
+		wantFront string
+		wantBack  string
+	}{
+		{
+			name:      "one deletion",
+			text:      "{{c1::Paris}} is the capital of France",
+			index:     1,
+			wantFront: "[...] is the capital of France",
+			wantBack:  "Paris is the capital of France",
+		},
+		{
+			name:      "other deletions shown",
+			text:      "{{c1::Paris}} is the capital of {{c2::France}}",
+			index:     2,
+			wantFront: "Paris is the capital of [...]",
+			wantBack:  "Paris is the capital of France",
+		},
+		{
+			name:      "repeated number hides every occurrence",
+			text:      "{{c1::Paris}} and {{c1::Lyon}} are in {{c2::France}}",
+			index:     1,
+			wantFront: "[...] and [...] are in France",
+			wantBack:  "Paris and Lyon are in France",
+		},
+		{
+			name:      "hint",
+			text:      "{{c1::Paris::city}} is the capital of France",
+			index:     1,
+			wantFront: "[city] is the capital of France",
+			wantBack:  "Paris is the capital of France",
+		},
+		{
+			name:      "blank hint",
+			text:      "{{c1::Paris:: }} is the capital of France",
+			index:     1,
+			wantFront: "[...] is the capital of France",
+			wantBack:  "Paris is the capital of France",
+		},
+		{
+			name:      "hint of another number",
+			text:      "{{c1::Paris::city}} is the capital of {{c2::France}}",
+			index:     2,
+			wantFront: "Paris is the capital of [...]",
+			wantBack:  "Paris is the capital of France",
+		},
+		{
+			name:      "unknown number",
+			text:      "{{c1::Paris}} is the capital of France",
+			index:     5,
+			wantFront: "Paris is the capital of France",
+			wantBack:  "Paris is the capital of France",
+		},
+		{
+			name:      "unclosed marker left as is",
+			text:      "{{c1::Paris is the capital of {{c2::France}}",
+			index:     2,
+			wantFront: "{{c1::Paris is the capital of [...]",
+			wantBack:  "{{c1::Paris is the capital of France",
+		},
+		{
+			name:      "nested renders the inner deletion only",
+			text:      "{{c1::capital of {{c2::France}}}}",
+			index:     2,
+			wantFront: "{{c1::capital of [...]}}",
+			wantBack:  "{{c1::capital of France}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			front, back := Render(tt.text, tt.index)
+			if front != tt.wantFront || back != tt.wantBack {
+				t.Errorf("Render(%q, %d) = %q, %q; want %q, %q", tt.text, tt.index, front, back, tt.wantFront, tt.wantBack)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// SuspendFlashcard takes a card out of reviews until it is restored.
+func (c *LearningCore) SuspendFlashcard(ctx context.Context, userID, flashcardID string, restore bool) error {
+	log.Printf("[Core.SuspendFlashcard] Flashcard: %s, restore: %v", flashcardID, restore)
+	if err := c.store.SetFlashcardSuspended(ctx, userID, flashcardID, !restore); err != nil {
+		log.Printf("[Core.SuspendFlashcard] Failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// BuryFlashcard hides a card from reviews until the user's next study day starts.
+func (c *LearningCore) BuryFlashcard(ctx context.Context, userID, flashcardID string, restore bool) error {
+	log.Printf("[Core.BuryFlashcard] Flashcard: %s, restore: %v", flashcardID, restore)
+
+	var until *time.Time
+	if !restore {
+		cal, err := c.calendarFor(ctx, userID)
+		if err != nil {
+			log.Printf("[Core.BuryFlashcard] Failed to get study settings: %v", err)
+			return fmt.Errorf("failed to get study settings: %w", err)
+		}
+		tomorrow := cal.dueAt(c.now(), 1)
+		until = &tomorrow
+	}
+
+	if err := c.store.SetFlashcardBuriedUntil(ctx, userID, flashcardID, until); err != nil {
+		log.Printf("[Core.BuryFlashcard] Failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// FlagFlashcard sets a card's color flag; FLASHCARD_FLAG_NONE clears it.
+func (c *LearningCore) FlagFlashcard(ctx context.Context, userID, flashcardID string, flag learning.FlashcardFlag) error {
+	log.Printf("[Core.FlagFlashcard] Flashcard: %s, flag: %s", flashcardID, flag)
+	if err := c.store.SetFlashcardFlag(ctx, userID, flashcardID, int32(flag)); err != nil {
+		log.Printf("[Core.FlagFlashcard] Failed: %v", err)
+		return err
+	}
+	return nil
+}
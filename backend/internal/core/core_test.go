@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amityadav/landr/internal/fake"
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// testEnv is a core with scripted dependencies on a fresh store, and a user
+// to act as.
+type testEnv struct {
+	core        *LearningCore
+	store       store.Store
+	faults      *faultyStore
+	ai          *fake.AI
+	scraper     *fake.Scraper
+	transcripts *fake.Transcripts
+	userID      string
+}
+
+// newTestEnv returns an environment on a MemoryStore.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	return newTestEnvWith(t, store.NewMemoryStore())
+}
+
+// newTestEnvWith returns an environment on st.
+func newTestEnvWith(t *testing.T, st store.Store) *testEnv {
+	t.Helper()
+	env := &testEnv{
+		store:       st,
+		ai:          &fake.AI{},
+		scraper:     &fake.Scraper{},
+		transcripts: &fake.Transcripts{},
+	}
+	env.faults = &faultyStore{Store: env.store, fail: make(map[string]error)}
+	env.core = NewLearningCore(env.faults, env.scraper, env.ai, env.transcripts, FixedScheduler{})
+	env.core.chunkDelay = 0
+
+	user, err := env.store.CreateUser(context.Background(), "test@example.com", "Test", "google-test", "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	env.userID = user.Id
+	return env
+}
+
+// addMaterial creates a material of the user with the given cards and
+// returns the IDs of both.
+func (env *testEnv) addMaterial(t *testing.T, content string, cards ...*learning.Flashcard) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	materialID, err := env.store.CreateMaterial(ctx, env.userID, "TEXT", content, "Title")
+	if err != nil {
+		t.Fatalf("CreateMaterial: %v", err)
+	}
+	if len(cards) == 0 {
+		return materialID, nil
+	}
+	ids, err := env.store.CreateFlashcards(ctx, materialID, cards)
+	if err != nil {
+		t.Fatalf("CreateFlashcards: %v", err)
+	}
+	return materialID, ids
+}
+
+// faultyStore makes the store methods named in fail return their error, in
+// the store itself and in the transactions it hands out.
+type faultyStore struct {
+	store.Store
+	fail map[string]error
+}
+
+func (s *faultyStore) WithTx(ctx context.Context, fn func(tx store.Store) error) error {
+	return s.Store.WithTx(ctx, func(tx store.Store) error {
+		return fn(&faultyStore{Store: tx, fail: s.fail})
+	})
+}
+
+func (s *faultyStore) CreateMaterial(ctx context.Context, userID, matType, content, title string) (string, error) {
+	if err := s.fail["CreateMaterial"]; err != nil {
+		return "", err
+	}
+	return s.Store.CreateMaterial(ctx, userID, matType, content, title)
+}
+
+func (s *faultyStore) UpdateMaterialSummary(ctx context.Context, userID, materialID, summary string) error {
+	if err := s.fail["UpdateMaterialSummary"]; err != nil {
+		return err
+	}
+	return s.Store.UpdateMaterialSummary(ctx, userID, materialID, summary)
+}
+
+func (s *faultyStore) CreateTag(ctx context.Context, userID, name string) (string, error) {
+	if err := s.fail["CreateTag"]; err != nil {
+		return "", err
+	}
+	return s.Store.CreateTag(ctx, userID, name)
+}
+
+func (s *faultyStore) AddMaterialTags(ctx context.Context, materialID string, tagIDs []string) error {
+	if err := s.fail["AddMaterialTags"]; err != nil {
+		return err
+	}
+	return s.Store.AddMaterialTags(ctx, materialID, tagIDs)
+}
+
+func (s *faultyStore) CreateMaterialChunks(ctx context.Context, materialID string, chunks []string) error {
+	if err := s.fail["CreateMaterialChunks"]; err != nil {
+		return err
+	}
+	return s.Store.CreateMaterialChunks(ctx, materialID, chunks)
+}
+
+func (s *faultyStore) FailMaterialChunk(ctx context.Context, materialID string, index int32, reason string) error {
+	if err := s.fail["FailMaterialChunk"]; err != nil {
+		return err
+	}
+	return s.Store.FailMaterialChunk(ctx, materialID, index, reason)
+}
+
+func (s *faultyStore) SaveChunkFlashcards(ctx context.Context, materialID string, index int32, cards []*learning.Flashcard) (int32, error) {
+	if err := s.fail["SaveChunkFlashcards"]; err != nil {
+		return 0, err
+	}
+	return s.Store.SaveChunkFlashcards(ctx, materialID, index, cards)
+}
+
+func (s *faultyStore) CreateIngestionJob(ctx context.Context, job *store.IngestionJob) error {
+	if err := s.fail["CreateIngestionJob"]; err != nil {
+		return err
+	}
+	return s.Store.CreateIngestionJob(ctx, job)
+}
+
+func (s *faultyStore) UpdateIngestionJob(ctx context.Context, job *store.IngestionJob) error {
+	if err := s.fail["UpdateIngestionJob"]; err != nil {
+		return err
+	}
+	return s.Store.UpdateIngestionJob(ctx, job)
+}
+
+func (s *faultyStore) RecordReview(ctx context.Context, schedule store.FlashcardSchedule, entry *store.ReviewLog) error {
+	if err := s.fail["RecordReview"]; err != nil {
+		return err
+	}
+	return s.Store.RecordReview(ctx, schedule, entry)
+}
+
+func (s *faultyStore) SaveQuizAttempt(ctx context.Context, attempt *store.QuizAttempt) error {
+	if err := s.fail["SaveQuizAttempt"]; err != nil {
+		return err
+	}
+	return s.Store.SaveQuizAttempt(ctx, attempt)
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"math"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+const (
+	// DefaultDesiredRetention is the probability of recall FSRS aims for when a card comes due.
+	DefaultDesiredRetention = 0.9
+
+	fsrsDecay           = -0.5
+	fsrsFactor          = 19.0 / 81.0 // 0.9^(1/decay) - 1, so that R(S, S) = 90%
+	fsrsMaxIntervalDays = 36500
+)
+
+// DefaultFSRSWeights are the published FSRS-5 default parameters, used until
+// a user has enough review history to fit their own.
+var DefaultFSRSWeights = []float64{
+	0.40255, 1.18385, 3.173, 15.69105, 7.1949, 0.5345, 1.4604, 0.0046, 1.54575, 0.1192,
+	1.01925, 1.9395, 0.11, 0.29605, 2.2698, 0.2315, 2.9898, 0.51655, 0.6621,
+}
+
+// fsrsWeightBounds are the ranges each FSRS weight is clamped to while optimizing.
+var fsrsWeightBounds = [][2]float64{
+	{0.01, 100}, {0.01, 100}, {0.01, 100}, {0.01, 100},
+	{1, 10}, {0.001, 4}, {0.001, 4}, {0.001, 0.75},
+	{0, 4.5}, {0, 0.8}, {0.001, 3.5}, {0.001, 5},
+	{0.001, 0.25}, {0.001, 0.9}, {0, 4}, {0, 1},
+	{1, 6}, {0, 2}, {0, 2},
+}
+
+// FSRSScheduler implements the Free Spaced Repetition Scheduler (FSRS-5). Each
+// card tracks a memory stability (days until recall drops to 90%) and a
+// difficulty between 1 and 10, and is scheduled for when its predicted
+// retrievability falls to the desired retention.
+type FSRSScheduler struct {
+	Weights          []float64
+	DesiredRetention float64
+}
+
+// NewFSRSScheduler returns an FSRS scheduler with the given weights, falling
+// back to the defaults when weights is not a full parameter set.
+func NewFSRSScheduler(weights []float64) FSRSScheduler {
+	if len(weights) != len(DefaultFSRSWeights) {
+		weights = DefaultFSRSWeights
+	}
+	return FSRSScheduler{Weights: weights, DesiredRetention: DefaultDesiredRetention}
+}
+
+func (FSRSScheduler) Name() string { return SchedulerFSRS }
+
+func (f FSRSScheduler) Schedule(card *learning.Flashcard, grade learning.ReviewGrade, elapsedDays int32) store.FlashcardSchedule {
+	m := fsrsModel(f.Weights)
+	g := fsrsGrade(grade)
+
+	var stability, difficulty float64
+	if card.LastReviewedAt == nil || card.Stability <= 0 {
+		stability, difficulty = m.initStability(g), m.initDifficulty(g)
+	} else {
+		stability, difficulty = m.next(card.Stability, card.Difficulty, float64(elapsedDays), g)
+	}
+
+	repetitions := card.Repetitions + 1
+	intervalDays := int32(1)
+	if g == 1 {
+		repetitions = 0
+	} else {
+		intervalDays = m.intervalDays(stability, f.desiredRetention())
+	}
+
+	stage := repetitions
+	if stage > MaxStage {
+		stage = MaxStage
+	}
+
+	return store.FlashcardSchedule{
+		Stage:        stage,
+		EaseFactor:   easeOrDefault(card.EaseFactor),
+		IntervalDays: intervalDays,
+		Repetitions:  repetitions,
+		Stability:    stability,
+		Difficulty:   difficulty,
+	}
+}
+
+func (f FSRSScheduler) desiredRetention() float64 {
+	if f.DesiredRetention <= 0 || f.DesiredRetention >= 1 {
+		return DefaultDesiredRetention
+	}
+	return f.DesiredRetention
+}
+
+// fsrsGrade converts a review grade to the 1-4 rating FSRS works with.
+// Unknown grades count as Good, like an unspecified one, since the rating
+// indexes the weights.
+func fsrsGrade(grade learning.ReviewGrade) float64 {
+	if grade < learning.ReviewGrade_REVIEW_GRADE_AGAIN || grade > learning.ReviewGrade_REVIEW_GRADE_EASY {
+		return float64(learning.ReviewGrade_REVIEW_GRADE_GOOD)
+	}
+	return float64(grade)
+}
+
+// fsrsModel holds the FSRS formulas for one set of weights.
+type fsrsModel []float64
+
+// retrievability is the predicted probability of recall after elapsed days.
+func (w fsrsModel) retrievability(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+func (w fsrsModel) intervalDays(stability, retention float64) int32 {
+	days := math.Round(stability / fsrsFactor * (math.Pow(retention, 1/fsrsDecay) - 1))
+	return int32(math.Max(1, math.Min(days, fsrsMaxIntervalDays)))
+}
+
+func (w fsrsModel) initStability(g float64) float64 {
+	return math.Max(w[int(g)-1], 0.1)
+}
+
+func (w fsrsModel) initDifficulty(g float64) float64 {
+	return clampDifficulty(w[4] - math.Exp(w[5]*(g-1)) + 1)
+}
+
+// next returns the stability and difficulty after a review with rating g
+// taken elapsedDays after the previous one.
+func (w fsrsModel) next(stability, difficulty, elapsedDays, g float64) (float64, float64) {
+	// Difficulty moves towards harder/easier with linear damping, then
+	// reverts slightly towards the initial difficulty of an "Easy" card.
+	d := difficulty - w[6]*(g-3)*(10-difficulty)/9
+	d = clampDifficulty(w[7]*w.initDifficulty(4) + (1-w[7])*d)
+
+	if elapsedDays < 1 {
+		// Same-day review
+		return math.Max(stability*math.Exp(w[17]*(g-3+w[18])), 0.1), d
+	}
+
+	r := w.retrievability(elapsedDays, stability)
+	if g == 1 {
+		s := w[11] * math.Pow(difficulty, -w[12]) * (math.Pow(stability+1, w[13]) - 1) * math.Exp(w[14]*(1-r))
+		return math.Max(math.Min(s, stability), 0.1), d
+	}
+
+	hardPenalty, easyBonus := 1.0, 1.0
+	if g == 2 {
+		hardPenalty = w[15]
+	}
+	if g == 4 {
+		easyBonus = w[16]
+	}
+	s := stability * (math.Exp(w[8])*(11-difficulty)*math.Pow(stability, -w[9])*(math.Exp(w[10]*(1-r))-1)*hardPenalty*easyBonus + 1)
+	return math.Max(s, 0.1), d
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Max(1, math.Min(d, 10))
+}
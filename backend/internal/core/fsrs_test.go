@@ -0,0 +1,96 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFSRSDefaultWeights(t *testing.T) {
+	type step struct {
+		stability  float64
+		difficulty float64
+		days       int32
+	}
+	// Computed from the published FSRS-5 formulas for the default weights,
+	// each review taken the day the previous one scheduled.
+	tests := []struct {
+		name   string
+		grades []learning.ReviewGrade
+		want   []step
+	}{
+		{
+			name:   "good every time",
+			grades: []learning.ReviewGrade{good, good, good, good, good},
+			want: []step{
+				{3.1730, 5.2824, 3},
+				{10.7389, 5.2730, 11},
+				{34.5776, 5.2635, 35},
+				{100.7483, 5.2542, 101},
+				{269.2838, 5.2448, 269},
+			},
+		},
+		{
+			name:   "lapse",
+			grades: []learning.ReviewGrade{good, good, again, good},
+			want: []step{
+				{3.1730, 5.2824, 3},
+				{10.7389, 5.2730, 11},
+				{2.1858, 6.7906, 1},
+				{4.2288, 6.7742, 4},
+			},
+		},
+		{
+			name:   "easy then hard",
+			grades: []learning.ReviewGrade{easy, hard, good},
+			want: []step{
+				{15.6911, 3.2245, 16},
+				{26.1125, 4.3189, 26},
+				{85.4098, 4.3138, 85},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFSRSScheduler(nil)
+			card := &learning.Flashcard{}
+			for i, grade := range tt.grades {
+				s := f.Schedule(card, grade, card.IntervalDays)
+				want := tt.want[i]
+				if math.Abs(s.Stability-want.stability) > 1e-4 || math.Abs(s.Difficulty-want.difficulty) > 1e-4 || s.IntervalDays != want.days {
+					t.Fatalf("review %d (%s): got stability %.4f, difficulty %.4f, %d days; want %.4f, %.4f, %d days",
+						i+1, grade, s.Stability, s.Difficulty, s.IntervalDays, want.stability, want.difficulty, want.days)
+				}
+				card = &learning.Flashcard{
+					Stability:      s.Stability,
+					Difficulty:     s.Difficulty,
+					IntervalDays:   s.IntervalDays,
+					Repetitions:    s.Repetitions,
+					LastReviewedAt: timestamppb.Now(),
+				}
+			}
+		})
+	}
+}
+
+func TestFSRSRetrievability(t *testing.T) {
+	m := fsrsModel(DefaultFSRSWeights)
+	for _, stability := range []float64{0.5, 3.173, 42} {
+		// Stability is the number of days until recall drops to 90%
+		if r := m.retrievability(stability, stability); math.Abs(r-0.9) > 1e-9 {
+			t.Errorf("retrievability(%v, %v) = %v, want 0.9", stability, stability, r)
+		}
+		if r := m.retrievability(0, stability); r != 1 {
+			t.Errorf("retrievability(0, %v) = %v, want 1", stability, r)
+		}
+	}
+	if days := m.intervalDays(42, 0.9); days != 42 {
+		t.Errorf("intervalDays(42, 0.9) = %d, want 42", days)
+	}
+	if days := m.intervalDays(1e9, 0.9); days != fsrsMaxIntervalDays {
+		t.Errorf("intervalDays(1e9, 0.9) = %d, want %d", days, fsrsMaxIntervalDays)
+	}
+}
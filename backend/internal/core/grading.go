@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// AnswerGrade is the AI's assessment of a typed answer.
+type AnswerGrade struct {
+	Score          float64
+	SuggestedGrade learning.ReviewGrade
+	Explanation    string
+	Schedule       *store.FlashcardSchedule // Set when the review was recorded
+}
+
+// gradeFromScore maps an answer score between 0 and 1 onto a review grade.
+func gradeFromScore(score float64) learning.ReviewGrade {
+	switch {
+	case score < 0.5:
+		return learning.ReviewGrade_REVIEW_GRADE_AGAIN
+	case score < 0.75:
+		return learning.ReviewGrade_REVIEW_GRADE_HARD
+	case score < 0.95:
+		return learning.ReviewGrade_REVIEW_GRADE_GOOD
+	default:
+		return learning.ReviewGrade_REVIEW_GRADE_EASY
+	}
+}
+
+// GradeTypedAnswer has the AI compare a typed answer with the card's answer and
+// suggests a review grade. With record, the review is also recorded with that grade.
+func (c *LearningCore) GradeTypedAnswer(ctx context.Context, userID, flashcardID, typed string, record bool, meta ReviewMeta) (*AnswerGrade, error) {
+	log.Printf("[Core.GradeTypedAnswer] Grading flashcard: %s, record: %v", flashcardID, record)
+
+	card, err := c.store.GetFlashcard(ctx, userID, flashcardID)
+	if err != nil {
+		log.Printf("[Core.GradeTypedAnswer] Failed to get flashcard: %v", err)
+		return nil, fmt.Errorf("failed to get flashcard: %w", err)
+	}
+
+	// Grade against what the user was shown, which differs for cloze and reverse cards
+	renderFlashcards(card)
+	score, explanation, err := c.ai.GradeAnswer(card.Front, card.Back, typed)
+	if err != nil {
+		log.Printf("[Core.GradeTypedAnswer] AI grading failed: %v", err)
+		return nil, fmt.Errorf("failed to grade answer: %w", err)
+	}
+
+	result := &AnswerGrade{
+		Score:          score,
+		SuggestedGrade: gradeFromScore(score),
+		Explanation:    explanation,
+	}
+	log.Printf("[Core.GradeTypedAnswer] Score: %.2f, suggested grade: %s", score, result.SuggestedGrade)
+
+	if record {
+		result.Schedule, err = c.ReviewFlashcard(ctx, userID, flashcardID, result.SuggestedGrade, meta)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
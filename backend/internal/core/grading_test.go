@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+func TestGradeFromScore(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  learning.ReviewGrade
+	}{
+		{0, again},
+		{0.49, again},
+		{0.5, hard},
+		{0.74, hard},
+		{0.75, good},
+		{0.94, good},
+		{0.95, easy},
+		{1, easy},
+	}
+	for _, tt := range tests {
+		if got := gradeFromScore(tt.score); got != tt.want {
+			t.Errorf("gradeFromScore(%v) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestGradeTypedAnswer(t *testing.T) {
+	errAI := errors.New("ai unavailable")
+	tests := []struct {
+		name      string
+		score     float64
+		aiErr     error
+		record    bool
+		wantGrade learning.ReviewGrade
+		wantErr   error
+	}{
+		{name: "suggested only", score: 0.8, wantGrade: good},
+		{name: "recorded", score: 0.8, record: true, wantGrade: good},
+		{name: "wrong answer recorded", score: 0.1, record: true, wantGrade: again},
+		{name: "AI fails", aiErr: errAI, record: true, wantErr: errAI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			_, ids := env.addMaterial(t, "Capitals", &learning.Flashcard{Question: "Capital of France?", Answer: "Paris"})
+			env.ai.AddGrade(tt.score, "Close enough", tt.aiErr)
+
+			grade, err := env.core.GradeTypedAnswer(ctx, env.userID, ids[0], "paris", tt.record, ReviewMeta{})
+			logs, logErr := env.store.GetReviewLogs(ctx, env.userID)
+			if logErr != nil {
+				t.Fatalf("GetReviewLogs: %v", logErr)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GradeTypedAnswer: got %v, want %v", err, tt.wantErr)
+				}
+				if len(logs) != 0 {
+					t.Errorf("%d reviews recorded for an answer that wasn't graded, want none", len(logs))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GradeTypedAnswer: %v", err)
+			}
+			if grade.Score != tt.score || grade.SuggestedGrade != tt.wantGrade || grade.Explanation != "Close enough" {
+				t.Errorf("got score %v, grade %s, explanation %q; want %v, %s and the AI's",
+					grade.Score, grade.SuggestedGrade, grade.Explanation, tt.score, tt.wantGrade)
+			}
+
+			if !tt.record {
+				if len(logs) != 0 || grade.Schedule != nil {
+					t.Errorf("%d reviews recorded without record, want none", len(logs))
+				}
+				return
+			}
+			if len(logs) != 1 || logs[0].Grade != tt.wantGrade || grade.Schedule == nil {
+				t.Fatalf("recorded %v, want one review graded %s", logs, tt.wantGrade)
+			}
+		})
+	}
+}
+
+func TestGradeTypedAnswerOfOtherUser(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	_, ids := env.addMaterial(t, "Capitals", &learning.Flashcard{Question: "Capital of France?", Answer: "Paris"})
+	other, err := env.store.CreateUser(ctx, "other@example.com", "Other", "google-other", "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := env.core.GradeTypedAnswer(ctx, other.Id, ids[0], "Paris", true, ReviewMeta{}); !errors.Is(err, store.ErrPermissionDenied) {
+		t.Fatalf("GradeTypedAnswer: got %v, want %v", err, store.ErrPermissionDenied)
+	}
+	if calls := env.ai.Calls(); len(calls) != 0 {
+		t.Errorf("AI called %v for another user's card", calls)
+	}
+}
@@ -0,0 +1,285 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// ingestionLease is how long a worker owns a job without reporting
+	// progress before another worker may take it over.
+	ingestionLease = 10 * time.Minute
+	// maxIngestionAttempts is how many times a job is picked up before it is failed.
+	maxIngestionAttempts = 3
+	// ingestionPollInterval is how often idle workers look for jobs queued
+	// elsewhere, e.g. by another server or before a restart.
+	ingestionPollInterval = 5 * time.Second
+	// ingestionWatchInterval is how often watchers reload a job whose worker
+	// may be on another server.
+	ingestionWatchInterval = 2 * time.Second
+)
+
+// ingestionTypeRetry is the type of jobs that finish an existing material's chunks.
+const ingestionTypeRetry = "RETRY"
+
+// ingestionProgress is told each stage ingestion reaches, with the chunk
+// being generated and the number of chunks while GENERATING.
+type ingestionProgress func(stage learning.IngestionStage, chunk, totalChunks int32)
+
+// ingestionEvents wakes idle workers when a job is queued and watchers when
+// a job they follow changes.
+type ingestionEvents struct {
+	queued chan struct{}
+
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]bool
+}
+
+func newIngestionEvents() *ingestionEvents {
+	return &ingestionEvents{
+		queued:   make(chan struct{}, 1),
+		watchers: make(map[string]map[chan struct{}]bool),
+	}
+}
+
+func (e *ingestionEvents) jobQueued() {
+	select {
+	case e.queued <- struct{}{}:
+	default: // A worker is already due to look
+	}
+}
+
+func (e *ingestionEvents) watch(jobID string) chan struct{} {
+	ch := make(chan struct{}, 1)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watchers[jobID] == nil {
+		e.watchers[jobID] = make(map[chan struct{}]bool)
+	}
+	e.watchers[jobID][ch] = true
+	return ch
+}
+
+func (e *ingestionEvents) unwatch(jobID string, ch chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.watchers[jobID], ch)
+	if len(e.watchers[jobID]) == 0 {
+		delete(e.watchers, jobID)
+	}
+}
+
+func (e *ingestionEvents) jobChanged(jobID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.watchers[jobID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// AddMaterial queues material to be turned into flashcards and returns the
+// job ID to follow with WatchIngestionJob.
+func (c *LearningCore) AddMaterial(ctx context.Context, userID, matType, content, imageData string, allowCloze, generateReverse bool) (string, error) {
+	log.Printf("[Core.AddMaterial] Queueing - UserID: %s, Type: %s", userID, matType)
+	job := &store.IngestionJob{
+		UserID:          userID,
+		Type:            matType,
+		Content:         content,
+		ImageData:       imageData,
+		AllowCloze:      allowCloze,
+		GenerateReverse: generateReverse,
+	}
+	if err := c.store.CreateIngestionJob(ctx, job); err != nil {
+		log.Printf("[Core.AddMaterial] Failed to queue job: %v", err)
+		return "", err
+	}
+	c.ingestion.jobQueued()
+	log.Printf("[Core.AddMaterial] Queued job: %s", job.ID)
+	return job.ID, nil
+}
+
+// RetryFailedChunks queues a job that generates the cards of a material's
+// failed or unfinished chunks and returns its ID.
+func (c *LearningCore) RetryFailedChunks(ctx context.Context, userID, materialID string, allowCloze, generateReverse bool) (string, error) {
+	log.Printf("[Core.RetryFailedChunks] Material: %s", materialID)
+	chunks, err := c.store.GetMaterialChunks(ctx, userID, materialID)
+	if err != nil {
+		log.Printf("[Core.RetryFailedChunks] Failed to load chunks: %v", err)
+		return "", err
+	}
+
+	var pending int
+	for _, chunk := range chunks {
+		if chunk.Status != store.ChunkDone {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return "", fmt.Errorf("material %s: %w", materialID, store.ErrNoFailedChunks)
+	}
+
+	job := &store.IngestionJob{
+		UserID:          userID,
+		Type:            ingestionTypeRetry,
+		MaterialID:      materialID,
+		AllowCloze:      allowCloze,
+		GenerateReverse: generateReverse,
+	}
+	if err := c.store.CreateIngestionJob(ctx, job); err != nil {
+		log.Printf("[Core.RetryFailedChunks] Failed to queue job: %v", err)
+		return "", err
+	}
+	c.ingestion.jobQueued()
+	log.Printf("[Core.RetryFailedChunks] Queued job %s for %d chunks", job.ID, pending)
+	return job.ID, nil
+}
+
+// StartIngestionWorkers starts n workers that process queued materials until ctx is done.
+func (c *LearningCore) StartIngestionWorkers(ctx context.Context, n int) {
+	log.Printf("[Core.Ingestion] Starting %d ingestion workers", n)
+	for i := 0; i < n; i++ {
+		go c.ingestionWorker(ctx, i+1)
+	}
+}
+
+func (c *LearningCore) ingestionWorker(ctx context.Context, worker int) {
+	for {
+		job, err := c.store.ClaimIngestionJob(ctx, time.Now().Add(ingestionLease), maxIngestionAttempts)
+		if err != nil {
+			log.Printf("[Core.Ingestion] Worker %d failed to claim a job: %v", worker, err)
+		}
+		if job != nil {
+			c.runIngestionJob(ctx, worker, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.ingestion.queued:
+		case <-time.After(ingestionPollInterval):
+		}
+	}
+}
+
+func (c *LearningCore) runIngestionJob(ctx context.Context, worker int, job *store.IngestionJob) {
+	log.Printf("[Core.Ingestion] Worker %d processing job %s (attempt %d)", worker, job.ID, job.Attempts)
+
+	// Losing the lease means another worker has the job now, so stop working on it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	save := func() {
+		if err := c.store.UpdateIngestionJob(ctx, job); err != nil {
+			log.Printf("[Core.Ingestion] Failed to save job %s: %v", job.ID, err)
+			if errors.Is(err, store.ErrIngestionLeaseLost) {
+				cancel()
+			}
+			return
+		}
+		c.ingestion.jobChanged(job.ID)
+	}
+	progress := func(stage learning.IngestionStage, chunk, totalChunks int32) {
+		job.Stage, job.Chunk, job.TotalChunks = stage, chunk, totalChunks
+		job.LeaseExpiresAt = time.Now().Add(ingestionLease)
+		save()
+	}
+
+	result, err := c.ingestMaterial(ctx, job, progress)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down or lost the lease; the job is picked up again once its lease runs out
+		log.Printf("[Core.Ingestion] Job %s interrupted: %v", job.ID, err)
+		return
+	}
+
+	job.Chunk = 0
+	if result != nil {
+		job.Title, job.Tags, job.FlashcardsCreated = result.Title, result.Tags, result.FlashcardsCreated
+		job.TotalChunks, job.FailedChunks = result.TotalChunks, result.FailedChunks
+	}
+	if err != nil {
+		log.Printf("[Core.Ingestion] Job %s failed: %v", job.ID, err)
+		job.Stage = learning.IngestionStage_INGESTION_STAGE_FAILED
+		job.Error = err.Error()
+	} else {
+		log.Printf("[Core.Ingestion] Job %s succeeded - MaterialID: %s, Cards: %d", job.ID, job.MaterialID, job.FlashcardsCreated)
+		job.Stage = learning.IngestionStage_INGESTION_STAGE_SUCCEEDED
+		job.Error = ""
+	}
+	save()
+}
+
+// WatchIngestionJob calls send with the job's state now and whenever its
+// stage or chunk changes, until the job finishes or ctx is done.
+func (c *LearningCore) WatchIngestionJob(ctx context.Context, userID, jobID string, send func(*learning.IngestionJob) error) error {
+	log.Printf("[Core.WatchIngestionJob] Watching job: %s", jobID)
+	changed := c.ingestion.watch(jobID)
+	defer c.ingestion.unwatch(jobID, changed)
+
+	ticker := time.NewTicker(ingestionWatchInterval)
+	defer ticker.Stop()
+
+	var last *learning.IngestionJob
+	for {
+		job, err := c.store.GetIngestionJob(ctx, userID, jobID)
+		if err != nil {
+			log.Printf("[Core.WatchIngestionJob] Failed to load job: %v", err)
+			return err
+		}
+
+		update := ingestionJobProto(job)
+		if last == nil || update.Stage != last.Stage || update.Chunk != last.Chunk || update.TotalChunks != last.TotalChunks {
+			if err := send(update); err != nil {
+				return err
+			}
+			last = update
+		}
+		if isIngestionDone(job.Stage) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+func isIngestionDone(stage learning.IngestionStage) bool {
+	return stage == learning.IngestionStage_INGESTION_STAGE_SUCCEEDED || stage == learning.IngestionStage_INGESTION_STAGE_FAILED
+}
+
+func ingestionJobProto(job *store.IngestionJob) *learning.IngestionJob {
+	pb := &learning.IngestionJob{
+		Id:          job.ID,
+		Stage:       job.Stage,
+		Chunk:       job.Chunk,
+		TotalChunks: job.TotalChunks,
+		Error:       job.Error,
+		UpdatedAt:   timestamppb.New(job.UpdatedAt),
+	}
+	if isIngestionDone(job.Stage) && job.MaterialID != "" {
+		pb.Result = &learning.AddMaterialResponse{
+			MaterialId:        job.MaterialID,
+			FlashcardsCreated: job.FlashcardsCreated,
+			Title:             job.Title,
+			Tags:              job.Tags,
+			JobId:             job.ID,
+			TotalChunks:       job.TotalChunks,
+			FailedChunks:      job.FailedChunks,
+		}
+	}
+	return pb
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amityadav/landr/internal/ai"
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+func TestAddMaterial(t *testing.T) {
+	cards := []*learning.Flashcard{
+		{Question: "What is Go?", Answer: "A programming language"},
+		{Question: "Who made Go?", Answer: "Google"},
+	}
+	generated := ai.ChunkResult{Title: "Go", Tags: []string{"go"}, Flashcards: cards}
+
+	tests := []struct {
+		name      string
+		matType   string
+		content   string
+		imageData string
+		reverse   bool
+		script    func(env *testEnv)
+		wantErr   string // Empty if the job succeeds
+		wantCards int
+		wantSum   string
+	}{
+		{
+			name:    "text",
+			matType: "TEXT",
+			content: "Go is a programming language made at Google.",
+			script: func(env *testEnv) {
+				env.ai.AddSummary("About Go", nil)
+				env.ai.AddChunk(generated)
+			},
+			wantCards: 2,
+			wantSum:   "About Go",
+		},
+		{
+			name:    "reverse cards",
+			matType: "TEXT",
+			content: "Go is a programming language made at Google.",
+			reverse: true,
+			script: func(env *testEnv) {
+				env.ai.AddSummary("About Go", nil)
+				env.ai.AddChunk(generated)
+			},
+			wantCards: 4,
+			wantSum:   "About Go",
+		},
+		{
+			name:    "summary fails",
+			matType: "TEXT",
+			content: "Go is a programming language made at Google.",
+			script: func(env *testEnv) {
+				env.ai.AddSummary("", errors.New("model overloaded"))
+				env.ai.AddChunk(generated)
+			},
+			wantCards: 2,
+		},
+		{
+			name:    "link",
+			matType: "LINK",
+			content: "https://go.dev",
+			script: func(env *testEnv) {
+				env.scraper.AddPage("https://go.dev", "Go is a programming language made at Google.", nil)
+				env.ai.AddSummary("About Go", nil)
+				env.ai.AddChunk(generated)
+			},
+			wantCards: 2,
+			wantSum:   "About Go",
+		},
+		{
+			name:    "link that can't be scraped",
+			matType: "LINK",
+			content: "https://go.dev",
+			script: func(env *testEnv) {
+				env.scraper.AddPage("https://go.dev", "", errors.New("403 Forbidden"))
+			},
+			wantErr: "failed to scrape url",
+		},
+		{
+			name:    "youtube",
+			matType: "YOUTUBE",
+			content: "https://youtu.be/go",
+			script: func(env *testEnv) {
+				env.transcripts.AddTranscript("https://youtu.be/go", "Go is a programming language made at Google.", nil)
+				env.ai.AddSummary("About Go", nil)
+				env.ai.AddChunk(generated)
+			},
+			wantCards: 2,
+			wantSum:   "About Go",
+		},
+		{
+			name:    "image without image data",
+			matType: "IMAGE",
+			wantErr: "image_data required",
+		},
+		{
+			name:    "no cards generated",
+			matType: "TEXT",
+			content: "Go is a programming language made at Google.",
+			script: func(env *testEnv) {
+				env.ai.AddSummary("About Go", nil)
+				env.ai.AddChunk(ai.ChunkResult{Error: errors.New("invalid flashcards")})
+			},
+			wantErr: "invalid flashcards",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			env := newTestEnv(t)
+			if tt.script != nil {
+				tt.script(env)
+			}
+			env.core.StartIngestionWorkers(ctx, 1)
+
+			jobID, err := env.core.AddMaterial(ctx, env.userID, tt.matType, tt.content, tt.imageData, false, tt.reverse)
+			if err != nil {
+				t.Fatalf("AddMaterial: %v", err)
+			}
+			if err := env.core.WatchIngestionJob(ctx, env.userID, jobID, func(*learning.IngestionJob) error { return nil }); err != nil {
+				t.Fatalf("WatchIngestionJob: %v", err)
+			}
+			job, err := env.store.GetIngestionJob(ctx, env.userID, jobID)
+			if err != nil {
+				t.Fatalf("GetIngestionJob: %v", err)
+			}
+
+			_, total, err := env.store.GetDueMaterials(ctx, env.userID, 1, 10)
+			if err != nil {
+				t.Fatalf("GetDueMaterials: %v", err)
+			}
+			if tt.wantErr != "" {
+				if job.Stage != learning.IngestionStage_INGESTION_STAGE_FAILED || !strings.Contains(job.Error, tt.wantErr) {
+					t.Fatalf("job ended %s with error %q, want it failed with %q", job.Stage, job.Error, tt.wantErr)
+				}
+				if total != 0 || job.MaterialID != "" {
+					t.Errorf("%d materials saved for a failed job, want none", total)
+				}
+				return
+			}
+			if job.Stage != learning.IngestionStage_INGESTION_STAGE_SUCCEEDED {
+				t.Fatalf("job ended %s with error %q, want it succeeded", job.Stage, job.Error)
+			}
+			if total != 1 || job.Title != "Go" || job.FlashcardsCreated != int32(tt.wantCards) {
+				t.Errorf("job saved %d materials titled %q with %d cards, want 1 titled %q with %d",
+					total, job.Title, job.FlashcardsCreated, "Go", tt.wantCards)
+			}
+
+			stored, err := env.store.GetDueFlashcards(ctx, env.userID, job.MaterialID)
+			if err != nil {
+				t.Fatalf("GetDueFlashcards: %v", err)
+			}
+			if len(stored) != tt.wantCards {
+				t.Errorf("material has %d cards, want %d", len(stored), tt.wantCards)
+			}
+			_, summary, _, err := env.store.GetMaterialContent(ctx, env.userID, job.MaterialID)
+			if err != nil {
+				t.Fatalf("GetMaterialContent: %v", err)
+			}
+			if summary != tt.wantSum {
+				t.Errorf("summary = %q, want %q", summary, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestAddMaterialStoreFailure(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	errQueue := errors.New("disk full")
+	env.faults.fail["CreateIngestionJob"] = errQueue
+
+	if _, err := env.core.AddMaterial(ctx, env.userID, "TEXT", "Some content", "", false, false); !errors.Is(err, errQueue) {
+		t.Fatalf("AddMaterial: got %v, want %v", err, errQueue)
+	}
+	if calls := env.ai.Calls(); len(calls) != 0 {
+		t.Errorf("AI called %v for a job that was never queued", calls)
+	}
+}
+
+func TestIngestionJobOfOtherUser(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	jobID, err := env.core.AddMaterial(ctx, env.userID, "TEXT", "Some content", "", false, false)
+	if err != nil {
+		t.Fatalf("AddMaterial: %v", err)
+	}
+	other, err := env.store.CreateUser(ctx, "other@example.com", "Other", "google-other", "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	err = env.core.WatchIngestionJob(ctx, other.Id, jobID, func(*learning.IngestionJob) error { return nil })
+	if !errors.Is(err, store.ErrPermissionDenied) {
+		t.Fatalf("WatchIngestionJob: got %v, want %v", err, store.ErrPermissionDenied)
+	}
+}
+
+func TestIngestionStopsWhenLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.ai.AddSummary("About Go", nil)
+	env.ai.AddChunk(ai.ChunkResult{Title: "Go", Flashcards: []*learning.Flashcard{{Question: "Q", Answer: "A"}}})
+	jobID, err := env.core.AddMaterial(ctx, env.userID, "TEXT", "Go is a programming language.", "", false, false)
+	if err != nil {
+		t.Fatalf("AddMaterial: %v", err)
+	}
+	job, err := env.store.ClaimIngestionJob(ctx, time.Now().Add(time.Minute), 3)
+	if err != nil || job == nil || job.ID != jobID {
+		t.Fatalf("ClaimIngestionJob: got %+v, %v; want job %s", job, err, jobID)
+	}
+
+	// Another worker claimed the job after this one's lease ran out
+	env.faults.fail["UpdateIngestionJob"] = fmt.Errorf("ingestion job %s: %w", jobID, store.ErrIngestionLeaseLost)
+	env.core.runIngestionJob(ctx, 0, job)
+
+	if _, total, err := env.store.GetDueMaterials(ctx, env.userID, 1, 10); err != nil || total != 0 {
+		t.Errorf("GetDueMaterials: got %d materials, %v; want none saved by a worker without the lease", total, err)
+	}
+	for _, call := range env.ai.Calls() {
+		if strings.HasPrefix(call, "GenerateChunkFlashcards") {
+			t.Errorf("AI called %s after the lease was lost", call)
+		}
+	}
+}
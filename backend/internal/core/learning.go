@@ -14,190 +14,392 @@ import (
 	"github.com/amityadav/landr/pkg/pb/learning"
 )
 
+// MaterialTypeManual is the type of decks built from cards the user wrote themselves.
+const MaterialTypeManual = "MANUAL"
+
+// AI generates and grades the content of decks. *ai.Client talks to the LLM.
+type AI interface {
+	ExtractTextFromImage(base64Image string) (string, error)
+	GenerateSummary(content string) (string, error)
+	GenerateChunkFlashcards(ctx context.Context, index int, chunk string, existingTags []string, allowCloze bool) ai.ChunkResult
+	RewriteFlashcard(question, answer string) (string, string, error)
+	GenerateDistractors(content string, requests []ai.DistractorRequest, count int) ([][]string, error)
+	GradeAnswer(question, reference, typed string) (float64, string, error)
+}
+
+// Scraper fetches the readable text of a web page.
+type Scraper interface {
+	Scrape(url string) (string, error)
+}
+
+// TranscriptFetcher fetches the transcript of a YouTube video.
+type TranscriptFetcher interface {
+	GetTranscript(ctx context.Context, videoURL string) (string, error)
+}
+
+var (
+	_ AI                = (*ai.Client)(nil)
+	_ Scraper           = (*scraper.Scraper)(nil)
+	_ TranscriptFetcher = (*youtube.TranscriptExtractor)(nil)
+)
+
 type LearningCore struct {
-	store   store.Store
-	scraper *scraper.Scraper
-	ai      *ai.Client
-	youtube *youtube.TranscriptExtractor
+	store       store.Store
+	scraper     Scraper
+	ai          AI
+	transcripts TranscriptFetcher
+	scheduler   Scheduler
+	ingestion   *ingestionEvents
+	chunkDelay  time.Duration    // Wait between chunks to stay under the LLM's rate limit
+	now         func() time.Time // Clock for study days and reviews
 }
 
-func NewLearningCore(s store.Store, scraper *scraper.Scraper, ai *ai.Client) *LearningCore {
+func NewLearningCore(s store.Store, scraper Scraper, aiClient AI, transcripts TranscriptFetcher, scheduler Scheduler) *LearningCore {
 	return &LearningCore{
-		store:   s,
-		scraper: scraper,
-		ai:      ai,
-		youtube: youtube.NewTranscriptExtractor(),
+		store:       s,
+		scraper:     scraper,
+		ai:          aiClient,
+		transcripts: transcripts,
+		scheduler:   scheduler,
+		ingestion:   newIngestionEvents(),
+		chunkDelay:  ai.ChunkDelay,
+		now:         time.Now,
 	}
 }
 
-func (c *LearningCore) AddMaterial(ctx context.Context, userID, matType, content, imageData string, existingTags []string) (string, int32, string, []string, error) {
-	log.Printf("[Core.AddMaterial] Starting - UserID: %s, Type: %s", userID, matType)
-
-	// 1. Process Content based on type
-	finalContent := content
+// withStore returns a copy of the core that works on st, such as the
+// transaction a WithTx callback is given.
+func (c *LearningCore) withStore(st store.Store) *LearningCore {
+	tc := *c
+	tc.store = st
+	return &tc
+}
 
-	switch matType {
-	case "LINK":
-		log.Printf("[Core.AddMaterial] Scraping URL: %s", content)
-		scraped, err := c.scraper.Scrape(content)
+// ingestMaterial turns a job's material into a deck of flashcards, reporting
+// each stage it reaches to progress. Large content is generated one chunk at
+// a time. The material is only saved once a chunk has produced cards, together
+// with them and its summary, tags and chunks; every later chunk's cards and
+// tags are saved together too. A job that already has a material resumes with
+// the chunks that have no cards yet.
+func (c *LearningCore) ingestMaterial(ctx context.Context, job *store.IngestionJob, progress ingestionProgress) (*learning.AddMaterialResponse, error) {
+	log.Printf("[Core.ingestMaterial] Starting - UserID: %s, Type: %s, MaterialID: %q", job.UserID, job.Type, job.MaterialID)
+
+	var content, summary, title string
+	var chunks []*store.MaterialChunk
+	resuming := job.MaterialID != ""
+	if resuming {
+		log.Printf("[Core.ingestMaterial] Resuming material: %s", job.MaterialID)
+		var err error
+		content, summary, title, err = c.store.GetMaterialContent(ctx, job.UserID, job.MaterialID)
 		if err != nil {
-			log.Printf("[Core.AddMaterial] Scraping failed: %v", err)
-			return "", 0, "", nil, fmt.Errorf("failed to scrape url: %w", err)
-		}
-		finalContent = scraped
-		log.Printf("[Core.AddMaterial] Scraped content length: %d", len(finalContent))
-
-	case "IMAGE":
-		log.Printf("[Core.AddMaterial] Extracting text from image, base64 length: %d", len(imageData))
-		if imageData == "" {
-			return "", 0, "", nil, fmt.Errorf("image_data required for IMAGE type")
+			log.Printf("[Core.ingestMaterial] Failed to load material: %v", err)
+			return nil, fmt.Errorf("failed to load material: %w", err)
 		}
-		extractedText, err := c.ai.ExtractTextFromImage(imageData)
+		chunks, err = c.store.GetMaterialChunks(ctx, job.UserID, job.MaterialID)
 		if err != nil {
-			log.Printf("[Core.AddMaterial] OCR extraction failed: %v", err)
-			return "", 0, "", nil, fmt.Errorf("failed to extract text from image: %w", err)
+			log.Printf("[Core.ingestMaterial] Failed to load chunks: %v", err)
+			return nil, fmt.Errorf("failed to load material chunks: %w", err)
 		}
-		finalContent = extractedText
-		log.Printf("[Core.AddMaterial] OCR extracted text length: %d", len(finalContent))
-
-	case "YOUTUBE":
-		log.Printf("[Core.AddMaterial] Extracting YouTube transcript: %s", content)
-		transcript, err := c.youtube.GetTranscript(ctx, content)
+	} else {
+		// 1. Process Content based on type
+		var err error
+		content, err = c.fetchContent(ctx, job.Type, job.Content, job.ImageData, progress)
 		if err != nil {
-			log.Printf("[Core.AddMaterial] YouTube transcript failed: %v", err)
-			return "", 0, "", nil, fmt.Errorf("failed to get youtube transcript: %w", err)
+			return nil, err
 		}
-		finalContent = transcript
-		log.Printf("[Core.AddMaterial] YouTube transcript length: %d", len(finalContent))
-
-	case "TEXT":
-		log.Printf("[Core.AddMaterial] Using provided text content, length: %d", len(content))
 
-	default:
-		log.Printf("[Core.AddMaterial] Unknown type: %s, treating as TEXT", matType)
+		// 2. Split large content into chunks, generated one at a time
+		texts := []string{content}
+		tokenEstimate := ai.EstimateTokens(content)
+		log.Printf("[Core.ingestMaterial] Estimated tokens: %d", tokenEstimate)
+		if tokenEstimate > 8000 {
+			log.Printf("[Core.ingestMaterial] Large content detected, using chunking...")
+			texts = ai.SplitIntoChunks(content, ai.ChunkSize, ai.ChunkOverlap)
+		}
+		for i, text := range texts {
+			chunks = append(chunks, &store.MaterialChunk{Index: int32(i), Content: text, Status: store.ChunkPending})
+		}
 	}
 
-	// 2. Fetch existing tags for AI context
-	userTags, err := c.store.GetTags(ctx, userID)
+	// 3. Fetch existing tags for AI context
+	userTags, err := c.store.GetTags(ctx, job.UserID)
 	if err != nil {
-		log.Printf("[Core.AddMaterial] Failed to fetch tags: %v", err)
+		log.Printf("[Core.ingestMaterial] Failed to fetch tags: %v", err)
 	}
 
-	// 3. Generate Flashcards + Summary in PARALLEL using goroutines
-	log.Printf("[Core.AddMaterial] Starting parallel AI generation...")
+	// 4. Generate the summary in the background while the first chunk is generated
+	var summaryErr error
+	var wg sync.WaitGroup
+	if summary == "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("[Core.ingestMaterial] Generating summary...")
+			summary, summaryErr = c.ai.GenerateSummary(content)
+			if summaryErr != nil {
+				log.Printf("[Core.ingestMaterial] Summary generation failed: %v", summaryErr)
+				summary = ""
+			} else {
+				log.Printf("[Core.ingestMaterial] Summary generated, length: %d", len(summary))
+			}
+		}()
+	}
 
-	var title string
+	// 5. Generate and save the flashcards of each unfinished chunk, one per
+	// cloze deletion for cloze cards and in both directions if asked
+	result := &learning.AddMaterialResponse{
+		MaterialId:  job.MaterialID,
+		Title:       title,
+		JobId:       job.ID,
+		TotalChunks: int32(len(chunks)),
+	}
+	seenTags := make(map[string]bool)
 	var tags []string
-	var cards []*learning.Flashcard
-	var summary string
-	var flashcardErr, summaryErr error
-
-	// Use WaitGroup to wait for both goroutines
-	var wg sync.WaitGroup
-	wg.Add(2)
+	var chunkErr error
+	processed := 0
+	for _, chunk := range chunks {
+		if chunk.Status == store.ChunkDone {
+			continue
+		}
+		if processed > 0 {
+			log.Printf("[Core.ingestMaterial] Waiting %v for rate limit...", c.chunkDelay)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.chunkDelay):
+			}
+		}
+		processed++
 
-	// Goroutine 1: Generate flashcards (with chunking for large content)
-	go func() {
-		defer wg.Done()
-		log.Printf("[Core.AddMaterial] Goroutine 1: Generating flashcards...")
+		progress(learning.IngestionStage_INGESTION_STAGE_GENERATING, chunk.Index+1, int32(len(chunks)))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		generated := c.ai.GenerateChunkFlashcards(ctx, int(chunk.Index), chunk.Content, userTags, job.AllowCloze)
+		if generated.Error != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			chunk.Status, chunk.Error = store.ChunkFailed, generated.Error.Error()
+			if chunkErr == nil {
+				chunkErr = generated.Error
+			}
+			if job.MaterialID != "" {
+				if err := c.store.FailMaterialChunk(ctx, job.MaterialID, chunk.Index, chunk.Error); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
 
-		// Check if content is large enough to need chunking
-		tokenEstimate := ai.EstimateTokens(finalContent)
-		log.Printf("[Core.AddMaterial] Estimated tokens: %d", tokenEstimate)
+		cards := expandClozeCards(generated.Flashcards)
+		if job.GenerateReverse {
+			cards = addReverseCards(cards)
+		}
 
-		if tokenEstimate > 8000 {
-			// Large content - use chunking with parallel processing
-			log.Printf("[Core.AddMaterial] Large content detected, using chunking...")
-			chunks := ai.SplitIntoChunks(finalContent, ai.ChunkSize, ai.ChunkOverlap)
-			title, tags, cards, flashcardErr = c.ai.ProcessChunksParallel(ctx, chunks, userTags)
+		var created int32
+		if job.MaterialID == "" {
+			// 6. Save Material with everything generated so far
+			wg.Wait()
+			progress(learning.IngestionStage_INGESTION_STAGE_SAVING, 0, 0)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			created, err = c.saveMaterial(ctx, job, content, generated.Title, summary, generated.Tags, chunks, chunk, cards)
+			result.MaterialId, result.Title = job.MaterialID, generated.Title
 		} else {
-			// Normal content - process directly with retry
-			flashcardErr = ai.RetryWithBackoff(ctx, "Flashcards", func() error {
+			err = c.store.WithTx(ctx, func(tx store.Store) error {
+				if result.Title == "" && generated.Title != "" {
+					if err := tx.UpdateMaterialTitle(ctx, job.UserID, job.MaterialID, generated.Title); err != nil {
+						return err
+					}
+				}
+				if err := tagMaterial(ctx, tx, job.UserID, job.MaterialID, generated.Tags); err != nil {
+					return err
+				}
 				var err error
-				title, tags, cards, err = c.ai.GenerateFlashcards(finalContent, userTags)
+				created, err = tx.SaveChunkFlashcards(ctx, job.MaterialID, chunk.Index, cards)
 				return err
 			})
+			if err == nil && result.Title == "" {
+				result.Title = generated.Title
+			}
 		}
-
-		if flashcardErr != nil {
-			log.Printf("[Core.AddMaterial] Flashcard generation failed: %v", flashcardErr)
-		} else {
-			log.Printf("[Core.AddMaterial] Flashcards generated: %d cards", len(cards))
+		if err != nil {
+			log.Printf("[Core.ingestMaterial] Failed to save flashcards: %v", err)
+			return nil, fmt.Errorf("failed to save flashcards: %w", err)
 		}
-	}()
 
-	// Goroutine 2: Generate summary
-	go func() {
-		defer wg.Done()
-		log.Printf("[Core.AddMaterial] Goroutine 2: Generating summary...")
-		summary, summaryErr = c.ai.GenerateSummary(finalContent)
-		if summaryErr != nil {
-			log.Printf("[Core.AddMaterial] Summary generation failed: %v", summaryErr)
-		} else {
-			log.Printf("[Core.AddMaterial] Summary generated, length: %d", len(summary))
+		chunk.Status = store.ChunkDone
+		result.FlashcardsCreated += created
+		for _, tag := range generated.Tags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				tags = append(tags, tag)
+			}
 		}
-	}()
-
-	// Wait for both to complete
+	}
 	wg.Wait()
-	log.Printf("[Core.AddMaterial] Parallel AI generation complete")
 
-	// Check for flashcard error (critical)
-	if flashcardErr != nil {
-		return "", 0, "", nil, fmt.Errorf("failed to generate flashcards: %w", flashcardErr)
+	if job.MaterialID == "" {
+		return nil, fmt.Errorf("failed to generate flashcards: %w", chunkErr)
+	}
+
+	// 7. Save the Summary of a resumed material that had none
+	if resuming && summary != "" {
+		progress(learning.IngestionStage_INGESTION_STAGE_SAVING, 0, 0)
+		if err := c.store.UpdateMaterialSummary(ctx, job.UserID, job.MaterialID, summary); err != nil {
+			log.Printf("[Core.ingestMaterial] Failed to save summary: %v", err)
+			// Non-critical, continue
+		}
+	}
+
+	result.Tags = tags
+	if resuming {
+		if all, err := c.store.GetMaterialTags(ctx, job.MaterialID); err == nil {
+			result.Tags = all
+		}
+	}
+	for _, chunk := range chunks {
+		if chunk.Status != store.ChunkDone {
+			result.FailedChunks = append(result.FailedChunks, chunk.Index+1)
+		}
+	}
+	if chunkErr != nil && len(result.FailedChunks) == len(chunks) {
+		return result, fmt.Errorf("failed to generate flashcards: %w", chunkErr)
 	}
-	// Summary error is non-critical - we can continue without it
 
-	log.Printf("[Core.AddMaterial] AI generated Title: %s, Tags: %v, Cards: %d", title, tags, len(cards))
+	log.Printf("[Core.ingestMaterial] Complete - MaterialID: %s, Cards: %d, Failed chunks: %v",
+		job.MaterialID, result.FlashcardsCreated, result.FailedChunks)
+	return result, nil
+}
 
-	// 4. Save Material with Title
-	log.Printf("[Core.AddMaterial] Saving material to database...")
-	materialID, err := c.store.CreateMaterial(ctx, userID, matType, finalContent, title)
+// saveMaterial saves a job's new material in one transaction with its summary,
+// tags, chunks and the cards generated from the first chunk, and records the
+// material on the job so that a restarted job resumes it. It returns how many
+// cards were added.
+func (c *LearningCore) saveMaterial(ctx context.Context, job *store.IngestionJob, content, title, summary string, tags []string, chunks []*store.MaterialChunk, first *store.MaterialChunk, cards []*learning.Flashcard) (int32, error) {
+	log.Printf("[Core.saveMaterial] Saving material to database...")
+	var materialID string
+	var created int32
+	err := c.store.WithTx(ctx, func(tx store.Store) error {
+		var err error
+		materialID, err = tx.CreateMaterial(ctx, job.UserID, job.Type, content, title)
+		if err != nil {
+			return fmt.Errorf("failed to create material: %w", err)
+		}
+		if summary != "" {
+			if err := tx.UpdateMaterialSummary(ctx, job.UserID, materialID, summary); err != nil {
+				return fmt.Errorf("failed to save summary: %w", err)
+			}
+		}
+		if err := tagMaterial(ctx, tx, job.UserID, materialID, tags); err != nil {
+			return err
+		}
+
+		texts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			texts[i] = chunk.Content
+		}
+		if err := tx.CreateMaterialChunks(ctx, materialID, texts); err != nil {
+			return err
+		}
+		for _, chunk := range chunks {
+			if chunk.Status == store.ChunkFailed {
+				if err := tx.FailMaterialChunk(ctx, materialID, chunk.Index, chunk.Error); err != nil {
+					return err
+				}
+			}
+		}
+		if created, err = tx.SaveChunkFlashcards(ctx, materialID, first.Index, cards); err != nil {
+			return err
+		}
+
+		job.MaterialID = materialID
+		if err := tx.UpdateIngestionJob(ctx, job); err != nil {
+			job.MaterialID = ""
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("[Core.AddMaterial] Failed to save material: %v", err)
-		return "", 0, "", nil, fmt.Errorf("failed to create material: %w", err)
+		job.MaterialID = ""
+		log.Printf("[Core.saveMaterial] Failed: %v", err)
+		return 0, err
 	}
-	log.Printf("[Core.AddMaterial] Material saved with ID: %s", materialID)
+	log.Printf("[Core.saveMaterial] Material saved with ID: %s", materialID)
+	return created, nil
+}
 
-	// 5. Save Summary if generated
-	if summary != "" && summaryErr == nil {
-		if err := c.store.UpdateMaterialSummary(ctx, materialID, summary); err != nil {
-			log.Printf("[Core.AddMaterial] Failed to save summary: %v", err)
-			// Non-critical, continue
-		} else {
-			log.Printf("[Core.AddMaterial] Summary saved successfully")
+// fetchContent returns the text to generate flashcards from: the scraped page
+// for a LINK, the text read from an IMAGE, the transcript of a YOUTUBE video,
+// or the content itself.
+func (c *LearningCore) fetchContent(ctx context.Context, matType, content, imageData string, progress ingestionProgress) (string, error) {
+	switch matType {
+	case "LINK":
+		log.Printf("[Core.fetchContent] Scraping URL: %s", content)
+		progress(learning.IngestionStage_INGESTION_STAGE_FETCHING, 0, 0)
+		scraped, err := c.scraper.Scrape(content)
+		if err != nil {
+			log.Printf("[Core.fetchContent] Scraping failed: %v", err)
+			return "", fmt.Errorf("failed to scrape url: %w", err)
 		}
+		log.Printf("[Core.fetchContent] Scraped content length: %d", len(scraped))
+		return scraped, nil
+
+	case "IMAGE":
+		log.Printf("[Core.fetchContent] Extracting text from image, base64 length: %d", len(imageData))
+		if imageData == "" {
+			return "", fmt.Errorf("image_data required for IMAGE type")
+		}
+		progress(learning.IngestionStage_INGESTION_STAGE_EXTRACTING, 0, 0)
+		extractedText, err := c.ai.ExtractTextFromImage(imageData)
+		if err != nil {
+			log.Printf("[Core.fetchContent] OCR extraction failed: %v", err)
+			return "", fmt.Errorf("failed to extract text from image: %w", err)
+		}
+		log.Printf("[Core.fetchContent] OCR extracted text length: %d", len(extractedText))
+		return extractedText, nil
+
+	case "YOUTUBE":
+		log.Printf("[Core.fetchContent] Extracting YouTube transcript: %s", content)
+		progress(learning.IngestionStage_INGESTION_STAGE_FETCHING, 0, 0)
+		transcript, err := c.transcripts.GetTranscript(ctx, content)
+		if err != nil {
+			log.Printf("[Core.fetchContent] YouTube transcript failed: %v", err)
+			return "", fmt.Errorf("failed to get youtube transcript: %w", err)
+		}
+		log.Printf("[Core.fetchContent] YouTube transcript length: %d", len(transcript))
+		return transcript, nil
+
+	case "TEXT":
+		log.Printf("[Core.fetchContent] Using provided text content, length: %d", len(content))
+
+	default:
+		log.Printf("[Core.fetchContent] Unknown type: %s, treating as TEXT", matType)
 	}
+	return content, nil
+}
 
-	// 6. Save Tags and Link to Material
+// tagMaterial creates the named tags and links them to a material through st.
+func tagMaterial(ctx context.Context, st store.Store, userID, materialID string, tags []string) error {
 	var tagIDs []string
 	for _, tagName := range tags {
-		tagID, err := c.store.CreateTag(ctx, userID, tagName)
+		tagID, err := st.CreateTag(ctx, userID, tagName)
 		if err != nil {
-			log.Printf("[Core.AddMaterial] Failed to create tag %s: %v", tagName, err)
-			continue
+			log.Printf("[Core.tagMaterial] Failed to create tag %s: %v", tagName, err)
+			return err
 		}
 		tagIDs = append(tagIDs, tagID)
 	}
 
 	if len(tagIDs) > 0 {
-		if err := c.store.AddMaterialTags(ctx, materialID, tagIDs); err != nil {
-			log.Printf("[Core.AddMaterial] Failed to link tags: %v", err)
+		if err := st.AddMaterialTags(ctx, materialID, tagIDs); err != nil {
+			log.Printf("[Core.tagMaterial] Failed to link tags: %v", err)
+			return err
 		}
 	}
-
-	// 7. Save Flashcards
-	if len(cards) > 0 {
-		log.Printf("[Core.AddMaterial] Saving %d flashcards to database...", len(cards))
-		if err := c.store.CreateFlashcards(ctx, materialID, cards); err != nil {
-			log.Printf("[Core.AddMaterial] Failed to save flashcards: %v", err)
-			return materialID, 0, title, tags, fmt.Errorf("failed to save flashcards: %w", err)
-		}
-		log.Printf("[Core.AddMaterial] Flashcards saved successfully")
-	}
-
-	log.Printf("[Core.AddMaterial] Complete - MaterialID: %s, Cards: %d", materialID, len(cards))
-	return materialID, int32(len(cards)), title, tags, nil
+	return nil
 }
 
 func (c *LearningCore) DeleteMaterial(ctx context.Context, userID, materialID string) error {
@@ -217,10 +419,52 @@ func (c *LearningCore) GetDueFlashcards(ctx context.Context, userID, materialID
 		log.Printf("[Core.GetDueFlashcards] Query failed: %v", err)
 		return nil, err
 	}
+	renderFlashcards(cards...)
 	log.Printf("[Core.GetDueFlashcards] Found %d cards", len(cards))
 	return cards, nil
 }
 
+// ReviewQueue is the set of cards a user should study now.
+type ReviewQueue struct {
+	Flashcards       []*learning.Flashcard
+	TotalDue         int32
+	NewRemaining     int32
+	ReviewsRemaining int32
+}
+
+// GetReviewQueue returns the user's due cards across materials, interleaved in
+// a stable order and capped by both maxCards and the user's daily limits.
+func (c *LearningCore) GetReviewQueue(ctx context.Context, userID string, materialIDs, tags []string, maxCards int32) (*ReviewQueue, error) {
+	log.Printf("[Core.GetReviewQueue] Querying for userID: %s, materials: %v, tags: %v, max: %d", userID, materialIDs, tags, maxCards)
+
+	limits, err := c.remainingToday(ctx, userID, c.now())
+	if err != nil {
+		log.Printf("[Core.GetReviewQueue] Failed to get daily limits: %v", err)
+		return nil, err
+	}
+
+	cards, totalDue, err := c.store.GetReviewQueue(ctx, userID, store.ReviewQueueFilter{
+		MaterialIDs: materialIDs,
+		Tags:        tags,
+		Limit:       maxCards,
+		NewLimit:    limits.newRemaining,
+		ReviewLimit: limits.reviewRemaining,
+		DueBefore:   limits.dueBefore,
+	})
+	if err != nil {
+		log.Printf("[Core.GetReviewQueue] Query failed: %v", err)
+		return nil, err
+	}
+	renderFlashcards(cards...)
+	log.Printf("[Core.GetReviewQueue] Found %d cards (total due: %d)", len(cards), totalDue)
+	return &ReviewQueue{
+		Flashcards:       cards,
+		TotalDue:         totalDue,
+		NewRemaining:     limits.newRemaining,
+		ReviewsRemaining: limits.reviewRemaining,
+	}, nil
+}
+
 func (c *LearningCore) GetDueMaterials(ctx context.Context, userID string, page, pageSize int32) ([]*learning.MaterialSummary, int32, error) {
 	log.Printf("[Core.GetDueMaterials] Querying for userID: %s, page: %d, pageSize: %d", userID, page, pageSize)
 	materials, totalCount, err := c.store.GetDueMaterials(ctx, userID, page, pageSize)
@@ -232,120 +476,258 @@ func (c *LearningCore) GetDueMaterials(ctx context.Context, userID string, page,
 	return materials, totalCount, nil
 }
 
-func (c *LearningCore) CompleteReview(ctx context.Context, flashcardID string) error {
-	log.Printf("[Core.CompleteReview] Updating flashcard: %s", flashcardID)
+// ReviewMeta is client-supplied context recorded alongside a review.
+type ReviewMeta struct {
+	ClientReviewedAt time.Time // Zero if the client did not report it
+	SessionID        string
+}
+
+// ReviewFlashcard grades a review of a flashcard, reschedules it using the
+// configured scheduler and records the review in the user's review log.
+func (c *LearningCore) ReviewFlashcard(ctx context.Context, userID, flashcardID string, grade learning.ReviewGrade, meta ReviewMeta) (*store.FlashcardSchedule, error) {
+	if grade < learning.ReviewGrade_REVIEW_GRADE_AGAIN || grade > learning.ReviewGrade_REVIEW_GRADE_EASY {
+		return nil, fmt.Errorf("unknown review grade %d", grade)
+	}
+	scheduler := c.schedulerFor(ctx, userID)
+	log.Printf("[Core.ReviewFlashcard] Reviewing flashcard: %s, Grade: %s, Scheduler: %s", flashcardID, grade, scheduler.Name())
 
-	// Fetch the current flashcard to get its stage
-	card, err := c.store.GetFlashcard(ctx, flashcardID)
+	// Fetch the current flashcard to get its scheduling state
+	card, err := c.store.GetFlashcard(ctx, userID, flashcardID)
 	if err != nil {
-		log.Printf("[Core.CompleteReview] Failed to get flashcard: %v", err)
-		return fmt.Errorf("failed to get flashcard: %w", err)
-	}
-
-	// Implement SRS logic: increment stage and calculate next review time
-	// Stage 0: New card -> 1 day
-	// Stage 1: 1 day -> 3 days
-	// Stage 2: 3 days -> 7 days
-	// Stage 3: 7 days -> 15 days
-	// Stage 4: 15 days -> 30 days
-	// Stage 5+: 30 days (max)
-
-	currentStage := card.Stage
-	nextStage := currentStage + 1
-
-	// Calculate next review interval based on new stage
-	var intervalDays int
-	switch nextStage {
-	case 1:
-		intervalDays = 1
-	case 2:
-		intervalDays = 3
-	case 3:
-		intervalDays = 7
-	case 4:
-		intervalDays = 15
-	default:
-		// Stage 5 and above: 30 days
-		intervalDays = 30
-		if nextStage > 5 {
-			nextStage = 5 // Cap at stage 5
-		}
+		log.Printf("[Core.ReviewFlashcard] Failed to get flashcard: %v", err)
+		return nil, fmt.Errorf("failed to get flashcard: %w", err)
 	}
 
-	nextReviewAt := time.Now().Add(time.Duration(intervalDays) * 24 * time.Hour)
+	settings, err := c.GetStudySettings(ctx, userID)
+	if err != nil {
+		log.Printf("[Core.ReviewFlashcard] Failed to get study settings: %v", err)
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	cal := newStudyCalendar(settings)
 
-	log.Printf("[Core.CompleteReview] Advancing from stage %d to %d (next review in %d days)",
-		currentStage, nextStage, intervalDays)
+	// Schedule in whole study days of the user's local calendar
+	now := c.now()
+	var elapsedDays int32
+	if card.LastReviewedAt != nil {
+		elapsedDays = cal.daysBetween(card.LastReviewedAt.AsTime(), now)
+	}
 
-	err = c.store.UpdateFlashcard(ctx, flashcardID, nextStage, nextReviewAt)
+	schedule := scheduler.Schedule(card, grade, elapsedDays)
+	schedule.NextReviewAt = cal.dueAt(now, schedule.IntervalDays)
+	schedule.LastReviewedAt = now
+	applyLapse(&schedule, card, grade, settings)
+
+	log.Printf("[Core.ReviewFlashcard] Moving from stage %d to %d (next review in %d days)",
+		card.Stage, schedule.Stage, schedule.IntervalDays)
+
+	entry := &store.ReviewLog{
+		FlashcardID:   flashcardID,
+		UserID:        userID,
+		Grade:         grade,
+		Scheduler:     scheduler.Name(),
+		PreviousStage: card.Stage,
+		NextStage:     schedule.Stage,
+		PreviousDueAt: card.NextReviewAt.AsTime(),
+		NextDueAt:     schedule.NextReviewAt,
+		ElapsedDays:   float64(elapsedDays),
+		ReviewedAt:    now,
+		SessionID:     meta.SessionID,
+
+		PreviousEaseFactor:   card.EaseFactor,
+		PreviousIntervalDays: card.IntervalDays,
+		PreviousRepetitions:  card.Repetitions,
+		PreviousStability:    card.Stability,
+		PreviousDifficulty:   card.Difficulty,
+		PreviousLapses:       card.Lapses,
+		PreviousIsLeech:      card.IsLeech,
+		PreviousSuspended:    card.Status == learning.FlashcardStatus_FLASHCARD_STATUS_SUSPENDED,
+	}
+	if card.LastReviewedAt != nil {
+		lastReviewedAt := card.LastReviewedAt.AsTime()
+		entry.PreviousLastReviewedAt = &lastReviewedAt
+	}
+	if !meta.ClientReviewedAt.IsZero() {
+		entry.ClientReviewedAt = &meta.ClientReviewedAt
+	}
+
+	// Keep other cards of the same note out of today's reviews, recording
+	// them with the review so undoing it can put them back
+	err = c.store.WithTx(ctx, func(tx store.Store) error {
+		if card.NoteId != "" {
+			buried, err := tx.BuryFlashcardSiblings(ctx, userID, flashcardID, cal.dueAt(now, 1))
+			if err != nil {
+				return fmt.Errorf("failed to bury siblings: %w", err)
+			}
+			entry.BuriedSiblings = buried
+		}
+		return tx.RecordReview(ctx, schedule, entry)
+	})
 	if err != nil {
-		log.Printf("[Core.CompleteReview] Update failed: %v", err)
-		return err
+		log.Printf("[Core.ReviewFlashcard] Update failed: %v", err)
+		return nil, err
 	}
 
-	log.Printf("[Core.CompleteReview] Updated successfully to stage %d", nextStage)
-	return nil
+	log.Printf("[Core.ReviewFlashcard] Updated successfully to stage %d", schedule.Stage)
+	return &schedule, nil
+}
+
+// schedulerFor returns the scheduler to use for a user's reviews. FSRS uses
+// the user's fitted weights when the optimizer has produced them.
+func (c *LearningCore) schedulerFor(ctx context.Context, userID string) Scheduler {
+	fsrs, ok := c.scheduler.(FSRSScheduler)
+	if !ok {
+		return c.scheduler
+	}
+	weights, err := c.store.GetFSRSParams(ctx, userID)
+	if err != nil {
+		log.Printf("[Core.schedulerFor] Failed to load FSRS params, using defaults: %v", err)
+		return fsrs
+	}
+	if weights != nil {
+		fsrs = NewFSRSScheduler(weights)
+	}
+	return fsrs
+}
+
+// CompleteReview records a correct answer, equivalent to a "Good" grade.
+func (c *LearningCore) CompleteReview(ctx context.Context, userID, flashcardID string, meta ReviewMeta) error {
+	log.Printf("[Core.CompleteReview] Updating flashcard: %s", flashcardID)
+	_, err := c.ReviewFlashcard(ctx, userID, flashcardID, learning.ReviewGrade_REVIEW_GRADE_GOOD, meta)
+	return err
 }
 
-func (c *LearningCore) FailReview(ctx context.Context, flashcardID string) error {
+// FailReview records a wrong answer, equivalent to an "Again" grade.
+func (c *LearningCore) FailReview(ctx context.Context, userID, flashcardID string, meta ReviewMeta) error {
 	log.Printf("[Core.FailReview] Failing flashcard: %s", flashcardID)
+	_, err := c.ReviewFlashcard(ctx, userID, flashcardID, learning.ReviewGrade_REVIEW_GRADE_AGAIN, meta)
+	return err
+}
 
-	// Fetch the current flashcard to get its stage
-	card, err := c.store.GetFlashcard(ctx, flashcardID)
-	if err != nil {
-		log.Printf("[Core.FailReview] Failed to get flashcard: %v", err)
-		return fmt.Errorf("failed to get flashcard: %w", err)
+func (c *LearningCore) UpdateFlashcard(ctx context.Context, userID, flashcardID, question, answer string) error {
+	log.Printf("[Core.UpdateFlashcard] Updating flashcard: %s", flashcardID)
+	if err := c.store.UpdateFlashcardContent(ctx, userID, flashcardID, question, answer); err != nil {
+		log.Printf("[Core.UpdateFlashcard] Failed: %v", err)
+		return err
 	}
+	log.Printf("[Core.UpdateFlashcard] Successfully updated")
+	return nil
+}
+
+// CreateFlashcard adds a card the user wrote themselves. Without a material it
+// starts a new manual deck, which is a material with no source content. A
+// cloze card is stored as one sibling per cloze number, a reversed card as a
+// forward and reverse pair, and the first card is returned.
+func (c *LearningCore) CreateFlashcard(ctx context.Context, userID, materialID string, card *learning.Flashcard, generateReverse bool, deckTitle string, tags []string) (*learning.Flashcard, error) {
+	log.Printf("[Core.CreateFlashcard] Creating %s flashcard for userID: %s, materialID: %s", card.CardType, userID, materialID)
 
-	// Decrease stage by 1, minimum 0
-	currentStage := card.Stage
-	nextStage := currentStage - 1
-	if nextStage < 0 {
-		nextStage = 0
+	cards := expandClozeCards([]*learning.Flashcard{card})
+	if generateReverse {
+		cards = addReverseCards(cards)
 	}
 
-	// Reset to review in 1 day (back to basics)
-	nextReviewAt := time.Now().Add(24 * time.Hour)
+	// A new deck is only kept if its cards are saved too
+	var ids []string
+	err := c.store.WithTx(ctx, func(tx store.Store) error {
+		if materialID == "" {
+			if deckTitle == "" {
+				deckTitle = "Untitled Deck"
+			}
+			var err error
+			materialID, err = tx.CreateMaterial(ctx, userID, MaterialTypeManual, "", deckTitle)
+			if err != nil {
+				log.Printf("[Core.CreateFlashcard] Failed to create deck: %v", err)
+				return fmt.Errorf("failed to create deck: %w", err)
+			}
+			if err := tagMaterial(ctx, tx, userID, materialID, tags); err != nil {
+				return fmt.Errorf("failed to tag deck: %w", err)
+			}
+			log.Printf("[Core.CreateFlashcard] Created deck %s: %s", materialID, deckTitle)
+		}
 
-	log.Printf("[Core.FailReview] Decreasing from stage %d to %d (next review in 1 day)",
-		currentStage, nextStage)
+		var err error
+		ids, err = tx.AddFlashcards(ctx, userID, materialID, cards)
+		return err
+	})
+	if err != nil {
+		log.Printf("[Core.CreateFlashcard] Failed: %v", err)
+		return nil, err
+	}
 
-	err = c.store.UpdateFlashcard(ctx, flashcardID, nextStage, nextReviewAt)
+	created, err := c.store.GetFlashcard(ctx, userID, ids[0])
 	if err != nil {
-		log.Printf("[Core.FailReview] Update failed: %v", err)
-		return err
+		log.Printf("[Core.CreateFlashcard] Failed to load created flashcard: %v", err)
+		return nil, err
 	}
+	renderFlashcards(created)
+	log.Printf("[Core.CreateFlashcard] Created %d flashcards", len(ids))
+	return created, nil
+}
 
-	log.Printf("[Core.FailReview] Updated successfully to stage %d", nextStage)
-	return nil
+// DeleteFlashcards permanently removes cards, all or nothing.
+func (c *LearningCore) DeleteFlashcards(ctx context.Context, userID string, flashcardIDs []string) (int32, error) {
+	log.Printf("[Core.DeleteFlashcards] Deleting %d flashcards for userID: %s", len(flashcardIDs), userID)
+	count, err := c.store.DeleteFlashcards(ctx, userID, flashcardIDs)
+	if err != nil {
+		log.Printf("[Core.DeleteFlashcards] Failed: %v", err)
+		return 0, err
+	}
+	log.Printf("[Core.DeleteFlashcards] Deleted %d flashcards", count)
+	return count, nil
 }
 
-func (c *LearningCore) UpdateFlashcard(ctx context.Context, flashcardID, question, answer string) error {
-	log.Printf("[Core.UpdateFlashcard] Updating flashcard: %s", flashcardID)
-	if err := c.store.UpdateFlashcardContent(ctx, flashcardID, question, answer); err != nil {
-		log.Printf("[Core.UpdateFlashcard] Failed: %v", err)
-		return err
+// UndoReview reverts the user's most recent review, optionally limited to one
+// review session. Calling it repeatedly walks further back through the session.
+func (c *LearningCore) UndoReview(ctx context.Context, userID, sessionID string) (*store.ReviewLog, int32, error) {
+	log.Printf("[Core.UndoReview] Undoing last review for userID: %s, sessionID: %s", userID, sessionID)
+	review, remaining, err := c.store.UndoLastReview(ctx, userID, sessionID)
+	if err != nil {
+		log.Printf("[Core.UndoReview] Failed: %v", err)
+		return nil, 0, err
 	}
-	log.Printf("[Core.UpdateFlashcard] Successfully updated")
-	return nil
+	log.Printf("[Core.UndoReview] Reverted review %s of flashcard %s, %d undos remaining", review.ID, review.FlashcardID, remaining)
+	return review, remaining, nil
+}
+
+func (c *LearningCore) GetReviewHistory(ctx context.Context, userID string, filter store.ReviewHistoryFilter) ([]*learning.ReviewLogEntry, int32, error) {
+	log.Printf("[Core.GetReviewHistory] Querying for userID: %s", userID)
+	entries, totalCount, err := c.store.GetReviewHistory(ctx, userID, filter)
+	if err != nil {
+		log.Printf("[Core.GetReviewHistory] Query failed: %v", err)
+		return nil, 0, err
+	}
+	log.Printf("[Core.GetReviewHistory] Found %d entries (total: %d)", len(entries), totalCount)
+	return entries, totalCount, nil
 }
 
 func (c *LearningCore) GetAllTags(ctx context.Context, userID string) ([]string, error) {
 	return c.store.GetTags(ctx, userID)
 }
 
-func (c *LearningCore) GetNotificationStatus(ctx context.Context, userID string) (int32, bool, error) {
+// GetNotificationStatus returns how many cards the user can study today,
+// split into new cards and reviews, after applying their daily limits.
+func (c *LearningCore) GetNotificationStatus(ctx context.Context, userID string) (int32, int32, int32, bool, error) {
 	log.Printf("[Core.GetNotificationStatus] Getting notification status for userID: %s", userID)
 
-	count, err := c.store.GetDueFlashcardsCount(ctx, userID)
+	limits, err := c.remainingToday(ctx, userID, c.now())
+	if err != nil {
+		log.Printf("[Core.GetNotificationStatus] Failed to get daily limits: %v", err)
+		return 0, 0, 0, false, err
+	}
+
+	// Count everything due by the end of the user's local study day
+	newDue, reviewDue, err := c.store.GetDueFlashcardsCount(ctx, userID, limits.dueBefore)
 	if err != nil {
 		log.Printf("[Core.GetNotificationStatus] Failed to get count: %v", err)
-		return 0, false, err
+		return 0, 0, 0, false, err
 	}
 
+	newCount := min(newDue, limits.newRemaining)
+	reviewCount := min(reviewDue, limits.reviewRemaining)
+	count := newCount + reviewCount
+
 	hasDue := count > 0
-	log.Printf("[Core.GetNotificationStatus] User has %d due flashcards", count)
-	return count, hasDue, nil
+	log.Printf("[Core.GetNotificationStatus] User has %d due flashcards (%d new, %d reviews)", count, newCount, reviewCount)
+	return count, newCount, reviewCount, hasDue, nil
 }
 
 func (c *LearningCore) GetMaterialSummary(ctx context.Context, userID, materialID string) (string, string, error) {
@@ -364,6 +746,12 @@ func (c *LearningCore) GetMaterialSummary(ctx context.Context, userID, materialI
 		return summary, title, nil
 	}
 
+	// Manual decks have no source text to summarize
+	if content == "" {
+		log.Printf("[Core.GetMaterialSummary] Material has no content, nothing to summarize")
+		return "", title, nil
+	}
+
 	// 3. Generate summary via AI
 	log.Printf("[Core.GetMaterialSummary] No summary found, generating via AI...")
 	summary, err = c.ai.GenerateSummary(content)
@@ -373,7 +761,7 @@ func (c *LearningCore) GetMaterialSummary(ctx context.Context, userID, materialI
 	}
 
 	// 4. Save summary to database
-	if err := c.store.UpdateMaterialSummary(ctx, materialID, summary); err != nil {
+	if err := c.store.UpdateMaterialSummary(ctx, userID, materialID, summary); err != nil {
 		log.Printf("[Core.GetMaterialSummary] Failed to save summary: %v", err)
 		// Continue - we can still return the generated summary
 	}
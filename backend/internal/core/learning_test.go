@@ -0,0 +1,277 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// testStores returns constructors for the stores core tests run against.
+func testStores() map[string]func(t *testing.T) store.Store {
+	return map[string]func(t *testing.T) store.Store{
+		"memory": func(t *testing.T) store.Store { return store.NewMemoryStore() },
+		"sqlite": func(t *testing.T) store.Store {
+			s, err := store.NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "landr.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteStore: %v", err)
+			}
+			t.Cleanup(s.Close)
+			return s
+		},
+	}
+}
+
+// TestSaveMaterialRollsBack fails each step of saving a new material in turn
+// and checks nothing of it is left behind.
+func TestSaveMaterialRollsBack(t *testing.T) {
+	errStep := errors.New("step failed")
+	steps := []string{
+		"", // Nothing fails
+		"CreateMaterial",
+		"UpdateMaterialSummary",
+		"CreateTag",
+		"AddMaterialTags",
+		"CreateMaterialChunks",
+		"FailMaterialChunk",
+		"SaveChunkFlashcards",
+		"UpdateIngestionJob",
+	}
+
+	for name, newStore := range testStores() {
+		for _, step := range steps {
+			t.Run(name+"/"+cmp.Or(step, "nothing fails"), func(t *testing.T) {
+				ctx := context.Background()
+				env := newTestEnvWith(t, newStore(t))
+				job := &store.IngestionJob{UserID: env.userID, Type: "TEXT", Content: "Some content"}
+				if err := env.store.CreateIngestionJob(ctx, job); err != nil {
+					t.Fatalf("CreateIngestionJob: %v", err)
+				}
+				chunks := []*store.MaterialChunk{
+					{Index: 0, Content: "first", Status: store.ChunkDone},
+					{Index: 1, Content: "second", Status: store.ChunkFailed, Error: "model error"},
+				}
+				cards := []*learning.Flashcard{{Question: "Q", Answer: "A"}}
+				if step != "" {
+					env.faults.fail[step] = errStep
+				}
+
+				created, err := env.core.saveMaterial(ctx, job, "Some content", "Title", "Summary", []string{"go"}, chunks, chunks[0], cards)
+				if step == "" {
+					if err != nil || created != 1 || job.MaterialID == "" {
+						t.Fatalf("saveMaterial = %d, %v with material %q, want 1 card saved", created, err, job.MaterialID)
+					}
+				} else {
+					if !errors.Is(err, errStep) {
+						t.Fatalf("saveMaterial: got %v, want %v", err, errStep)
+					}
+					if job.MaterialID != "" {
+						t.Errorf("job.MaterialID = %q after a failure, want it cleared", job.MaterialID)
+					}
+				}
+
+				wantSaved := step == ""
+				materials, total, err := env.store.GetDueMaterials(ctx, env.userID, 1, 10)
+				if err != nil {
+					t.Fatalf("GetDueMaterials: %v", err)
+				}
+				if saved := total > 0; saved != wantSaved {
+					t.Errorf("%d materials saved, want saved = %v", total, wantSaved)
+				}
+				tags, err := env.store.GetTags(ctx, env.userID)
+				if err != nil {
+					t.Fatalf("GetTags: %v", err)
+				}
+				if saved := len(tags) > 0; saved != wantSaved {
+					t.Errorf("tags %v saved, want saved = %v", tags, wantSaved)
+				}
+				stored, err := env.store.GetIngestionJob(ctx, env.userID, job.ID)
+				if err != nil {
+					t.Fatalf("GetIngestionJob: %v", err)
+				}
+				if saved := stored.MaterialID != ""; saved != wantSaved {
+					t.Errorf("stored job has material %q, want saved = %v", stored.MaterialID, wantSaved)
+				}
+
+				if wantSaved {
+					materialChunks, err := env.store.GetMaterialChunks(ctx, env.userID, materials[0].Id)
+					if err != nil {
+						t.Fatalf("GetMaterialChunks: %v", err)
+					}
+					if len(materialChunks) != 2 || materialChunks[0].Status != store.ChunkDone || materialChunks[1].Status != store.ChunkFailed {
+						t.Errorf("chunks not saved as generated: %+v", materialChunks)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCompleteAndFailReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		reviews    []bool // Earlier reviews, true if answered correctly
+		correct    bool
+		otherUser  bool
+		missing    bool
+		leechAfter int32
+		wantErr    error
+		wantStage  int32
+		wantLapses int32
+		wantLeech  bool
+	}{
+		{name: "new card answered", correct: true, wantStage: 1},
+		{name: "new card failed is no lapse", correct: false, wantStage: 0},
+		{name: "learned card answered", reviews: []bool{true}, correct: true, wantStage: 2},
+		{name: "learned card failed", reviews: []bool{true}, correct: false, wantStage: 0, wantLapses: 1},
+		{name: "leech at the threshold", reviews: []bool{true, false, true}, correct: false, leechAfter: 2, wantStage: 0, wantLapses: 2, wantLeech: true},
+		{name: "missing card", missing: true, correct: true, wantErr: store.ErrNotFound},
+		{name: "card of another user", otherUser: true, correct: true, wantErr: store.ErrPermissionDenied},
+		{name: "card of another user failed", otherUser: true, correct: false, wantErr: store.ErrPermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			_, ids := env.addMaterial(t, "Some content", &learning.Flashcard{Question: "Q", Answer: "A"})
+			cardID := ids[0]
+			if tt.leechAfter > 0 {
+				settings := DefaultStudySettings()
+				settings.LeechThreshold = tt.leechAfter
+				if _, err := env.core.UpdateStudySettings(ctx, env.userID, settings); err != nil {
+					t.Fatalf("UpdateStudySettings: %v", err)
+				}
+			}
+			review := func(userID string, correct bool) error {
+				if correct {
+					return env.core.CompleteReview(ctx, userID, cardID, ReviewMeta{})
+				}
+				return env.core.FailReview(ctx, userID, cardID, ReviewMeta{})
+			}
+			for _, correct := range tt.reviews {
+				if err := review(env.userID, correct); err != nil {
+					t.Fatalf("earlier review: %v", err)
+				}
+			}
+
+			userID := env.userID
+			if tt.otherUser {
+				other, err := env.store.CreateUser(ctx, "other@example.com", "Other", "google-other", "")
+				if err != nil {
+					t.Fatalf("CreateUser: %v", err)
+				}
+				userID = other.Id
+			}
+			if tt.missing {
+				cardID = store.NewUUID()
+			}
+			err := review(userID, tt.correct)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("review: got %v, want %v", err, tt.wantErr)
+			}
+
+			logs, err := env.store.GetReviewLogs(ctx, env.userID)
+			if err != nil {
+				t.Fatalf("GetReviewLogs: %v", err)
+			}
+			wantLogs := len(tt.reviews)
+			if tt.wantErr == nil {
+				wantLogs++
+			}
+			if len(logs) != wantLogs {
+				t.Errorf("%d reviews recorded, want %d", len(logs), wantLogs)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			card, err := env.store.GetFlashcard(ctx, env.userID, cardID)
+			if err != nil {
+				t.Fatalf("GetFlashcard: %v", err)
+			}
+			if card.Stage != tt.wantStage || card.Lapses != tt.wantLapses || card.IsLeech != tt.wantLeech {
+				t.Errorf("card at stage %d with %d lapses, leech %v; want stage %d with %d lapses, leech %v",
+					card.Stage, card.Lapses, card.IsLeech, tt.wantStage, tt.wantLapses, tt.wantLeech)
+			}
+			if card.LastReviewedAt == nil {
+				t.Errorf("card not marked reviewed")
+			}
+		})
+	}
+}
+
+func TestGetMaterialSummary(t *testing.T) {
+	errAI := errors.New("model overloaded")
+	tests := []struct {
+		name        string
+		content     string
+		saved       string // Summary saved before asking
+		aiSummary   string
+		aiErr       error
+		otherUser   bool
+		missing     bool
+		wantSummary string
+		wantErr     error
+		wantAICalls int
+		wantSaved   string
+	}{
+		{name: "saved summary", content: "Some content", saved: "Saved", wantSummary: "Saved", wantSaved: "Saved"},
+		{name: "generated and saved", content: "Some content", aiSummary: "Generated", wantSummary: "Generated", wantAICalls: 1, wantSaved: "Generated"},
+		{name: "generation fails", content: "Some content", aiErr: errAI, wantErr: errAI, wantAICalls: 1},
+		{name: "manual deck without content", content: ""},
+		{name: "missing material", content: "Some content", missing: true, wantErr: store.ErrNotFound},
+		{name: "material of another user", content: "Some content", otherUser: true, wantErr: store.ErrPermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			materialID, _ := env.addMaterial(t, tt.content)
+			if tt.saved != "" {
+				if err := env.store.UpdateMaterialSummary(ctx, env.userID, materialID, tt.saved); err != nil {
+					t.Fatalf("UpdateMaterialSummary: %v", err)
+				}
+			}
+			if tt.aiSummary != "" || tt.aiErr != nil {
+				env.ai.AddSummary(tt.aiSummary, tt.aiErr)
+			}
+
+			userID, id := env.userID, materialID
+			if tt.otherUser {
+				other, err := env.store.CreateUser(ctx, "other@example.com", "Other", "google-other", "")
+				if err != nil {
+					t.Fatalf("CreateUser: %v", err)
+				}
+				userID = other.Id
+			}
+			if tt.missing {
+				id = store.NewUUID()
+			}
+
+			summary, title, err := env.core.GetMaterialSummary(ctx, userID, id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetMaterialSummary: got %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (summary != tt.wantSummary || title != "Title") {
+				t.Errorf("got summary %q of %q, want %q of %q", summary, title, tt.wantSummary, "Title")
+			}
+			if calls := len(env.ai.Calls()); calls != tt.wantAICalls {
+				t.Errorf("AI called %d times, want %d", calls, tt.wantAICalls)
+			}
+
+			_, saved, _, err := env.store.GetMaterialContent(ctx, env.userID, materialID)
+			if err != nil {
+				t.Fatalf("GetMaterialContent: %v", err)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("saved summary %q, want %q", saved, tt.wantSaved)
+			}
+		})
+	}
+}
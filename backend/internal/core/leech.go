@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// applyLapse counts a forgotten review of an already learned card and marks the
+// card as a leech, suspending it if the user asked for that, once it reaches
+// the user's leech threshold.
+func applyLapse(schedule *store.FlashcardSchedule, card *learning.Flashcard, grade learning.ReviewGrade, settings *learning.StudySettings) {
+	schedule.Lapses = card.Lapses
+	schedule.IsLeech = card.IsLeech
+	schedule.Suspended = card.Status == learning.FlashcardStatus_FLASHCARD_STATUS_SUSPENDED
+
+	// Failing a card that was never learned is not a lapse
+	if grade != learning.ReviewGrade_REVIEW_GRADE_AGAIN || card.LastReviewedAt == nil {
+		return
+	}
+	schedule.Lapses++
+
+	if schedule.IsLeech || schedule.Lapses < settings.LeechThreshold {
+		return
+	}
+	log.Printf("[Core.applyLapse] Flashcard %s became a leech after %d lapses", card.Id, schedule.Lapses)
+	schedule.IsLeech = true
+	if settings.LeechAction == learning.LeechAction_LEECH_ACTION_SUSPEND {
+		schedule.Suspended = true
+	}
+}
+
+func (c *LearningCore) GetLeeches(ctx context.Context, userID string) ([]*learning.Flashcard, error) {
+	log.Printf("[Core.GetLeeches] Querying for userID: %s", userID)
+	cards, err := c.store.GetLeeches(ctx, userID)
+	if err != nil {
+		log.Printf("[Core.GetLeeches] Query failed: %v", err)
+		return nil, err
+	}
+	renderFlashcards(cards...)
+	log.Printf("[Core.GetLeeches] Found %d leeches", len(cards))
+	return cards, nil
+}
+
+// RewriteFlashcard asks the AI for a clearer version of a card. The suggestion
+// is not saved; the user reviews it and saves it with UpdateFlashcard.
+func (c *LearningCore) RewriteFlashcard(ctx context.Context, userID, flashcardID string) (string, string, error) {
+	log.Printf("[Core.RewriteFlashcard] Rewriting flashcard: %s", flashcardID)
+	card, err := c.store.GetFlashcard(ctx, userID, flashcardID)
+	if err != nil {
+		log.Printf("[Core.RewriteFlashcard] Failed to get flashcard: %v", err)
+		return "", "", fmt.Errorf("failed to get flashcard: %w", err)
+	}
+
+	question, answer, err := c.ai.RewriteFlashcard(card.Question, card.Answer)
+	if err != nil {
+		log.Printf("[Core.RewriteFlashcard] AI rewrite failed: %v", err)
+		return "", "", fmt.Errorf("failed to rewrite flashcard: %w", err)
+	}
+	return question, answer, nil
+}
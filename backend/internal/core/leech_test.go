@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+func TestLeech(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        learning.LeechAction
+		wantSuspended bool
+	}{
+		{name: "tag", action: learning.LeechAction_LEECH_ACTION_TAG},
+		{name: "suspend", action: learning.LeechAction_LEECH_ACTION_SUSPEND, wantSuspended: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			_, ids := env.addMaterial(t, "Capitals", &learning.Flashcard{Question: "Capital of France?", Answer: "Paris"})
+			settings := DefaultStudySettings()
+			settings.LeechThreshold, settings.LeechAction = 2, tt.action
+			if _, err := env.core.UpdateStudySettings(ctx, env.userID, settings); err != nil {
+				t.Fatalf("UpdateStudySettings: %v", err)
+			}
+
+			// Failing a new card isn't a lapse; failing it once learned is, and
+			// the second lapse reaches the threshold
+			steps := []struct {
+				grade      learning.ReviewGrade
+				wantLapses int32
+				wantLeech  bool
+			}{
+				{again, 0, false},
+				{good, 0, false},
+				{again, 1, false},
+				{good, 1, false},
+				{again, 2, true},
+				{again, 3, true},
+			}
+			for i, step := range steps {
+				schedule, err := env.core.ReviewFlashcard(ctx, env.userID, ids[0], step.grade, ReviewMeta{})
+				if err != nil {
+					t.Fatalf("review %d: ReviewFlashcard: %v", i+1, err)
+				}
+				card, err := env.store.GetFlashcard(ctx, env.userID, ids[0])
+				if err != nil {
+					t.Fatalf("GetFlashcard: %v", err)
+				}
+				suspended := card.Status == learning.FlashcardStatus_FLASHCARD_STATUS_SUSPENDED
+				wantSuspended := step.wantLeech && tt.wantSuspended
+				if card.Lapses != step.wantLapses || card.IsLeech != step.wantLeech || suspended != wantSuspended {
+					t.Errorf("after review %d (%s): %d lapses, leech %v, suspended %v; want %d, %v, %v",
+						i+1, step.grade, card.Lapses, card.IsLeech, suspended, step.wantLapses, step.wantLeech, wantSuspended)
+				}
+				if schedule.IsLeech != card.IsLeech || schedule.Suspended != suspended {
+					t.Errorf("after review %d: returned schedule leech %v, suspended %v; stored %v, %v",
+						i+1, schedule.IsLeech, schedule.Suspended, card.IsLeech, suspended)
+				}
+			}
+
+			leeches, err := env.core.GetLeeches(ctx, env.userID)
+			if err != nil {
+				t.Fatalf("GetLeeches: %v", err)
+			}
+			if len(leeches) != 1 || leeches[0].Id != ids[0] {
+				t.Errorf("GetLeeches = %v, want the card", leeches)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/amityadav/landr/internal/store"
+)
+
+const (
+	// MinReviewsForOptimization is how many graded recalls (reviews that are
+	// not a card's first) a user needs before we fit personal FSRS weights.
+	MinReviewsForOptimization = 100
+
+	optimizerIterations   = 200
+	optimizerLearningRate = 0.05
+	optimizerStep         = 1e-4
+
+	// optimizerMaxSamples and optimizerTimeLimit bound the cost of a fit, which
+	// runs inline when a user asks for it. Each iteration evaluates the loss
+	// twice per weight over every sample.
+	optimizerMaxSamples = 2000
+	optimizerTimeLimit  = 10 * time.Second
+)
+
+// ScheduleOptimization is the outcome of fitting FSRS weights to a user's review history.
+type ScheduleOptimization struct {
+	Weights                 []float64
+	ReviewCount             int32
+	LogLoss                 float64
+	DesiredRetention        float64
+	PredictedRetentionFSRS  float64
+	PredictedRetentionFixed float64
+	FixedIntervalsDays      []int32
+	FSRSIntervalsDays       []int32
+	UsedDefaultWeights      bool
+	OptimizedAt             time.Time
+}
+
+// fsrsSample is a single recall prediction: a review of a card with known
+// prior history, and whether the user remembered it.
+type fsrsSample struct {
+	history     []float64 // ratings of the earlier reviews
+	elapsed     []float64 // days elapsed before each earlier review (first is ignored)
+	elapsedDays float64
+	recalled    bool
+}
+
+// OptimizeSchedule fits FSRS weights to the user's review log, stores them,
+// and compares predicted retention against the fixed schedule.
+func (c *LearningCore) OptimizeSchedule(ctx context.Context, userID string) (*ScheduleOptimization, error) {
+	log.Printf("[Core.OptimizeSchedule] Optimizing schedule for userID: %s", userID)
+
+	logs, err := c.store.GetReviewLogs(ctx, userID)
+	if err != nil {
+		log.Printf("[Core.OptimizeSchedule] Failed to get review logs: %v", err)
+		return nil, fmt.Errorf("failed to get review logs: %w", err)
+	}
+
+	samples := thinSamples(buildFSRSSamples(logs), optimizerMaxSamples)
+	log.Printf("[Core.OptimizeSchedule] Built %d samples from %d review logs", len(samples), len(logs))
+
+	result := &ScheduleOptimization{
+		Weights:          append([]float64(nil), DefaultFSRSWeights...),
+		ReviewCount:      int32(len(logs)),
+		DesiredRetention: DefaultDesiredRetention,
+		OptimizedAt:      time.Now(),
+	}
+
+	if len(samples) < MinReviewsForOptimization {
+		log.Printf("[Core.OptimizeSchedule] Not enough history (%d < %d), using default weights", len(samples), MinReviewsForOptimization)
+		result.UsedDefaultWeights = true
+	} else {
+		fitCtx, cancel := context.WithTimeout(ctx, optimizerTimeLimit)
+		result.Weights = fitFSRSWeights(fitCtx, samples)
+		cancel()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := c.store.SaveFSRSParams(ctx, userID, result.Weights, result.ReviewCount); err != nil {
+			log.Printf("[Core.OptimizeSchedule] Failed to save params: %v", err)
+			return nil, fmt.Errorf("failed to save fsrs params: %w", err)
+		}
+	}
+	result.LogLoss = fsrsLogLoss(fsrsModel(result.Weights), samples)
+
+	// Compare how a card answered "Good" every time would fare under each schedule
+	m := fsrsModel(result.Weights)
+	for stage := int32(1); stage <= MaxStage; stage++ {
+		result.FixedIntervalsDays = append(result.FixedIntervalsDays, fixedIntervalDays(stage))
+	}
+	result.PredictedRetentionFixed = simulateRetention(m, result.FixedIntervalsDays)
+	result.FSRSIntervalsDays = simulateFSRSIntervals(m, result.DesiredRetention, len(result.FixedIntervalsDays))
+	result.PredictedRetentionFSRS = simulateRetention(m, result.FSRSIntervalsDays)
+
+	log.Printf("[Core.OptimizeSchedule] Log loss: %.4f, predicted retention FSRS: %.3f, fixed: %.3f",
+		result.LogLoss, result.PredictedRetentionFSRS, result.PredictedRetentionFixed)
+	return result, nil
+}
+
+// StartScheduleOptimizer periodically refits FSRS weights for users who have
+// logged enough new reviews since their last optimization. It returns
+// immediately; the optimizer stops when ctx is cancelled.
+func (c *LearningCore) StartScheduleOptimizer(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.runScheduleOptimizer(ctx)
+			}
+		}
+	}()
+}
+
+func (c *LearningCore) runScheduleOptimizer(ctx context.Context) {
+	userIDs, err := c.store.GetUsersNeedingOptimization(ctx, MinReviewsForOptimization)
+	if err != nil {
+		log.Printf("[Core.ScheduleOptimizer] Failed to list users: %v", err)
+		return
+	}
+	log.Printf("[Core.ScheduleOptimizer] %d users need optimization", len(userIDs))
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := c.OptimizeSchedule(ctx, userID); err != nil {
+			log.Printf("[Core.ScheduleOptimizer] Failed for userID %s: %v", userID, err)
+		}
+	}
+}
+
+// buildFSRSSamples groups review logs by card (logs must be ordered by card
+// and review time) and turns every review after a card's first into a sample.
+func buildFSRSSamples(logs []*store.ReviewLog) []fsrsSample {
+	var samples []fsrsSample
+	var ratings, elapsed []float64
+	var cardID string
+	for _, l := range logs {
+		if l.FlashcardID != cardID {
+			cardID = l.FlashcardID
+			ratings, elapsed = nil, nil
+		}
+		g := float64(l.Grade)
+		if len(ratings) > 0 && l.ElapsedDays >= 1 {
+			samples = append(samples, fsrsSample{
+				history:     ratings,
+				elapsed:     elapsed,
+				elapsedDays: l.ElapsedDays,
+				recalled:    g > 1,
+			})
+		}
+		ratings = append(append([]float64(nil), ratings...), g)
+		elapsed = append(append([]float64(nil), elapsed...), l.ElapsedDays)
+	}
+	return samples
+}
+
+// thinSamples keeps at most limit samples, spread evenly over the review log.
+func thinSamples(samples []fsrsSample, limit int) []fsrsSample {
+	if len(samples) <= limit {
+		return samples
+	}
+	thinned := make([]fsrsSample, limit)
+	for i := range thinned {
+		thinned[i] = samples[i*len(samples)/limit]
+	}
+	return thinned
+}
+
+// replay returns the memory stability after the reviews in a sample's history.
+func (w fsrsModel) replay(s fsrsSample) float64 {
+	stability, difficulty := w.initStability(s.history[0]), w.initDifficulty(s.history[0])
+	for i := 1; i < len(s.history); i++ {
+		stability, difficulty = w.next(stability, difficulty, s.elapsed[i], s.history[i])
+	}
+	return stability
+}
+
+// fsrsLogLoss is the mean binary cross-entropy of predicted recall over samples.
+func fsrsLogLoss(w fsrsModel, samples []fsrsSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var loss float64
+	for _, s := range samples {
+		r := w.retrievability(s.elapsedDays, w.replay(s))
+		r = math.Min(math.Max(r, 1e-6), 1-1e-6)
+		if s.recalled {
+			loss -= math.Log(r)
+		} else {
+			loss -= math.Log(1 - r)
+		}
+	}
+	return loss / float64(len(samples))
+}
+
+// fitFSRSWeights minimizes log loss with projected gradient descent using
+// central finite differences, starting from the default weights. It returns
+// the weights reached so far once ctx is done.
+func fitFSRSWeights(ctx context.Context, samples []fsrsSample) []float64 {
+	w := append([]float64(nil), DefaultFSRSWeights...)
+	grad := make([]float64, len(w))
+	for iter := 0; iter < optimizerIterations; iter++ {
+		if ctx.Err() != nil {
+			log.Printf("[Core.fitFSRSWeights] Stopped after %d of %d iterations", iter, optimizerIterations)
+			break
+		}
+		for i := range w {
+			orig := w[i]
+			w[i] = orig + optimizerStep
+			up := fsrsLogLoss(fsrsModel(w), samples)
+			w[i] = orig - optimizerStep
+			down := fsrsLogLoss(fsrsModel(w), samples)
+			w[i] = orig
+			grad[i] = (up - down) / (2 * optimizerStep)
+		}
+		for i := range w {
+			w[i] -= optimizerLearningRate * grad[i]
+			w[i] = math.Max(fsrsWeightBounds[i][0], math.Min(w[i], fsrsWeightBounds[i][1]))
+		}
+	}
+	return w
+}
+
+// simulateRetention returns the mean predicted recall of a card answered
+// "Good" at every review, when reviewed after each of the given intervals.
+func simulateRetention(w fsrsModel, intervals []int32) float64 {
+	if len(intervals) == 0 {
+		return 0
+	}
+	good := float64(3)
+	stability, difficulty := w.initStability(good), w.initDifficulty(good)
+	var total float64
+	for _, days := range intervals {
+		total += w.retrievability(float64(days), stability)
+		stability, difficulty = w.next(stability, difficulty, float64(days), good)
+	}
+	return total / float64(len(intervals))
+}
+
+// simulateFSRSIntervals returns the first n intervals FSRS schedules for a
+// card answered "Good" at every review.
+func simulateFSRSIntervals(w fsrsModel, retention float64, n int) []int32 {
+	good := float64(3)
+	stability, difficulty := w.initStability(good), w.initDifficulty(good)
+	intervals := make([]int32, 0, n)
+	for i := 0; i < n; i++ {
+		days := w.intervalDays(stability, retention)
+		intervals = append(intervals, days)
+		stability, difficulty = w.next(stability, difficulty, float64(days), good)
+	}
+	return intervals
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+func TestBuildFSRSSamples(t *testing.T) {
+	logs := []*store.ReviewLog{
+		{FlashcardID: "a", Grade: good, ElapsedDays: 0},
+		{FlashcardID: "a", Grade: again, ElapsedDays: 0.2}, // Same day
+		{FlashcardID: "a", Grade: good, ElapsedDays: 3},
+		{FlashcardID: "a", Grade: again, ElapsedDays: 8},
+		{FlashcardID: "b", Grade: easy, ElapsedDays: 0},
+		{FlashcardID: "c", Grade: hard, ElapsedDays: 0},
+		{FlashcardID: "c", Grade: good, ElapsedDays: 1},
+	}
+	want := []fsrsSample{
+		{history: []float64{3, 1}, elapsed: []float64{0, 0.2}, elapsedDays: 3, recalled: true},
+		{history: []float64{3, 1, 3}, elapsed: []float64{0, 0.2, 3}, elapsedDays: 8, recalled: false},
+		{history: []float64{2}, elapsed: []float64{0}, elapsedDays: 1, recalled: true},
+	}
+
+	got := buildFSRSSamples(logs)
+	if len(got) != len(want) {
+		t.Fatalf("got %d samples, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if !slices.Equal(g.history, w.history) || !slices.Equal(g.elapsed, w.elapsed) || g.elapsedDays != w.elapsedDays || g.recalled != w.recalled {
+			t.Errorf("sample %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestThinSamples(t *testing.T) {
+	samples := make([]fsrsSample, 10)
+	for i := range samples {
+		samples[i].elapsedDays = float64(i)
+	}
+	if got := thinSamples(samples, 20); len(got) != 10 {
+		t.Errorf("kept %d of 10 samples under the limit, want all", len(got))
+	}
+	got := thinSamples(samples, 4)
+	var days []float64
+	for _, s := range got {
+		days = append(days, s.elapsedDays)
+	}
+	if !slices.Equal(days, []float64{0, 2, 5, 7}) {
+		t.Errorf("kept samples %v, want [0 2 5 7]", days)
+	}
+}
+
+func TestFitFSRSWeightsLowersLogLoss(t *testing.T) {
+	// A user who forgets much faster than the defaults expect: after a first
+	// "Good" their memory lasts a day, not three.
+	var samples []fsrsSample
+	for days := 1; days <= 30; days++ {
+		recalled := int(math.Round(10 * fsrsModel(DefaultFSRSWeights).retrievability(float64(days), 1)))
+		for i := 0; i < 10; i++ {
+			samples = append(samples, fsrsSample{
+				history:     []float64{3},
+				elapsed:     []float64{0},
+				elapsedDays: float64(days),
+				recalled:    i < recalled,
+			})
+		}
+	}
+
+	before := fsrsLogLoss(fsrsModel(DefaultFSRSWeights), samples)
+	weights := fitFSRSWeights(context.Background(), samples)
+	after := fsrsLogLoss(fsrsModel(weights), samples)
+	if after >= before {
+		t.Errorf("log loss %.4f after fitting, want it below %.4f", after, before)
+	}
+	if weights[2] >= DefaultFSRSWeights[2] {
+		t.Errorf("initial Good stability %.3f, want it below the default %.3f", weights[2], DefaultFSRSWeights[2])
+	}
+	for i, w := range weights {
+		if w < fsrsWeightBounds[i][0] || w > fsrsWeightBounds[i][1] {
+			t.Errorf("weight %d = %v, outside %v", i, w, fsrsWeightBounds[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if weights := fitFSRSWeights(ctx, samples); !slices.Equal(weights, DefaultFSRSWeights) {
+		t.Errorf("fit with a cancelled context moved the weights to %v", weights)
+	}
+}
+
+func TestOptimizeSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		cards       int // Each gives two samples
+		wantDefault bool
+	}{
+		{name: "no reviews", cards: 0, wantDefault: true},
+		{name: "below the minimum", cards: MinReviewsForOptimization/2 - 1, wantDefault: true},
+		{name: "at the minimum", cards: MinReviewsForOptimization / 2, wantDefault: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			cards := make([]*learning.Flashcard, tt.cards)
+			for i := range cards {
+				cards[i] = &learning.Flashcard{Question: fmt.Sprintf("Q%d", i), Answer: "A"}
+			}
+			_, ids := env.addMaterial(t, "Content", cards...)
+			reviewedAt := time.Now().Add(-time.Hour)
+			for _, id := range ids {
+				for _, r := range []struct {
+					grade   learning.ReviewGrade
+					elapsed float64
+				}{{good, 0}, {good, 3}, {again, 10}} {
+					reviewedAt = reviewedAt.Add(time.Millisecond)
+					entry := &store.ReviewLog{FlashcardID: id, UserID: env.userID, Grade: r.grade, ElapsedDays: r.elapsed, ReviewedAt: reviewedAt}
+					if err := env.store.RecordReview(ctx, store.FlashcardSchedule{NextReviewAt: reviewedAt, LastReviewedAt: reviewedAt}, entry); err != nil {
+						t.Fatalf("RecordReview: %v", err)
+					}
+				}
+			}
+
+			result, err := env.core.OptimizeSchedule(ctx, env.userID)
+			if err != nil {
+				t.Fatalf("OptimizeSchedule: %v", err)
+			}
+			if result.ReviewCount != int32(3*tt.cards) {
+				t.Errorf("ReviewCount = %d, want %d", result.ReviewCount, 3*tt.cards)
+			}
+			saved, err := env.store.GetFSRSParams(ctx, env.userID)
+			if err != nil {
+				t.Fatalf("GetFSRSParams: %v", err)
+			}
+			if result.UsedDefaultWeights != tt.wantDefault {
+				t.Fatalf("UsedDefaultWeights = %v, want %v", result.UsedDefaultWeights, tt.wantDefault)
+			}
+			if tt.wantDefault {
+				if !slices.Equal(result.Weights, DefaultFSRSWeights) || saved != nil {
+					t.Errorf("got weights %v and saved %v, want the defaults and nothing saved", result.Weights, saved)
+				}
+				return
+			}
+			if !slices.Equal(saved, result.Weights) {
+				t.Errorf("saved %v, want the fitted %v", saved, result.Weights)
+			}
+			if len(result.FixedIntervalsDays) != MaxStage || len(result.FSRSIntervalsDays) != MaxStage {
+				t.Errorf("compared %d fixed and %d FSRS intervals, want %d", len(result.FixedIntervalsDays), len(result.FSRSIntervalsDays), MaxStage)
+			}
+		})
+	}
+}
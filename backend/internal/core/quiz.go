@@ -0,0 +1,258 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/amityadav/landr/internal/ai"
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// DefaultQuizQuestions is the quiz length when the client does not ask for one.
+	DefaultQuizQuestions = 10
+	// MaxQuizQuestions caps how long a single quiz can be.
+	MaxQuizQuestions = 50
+
+	quizDistractors = 3
+	quizCardPool    = 200 // Cards loaded per quiz; the extras supply distractors
+	quizCandidates  = 10  // Other cards' answers offered to the AI per question
+)
+
+// GenerateQuiz builds a multiple-choice quiz from the cards of a material, or
+// of every material with a tag. Wrong choices are written by the AI from the
+// material content and other cards' answers, falling back to other cards'
+// answers alone if the AI is unavailable. Cards no wrong choice can be found
+// for are left out.
+func (c *LearningCore) GenerateQuiz(ctx context.Context, userID, materialID, tag string, questionCount int32) (*learning.Quiz, error) {
+	log.Printf("[Core.GenerateQuiz] Generating quiz for userID: %s, materialID: %s, tag: %s", userID, materialID, tag)
+
+	if questionCount <= 0 {
+		questionCount = DefaultQuizQuestions
+	}
+	questionCount = min(questionCount, MaxQuizQuestions)
+
+	pool, err := c.store.GetQuizCards(ctx, userID, materialID, tag, quizCardPool)
+	if err != nil {
+		log.Printf("[Core.GenerateQuiz] Failed to get cards: %v", err)
+		return nil, err
+	}
+	if len(pool) == 0 {
+		return nil, fmt.Errorf("no flashcards to build a quiz from: %w", store.ErrNotFound)
+	}
+	cards := pool[:min(int(questionCount), len(pool))]
+
+	var content string
+	if materialID != "" {
+		if content, _, _, err = c.store.GetMaterialContent(ctx, userID, materialID); err != nil {
+			log.Printf("[Core.GenerateQuiz] Failed to get material content: %v", err)
+			return nil, err
+		}
+	}
+
+	requests := make([]ai.DistractorRequest, len(cards))
+	for i, card := range cards {
+		requests[i] = ai.DistractorRequest{
+			Question:   card.Question,
+			Answer:     card.Answer,
+			Candidates: otherAnswers(pool, card, quizCandidates),
+		}
+	}
+	distractors, err := c.ai.GenerateDistractors(content, requests, quizDistractors)
+	if err != nil {
+		log.Printf("[Core.GenerateQuiz] AI distractors failed, using other cards' answers: %v", err)
+		distractors = make([][]string, len(cards))
+	}
+
+	// A question needs at least one wrong choice, so cards without any are left out
+	var questions []*store.QuizQuestion
+	for i, card := range cards {
+		wrong := uniqueDistractors(card.Answer, distractors[i], otherAnswers(pool, card, len(pool)))
+		if len(wrong) == 0 {
+			log.Printf("[Core.GenerateQuiz] Skipping flashcard %s: no wrong choices for it", card.Id)
+			continue
+		}
+		choices := append([]string{card.Answer}, wrong...)
+		rand.Shuffle(len(choices), func(a, b int) { choices[a], choices[b] = choices[b], choices[a] })
+
+		q := &store.QuizQuestion{
+			FlashcardID: card.Id,
+			Question:    card.Question,
+			Choices:     choices,
+		}
+		for j, choice := range choices {
+			if choice == card.Answer {
+				q.CorrectIndex = int32(j)
+			}
+		}
+		questions = append(questions, q)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("not enough distinct answers to build a quiz from: %w", store.ErrNotFound)
+	}
+
+	quizID, err := c.store.CreateQuiz(ctx, userID, materialID, tag, questions)
+	if err != nil {
+		log.Printf("[Core.GenerateQuiz] Failed to save quiz: %v", err)
+		return nil, err
+	}
+
+	quiz := &learning.Quiz{Id: quizID}
+	for _, q := range questions {
+		quiz.Questions = append(quiz.Questions, &learning.QuizQuestion{
+			Id:          q.ID,
+			FlashcardId: q.FlashcardID,
+			Question:    q.Question,
+			Choices:     q.Choices,
+		})
+	}
+	log.Printf("[Core.GenerateQuiz] Created quiz %s with %d questions", quizID, len(quiz.Questions))
+	return quiz, nil
+}
+
+// SubmitQuizAnswers scores a quiz attempt and saves it. With applyToSchedule,
+// each card that is due today is reviewed as Good if answered correctly and
+// Again otherwise; cards that are not due yet keep their schedule. Nothing is
+// rescheduled unless the attempt is saved.
+func (c *LearningCore) SubmitQuizAnswers(ctx context.Context, userID, quizID string, answers []*learning.QuizAnswer, applyToSchedule bool) (*learning.QuizResult, error) {
+	log.Printf("[Core.SubmitQuizAnswers] Scoring quiz: %s for userID: %s, %d answers", quizID, userID, len(answers))
+
+	questions, err := c.store.GetQuizQuestions(ctx, userID, quizID)
+	if err != nil {
+		log.Printf("[Core.SubmitQuizAnswers] Failed to get quiz: %v", err)
+		return nil, err
+	}
+
+	chosen := make(map[string]int32, len(answers))
+	for _, a := range answers {
+		chosen[a.QuestionId] = a.ChoiceIndex
+	}
+
+	now := time.Now()
+	attempt := &store.QuizAttempt{
+		QuizID:      quizID,
+		UserID:      userID,
+		TotalCount:  int32(len(questions)),
+		SubmittedAt: now,
+	}
+	result := &learning.QuizResult{QuizId: quizID, TotalCount: attempt.TotalCount}
+	for _, q := range questions {
+		choice, ok := chosen[q.ID]
+		if !ok {
+			choice = -1
+		}
+		correct := choice == q.CorrectIndex
+		if correct {
+			attempt.CorrectCount++
+		}
+		attempt.Answers = append(attempt.Answers, store.QuizAttemptAnswer{QuestionID: q.ID, ChosenIndex: choice, Correct: correct})
+		result.Results = append(result.Results, &learning.QuizQuestionResult{
+			QuestionId:   q.ID,
+			FlashcardId:  q.FlashcardID,
+			Correct:      correct,
+			CorrectIndex: q.CorrectIndex,
+			ChosenIndex:  choice,
+		})
+	}
+	result.CorrectCount = attempt.CorrectCount
+
+	// Cards are only rescheduled if the attempt is saved too, and the other way round
+	err = c.store.WithTx(ctx, func(tx store.Store) error {
+		if applyToSchedule {
+			rescheduled, err := c.withStore(tx).applyQuizOutcomes(ctx, userID, quizID, result.Results, now)
+			if err != nil {
+				return fmt.Errorf("failed to apply outcomes: %w", err)
+			}
+			result.CardsRescheduled = rescheduled
+			attempt.AppliedToSchedule = true
+		}
+		return tx.SaveQuizAttempt(ctx, attempt)
+	})
+	if err != nil {
+		log.Printf("[Core.SubmitQuizAnswers] Failed to save attempt: %v", err)
+		return nil, err
+	}
+	result.AttemptId = attempt.ID
+	result.SubmittedAt = timestamppb.New(now)
+
+	log.Printf("[Core.SubmitQuizAnswers] Scored %d/%d, rescheduled %d cards", result.CorrectCount, result.TotalCount, result.CardsRescheduled)
+	return result, nil
+}
+
+// applyQuizOutcomes reviews the quiz's cards that are due today and returns how many were rescheduled.
+func (c *LearningCore) applyQuizOutcomes(ctx context.Context, userID, quizID string, results []*learning.QuizQuestionResult, now time.Time) (int32, error) {
+	limits, err := c.remainingToday(ctx, userID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var rescheduled int32
+	for _, r := range results {
+		if r.FlashcardId == "" {
+			continue // Card was deleted since the quiz was generated
+		}
+		card, err := c.store.GetFlashcard(ctx, userID, r.FlashcardId)
+		if err != nil {
+			log.Printf("[Core.applyQuizOutcomes] Skipping card %s: %v", r.FlashcardId, err)
+			continue
+		}
+		if !card.NextReviewAt.AsTime().Before(limits.dueBefore) {
+			continue
+		}
+
+		grade := learning.ReviewGrade_REVIEW_GRADE_AGAIN
+		if r.Correct {
+			grade = learning.ReviewGrade_REVIEW_GRADE_GOOD
+		}
+		meta := ReviewMeta{SessionID: "quiz:" + quizID}
+		if _, err := c.ReviewFlashcard(ctx, userID, r.FlashcardId, grade, meta); err != nil {
+			return rescheduled, err
+		}
+		rescheduled++
+	}
+	return rescheduled, nil
+}
+
+// otherAnswers returns up to n answers of cards in pool other than card.
+func otherAnswers(pool []*learning.Flashcard, card *learning.Flashcard, n int) []string {
+	var answers []string
+	for _, other := range pool {
+		if len(answers) >= n {
+			break
+		}
+		if other.Id != card.Id {
+			answers = append(answers, other.Answer)
+		}
+	}
+	return answers
+}
+
+// uniqueDistractors picks up to quizDistractors wrong choices, preferring the
+// AI's and topping up from fallback, skipping blanks and anything that matches
+// the correct answer or an earlier choice.
+func uniqueDistractors(answer string, preferred, fallback []string) []string {
+	seen := map[string]bool{normalizeChoice(answer): true}
+	var picked []string
+	for _, choice := range append(append([]string(nil), preferred...), fallback...) {
+		key := normalizeChoice(choice)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		picked = append(picked, strings.TrimSpace(choice))
+		if len(picked) == quizDistractors {
+			break
+		}
+	}
+	return picked
+}
+
+func normalizeChoice(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
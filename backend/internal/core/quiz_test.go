@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+func TestGenerateQuiz(t *testing.T) {
+	errAI := errors.New("ai unavailable")
+	tests := []struct {
+		name          string
+		answers       []string
+		distractors   [][]string // Scripted AI reply, one entry per question
+		aiErr         error
+		wantQuestions int
+		wantErr       error
+	}{
+		{
+			name:          "AI distractors",
+			answers:       []string{"Paris", "Rome"},
+			distractors:   [][]string{{"Lyon", "Nice", "Lille"}, {"Milan", "Turin", "Naples"}},
+			wantQuestions: 2,
+		},
+		{
+			name:          "other answers when the AI fails",
+			answers:       []string{"Paris", "Rome", "Berlin"},
+			aiErr:         errAI,
+			wantQuestions: 3,
+		},
+		{
+			name:    "a single card and no AI",
+			answers: []string{"Paris"},
+			aiErr:   errAI,
+			wantErr: store.ErrNotFound,
+		},
+		{
+			name:    "only repeated answers and no AI",
+			answers: []string{"Paris", " paris"},
+			aiErr:   errAI,
+			wantErr: store.ErrNotFound,
+		},
+		{
+			name:          "a card without wrong choices is left out",
+			answers:       []string{"Paris", "Paris"},
+			distractors:   [][]string{{"Lyon"}, {"paris", ""}},
+			wantQuestions: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			var cards []*learning.Flashcard
+			for _, answer := range tt.answers {
+				cards = append(cards, &learning.Flashcard{Question: "Capital?", Answer: answer})
+			}
+			materialID, _ := env.addMaterial(t, "Capitals of Europe", cards...)
+			env.ai.AddDistractors(tt.distractors, tt.aiErr)
+
+			quiz, err := env.core.GenerateQuiz(context.Background(), env.userID, materialID, "", 0)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GenerateQuiz: got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateQuiz: %v", err)
+			}
+			if len(quiz.Questions) != tt.wantQuestions {
+				t.Fatalf("got %d questions, want %d", len(quiz.Questions), tt.wantQuestions)
+			}
+
+			stored, err := env.store.GetQuizQuestions(context.Background(), env.userID, quiz.Id)
+			if err != nil {
+				t.Fatalf("GetQuizQuestions: %v", err)
+			}
+			for _, q := range stored {
+				if len(q.Choices) < 2 {
+					t.Errorf("question %s has choices %q, want at least 2", q.ID, q.Choices)
+				}
+				card, err := env.store.GetFlashcard(context.Background(), env.userID, q.FlashcardID)
+				if err != nil {
+					t.Fatalf("GetFlashcard: %v", err)
+				}
+				if q.Choices[q.CorrectIndex] != card.Answer {
+					t.Errorf("question %s: correct choice %q, want %q", q.ID, q.Choices[q.CorrectIndex], card.Answer)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmitQuizAnswers(t *testing.T) {
+	errSave := errors.New("disk full")
+	tests := []struct {
+		name            string
+		applyToSchedule bool
+		fail            map[string]error
+		wantRescheduled int32
+		wantErr         error
+	}{
+		{"scores without rescheduling", false, nil, 0, nil},
+		{"reschedules due cards", true, nil, 2, nil},
+		{"saving the attempt fails", true, map[string]error{"SaveQuizAttempt": errSave}, 0, errSave},
+		{"rescheduling fails", true, map[string]error{"RecordReview": errSave}, 0, errSave},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTestEnv(t)
+			materialID, ids := env.addMaterial(t, "Capitals of Europe",
+				&learning.Flashcard{Question: "France?", Answer: "Paris"},
+				&learning.Flashcard{Question: "Italy?", Answer: "Rome"})
+			env.ai.AddDistractors(nil, errors.New("ai unavailable"))
+			quiz, err := env.core.GenerateQuiz(ctx, env.userID, materialID, "", 0)
+			if err != nil {
+				t.Fatalf("GenerateQuiz: %v", err)
+			}
+
+			// Answer the first question right and the second wrong
+			questions, err := env.store.GetQuizQuestions(ctx, env.userID, quiz.Id)
+			if err != nil {
+				t.Fatalf("GetQuizQuestions: %v", err)
+			}
+			answers := []*learning.QuizAnswer{
+				{QuestionId: questions[0].ID, ChoiceIndex: questions[0].CorrectIndex},
+				{QuestionId: questions[1].ID, ChoiceIndex: 1 - questions[1].CorrectIndex},
+			}
+
+			for method, err := range tt.fail {
+				env.faults.fail[method] = err
+			}
+			result, err := env.core.SubmitQuizAnswers(ctx, env.userID, quiz.Id, answers, tt.applyToSchedule)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubmitQuizAnswers: got %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if result.CorrectCount != 1 || result.TotalCount != 2 || result.CardsRescheduled != tt.wantRescheduled {
+					t.Errorf("got %d/%d correct, %d rescheduled, want 1/2 and %d",
+						result.CorrectCount, result.TotalCount, result.CardsRescheduled, tt.wantRescheduled)
+				}
+			}
+
+			// Either both cards were rescheduled, or neither was
+			logs, err := env.store.GetReviewLogs(ctx, env.userID)
+			if err != nil {
+				t.Fatalf("GetReviewLogs: %v", err)
+			}
+			if len(logs) != int(tt.wantRescheduled) {
+				t.Errorf("%d reviews recorded, want %d", len(logs), tt.wantRescheduled)
+			}
+			for _, id := range ids {
+				card, err := env.store.GetFlashcard(ctx, env.userID, id)
+				if err != nil {
+					t.Fatalf("GetFlashcard: %v", err)
+				}
+				if reviewed := card.LastReviewedAt != nil; reviewed != (tt.wantRescheduled > 0) {
+					t.Errorf("card %s reviewed = %v, want %v", id, reviewed, tt.wantRescheduled > 0)
+				}
+			}
+		})
+	}
+}
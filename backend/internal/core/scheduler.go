@@ -0,0 +1,185 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+const (
+	SchedulerFixed = "fixed"
+	SchedulerSM2   = "sm2"
+	SchedulerFSRS  = "fsrs"
+
+	// MaxStage is the highest stage a card can reach on the fixed schedule.
+	MaxStage = 5
+
+	defaultEaseFactor = 2.5
+	minEaseFactor     = 1.3
+)
+
+// Scheduler decides when a flashcard should be reviewed next based on how
+// well the user recalled it. Schedulers work in whole study days: elapsedDays
+// is the number of the user's study days since the card was last reviewed, and
+// the caller turns the returned IntervalDays into a due time on the user's
+// calendar (NextReviewAt and LastReviewedAt are left unset).
+type Scheduler interface {
+	Name() string
+	Schedule(card *learning.Flashcard, grade learning.ReviewGrade, elapsedDays int32) store.FlashcardSchedule
+}
+
+// NewScheduler returns the scheduler registered under name.
+// An empty name selects the fixed schedule.
+func NewScheduler(name string) (Scheduler, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", SchedulerFixed:
+		return FixedScheduler{}, nil
+	case SchedulerSM2:
+		return SM2Scheduler{}, nil
+	case SchedulerFSRS:
+		return NewFSRSScheduler(nil), nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler: %s", name)
+	}
+}
+
+// FixedScheduler is the original LandR schedule: each stage maps to a fixed
+// interval of 1, 3, 7, 15 and 30 days.
+type FixedScheduler struct{}
+
+func (FixedScheduler) Name() string { return SchedulerFixed }
+
+// fixedIntervalDays returns the review interval for a stage on the fixed schedule.
+// Stage 0: New card -> 1 day
+// Stage 1: 1 day -> 3 days
+// Stage 2: 3 days -> 7 days
+// Stage 3: 7 days -> 15 days
+// Stage 4: 15 days -> 30 days
+// Stage 5+: 30 days (max)
+func fixedIntervalDays(stage int32) int32 {
+	switch stage {
+	case 0, 1:
+		return 1
+	case 2:
+		return 3
+	case 3:
+		return 7
+	case 4:
+		return 15
+	default:
+		return 30
+	}
+}
+
+func (FixedScheduler) Schedule(card *learning.Flashcard, grade learning.ReviewGrade, _ int32) store.FlashcardSchedule {
+	nextStage := card.Stage
+	repetitions := card.Repetitions + 1
+	intervalDays := int32(1)
+
+	switch grade {
+	case learning.ReviewGrade_REVIEW_GRADE_AGAIN:
+		// Wrong = go back 1 stage and review again tomorrow
+		nextStage = card.Stage - 1
+		repetitions = 0
+	case learning.ReviewGrade_REVIEW_GRADE_HARD:
+		// Stay on the current stage
+		intervalDays = fixedIntervalDays(nextStage)
+	case learning.ReviewGrade_REVIEW_GRADE_EASY:
+		nextStage = card.Stage + 2
+		intervalDays = fixedIntervalDays(nextStage)
+	default:
+		nextStage = card.Stage + 1
+		intervalDays = fixedIntervalDays(nextStage)
+	}
+
+	if nextStage < 0 {
+		nextStage = 0
+	}
+	if nextStage > MaxStage {
+		nextStage = MaxStage
+	}
+
+	return store.FlashcardSchedule{
+		Stage:        nextStage,
+		EaseFactor:   easeOrDefault(card.EaseFactor),
+		IntervalDays: intervalDays,
+		Repetitions:  repetitions,
+		Stability:    card.Stability,
+		Difficulty:   card.Difficulty,
+	}
+}
+
+// SM2Scheduler implements the SuperMemo-2 algorithm. Each card keeps its own
+// ease factor which grows or shrinks with the quality of every answer.
+type SM2Scheduler struct{}
+
+func (SM2Scheduler) Name() string { return SchedulerSM2 }
+
+// sm2Quality maps a review grade onto the 0-5 quality scale used by SM-2.
+func sm2Quality(grade learning.ReviewGrade) float64 {
+	switch grade {
+	case learning.ReviewGrade_REVIEW_GRADE_AGAIN:
+		return 2
+	case learning.ReviewGrade_REVIEW_GRADE_HARD:
+		return 3
+	case learning.ReviewGrade_REVIEW_GRADE_EASY:
+		return 5
+	default:
+		return 4
+	}
+}
+
+func (SM2Scheduler) Schedule(card *learning.Flashcard, grade learning.ReviewGrade, _ int32) store.FlashcardSchedule {
+	q := sm2Quality(grade)
+	ease := easeOrDefault(card.EaseFactor)
+	ease += 0.1 - (5-q)*(0.08+(5-q)*0.02)
+	if ease < minEaseFactor {
+		ease = minEaseFactor
+	}
+
+	repetitions := card.Repetitions
+	var intervalDays int32
+	if q < 3 {
+		// Failed recall restarts the repetition sequence
+		repetitions = 0
+		intervalDays = 1
+	} else {
+		repetitions++
+		switch repetitions {
+		case 1:
+			intervalDays = 1
+		case 2:
+			intervalDays = 6
+		default:
+			intervalDays = int32(math.Round(float64(card.IntervalDays) * ease))
+		}
+		if intervalDays < 1 {
+			intervalDays = 1
+		}
+	}
+
+	// Keep stage meaningful for clients that still display it
+	stage := repetitions
+	if stage > MaxStage {
+		stage = MaxStage
+	}
+
+	return store.FlashcardSchedule{
+		Stage:        stage,
+		EaseFactor:   ease,
+		IntervalDays: intervalDays,
+		Repetitions:  repetitions,
+		Stability:    card.Stability,
+		Difficulty:   card.Difficulty,
+	}
+}
+
+func easeOrDefault(ease float64) float64 {
+	if ease <= 0 {
+		return defaultEaseFactor
+	}
+	return ease
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	again = learning.ReviewGrade_REVIEW_GRADE_AGAIN
+	hard  = learning.ReviewGrade_REVIEW_GRADE_HARD
+	good  = learning.ReviewGrade_REVIEW_GRADE_GOOD
+	easy  = learning.ReviewGrade_REVIEW_GRADE_EASY
+)
+
+func TestFixedIntervalDays(t *testing.T) {
+	want := map[int32]int32{0: 1, 1: 1, 2: 3, 3: 7, 4: 15, 5: 30, 9: 30}
+	for stage, days := range want {
+		if got := fixedIntervalDays(stage); got != days {
+			t.Errorf("fixedIntervalDays(%d) = %d, want %d", stage, got, days)
+		}
+	}
+}
+
+func TestFixedScheduler(t *testing.T) {
+	tests := []struct {
+		name      string
+		stage     int32
+		reps      int32
+		grade     learning.ReviewGrade
+		wantStage int32
+		wantDays  int32
+		wantReps  int32
+	}{
+		{name: "new card good", stage: 0, grade: good, wantStage: 1, wantDays: 1, wantReps: 1},
+		{name: "stage 1 good", stage: 1, reps: 1, grade: good, wantStage: 2, wantDays: 3, wantReps: 2},
+		{name: "stage 2 good", stage: 2, reps: 2, grade: good, wantStage: 3, wantDays: 7, wantReps: 3},
+		{name: "stage 3 good", stage: 3, reps: 3, grade: good, wantStage: 4, wantDays: 15, wantReps: 4},
+		{name: "stage 4 good", stage: 4, reps: 4, grade: good, wantStage: 5, wantDays: 30, wantReps: 5},
+		{name: "last stage good", stage: 5, reps: 5, grade: good, wantStage: 5, wantDays: 30, wantReps: 6},
+		{name: "hard stays", stage: 3, reps: 3, grade: hard, wantStage: 3, wantDays: 7, wantReps: 4},
+		{name: "easy skips a stage", stage: 2, reps: 2, grade: easy, wantStage: 4, wantDays: 15, wantReps: 3},
+		{name: "easy past the last stage", stage: 4, reps: 4, grade: easy, wantStage: 5, wantDays: 30, wantReps: 5},
+		{name: "again steps back", stage: 3, reps: 3, grade: again, wantStage: 2, wantDays: 1, wantReps: 0},
+		{name: "again on a new card", stage: 0, grade: again, wantStage: 0, wantDays: 1, wantReps: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &learning.Flashcard{Stage: tt.stage, Repetitions: tt.reps}
+			s := FixedScheduler{}.Schedule(card, tt.grade, 0)
+			if s.Stage != tt.wantStage || s.IntervalDays != tt.wantDays || s.Repetitions != tt.wantReps {
+				t.Errorf("got stage %d, %d days, %d reps; want stage %d, %d days, %d reps",
+					s.Stage, s.IntervalDays, s.Repetitions, tt.wantStage, tt.wantDays, tt.wantReps)
+			}
+			if s.EaseFactor != defaultEaseFactor {
+				t.Errorf("EaseFactor = %v, want %v", s.EaseFactor, defaultEaseFactor)
+			}
+		})
+	}
+}
+
+func TestSM2Progression(t *testing.T) {
+	tests := []struct {
+		name     string
+		grades   []learning.ReviewGrade
+		wantDays []int32
+		wantEase []float64
+	}{
+		{
+			name:     "good",
+			grades:   []learning.ReviewGrade{good, good, good, good},
+			wantDays: []int32{1, 6, 15, 38}, // 6×2.5 and 15×2.5 rounded
+			wantEase: []float64{2.5, 2.5, 2.5, 2.5},
+		},
+		{
+			name:     "easy",
+			grades:   []learning.ReviewGrade{easy, easy, easy},
+			wantDays: []int32{1, 6, 17}, // 6×2.8 rounded
+			wantEase: []float64{2.6, 2.7, 2.8},
+		},
+		{
+			name:     "hard",
+			grades:   []learning.ReviewGrade{hard, hard, hard},
+			wantDays: []int32{1, 6, 12}, // 6×2.08 rounded
+			wantEase: []float64{2.36, 2.22, 2.08},
+		},
+		{
+			name:     "lapse restarts the sequence",
+			grades:   []learning.ReviewGrade{good, good, good, again, good, good, good},
+			wantDays: []int32{1, 6, 15, 1, 1, 6, 13}, // 6×2.18 rounded
+			wantEase: []float64{2.5, 2.5, 2.5, 2.18, 2.18, 2.18, 2.18},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &learning.Flashcard{}
+			for i, grade := range tt.grades {
+				s := SM2Scheduler{}.Schedule(card, grade, card.IntervalDays)
+				if s.IntervalDays != tt.wantDays[i] || math.Abs(s.EaseFactor-tt.wantEase[i]) > 1e-9 {
+					t.Fatalf("review %d (%s): got %d days at ease %v, want %d days at ease %v",
+						i+1, grade, s.IntervalDays, s.EaseFactor, tt.wantDays[i], tt.wantEase[i])
+				}
+				card = &learning.Flashcard{
+					Stage:          s.Stage,
+					EaseFactor:     s.EaseFactor,
+					IntervalDays:   s.IntervalDays,
+					Repetitions:    s.Repetitions,
+					LastReviewedAt: timestamppb.Now(),
+				}
+			}
+		})
+	}
+}
+
+func TestSM2Ease(t *testing.T) {
+	tests := []struct {
+		name     string
+		ease     float64
+		grade    learning.ReviewGrade
+		wantEase float64
+	}{
+		{name: "again", ease: 2.5, grade: again, wantEase: 2.18},
+		{name: "hard", ease: 2.5, grade: hard, wantEase: 2.36},
+		{name: "good", ease: 2.5, grade: good, wantEase: 2.5},
+		{name: "easy", ease: 2.5, grade: easy, wantEase: 2.6},
+		{name: "unset ease starts at the default", ease: 0, grade: easy, wantEase: 2.6},
+		{name: "again at the floor", ease: 1.3, grade: again, wantEase: 1.3},
+		{name: "again near the floor", ease: 1.5, grade: again, wantEase: 1.3},
+		{name: "hard near the floor", ease: 1.4, grade: hard, wantEase: 1.3},
+		{name: "easy from the floor", ease: 1.3, grade: easy, wantEase: 1.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &learning.Flashcard{EaseFactor: tt.ease, Repetitions: 3, IntervalDays: 10, LastReviewedAt: timestamppb.Now()}
+			s := SM2Scheduler{}.Schedule(card, tt.grade, 10)
+			if math.Abs(s.EaseFactor-tt.wantEase) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", s.EaseFactor, tt.wantEase)
+			}
+		})
+	}
+}
+
+func TestSM2Lapse(t *testing.T) {
+	card := &learning.Flashcard{Stage: 5, EaseFactor: 2.5, IntervalDays: 38, Repetitions: 6, LastReviewedAt: timestamppb.Now()}
+	s := SM2Scheduler{}.Schedule(card, again, 38)
+	if s.Repetitions != 0 || s.Stage != 0 || s.IntervalDays != 1 {
+		t.Errorf("got %d reps at stage %d due in %d days, want 0 reps at stage 0 due in 1 day",
+			s.Repetitions, s.Stage, s.IntervalDays)
+	}
+}
+
+func TestSchedulersHandleUnknownGrades(t *testing.T) {
+	schedulers := []Scheduler{FixedScheduler{}, SM2Scheduler{}, NewFSRSScheduler(nil)}
+	grades := []learning.ReviewGrade{learning.ReviewGrade_REVIEW_GRADE_UNSPECIFIED, -1, 5, 42}
+	cards := map[string]*learning.Flashcard{
+		"new":      {},
+		"reviewed": {Stage: 2, Stability: 3, Difficulty: 5, EaseFactor: 2.5, IntervalDays: 3, Repetitions: 2, LastReviewedAt: timestamppb.Now()},
+	}
+
+	// Grades outside the scale count as Good
+	for _, scheduler := range schedulers {
+		for _, grade := range grades {
+			for name, card := range cards {
+				t.Run(scheduler.Name()+"/"+name+"/"+grade.String(), func(t *testing.T) {
+					got, want := scheduler.Schedule(card, grade, 3), scheduler.Schedule(card, good, 3)
+					if got != want {
+						t.Errorf("got %+v, want %+v", got, want)
+					}
+				})
+			}
+		}
+	}
+}
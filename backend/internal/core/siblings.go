@@ -0,0 +1,72 @@
+package core
+
+import (
+	"github.com/amityadav/landr/internal/cloze"
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// expandClozeCards turns every cloze card into one sibling card per cloze
+// number, all sharing a new note ID, so each deletion is scheduled on its own.
+// Basic cards and cloze cards without any deletions are returned unchanged.
+func expandClozeCards(cards []*learning.Flashcard) []*learning.Flashcard {
+	expanded := make([]*learning.Flashcard, 0, len(cards))
+	for _, card := range cards {
+		indices := cloze.Indices(card.Question)
+		if card.CardType != learning.CardType_CARD_TYPE_CLOZE || len(indices) == 0 {
+			expanded = append(expanded, card)
+			continue
+		}
+		noteID := store.NewUUID()
+		for _, index := range indices {
+			expanded = append(expanded, &learning.Flashcard{
+				Question:   card.Question,
+				Answer:     card.Answer,
+				CardType:   learning.CardType_CARD_TYPE_CLOZE,
+				NoteId:     noteID,
+				ClozeIndex: index,
+			})
+		}
+	}
+	return expanded
+}
+
+// addReverseCards pairs every basic card with a reverse sibling that asks for
+// the question given the answer. Both keep the pair's question and answer and
+// share a new note ID; only how they are rendered differs.
+func addReverseCards(cards []*learning.Flashcard) []*learning.Flashcard {
+	paired := make([]*learning.Flashcard, 0, 2*len(cards))
+	for _, card := range cards {
+		if card.CardType != learning.CardType_CARD_TYPE_BASIC {
+			paired = append(paired, card)
+			continue
+		}
+		card.NoteId = store.NewUUID()
+		paired = append(paired, card, &learning.Flashcard{
+			Question: card.Question,
+			Answer:   card.Answer,
+			CardType: learning.CardType_CARD_TYPE_REVERSE,
+			NoteId:   card.NoteId,
+		})
+	}
+	return paired
+}
+
+// renderFlashcards fills in the front and back clients should display. For a
+// cloze card the question holds the cloze text and the answer any extra notes,
+// and a reverse card shows the answer first.
+func renderFlashcards(cards ...*learning.Flashcard) {
+	for _, card := range cards {
+		switch card.CardType {
+		case learning.CardType_CARD_TYPE_CLOZE:
+			card.Front, card.Back = cloze.Render(card.Question, card.ClozeIndex)
+			if card.Answer != "" {
+				card.Back += "\n\n" + card.Answer
+			}
+		case learning.CardType_CARD_TYPE_REVERSE:
+			card.Front, card.Back = card.Answer, card.Question
+		default:
+			card.Front, card.Back = card.Question, card.Answer
+		}
+	}
+}
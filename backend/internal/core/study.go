@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// DefaultLeechThreshold is how many lapses turn a card into a leech when the
+// user has not chosen their own threshold.
+const DefaultLeechThreshold = 8
+
+// DefaultStudySettings are used for users who have not saved their own.
+func DefaultStudySettings() *learning.StudySettings {
+	return &learning.StudySettings{
+		NewCardsPerDay:   20,
+		MaxReviewsPerDay: 200,
+		DayRolloverHour:  4,
+		Timezone:         "UTC",
+		LeechThreshold:   DefaultLeechThreshold,
+		LeechAction:      learning.LeechAction_LEECH_ACTION_TAG,
+	}
+}
+
+// ValidateStudySettings checks that settings are within range and the timezone is known.
+func ValidateStudySettings(settings *learning.StudySettings) error {
+	if settings.NewCardsPerDay < 0 {
+		return fmt.Errorf("new_cards_per_day must not be negative")
+	}
+	if settings.MaxReviewsPerDay < 0 {
+		return fmt.Errorf("max_reviews_per_day must not be negative")
+	}
+	if settings.DayRolloverHour < 0 || settings.DayRolloverHour > 23 {
+		return fmt.Errorf("day_rollover_hour must be between 0 and 23")
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", settings.Timezone)
+	}
+	if settings.LeechThreshold < 1 {
+		return fmt.Errorf("leech_threshold must be at least 1")
+	}
+	if _, ok := learning.LeechAction_name[int32(settings.LeechAction)]; !ok {
+		return fmt.Errorf("unknown leech_action %d", settings.LeechAction)
+	}
+	return nil
+}
+
+// dailyLimits is how much studying a user has left for the current study day.
+type dailyLimits struct {
+	newRemaining    int32
+	reviewRemaining int32
+	dueBefore       time.Time // Start of the next study day; cards due before it count as due today
+}
+
+func (c *LearningCore) GetStudySettings(ctx context.Context, userID string) (*learning.StudySettings, error) {
+	settings, err := c.store.GetStudySettings(ctx, userID)
+	if err != nil {
+		log.Printf("[Core.GetStudySettings] Failed: %v", err)
+		return nil, err
+	}
+	if settings == nil {
+		return DefaultStudySettings(), nil
+	}
+	return settings, nil
+}
+
+func (c *LearningCore) UpdateStudySettings(ctx context.Context, userID string, settings *learning.StudySettings) (*learning.StudySettings, error) {
+	log.Printf("[Core.UpdateStudySettings] Updating settings for userID: %s", userID)
+	if err := c.store.UpdateStudySettings(ctx, userID, settings); err != nil {
+		log.Printf("[Core.UpdateStudySettings] Failed: %v", err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+// studyCalendar maps moments onto a user's study days, which begin at the
+// rollover hour in the user's timezone rather than at midnight UTC.
+type studyCalendar struct {
+	loc          *time.Location
+	rolloverHour int
+}
+
+func newStudyCalendar(settings *learning.StudySettings) studyCalendar {
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return studyCalendar{loc: loc, rolloverHour: int(settings.DayRolloverHour)}
+}
+
+// rollover returns when the study day of the given date begins. When a DST
+// change skips the rollover hour, the day begins as the clocks jump forward.
+func (c studyCalendar) rollover(year int, month time.Month, day int) time.Time {
+	start := time.Date(year, month, day, c.rolloverHour, 0, 0, 0, c.loc)
+	if start.Hour() != c.rolloverHour {
+		// time.Date picked a time before the gap
+		_, start = start.ZoneBounds()
+	}
+	return start
+}
+
+// dayStart returns when the study day containing t began.
+func (c studyCalendar) dayStart(t time.Time) time.Time {
+	local := t.In(c.loc)
+	start := c.rollover(local.Year(), local.Month(), local.Day())
+	if start.After(local) {
+		start = c.rollover(local.Year(), local.Month(), local.Day()-1)
+	}
+	return start
+}
+
+// dueAt returns the start of the study day that is days after the one containing now.
+func (c studyCalendar) dueAt(now time.Time, days int32) time.Time {
+	start := c.dayStart(now)
+	return c.rollover(start.Year(), start.Month(), start.Day()+int(days))
+}
+
+// daysBetween counts the study days from the one containing from to the one containing to.
+func (c studyCalendar) daysBetween(from, to time.Time) int32 {
+	a, b := c.dayStart(from), c.dayStart(to)
+	// Compare calendar dates so DST changes don't skew the count
+	dateA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dateB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int32(dateB.Sub(dateA).Hours() / 24)
+}
+
+// calendarFor returns the study calendar from the user's settings.
+func (c *LearningCore) calendarFor(ctx context.Context, userID string) (studyCalendar, error) {
+	settings, err := c.GetStudySettings(ctx, userID)
+	if err != nil {
+		return studyCalendar{}, err
+	}
+	return newStudyCalendar(settings), nil
+}
+
+// remainingToday works out how many new cards and reviews the user may still
+// study today, given their settings and what they have already reviewed.
+func (c *LearningCore) remainingToday(ctx context.Context, userID string, now time.Time) (dailyLimits, error) {
+	settings, err := c.GetStudySettings(ctx, userID)
+	if err != nil {
+		return dailyLimits{}, err
+	}
+
+	cal := newStudyCalendar(settings)
+	since := cal.dayStart(now)
+	newStudied, reviewsDone, err := c.store.GetStudyCountsSince(ctx, userID, since)
+	if err != nil {
+		return dailyLimits{}, err
+	}
+
+	limits := dailyLimits{
+		newRemaining:    max(settings.NewCardsPerDay-newStudied, 0),
+		reviewRemaining: max(settings.MaxReviewsPerDay-reviewsDone, 0),
+		dueBefore:       cal.dueAt(now, 1),
+	}
+	log.Printf("[Core.remainingToday] Day started %v: %d new studied, %d reviews done, remaining %+v",
+		since, newStudied, reviewsDone, limits)
+	return limits, nil
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata" // The calendar tests need zones with DST wherever they run
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+func TestValidateStudySettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(s *learning.StudySettings)
+		wantErr bool
+	}{
+		{"defaults", func(s *learning.StudySettings) {}, false},
+		{"no new cards", func(s *learning.StudySettings) { s.NewCardsPerDay = 0 }, false},
+		{"negative reviews", func(s *learning.StudySettings) { s.MaxReviewsPerDay = -1 }, true},
+		{"rollover hour 24", func(s *learning.StudySettings) { s.DayRolloverHour = 24 }, true},
+		{"unknown timezone", func(s *learning.StudySettings) { s.Timezone = "Mars/Olympus" }, true},
+		{"leech threshold 1", func(s *learning.StudySettings) { s.LeechThreshold = 1 }, false},
+		{"leech threshold 0", func(s *learning.StudySettings) { s.LeechThreshold = 0 }, true},
+		{"negative leech threshold", func(s *learning.StudySettings) { s.LeechThreshold = -1 }, true},
+		{"unknown leech action", func(s *learning.StudySettings) { s.LeechAction = 9 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DefaultStudySettings()
+			tt.change(settings)
+			if err := ValidateStudySettings(settings); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStudySettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStudyCalendar(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	at := func(loc *time.Location, month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, loc)
+	}
+
+	t.Run("dayStart", func(t *testing.T) {
+		tests := []struct {
+			name string
+			cal  studyCalendar
+			t    time.Time
+			want time.Time
+		}{
+			{"after the rollover", studyCalendar{newYork, 4}, at(newYork, 3, 10, 10, 0), at(newYork, 3, 10, 4, 0)},
+			{"before the rollover", studyCalendar{newYork, 4}, at(newYork, 3, 10, 3, 59), at(newYork, 3, 9, 4, 0)},
+			{"at the rollover", studyCalendar{newYork, 4}, at(newYork, 3, 10, 4, 0), at(newYork, 3, 10, 4, 0)},
+			{"midnight rollover", studyCalendar{time.UTC, 0}, at(time.UTC, 3, 10, 0, 0), at(time.UTC, 3, 10, 0, 0)},
+			{"first of the month", studyCalendar{newYork, 4}, at(newYork, 4, 1, 1, 0), at(newYork, 3, 31, 4, 0)},
+			{"time given in UTC", studyCalendar{kolkata, 4}, at(time.UTC, 3, 10, 22, 0), at(kolkata, 3, 10, 4, 0)},
+			{"day after spring forward", studyCalendar{newYork, 4}, at(newYork, 3, 8, 12, 0), at(newYork, 3, 8, 4, 0)},
+			// 2:00 doesn't exist on March 8, when clocks jump to 3:00
+			{"rollover skipped by spring forward", studyCalendar{newYork, 2}, at(newYork, 3, 8, 5, 0), at(newYork, 3, 8, 3, 0)},
+			{"before a skipped rollover", studyCalendar{newYork, 2}, at(newYork, 3, 8, 1, 30), at(newYork, 3, 7, 2, 0)},
+			{"in the hour after a skipped rollover", studyCalendar{newYork, 2}, at(newYork, 3, 8, 3, 30), at(newYork, 3, 8, 3, 0)},
+			{"fall back", studyCalendar{newYork, 4}, at(newYork, 11, 1, 12, 0), at(newYork, 11, 1, 4, 0)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := tt.cal.dayStart(tt.t); !got.Equal(tt.want) {
+					t.Errorf("dayStart(%v) = %v, want %v", tt.t, got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("dueAt", func(t *testing.T) {
+		tests := []struct {
+			name string
+			cal  studyCalendar
+			now  time.Time
+			days int32
+			want time.Time
+		}{
+			{"tomorrow", studyCalendar{newYork, 4}, at(newYork, 3, 10, 10, 0), 1, at(newYork, 3, 11, 4, 0)},
+			{"before the rollover", studyCalendar{newYork, 4}, at(newYork, 3, 10, 3, 0), 1, at(newYork, 3, 10, 4, 0)},
+			{"today", studyCalendar{newYork, 4}, at(newYork, 3, 10, 10, 0), 0, at(newYork, 3, 10, 4, 0)},
+			{"over spring forward", studyCalendar{newYork, 4}, at(newYork, 3, 7, 12, 0), 1, at(newYork, 3, 8, 4, 0)},
+			{"over fall back", studyCalendar{newYork, 4}, at(newYork, 10, 31, 12, 0), 3, at(newYork, 11, 3, 4, 0)},
+			{"onto a skipped rollover", studyCalendar{newYork, 2}, at(newYork, 3, 7, 12, 0), 1, at(newYork, 3, 8, 3, 0)},
+			{"a month", studyCalendar{newYork, 4}, at(newYork, 3, 1, 12, 0), 30, at(newYork, 3, 31, 4, 0)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := tt.cal.dueAt(tt.now, tt.days); !got.Equal(tt.want) {
+					t.Errorf("dueAt(%v, %d) = %v, want %v", tt.now, tt.days, got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("daysBetween", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			cal      studyCalendar
+			from, to time.Time
+			want     int32
+		}{
+			{"same day", studyCalendar{newYork, 4}, at(newYork, 3, 10, 5, 0), at(newYork, 3, 10, 23, 0), 0},
+			{"over the rollover", studyCalendar{newYork, 4}, at(newYork, 3, 10, 3, 0), at(newYork, 3, 10, 5, 0), 1},
+			{"past midnight before the rollover", studyCalendar{newYork, 4}, at(newYork, 3, 10, 23, 0), at(newYork, 3, 11, 3, 0), 0},
+			{"over spring forward", studyCalendar{newYork, 4}, at(newYork, 3, 7, 12, 0), at(newYork, 3, 9, 12, 0), 2},
+			{"over fall back", studyCalendar{newYork, 4}, at(newYork, 10, 31, 12, 0), at(newYork, 11, 2, 12, 0), 2},
+			{"backwards", studyCalendar{newYork, 4}, at(newYork, 3, 10, 12, 0), at(newYork, 3, 8, 12, 0), -2},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := tt.cal.daysBetween(tt.from, tt.to); got != tt.want {
+					t.Errorf("daysBetween(%v, %v) = %d, want %d", tt.from, tt.to, got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("due at day start", func(t *testing.T) {
+		for _, cal := range []studyCalendar{{newYork, 4}, {newYork, 2}, {newYork, 0}, {kolkata, 4}} {
+			for _, now := range []time.Time{at(newYork, 3, 7, 12, 0), at(newYork, 10, 31, 23, 30), at(newYork, 6, 1, 3, 0)} {
+				due := cal.dueAt(now, 1)
+				if start := cal.dayStart(due); !start.Equal(due) {
+					t.Errorf("%s %d: card due %v, which is in the day starting %v", cal.loc, cal.rolloverHour, due, start)
+				}
+				if days := cal.daysBetween(now, due); days != 1 {
+					t.Errorf("%s %d: card due %v is %d days after %v, want 1", cal.loc, cal.rolloverHour, due, days, now)
+				}
+				if start := cal.dayStart(due.Add(-time.Nanosecond)); !start.Equal(cal.dayStart(now)) {
+					t.Errorf("%s %d: just before %v is in the day starting %v, want %v", cal.loc, cal.rolloverHour, due, start, cal.dayStart(now))
+				}
+			}
+		}
+	})
+}
+
+func TestNewStudyCalendar(t *testing.T) {
+	settings := DefaultStudySettings()
+	settings.Timezone, settings.DayRolloverHour = "Europe/Berlin", 5
+	if cal := newStudyCalendar(settings); cal.loc.String() != "Europe/Berlin" || cal.rolloverHour != 5 {
+		t.Errorf("calendar in %s rolling over at %d, want Europe/Berlin at 5", cal.loc, cal.rolloverHour)
+	}
+	settings.Timezone = "Mars/Olympus"
+	if cal := newStudyCalendar(settings); cal.loc != time.UTC {
+		t.Errorf("calendar for an unknown timezone in %s, want UTC", cal.loc)
+	}
+}
+
+func TestDailyLimitsAcrossStudyDays(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	settings := DefaultStudySettings()
+	settings.NewCardsPerDay, settings.MaxReviewsPerDay = 2, 2
+	settings.Timezone, settings.DayRolloverHour = "America/New_York", 4
+	if _, err := env.core.UpdateStudySettings(ctx, env.userID, settings); err != nil {
+		t.Fatalf("UpdateStudySettings: %v", err)
+	}
+	cards := make([]*learning.Flashcard, 5)
+	for i := range cards {
+		cards[i] = &learning.Flashcard{Question: fmt.Sprintf("Q%d", i), Answer: "A"}
+	}
+	env.addMaterial(t, "Content", cards...)
+
+	// Study days after the cards were added, so they are all due
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	next := time.Now().In(newYork).AddDate(0, 0, 1)
+	at := func(day, hour int) time.Time {
+		return time.Date(next.Year(), next.Month(), next.Day()+day, hour, 0, 0, 0, newYork)
+	}
+
+	steps := []struct {
+		name         string
+		now          time.Time
+		review       []bool // Cards to review from the queue, true for a new card
+		wantNew      int32
+		wantReviews  int32
+		wantCards    int
+		wantTotalDue int32
+	}{
+		{name: "first day", now: at(0, 10), wantNew: 2, wantReviews: 2, wantCards: 2, wantTotalDue: 5, review: []bool{true, true}},
+		{name: "new cards used up", now: at(0, 11), wantNew: 0, wantReviews: 2, wantCards: 0, wantTotalDue: 3},
+		{name: "past midnight before the rollover", now: at(1, 3), wantNew: 0, wantReviews: 2, wantCards: 0, wantTotalDue: 3},
+		{name: "after the rollover", now: at(1, 5), wantNew: 2, wantReviews: 2, wantCards: 4, wantTotalDue: 5, review: []bool{false, false, true}},
+		{name: "second day", now: at(1, 6), wantNew: 1, wantReviews: 0, wantCards: 1, wantTotalDue: 2},
+	}
+
+	for _, step := range steps {
+		env.core.now = func() time.Time { return step.now }
+		queue, err := env.core.GetReviewQueue(ctx, env.userID, nil, nil, 20)
+		if err != nil {
+			t.Fatalf("%s: GetReviewQueue: %v", step.name, err)
+		}
+		if queue.NewRemaining != step.wantNew || queue.ReviewsRemaining != step.wantReviews ||
+			len(queue.Flashcards) != step.wantCards || queue.TotalDue != step.wantTotalDue {
+			t.Fatalf("%s: %d new and %d reviews remaining, %d cards of %d due; want %d, %d, %d of %d",
+				step.name, queue.NewRemaining, queue.ReviewsRemaining, len(queue.Flashcards), queue.TotalDue,
+				step.wantNew, step.wantReviews, step.wantCards, step.wantTotalDue)
+		}
+
+		for _, isNew := range step.review {
+			i := slices.IndexFunc(queue.Flashcards, func(card *learning.Flashcard) bool { return (card.LastReviewedAt == nil) == isNew })
+			if i < 0 {
+				t.Fatalf("%s: no card to review in %v", step.name, queue.Flashcards)
+			}
+			if _, err := env.core.ReviewFlashcard(ctx, env.userID, queue.Flashcards[i].Id, learning.ReviewGrade_REVIEW_GRADE_GOOD, ReviewMeta{}); err != nil {
+				t.Fatalf("%s: ReviewFlashcard: %v", step.name, err)
+			}
+			queue.Flashcards = slices.Delete(queue.Flashcards, i, i+1)
+		}
+	}
+}
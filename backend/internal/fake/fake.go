@@ -0,0 +1,193 @@
+// Package fake provides scripted stand-ins for the LLM, scraper and YouTube
+// dependencies of the learning core, so it can run without network access.
+// Each fake answers calls with the responses queued on it, in order, and
+// fails calls it has no response for.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/amityadav/landr/internal/ai"
+)
+
+// AI is a scripted LLM. Chunk results get the index they were asked for.
+type AI struct {
+	mu          sync.Mutex
+	texts       []response[string]
+	summaries   []response[string]
+	chunks      []ai.ChunkResult
+	rewrites    []response[[2]string]
+	distractors []response[[][]string]
+	grades      []grade
+	calls       []string
+}
+
+type response[T any] struct {
+	value T
+	err   error
+}
+
+type grade struct {
+	score       float64
+	explanation string
+	err         error
+}
+
+func (f *AI) AddText(text string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.texts = append(f.texts, response[string]{text, err})
+}
+
+func (f *AI) AddSummary(summary string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.summaries = append(f.summaries, response[string]{summary, err})
+}
+
+func (f *AI) AddChunk(result ai.ChunkResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.chunks = append(f.chunks, result)
+}
+
+func (f *AI) AddRewrite(question, answer string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rewrites = append(f.rewrites, response[[2]string]{[2]string{question, answer}, err})
+}
+
+func (f *AI) AddDistractors(distractors [][]string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.distractors = append(f.distractors, response[[][]string]{distractors, err})
+}
+
+func (f *AI) AddGrade(score float64, explanation string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.grades = append(f.grades, grade{score, explanation, err})
+}
+
+// Calls returns the names of the methods called so far, in order.
+func (f *AI) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *AI) ExtractTextFromImage(base64Image string) (string, error) {
+	r, err := next(f, &f.texts, "ExtractTextFromImage")
+	if err != nil {
+		return "", err
+	}
+	return r.value, r.err
+}
+
+func (f *AI) GenerateSummary(content string) (string, error) {
+	r, err := next(f, &f.summaries, "GenerateSummary")
+	if err != nil {
+		return "", err
+	}
+	return r.value, r.err
+}
+
+func (f *AI) GenerateChunkFlashcards(ctx context.Context, index int, chunk string, existingTags []string, allowCloze bool) ai.ChunkResult {
+	result, err := next(f, &f.chunks, "GenerateChunkFlashcards")
+	if err != nil {
+		result.Error = err
+	}
+	result.ChunkIndex = index
+	return result
+}
+
+func (f *AI) RewriteFlashcard(question, answer string) (string, string, error) {
+	r, err := next(f, &f.rewrites, "RewriteFlashcard")
+	if err != nil {
+		return "", "", err
+	}
+	return r.value[0], r.value[1], r.err
+}
+
+func (f *AI) GenerateDistractors(content string, requests []ai.DistractorRequest, count int) ([][]string, error) {
+	r, err := next(f, &f.distractors, "GenerateDistractors")
+	if err != nil {
+		return nil, err
+	}
+	return r.value, r.err
+}
+
+func (f *AI) GradeAnswer(question, reference, typed string) (float64, string, error) {
+	g, err := next(f, &f.grades, "GradeAnswer")
+	if err != nil {
+		return 0, "", err
+	}
+	return g.score, g.explanation, g.err
+}
+
+// next records a call to method and takes the first response from queue.
+func next[T any](f *AI, queue *[]T, method string) (T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, method)
+
+	var zero T
+	if len(*queue) == 0 {
+		return zero, fmt.Errorf("fake: no %s response scripted", method)
+	}
+	r := (*queue)[0]
+	*queue = (*queue)[1:]
+	return r, nil
+}
+
+// Scraper is a scripted web scraper, answering by URL.
+type Scraper struct {
+	mu    sync.Mutex
+	pages map[string]response[string]
+}
+
+func (f *Scraper) AddPage(url, text string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pages == nil {
+		f.pages = make(map[string]response[string])
+	}
+	f.pages[url] = response[string]{text, err}
+}
+
+func (f *Scraper) Scrape(url string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page, ok := f.pages[url]
+	if !ok {
+		return "", fmt.Errorf("fake: no page scripted for %s", url)
+	}
+	return page.value, page.err
+}
+
+// Transcripts is a scripted YouTube transcript fetcher, answering by video URL.
+type Transcripts struct {
+	mu          sync.Mutex
+	transcripts map[string]response[string]
+}
+
+func (f *Transcripts) AddTranscript(videoURL, transcript string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.transcripts == nil {
+		f.transcripts = make(map[string]response[string])
+	}
+	f.transcripts[videoURL] = response[string]{transcript, err}
+}
+
+func (f *Transcripts) GetTranscript(ctx context.Context, videoURL string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.transcripts[videoURL]
+	if !ok {
+		return "", fmt.Errorf("fake: no transcript scripted for %s", videoURL)
+	}
+	return t.value, t.err
+}
@@ -44,36 +44,77 @@ func (interceptor *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 			return handler(ctx, req)
 		}
 
-		// Extract token from metadata
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Errorf(codes.Unauthenticated, "metadata is not provided")
+		userID, err := interceptor.authorize(ctx)
+		if err != nil {
+			return nil, err
 		}
 
-		values := md["authorization"]
-		if len(values) == 0 {
-			return nil, status.Errorf(codes.Unauthenticated, "authorization token is not provided")
-		}
+		// Add user ID to context
+		ctx = context.WithValue(ctx, UserIDKey, userID)
 
-		// Token format: "Bearer <token>"
-		accessToken := values[0]
-		if !strings.HasPrefix(accessToken, "Bearer ") {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid authorization format")
-		}
+		return handler(ctx, req)
+	}
+}
 
-		accessToken = strings.TrimPrefix(accessToken, "Bearer ")
+// Stream returns a server interceptor function to authenticate and authorize streaming RPC
+func (interceptor *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if interceptor.publicMethods[info.FullMethod] {
+			return handler(srv, stream)
+		}
 
-		// Verify token and extract user ID
-		userID, err := interceptor.tokenManager.Verify(accessToken)
+		userID, err := interceptor.authorize(stream.Context())
 		if err != nil {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+			return err
 		}
 
-		// Add user ID to context
-		ctx = context.WithValue(ctx, UserIDKey, userID)
+		ctx := context.WithValue(stream.Context(), UserIDKey, userID)
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
 
-		return handler(ctx, req)
+// authenticatedStream carries the user ID in its context for stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize verifies the bearer token in the request metadata and returns its user ID
+func (interceptor *AuthInterceptor) authorize(ctx context.Context) (string, error) {
+	// Extract token from metadata
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Errorf(codes.Unauthenticated, "metadata is not provided")
+	}
+
+	values := md["authorization"]
+	if len(values) == 0 {
+		return "", status.Errorf(codes.Unauthenticated, "authorization token is not provided")
+	}
+
+	// Token format: "Bearer <token>"
+	accessToken := values[0]
+	if !strings.HasPrefix(accessToken, "Bearer ") {
+		return "", status.Errorf(codes.Unauthenticated, "invalid authorization format")
 	}
+
+	accessToken = strings.TrimPrefix(accessToken, "Bearer ")
+
+	// Verify token and extract user ID
+	userID, err := interceptor.tokenManager.Verify(accessToken)
+	if err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return userID, nil
 }
 
 // GetUserID extracts the user ID from context
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnary returns a server interceptor that turns a panic in a unary
+// handler into an Internal error, so one bad request cannot crash the server
+func RecoveryUnary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStream returns a server interceptor that turns a panic in a
+// streaming handler into an Internal error
+func RecoveryStream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(info.FullMethod, r)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+func recovered(method string, r interface{}) error {
+	log.Printf("[Recovery] Panic in %s: %v\n%s", method, r, debug.Stack())
+	return status.Errorf(codes.Internal, "internal error")
+}
@@ -0,0 +1,337 @@
+// Package migrate applies the numbered SQL migrations in db/migrations to a
+// Postgres database. It keeps its state in golang-migrate's schema_migrations
+// table, so databases migrated with the migrate CLI carry on where they left off.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// lockID is the Postgres advisory lock held while migrating, so servers
+// starting together don't apply the same migration twice.
+const lockID = 7313902047
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var (
+	// ErrDirty means a migration failed part way; fix the schema by hand and
+	// force the version it is now at.
+	ErrDirty = errors.New("database schema is dirty")
+	// ErrBehind means the database has not had every migration applied.
+	ErrBehind = errors.New("database schema is behind")
+	// ErrUnversioned means the database has tables but no schema version,
+	// such as one migrated by hand before the server applied migrations;
+	// force the version its schema is at before migrating it.
+	ErrUnversioned = errors.New("database has tables but no schema version")
+)
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes a database's schema version against the known migrations.
+type Status struct {
+	Version int64 // 0 before any migration has been applied
+	Dirty   bool
+	Latest  int64
+	Pending []Migration
+}
+
+// Migrator applies migrations over a single connection.
+type Migrator struct {
+	conn       *pgx.Conn
+	migrations []Migration
+}
+
+// Load reads the migrations in dir of fsys, ordered by version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		sql, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if m[3] == "up" {
+			mig.Up = string(sql)
+		} else {
+			mig.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// New connects to the database to apply migrations.
+func New(ctx context.Context, connString string, migrations []Migration) (*Migrator, error) {
+	conn, err := pgx.Connect(ctx, connString)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
+	}
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)
+	`)
+	if err != nil {
+		conn.Close(ctx)
+		return nil, fmt.Errorf("unable to create schema_migrations: %w", err)
+	}
+	return &Migrator{conn: conn, migrations: migrations}, nil
+}
+
+// Close closes the connection.
+func (m *Migrator) Close(ctx context.Context) error {
+	return m.conn.Close(ctx)
+}
+
+// Latest returns the version of the newest migration.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the database's schema version and whether the last
+// migration failed part way.
+func (m *Migrator) Version(ctx context.Context) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Status reports the schema version and the migrations still to apply.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &Status{Version: version, Dirty: dirty, Latest: m.Latest()}
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			s.Pending = append(s.Pending, mig)
+		}
+	}
+	return s, nil
+}
+
+// Check returns ErrDirty, ErrUnversioned or ErrBehind unless every migration
+// has been applied.
+func (m *Migrator) Check(ctx context.Context) error {
+	s, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if s.Dirty {
+		return fmt.Errorf("%w at version %d", ErrDirty, s.Version)
+	}
+	if s.Version == 0 {
+		if err := m.checkEmpty(ctx); err != nil {
+			return err
+		}
+	}
+	if s.Version < s.Latest {
+		return fmt.Errorf("%w: at version %d, expected %d", ErrBehind, s.Version, s.Latest)
+	}
+	return nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the last steps migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(version int64) error {
+		target := version
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			if m.migrations[i].Version <= target {
+				target = m.previous(i)
+				steps--
+			}
+		}
+		return m.migrate(ctx, version, target)
+	})
+}
+
+// To migrates up or down to version, which must be 0 or a known migration.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("no migration with version %d", version)
+	}
+	return m.locked(ctx, func(current int64) error {
+		return m.migrate(ctx, current, version)
+	})
+}
+
+// Force records version as the schema version and clears the dirty flag
+// without running anything, after a failed migration was fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.setVersion(ctx, version, false)
+}
+
+// locked runs fn with the advisory lock held and the current, clean, version.
+func (m *Migrator) locked(ctx context.Context, fn func(version int64) error) error {
+	if _, err := m.conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("unable to lock schema_migrations: %w", err)
+	}
+	defer m.conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+	if version == 0 {
+		if err := m.checkEmpty(ctx); err != nil {
+			return err
+		}
+	}
+	return fn(version)
+}
+
+// checkEmpty returns ErrUnversioned if the database, with no schema version,
+// already has tables that the first migration would clash with.
+func (m *Migrator) checkEmpty(ctx context.Context) error {
+	var hasTables bool
+	err := m.conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'
+		)
+	`).Scan(&hasTables)
+	if err != nil {
+		return fmt.Errorf("unable to list tables: %w", err)
+	}
+	if hasTables {
+		return fmt.Errorf("%w (run `server migrate force <version>` with the version its schema is at)", ErrUnversioned)
+	}
+	return nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, from, to int64) error {
+	for _, mig := range m.migrations {
+		if mig.Version <= from || mig.Version > to {
+			continue
+		}
+		if err := m.run(ctx, mig, mig.Up, "up", mig.Version); err != nil {
+			return err
+		}
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version > from || mig.Version <= to {
+			continue
+		}
+		if mig.Down == "" {
+			return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+		if err := m.run(ctx, mig, mig.Down, "down", m.previous(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run executes one migration file to reach version, which is left dirty if
+// the file fails.
+func (m *Migrator) run(ctx context.Context, mig Migration, sql, direction string, version int64) error {
+	log.Printf("[Migrate] Applying %d_%s (%s)", mig.Version, mig.Name, direction)
+	if err := m.setVersion(ctx, version, true); err != nil {
+		return err
+	}
+	// Without arguments Exec uses the simple protocol, which allows the
+	// several statements a migration file holds
+	if _, err := m.conn.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("migration %d_%s %s failed: %w", mig.Version, mig.Name, direction, err)
+	}
+	return m.setVersion(ctx, version, false)
+}
+
+func (m *Migrator) setVersion(ctx context.Context, version int64, dirty bool) error {
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return fmt.Errorf("unable to set schema version: %w", err)
+	}
+	// golang-migrate records no row, rather than version 0, before the first migration
+	if version > 0 || dirty {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty); err != nil {
+			return fmt.Errorf("unable to set schema version: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// previous returns the version before the i'th migration.
+func (m *Migrator) previous(i int) int64 {
+	if i == 0 {
+		return 0
+	}
+	return m.migrations[i-1].Version
+}
+
+func (m *Migrator) find(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,318 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// testDatabaseURL names the environment variable holding a Postgres database
+// URL to run the migrator against. Each test works in a schema of its own.
+const testDatabaseURL = "TEST_DATABASE_URL"
+
+var testMigrations = fstest.MapFS{
+	"m/000001_create_a.up.sql":   {Data: []byte(`CREATE TABLE a (id INT)`)},
+	"m/000001_create_a.down.sql": {Data: []byte(`DROP TABLE a`)},
+	"m/000002_create_b.up.sql":   {Data: []byte(`CREATE TABLE b (id INT)`)},
+	"m/000002_create_b.down.sql": {Data: []byte(`DROP TABLE b`)},
+	"m/000005_create_c.up.sql":   {Data: []byte(`CREATE TABLE c (id INT); CREATE INDEX c_id ON c (id)`)},
+	"m/000005_create_c.down.sql": {Data: []byte(`DROP TABLE c`)},
+	"m/README.md":                {Data: []byte(`Not a migration`)},
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testMigrations, "m")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var versions []int64
+	for _, mig := range migrations {
+		versions = append(versions, mig.Version)
+		if mig.Up == "" || mig.Down == "" {
+			t.Errorf("migration %d is missing a file", mig.Version)
+		}
+	}
+	if len(versions) != 3 || versions[0] != 1 || versions[1] != 2 || versions[2] != 5 {
+		t.Errorf("loaded versions %v, want [1 2 5]", versions)
+	}
+	if migrations[2].Name != "create_c" {
+		t.Errorf("name = %q, want create_c", migrations[2].Name)
+	}
+
+	noUp := fstest.MapFS{"m/000001_create_a.down.sql": {Data: []byte(`DROP TABLE a`)}}
+	if _, err := Load(noUp, "m"); err == nil || !strings.Contains(err.Error(), "no up file") {
+		t.Errorf("Load without an up file: got %v, want an error", err)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(ctx context.Context, m *Migrator) error
+		wantErr     string
+		wantVersion int64
+		wantDirty   bool
+		wantTables  []string
+	}{
+		{
+			name:        "up",
+			run:         func(ctx context.Context, m *Migrator) error { return m.Up(ctx) },
+			wantVersion: 5,
+			wantTables:  []string{"a", "b", "c"},
+		},
+		{
+			name: "up twice",
+			run: func(ctx context.Context, m *Migrator) error {
+				if err := m.Up(ctx); err != nil {
+					return err
+				}
+				return m.Up(ctx)
+			},
+			wantVersion: 5,
+			wantTables:  []string{"a", "b", "c"},
+		},
+		{
+			name: "down",
+			run: func(ctx context.Context, m *Migrator) error {
+				if err := m.Up(ctx); err != nil {
+					return err
+				}
+				return m.Down(ctx, 2)
+			},
+			wantVersion: 1,
+			wantTables:  []string{"a"},
+		},
+		{
+			name: "down past the first",
+			run: func(ctx context.Context, m *Migrator) error {
+				if err := m.Up(ctx); err != nil {
+					return err
+				}
+				return m.Down(ctx, 10)
+			},
+		},
+		{
+			name:        "to",
+			run:         func(ctx context.Context, m *Migrator) error { return m.To(ctx, 2) },
+			wantVersion: 2,
+			wantTables:  []string{"a", "b"},
+		},
+		{
+			name:    "to an unknown version",
+			run:     func(ctx context.Context, m *Migrator) error { return m.To(ctx, 3) },
+			wantErr: "no migration with version 3",
+		},
+		{
+			name: "failed migration leaves it dirty",
+			run: func(ctx context.Context, m *Migrator) error {
+				m.migrations[1].Up = `CREATE TABLE b (id NOT_A_TYPE)`
+				return m.Up(ctx)
+			},
+			wantErr:     "migration 2_create_b up failed",
+			wantVersion: 2,
+			wantDirty:   true,
+			wantTables:  []string{"a"},
+		},
+		{
+			name: "dirty database refused",
+			run: func(ctx context.Context, m *Migrator) error {
+				m.migrations[1].Up = `CREATE TABLE b (id NOT_A_TYPE)`
+				m.Up(ctx)
+				m.migrations[1].Up = `CREATE TABLE b (id INT)`
+				return m.Up(ctx)
+			},
+			wantErr:     ErrDirty.Error(),
+			wantVersion: 2,
+			wantDirty:   true,
+			wantTables:  []string{"a"},
+		},
+		{
+			name: "forced after a fix",
+			run: func(ctx context.Context, m *Migrator) error {
+				m.migrations[1].Up = `CREATE TABLE b (id NOT_A_TYPE)`
+				m.Up(ctx)
+				if _, err := m.conn.Exec(ctx, `CREATE TABLE b (id INT)`); err != nil {
+					return err
+				}
+				if err := m.Force(ctx, 2); err != nil {
+					return err
+				}
+				return m.Up(ctx)
+			},
+			wantVersion: 5,
+			wantTables:  []string{"a", "b", "c"},
+		},
+		{
+			name: "migrated by hand refused",
+			run: func(ctx context.Context, m *Migrator) error {
+				if _, err := m.conn.Exec(ctx, `CREATE TABLE a (id INT)`); err != nil {
+					return err
+				}
+				return m.Up(ctx)
+			},
+			wantErr:    ErrUnversioned.Error(),
+			wantTables: []string{"a"},
+		},
+		{
+			name: "migrated by hand and forced",
+			run: func(ctx context.Context, m *Migrator) error {
+				if _, err := m.conn.Exec(ctx, `CREATE TABLE a (id INT)`); err != nil {
+					return err
+				}
+				if err := m.Force(ctx, 1); err != nil {
+					return err
+				}
+				return m.Up(ctx)
+			},
+			wantVersion: 5,
+			wantTables:  []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := newTestMigrator(t)
+
+			err := tt.run(ctx, m)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("got %v, want no error", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("got %v, want %q", err, tt.wantErr)
+			}
+
+			version, dirty, err := m.Version(ctx)
+			if err != nil {
+				t.Fatalf("Version: %v", err)
+			}
+			if version != tt.wantVersion || dirty != tt.wantDirty {
+				t.Errorf("at version %d, dirty %v; want %d, dirty %v", version, dirty, tt.wantVersion, tt.wantDirty)
+			}
+			if tables := schemaTables(t, m.conn); strings.Join(tables, ",") != strings.Join(tt.wantTables, ",") {
+				t.Errorf("tables %v, want %v", tables, tt.wantTables)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t)
+
+	if err := m.Check(ctx); !errors.Is(err, ErrBehind) {
+		t.Errorf("Check of an empty database: got %v, want %v", err, ErrBehind)
+	}
+	if err := m.To(ctx, 2); err != nil {
+		t.Fatalf("To: %v", err)
+	}
+	if err := m.Check(ctx); !errors.Is(err, ErrBehind) {
+		t.Errorf("Check at version 2: got %v, want %v", err, ErrBehind)
+	}
+	s, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if s.Version != 2 || s.Latest != 5 || len(s.Pending) != 1 || s.Pending[0].Version != 5 {
+		t.Errorf("status %+v, want version 2 of 5 with 5 pending", s)
+	}
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check after Up: %v", err)
+	}
+	if err := m.Force(ctx, 0); err != nil {
+		t.Fatalf("Force: %v", err)
+	}
+	if err := m.Check(ctx); !errors.Is(err, ErrUnversioned) {
+		t.Errorf("Check of tables without a version: got %v, want %v", err, ErrUnversioned)
+	}
+}
+
+// newTestMigrator returns a migrator of testMigrations working in a new, empty
+// schema of the database TEST_DATABASE_URL names, skipping the test when it
+// isn't set.
+func newTestMigrator(t *testing.T) *Migrator {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseURL)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseURL)
+	}
+	ctx := context.Background()
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	schema := "migrate_test_" + hex.EncodeToString(b)
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin, err := pgx.Connect(ctx, dsn)
+		if err != nil {
+			t.Errorf("connect: %v", err)
+			return
+		}
+		defer admin.Close(ctx)
+		if _, err := admin.Exec(ctx, `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+
+	// Unknown parameters of a URL or key=value string are sent to the server
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+	migrations, err := Load(testMigrations, "m")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	m, err := New(ctx, dsn, migrations)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { m.Close(ctx) })
+	return m
+}
+
+// schemaTables lists the tables the migrations made, in order.
+func schemaTables(t *testing.T, conn *pgx.Conn) []string {
+	t.Helper()
+	rows, err := conn.Query(context.Background(), `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables
+}
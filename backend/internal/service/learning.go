@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
+	"strings"
 
+	"github.com/amityadav/landr/internal/cloze"
 	"github.com/amityadav/landr/internal/core"
 	"github.com/amityadav/landr/internal/middleware"
+	"github.com/amityadav/landr/internal/store"
 	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type LearningService struct {
@@ -34,19 +40,66 @@ func (s *LearningService) AddMaterial(ctx context.Context, req *learning.AddMate
 	}
 	log.Printf("[AddMaterial] Using userID: %s", userID)
 
-	materialID, count, title, tags, err := s.core.AddMaterial(ctx, userID, req.Type, req.Content, req.ImageData, req.ExistingTags)
+	if req.Type == "IMAGE" && req.ImageData == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "image_data required for IMAGE type")
+	}
+
+	jobID, err := s.core.AddMaterial(ctx, userID, req.Type, req.Content, req.ImageData, req.AllowCloze, req.GenerateReverse)
 	if err != nil {
 		log.Printf("[AddMaterial] ERROR: %v", err)
 		return nil, status.Errorf(codes.Internal, "failed to add material: %v", err)
 	}
 
-	log.Printf("[AddMaterial] SUCCESS - MaterialID: %s, Flashcards created: %d", materialID, count)
-	return &learning.AddMaterialResponse{
-		MaterialId:        materialID,
-		FlashcardsCreated: count,
-		Title:             title,
-		Tags:              tags,
-	}, nil
+	log.Printf("[AddMaterial] SUCCESS - Queued job: %s", jobID)
+	return &learning.AddMaterialResponse{JobId: jobID}, nil
+}
+
+func (s *LearningService) WatchIngestionJob(req *learning.WatchIngestionJobRequest, stream grpc.ServerStreamingServer[learning.IngestionJob]) error {
+	ctx := stream.Context()
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[WatchIngestionJob] ERROR: Failed to get user ID: %v", err)
+		return err
+	}
+	log.Printf("[WatchIngestionJob] Request for job: %s, user: %s", req.JobId, userID)
+
+	if req.JobId == "" {
+		return status.Errorf(codes.InvalidArgument, "job_id is required")
+	}
+
+	if err := s.core.WatchIngestionJob(ctx, userID, req.JobId, stream.Send); err != nil {
+		if ctx.Err() != nil {
+			log.Printf("[WatchIngestionJob] Client went away: %v", ctx.Err())
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		log.Printf("[WatchIngestionJob] ERROR: %v", err)
+		return statusFromError(err, "failed to watch ingestion job")
+	}
+
+	log.Printf("[WatchIngestionJob] SUCCESS - Job %s finished", req.JobId)
+	return nil
+}
+
+func (s *LearningService) RetryFailedChunks(ctx context.Context, req *learning.RetryFailedChunksRequest) (*learning.AddMaterialResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[RetryFailedChunks] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[RetryFailedChunks] Request for material: %s, user: %s", req.MaterialId, userID)
+
+	if req.MaterialId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "material_id is required")
+	}
+
+	jobID, err := s.core.RetryFailedChunks(ctx, userID, req.MaterialId, req.AllowCloze, req.GenerateReverse)
+	if err != nil {
+		log.Printf("[RetryFailedChunks] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to retry chunks")
+	}
+
+	log.Printf("[RetryFailedChunks] SUCCESS - Queued job: %s", jobID)
+	return &learning.AddMaterialResponse{MaterialId: req.MaterialId, JobId: jobID}, nil
 }
 
 func (s *LearningService) DeleteMaterial(ctx context.Context, req *learning.DeleteMaterialRequest) (*emptypb.Empty, error) {
@@ -59,7 +112,7 @@ func (s *LearningService) DeleteMaterial(ctx context.Context, req *learning.Dele
 
 	if err := s.core.DeleteMaterial(ctx, userID, req.MaterialId); err != nil {
 		log.Printf("[DeleteMaterial] ERROR: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to delete material: %v", err)
+		return nil, statusFromError(err, "failed to delete material")
 	}
 
 	log.Printf("[DeleteMaterial] SUCCESS")
@@ -78,7 +131,7 @@ func (s *LearningService) GetDueFlashcards(ctx context.Context, req *learning.Ge
 	cards, err := s.core.GetDueFlashcards(ctx, userID, req.MaterialId)
 	if err != nil {
 		log.Printf("[GetDueFlashcards] ERROR: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to get due flashcards: %v", err)
+		return nil, statusFromError(err, "failed to get due flashcards")
 	}
 
 	log.Printf("[GetDueFlashcards] SUCCESS - Found %d flashcards", len(cards))
@@ -87,6 +140,35 @@ func (s *LearningService) GetDueFlashcards(ctx context.Context, req *learning.Ge
 	}, nil
 }
 
+func (s *LearningService) GetReviewQueue(ctx context.Context, req *learning.GetReviewQueueRequest) (*learning.GetReviewQueueResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[GetReviewQueue] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+
+	maxCards := req.MaxCards
+	if maxCards < 1 {
+		maxCards = 100 // Default daily maximum
+	}
+
+	log.Printf("[GetReviewQueue] Fetching queue for userID: %s, max: %d", userID, maxCards)
+
+	queue, err := s.core.GetReviewQueue(ctx, userID, req.MaterialIds, req.Tags, maxCards)
+	if err != nil {
+		log.Printf("[GetReviewQueue] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to get review queue")
+	}
+
+	log.Printf("[GetReviewQueue] SUCCESS - %d cards (total due: %d)", len(queue.Flashcards), queue.TotalDue)
+	return &learning.GetReviewQueueResponse{
+		Flashcards:        queue.Flashcards,
+		TotalDue:          queue.TotalDue,
+		NewCardsRemaining: queue.NewRemaining,
+		ReviewsRemaining:  queue.ReviewsRemaining,
+	}, nil
+}
+
 func (s *LearningService) GetDueMaterials(ctx context.Context, req *learning.GetDueMaterialsRequest) (*learning.GetDueMaterialsResponse, error) {
 	// Extract user ID from context (set by auth interceptor)
 	userID, err := middleware.GetUserID(ctx)
@@ -127,11 +209,16 @@ func (s *LearningService) GetDueMaterials(ctx context.Context, req *learning.Get
 }
 
 func (s *LearningService) CompleteReview(ctx context.Context, req *learning.CompleteReviewRequest) (*emptypb.Empty, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[CompleteReview] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
 	log.Printf("[CompleteReview] Completing review for flashcardID: %s", req.FlashcardId)
 
-	if err := s.core.CompleteReview(ctx, req.FlashcardId); err != nil {
+	if err := s.core.CompleteReview(ctx, userID, req.FlashcardId, reviewMeta(req.ClientReviewedAt, req.SessionId)); err != nil {
 		log.Printf("[CompleteReview] ERROR: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to complete review: %v", err)
+		return nil, statusFromError(err, "failed to complete review")
 	}
 
 	log.Printf("[CompleteReview] SUCCESS")
@@ -139,29 +226,413 @@ func (s *LearningService) CompleteReview(ctx context.Context, req *learning.Comp
 }
 
 func (s *LearningService) FailReview(ctx context.Context, req *learning.FailReviewRequest) (*emptypb.Empty, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[FailReview] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
 	log.Printf("[FailReview] Failing review for flashcardID: %s", req.FlashcardId)
 
-	if err := s.core.FailReview(ctx, req.FlashcardId); err != nil {
+	if err := s.core.FailReview(ctx, userID, req.FlashcardId, reviewMeta(req.ClientReviewedAt, req.SessionId)); err != nil {
 		log.Printf("[FailReview] ERROR: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to fail review: %v", err)
+		return nil, statusFromError(err, "failed to fail review")
 	}
 
 	log.Printf("[FailReview] SUCCESS")
 	return &emptypb.Empty{}, nil
 }
 
+func (s *LearningService) ReviewFlashcard(ctx context.Context, req *learning.ReviewFlashcardRequest) (*learning.ReviewFlashcardResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[ReviewFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[ReviewFlashcard] Reviewing flashcardID: %s, Grade: %s", req.FlashcardId, req.Grade)
+
+	if req.Grade == learning.ReviewGrade_REVIEW_GRADE_UNSPECIFIED {
+		return nil, status.Errorf(codes.InvalidArgument, "grade is required")
+	}
+	if _, ok := learning.ReviewGrade_name[int32(req.Grade)]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown grade %d", req.Grade)
+	}
+
+	schedule, err := s.core.ReviewFlashcard(ctx, userID, req.FlashcardId, req.Grade, reviewMeta(req.ClientReviewedAt, req.SessionId))
+	if err != nil {
+		log.Printf("[ReviewFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to review flashcard")
+	}
+
+	log.Printf("[ReviewFlashcard] SUCCESS - Stage: %d, Interval: %d days", schedule.Stage, schedule.IntervalDays)
+	return reviewResponse(schedule), nil
+}
+
+func (s *LearningService) GradeTypedAnswer(ctx context.Context, req *learning.GradeTypedAnswerRequest) (*learning.GradeTypedAnswerResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[GradeTypedAnswer] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[GradeTypedAnswer] Grading flashcardID: %s, Record: %v", req.FlashcardId, req.RecordReview)
+
+	if strings.TrimSpace(req.TypedAnswer) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "typed_answer is required")
+	}
+
+	grade, err := s.core.GradeTypedAnswer(ctx, userID, req.FlashcardId, req.TypedAnswer, req.RecordReview,
+		reviewMeta(req.ClientReviewedAt, req.SessionId))
+	if err != nil {
+		log.Printf("[GradeTypedAnswer] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to grade answer")
+	}
+
+	resp := &learning.GradeTypedAnswerResponse{
+		Score:          grade.Score,
+		SuggestedGrade: grade.SuggestedGrade,
+		Explanation:    grade.Explanation,
+	}
+	if grade.Schedule != nil {
+		resp.Review = reviewResponse(grade.Schedule)
+	}
+
+	log.Printf("[GradeTypedAnswer] SUCCESS - Score: %.2f, Grade: %s", grade.Score, grade.SuggestedGrade)
+	return resp, nil
+}
+
+func (s *LearningService) OptimizeSchedule(ctx context.Context, _ *emptypb.Empty) (*learning.OptimizeScheduleResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[OptimizeSchedule] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[OptimizeSchedule] Optimizing schedule for userID: %s", userID)
+
+	result, err := s.core.OptimizeSchedule(ctx, userID)
+	if err != nil {
+		log.Printf("[OptimizeSchedule] ERROR: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to optimize schedule: %v", err)
+	}
+
+	log.Printf("[OptimizeSchedule] SUCCESS - %d reviews, default weights: %v", result.ReviewCount, result.UsedDefaultWeights)
+	return &learning.OptimizeScheduleResponse{
+		Weights:                 result.Weights,
+		ReviewCount:             result.ReviewCount,
+		LogLoss:                 result.LogLoss,
+		DesiredRetention:        result.DesiredRetention,
+		PredictedRetentionFsrs:  result.PredictedRetentionFSRS,
+		PredictedRetentionFixed: result.PredictedRetentionFixed,
+		FixedIntervalsDays:      result.FixedIntervalsDays,
+		FsrsIntervalsDays:       result.FSRSIntervalsDays,
+		UsedDefaultWeights:      result.UsedDefaultWeights,
+		OptimizedAt:             timestamppb.New(result.OptimizedAt),
+	}, nil
+}
+
+func (s *LearningService) UndoReview(ctx context.Context, req *learning.UndoReviewRequest) (*learning.UndoReviewResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[UndoReview] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[UndoReview] Undoing last review for userID: %s, sessionID: %s", userID, req.SessionId)
+
+	review, remaining, err := s.core.UndoReview(ctx, userID, req.SessionId)
+	if err != nil {
+		log.Printf("[UndoReview] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to undo review")
+	}
+
+	log.Printf("[UndoReview] SUCCESS - Reverted review %s", review.ID)
+	resp := &learning.UndoReviewResponse{
+		ReviewId:       review.ID,
+		FlashcardId:    review.FlashcardID,
+		RestoredStage:  review.PreviousStage,
+		RemainingUndos: remaining,
+	}
+	if !review.PreviousDueAt.IsZero() {
+		resp.RestoredNextReviewAt = timestamppb.New(review.PreviousDueAt)
+	}
+	return resp, nil
+}
+
+func (s *LearningService) GetReviewHistory(ctx context.Context, req *learning.GetReviewHistoryRequest) (*learning.GetReviewHistoryResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[GetReviewHistory] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+
+	// Set default values if not provided
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = 50 // Default page size
+	}
+
+	filter := store.ReviewHistoryFilter{
+		MaterialID: req.MaterialId,
+		Tag:        req.Tag,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	if req.From != nil {
+		filter.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		filter.To = req.To.AsTime()
+	}
+
+	log.Printf("[GetReviewHistory] Fetching history for userID: %s, page: %d, pageSize: %d", userID, page, pageSize)
+
+	entries, totalCount, err := s.core.GetReviewHistory(ctx, userID, filter)
+	if err != nil {
+		log.Printf("[GetReviewHistory] ERROR: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to get review history: %v", err)
+	}
+
+	// Calculate total pages
+	totalPages := (totalCount + pageSize - 1) / pageSize
+
+	log.Printf("[GetReviewHistory] SUCCESS - Found %d entries (page %d/%d, total: %d)", len(entries), page, totalPages, totalCount)
+	return &learning.GetReviewHistoryResponse{
+		Entries:    entries,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
 func (s *LearningService) UpdateFlashcard(ctx context.Context, req *learning.UpdateFlashcardRequest) (*emptypb.Empty, error) {
-	log.Printf("[UpdateFlashcard] Updating flashcardID: %s", req.FlashcardId)
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[UpdateFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[UpdateFlashcard] Updating flashcardID: %s for user: %s", req.FlashcardId, userID)
 
-	if err := s.core.UpdateFlashcard(ctx, req.FlashcardId, req.Question, req.Answer); err != nil {
+	if err := s.core.UpdateFlashcard(ctx, userID, req.FlashcardId, req.Question, req.Answer); err != nil {
 		log.Printf("[UpdateFlashcard] ERROR: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to update flashcard: %v", err)
+		return nil, statusFromError(err, "failed to update flashcard")
 	}
 
 	log.Printf("[UpdateFlashcard] SUCCESS")
 	return &emptypb.Empty{}, nil
 }
 
+func (s *LearningService) SuspendFlashcard(ctx context.Context, req *learning.SuspendFlashcardRequest) (*emptypb.Empty, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[SuspendFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[SuspendFlashcard] FlashcardID: %s, Restore: %v", req.FlashcardId, req.Restore)
+
+	if err := s.core.SuspendFlashcard(ctx, userID, req.FlashcardId, req.Restore); err != nil {
+		log.Printf("[SuspendFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to suspend flashcard")
+	}
+
+	log.Printf("[SuspendFlashcard] SUCCESS")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *LearningService) BuryFlashcard(ctx context.Context, req *learning.BuryFlashcardRequest) (*emptypb.Empty, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[BuryFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[BuryFlashcard] FlashcardID: %s, Restore: %v", req.FlashcardId, req.Restore)
+
+	if err := s.core.BuryFlashcard(ctx, userID, req.FlashcardId, req.Restore); err != nil {
+		log.Printf("[BuryFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to bury flashcard")
+	}
+
+	log.Printf("[BuryFlashcard] SUCCESS")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *LearningService) FlagFlashcard(ctx context.Context, req *learning.FlagFlashcardRequest) (*emptypb.Empty, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[FlagFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[FlagFlashcard] FlashcardID: %s, Flag: %s", req.FlashcardId, req.Flag)
+
+	if _, ok := learning.FlashcardFlag_name[int32(req.Flag)]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown flag: %d", req.Flag)
+	}
+
+	if err := s.core.FlagFlashcard(ctx, userID, req.FlashcardId, req.Flag); err != nil {
+		log.Printf("[FlagFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to flag flashcard")
+	}
+
+	log.Printf("[FlagFlashcard] SUCCESS")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *LearningService) GetLeeches(ctx context.Context, _ *emptypb.Empty) (*learning.FlashcardList, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[GetLeeches] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[GetLeeches] Fetching leeches for userID: %s", userID)
+
+	cards, err := s.core.GetLeeches(ctx, userID)
+	if err != nil {
+		log.Printf("[GetLeeches] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to get leeches")
+	}
+
+	log.Printf("[GetLeeches] SUCCESS - Returning %d flashcards", len(cards))
+	return &learning.FlashcardList{Flashcards: cards}, nil
+}
+
+func (s *LearningService) RewriteFlashcard(ctx context.Context, req *learning.RewriteFlashcardRequest) (*learning.RewriteFlashcardResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[RewriteFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[RewriteFlashcard] Rewriting flashcardID: %s", req.FlashcardId)
+
+	question, answer, err := s.core.RewriteFlashcard(ctx, userID, req.FlashcardId)
+	if err != nil {
+		log.Printf("[RewriteFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to rewrite flashcard")
+	}
+
+	log.Printf("[RewriteFlashcard] SUCCESS")
+	return &learning.RewriteFlashcardResponse{Question: question, Answer: answer}, nil
+}
+
+func (s *LearningService) CreateFlashcard(ctx context.Context, req *learning.CreateFlashcardRequest) (*learning.Flashcard, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[CreateFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[CreateFlashcard] Creating flashcard for userID: %s, materialID: %s", userID, req.MaterialId)
+
+	card := &learning.Flashcard{
+		Question: strings.TrimSpace(req.Question),
+		Answer:   strings.TrimSpace(req.Answer),
+		CardType: req.CardType,
+	}
+	switch req.CardType {
+	case learning.CardType_CARD_TYPE_BASIC:
+		if card.Question == "" || card.Answer == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "question and answer are required")
+		}
+	case learning.CardType_CARD_TYPE_CLOZE:
+		if len(cloze.Indices(card.Question)) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "cloze text needs at least one {{c1::...}} deletion")
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown card type: %d", req.CardType)
+	}
+
+	card, err = s.core.CreateFlashcard(ctx, userID, req.MaterialId, card, req.GenerateReverse, strings.TrimSpace(req.DeckTitle), req.Tags)
+	if err != nil {
+		log.Printf("[CreateFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to create flashcard")
+	}
+
+	log.Printf("[CreateFlashcard] SUCCESS - FlashcardID: %s", card.Id)
+	return card, nil
+}
+
+func (s *LearningService) DeleteFlashcard(ctx context.Context, req *learning.DeleteFlashcardRequest) (*emptypb.Empty, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[DeleteFlashcard] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[DeleteFlashcard] Deleting flashcardID: %s for user: %s", req.FlashcardId, userID)
+
+	if _, err := s.core.DeleteFlashcards(ctx, userID, []string{req.FlashcardId}); err != nil {
+		log.Printf("[DeleteFlashcard] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to delete flashcard")
+	}
+
+	log.Printf("[DeleteFlashcard] SUCCESS")
+	return &emptypb.Empty{}, nil
+}
+
+func (s *LearningService) BulkDeleteFlashcards(ctx context.Context, req *learning.BulkDeleteFlashcardsRequest) (*learning.BulkDeleteFlashcardsResponse, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[BulkDeleteFlashcards] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[BulkDeleteFlashcards] Deleting %d flashcards for user: %s", len(req.FlashcardIds), userID)
+
+	if len(req.FlashcardIds) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "flashcard_ids is required")
+	}
+
+	count, err := s.core.DeleteFlashcards(ctx, userID, req.FlashcardIds)
+	if err != nil {
+		log.Printf("[BulkDeleteFlashcards] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to delete flashcards")
+	}
+
+	log.Printf("[BulkDeleteFlashcards] SUCCESS - Deleted %d flashcards", count)
+	return &learning.BulkDeleteFlashcardsResponse{DeletedCount: count}, nil
+}
+
+func (s *LearningService) GenerateQuiz(ctx context.Context, req *learning.GenerateQuizRequest) (*learning.Quiz, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[GenerateQuiz] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[GenerateQuiz] MaterialID: %s, Tag: %s, Questions: %d", req.MaterialId, req.Tag, req.QuestionCount)
+
+	if (req.MaterialId == "") == (req.Tag == "") {
+		return nil, status.Errorf(codes.InvalidArgument, "exactly one of material_id or tag is required")
+	}
+	if req.QuestionCount < 0 || req.QuestionCount > core.MaxQuizQuestions {
+		return nil, status.Errorf(codes.InvalidArgument, "question_count must be between 0 and %d", core.MaxQuizQuestions)
+	}
+
+	quiz, err := s.core.GenerateQuiz(ctx, userID, req.MaterialId, req.Tag, req.QuestionCount)
+	if err != nil {
+		log.Printf("[GenerateQuiz] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to generate quiz")
+	}
+
+	log.Printf("[GenerateQuiz] SUCCESS - QuizID: %s, Questions: %d", quiz.Id, len(quiz.Questions))
+	return quiz, nil
+}
+
+func (s *LearningService) SubmitQuizAnswers(ctx context.Context, req *learning.SubmitQuizAnswersRequest) (*learning.QuizResult, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[SubmitQuizAnswers] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[SubmitQuizAnswers] QuizID: %s, Answers: %d, Apply: %v", req.QuizId, len(req.Answers), req.ApplyToSchedule)
+
+	result, err := s.core.SubmitQuizAnswers(ctx, userID, req.QuizId, req.Answers, req.ApplyToSchedule)
+	if err != nil {
+		log.Printf("[SubmitQuizAnswers] ERROR: %v", err)
+		return nil, statusFromError(err, "failed to submit quiz answers")
+	}
+
+	log.Printf("[SubmitQuizAnswers] SUCCESS - Score: %d/%d", result.CorrectCount, result.TotalCount)
+	return result, nil
+}
+
 func (s *LearningService) GetAllTags(ctx context.Context, _ *emptypb.Empty) (*learning.GetAllTagsResponse, error) {
 	// Extract user ID from context (set by auth interceptor)
 	userID, err := middleware.GetUserID(ctx)
@@ -194,7 +665,7 @@ func (s *LearningService) GetNotificationStatus(ctx context.Context, _ *emptypb.
 
 	log.Printf("[GetNotificationStatus] Fetching notification status for userID: %s", userID)
 
-	count, hasDue, err := s.core.GetNotificationStatus(ctx, userID)
+	count, newCount, reviewCount, hasDue, err := s.core.GetNotificationStatus(ctx, userID)
 	if err != nil {
 		log.Printf("[GetNotificationStatus] ERROR: %v", err)
 		return nil, status.Errorf(codes.Internal, "failed to get notification status: %v", err)
@@ -204,9 +675,48 @@ func (s *LearningService) GetNotificationStatus(ctx context.Context, _ *emptypb.
 	return &learning.NotificationStatusResponse{
 		DueFlashcardsCount: count,
 		HasDueMaterials:    hasDue,
+		NewCardsCount:      newCount,
+		ReviewsCount:       reviewCount,
 	}, nil
 }
 
+func (s *LearningService) GetStudySettings(ctx context.Context, _ *emptypb.Empty) (*learning.StudySettings, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[GetStudySettings] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+
+	settings, err := s.core.GetStudySettings(ctx, userID)
+	if err != nil {
+		log.Printf("[GetStudySettings] ERROR: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to get study settings: %v", err)
+	}
+	return settings, nil
+}
+
+func (s *LearningService) UpdateStudySettings(ctx context.Context, req *learning.StudySettings) (*learning.StudySettings, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		log.Printf("[UpdateStudySettings] ERROR: Failed to get user ID: %v", err)
+		return nil, err
+	}
+	log.Printf("[UpdateStudySettings] Updating settings for userID: %s", userID)
+
+	if err := core.ValidateStudySettings(req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid study settings: %v", err)
+	}
+
+	settings, err := s.core.UpdateStudySettings(ctx, userID, req)
+	if err != nil {
+		log.Printf("[UpdateStudySettings] ERROR: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to update study settings: %v", err)
+	}
+
+	log.Printf("[UpdateStudySettings] SUCCESS")
+	return settings, nil
+}
+
 func (s *LearningService) GetMaterialSummary(ctx context.Context, req *learning.GetMaterialSummaryRequest) (*learning.GetMaterialSummaryResponse, error) {
 	// Extract user ID from context (set by auth interceptor)
 	userID, err := middleware.GetUserID(ctx)
@@ -220,7 +730,7 @@ func (s *LearningService) GetMaterialSummary(ctx context.Context, req *learning.
 	summary, title, err := s.core.GetMaterialSummary(ctx, userID, req.MaterialId)
 	if err != nil {
 		log.Printf("[GetMaterialSummary] ERROR: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to get material summary: %v", err)
+		return nil, statusFromError(err, "failed to get material summary")
 	}
 
 	log.Printf("[GetMaterialSummary] SUCCESS - Summary length: %d", len(summary))
@@ -229,3 +739,43 @@ func (s *LearningService) GetMaterialSummary(ctx context.Context, req *learning.
 		Title:   title,
 	}, nil
 }
+
+// reviewResponse describes a card's new schedule to the client.
+func reviewResponse(schedule *store.FlashcardSchedule) *learning.ReviewFlashcardResponse {
+	return &learning.ReviewFlashcardResponse{
+		Stage:        schedule.Stage,
+		NextReviewAt: timestamppb.New(schedule.NextReviewAt),
+		IntervalDays: schedule.IntervalDays,
+		EaseFactor:   schedule.EaseFactor,
+		Repetitions:  schedule.Repetitions,
+		Stability:    schedule.Stability,
+		Difficulty:   schedule.Difficulty,
+	}
+}
+
+// reviewMeta converts client-supplied review context from a request.
+func reviewMeta(clientReviewedAt *timestamppb.Timestamp, sessionID string) core.ReviewMeta {
+	meta := core.ReviewMeta{SessionID: sessionID}
+	if clientReviewedAt != nil {
+		meta.ClientReviewedAt = clientReviewedAt.AsTime()
+	}
+	return meta
+}
+
+// statusFromError converts an error from the core layer into a gRPC status,
+// mapping known store errors to their codes and everything else to Internal.
+func statusFromError(err error, msg string) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, store.ErrNothingToUndo):
+		code = codes.NotFound
+	case errors.Is(err, store.ErrPermissionDenied):
+		code = codes.PermissionDenied
+	case errors.Is(err, store.ErrReviewSuperseded), errors.Is(err, store.ErrIngestionInProgress),
+		errors.Is(err, store.ErrNoFailedChunks):
+		code = codes.FailedPrecondition
+	case errors.Is(err, store.ErrNoClozeDeletions):
+		code = codes.InvalidArgument
+	}
+	return status.Errorf(code, "%s: %v", msg, err)
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/amityadav/landr/internal/core"
+	"github.com/amityadav/landr/internal/fake"
+	"github.com/amityadav/landr/internal/middleware"
+	"github.com/amityadav/landr/internal/store"
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// owned is what one user has made, for another user to try to reach.
+type owned struct {
+	materialID string
+	cardID     string
+	quizID     string
+	questionID string
+	jobID      string
+}
+
+// TestCrossUserAccess has one user create a material with reviewed cards, a
+// quiz and an ingestion job, and another call every RPC with their IDs. RPCs
+// taking an ID must answer PermissionDenied, and the rest must show nothing of
+// the first user's.
+func TestCrossUserAccess(t *testing.T) {
+	stores := map[string]func(t *testing.T) store.Store{
+		"memory": func(t *testing.T) store.Store { return store.NewMemoryStore() },
+		"sqlite": func(t *testing.T) store.Store {
+			s, err := store.NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "landr.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteStore: %v", err)
+			}
+			t.Cleanup(s.Close)
+			return s
+		},
+	}
+
+	tests := []struct {
+		name string
+		want codes.Code
+		call func(ctx context.Context, s *LearningService, a owned) error
+	}{
+		{"WatchIngestionJob", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			return s.WatchIngestionJob(&learning.WatchIngestionJobRequest{JobId: a.jobID}, &jobStream{ctx: ctx})
+		}},
+		{"RetryFailedChunks", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.RetryFailedChunks(ctx, &learning.RetryFailedChunksRequest{MaterialId: a.materialID})
+			return err
+		}},
+		{"DeleteMaterial", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.DeleteMaterial(ctx, &learning.DeleteMaterialRequest{MaterialId: a.materialID})
+			return err
+		}},
+		{"GetDueFlashcards", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.GetDueFlashcards(ctx, &learning.GetDueFlashcardsRequest{MaterialId: a.materialID})
+			return err
+		}},
+		{"CompleteReview", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.CompleteReview(ctx, &learning.CompleteReviewRequest{FlashcardId: a.cardID})
+			return err
+		}},
+		{"FailReview", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.FailReview(ctx, &learning.FailReviewRequest{FlashcardId: a.cardID})
+			return err
+		}},
+		{"ReviewFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.ReviewFlashcard(ctx, &learning.ReviewFlashcardRequest{FlashcardId: a.cardID, Grade: learning.ReviewGrade_REVIEW_GRADE_GOOD})
+			return err
+		}},
+		{"GradeTypedAnswer", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.GradeTypedAnswer(ctx, &learning.GradeTypedAnswerRequest{FlashcardId: a.cardID, TypedAnswer: "Paris", RecordReview: true})
+			return err
+		}},
+		{"UpdateFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.UpdateFlashcard(ctx, &learning.UpdateFlashcardRequest{FlashcardId: a.cardID, Question: "Q", Answer: "A"})
+			return err
+		}},
+		{"SuspendFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.SuspendFlashcard(ctx, &learning.SuspendFlashcardRequest{FlashcardId: a.cardID})
+			return err
+		}},
+		{"BuryFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.BuryFlashcard(ctx, &learning.BuryFlashcardRequest{FlashcardId: a.cardID})
+			return err
+		}},
+		{"FlagFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.FlagFlashcard(ctx, &learning.FlagFlashcardRequest{FlashcardId: a.cardID, Flag: learning.FlashcardFlag_FLASHCARD_FLAG_RED})
+			return err
+		}},
+		{"RewriteFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.RewriteFlashcard(ctx, &learning.RewriteFlashcardRequest{FlashcardId: a.cardID})
+			return err
+		}},
+		{"CreateFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.CreateFlashcard(ctx, &learning.CreateFlashcardRequest{MaterialId: a.materialID, Question: "Q", Answer: "A"})
+			return err
+		}},
+		{"DeleteFlashcard", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.DeleteFlashcard(ctx, &learning.DeleteFlashcardRequest{FlashcardId: a.cardID})
+			return err
+		}},
+		{"BulkDeleteFlashcards", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.BulkDeleteFlashcards(ctx, &learning.BulkDeleteFlashcardsRequest{FlashcardIds: []string{a.cardID}})
+			return err
+		}},
+		{"GenerateQuiz", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.GenerateQuiz(ctx, &learning.GenerateQuizRequest{MaterialId: a.materialID})
+			return err
+		}},
+		{"SubmitQuizAnswers", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.SubmitQuizAnswers(ctx, &learning.SubmitQuizAnswersRequest{
+				QuizId:          a.quizID,
+				Answers:         []*learning.QuizAnswer{{QuestionId: a.questionID}},
+				ApplyToSchedule: true,
+			})
+			return err
+		}},
+		{"GetMaterialSummary", codes.PermissionDenied, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.GetMaterialSummary(ctx, &learning.GetMaterialSummaryRequest{MaterialId: a.materialID})
+			return err
+		}},
+
+		// RPCs over the caller's own data must not include the other user's
+		{"UndoReview", codes.NotFound, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.UndoReview(ctx, &learning.UndoReviewRequest{})
+			return err
+		}},
+		{"UndoReview of the session", codes.NotFound, func(ctx context.Context, s *LearningService, a owned) error {
+			_, err := s.UndoReview(ctx, &learning.UndoReviewRequest{SessionId: "session"})
+			return err
+		}},
+		{"GetReviewHistory", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetReviewHistory(ctx, &learning.GetReviewHistoryRequest{MaterialId: a.materialID})
+			if err == nil && (len(resp.Entries) > 0 || resp.TotalCount > 0) {
+				return leaked(resp.TotalCount, "reviews")
+			}
+			return err
+		}},
+		{"GetReviewQueue", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetReviewQueue(ctx, &learning.GetReviewQueueRequest{MaterialIds: []string{a.materialID}})
+			if err == nil && (len(resp.Flashcards) > 0 || resp.TotalDue > 0) {
+				return leaked(resp.TotalDue, "due cards")
+			}
+			return err
+		}},
+		{"GetDueMaterials", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetDueMaterials(ctx, &learning.GetDueMaterialsRequest{})
+			if err == nil && resp.TotalCount > 0 {
+				return leaked(resp.TotalCount, "materials")
+			}
+			return err
+		}},
+		{"GetLeeches", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetLeeches(ctx, &emptypb.Empty{})
+			if err == nil && len(resp.Flashcards) > 0 {
+				return leaked(int32(len(resp.Flashcards)), "leeches")
+			}
+			return err
+		}},
+		{"GetAllTags", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetAllTags(ctx, &emptypb.Empty{})
+			if err == nil && len(resp.Tags) > 0 {
+				return leaked(int32(len(resp.Tags)), "tags")
+			}
+			return err
+		}},
+		{"GetNotificationStatus", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetNotificationStatus(ctx, &emptypb.Empty{})
+			if err == nil && resp.DueFlashcardsCount > 0 {
+				return leaked(resp.DueFlashcardsCount, "due cards")
+			}
+			return err
+		}},
+		{"GetStudySettings", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.GetStudySettings(ctx, &emptypb.Empty{})
+			if err == nil && resp.LeechThreshold != core.DefaultStudySettings().LeechThreshold {
+				return fmt.Errorf("got the other user's leech threshold %d", resp.LeechThreshold)
+			}
+			return err
+		}},
+		{"OptimizeSchedule", codes.OK, func(ctx context.Context, s *LearningService, a owned) error {
+			resp, err := s.OptimizeSchedule(ctx, &emptypb.Empty{})
+			if err == nil && resp.ReviewCount > 0 {
+				return leaked(resp.ReviewCount, "reviews")
+			}
+			return err
+		}},
+	}
+
+	for name, newStore := range stores {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				st := newStore(t)
+				ai := &fake.AI{}
+				learningCore := core.NewLearningCore(st, &fake.Scraper{}, ai, &fake.Transcripts{}, core.FixedScheduler{})
+				s := NewLearningService(learningCore)
+				a, aCtx := setUpOwner(t, s, st, ai)
+				bCtx := userContext(t, st, "b")
+
+				before := len(ai.Calls())
+				err := tt.call(bCtx, s, a)
+				if got := status.Code(err); got != tt.want {
+					t.Fatalf("%s: got %v (%v), want %v", tt.name, got, err, tt.want)
+				}
+				if calls := ai.Calls()[before:]; len(calls) > 0 {
+					t.Errorf("AI called %v for another user's request", calls)
+				}
+
+				// The owner's data is untouched
+				cards, err := s.GetDueFlashcards(aCtx, &learning.GetDueFlashcardsRequest{MaterialId: a.materialID})
+				if err != nil {
+					t.Fatalf("owner's GetDueFlashcards: %v", err)
+				}
+				questions := make(map[string]bool)
+				for _, card := range cards.Flashcards {
+					questions[card.Question] = true
+				}
+				if len(cards.Flashcards) != 2 || !questions["France?"] || !questions["Italy?"] {
+					t.Errorf("owner's cards changed: %v", cards.Flashcards)
+				}
+				history, err := s.GetReviewHistory(aCtx, &learning.GetReviewHistoryRequest{})
+				if err != nil {
+					t.Fatalf("owner's GetReviewHistory: %v", err)
+				}
+				if history.TotalCount != 2 {
+					t.Errorf("owner has %d reviews, want 2", history.TotalCount)
+				}
+			})
+		}
+	}
+}
+
+// setUpOwner has a new user make a tagged material with two cards, one of them
+// reviewed into a leech, a quiz, a failed chunk and a queued ingestion job. It
+// returns their IDs and a context acting as the user.
+func setUpOwner(t *testing.T, s *LearningService, st store.Store, ai *fake.AI) (owned, context.Context) {
+	t.Helper()
+	ctx := userContext(t, st, "a")
+	userID, _ := middleware.GetUserID(ctx)
+	var a owned
+
+	var err error
+	a.materialID, err = st.CreateMaterial(ctx, userID, "TEXT", "Capitals of Europe", "Capitals")
+	if err != nil {
+		t.Fatalf("CreateMaterial: %v", err)
+	}
+	ids, err := st.CreateFlashcards(ctx, a.materialID, []*learning.Flashcard{
+		{Question: "France?", Answer: "Paris"},
+		{Question: "Italy?", Answer: "Rome"},
+	})
+	if err != nil {
+		t.Fatalf("CreateFlashcards: %v", err)
+	}
+	a.cardID = ids[0]
+	tagID, err := st.CreateTag(ctx, userID, "geography")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := st.AddMaterialTags(ctx, a.materialID, []string{tagID}); err != nil {
+		t.Fatalf("AddMaterialTags: %v", err)
+	}
+	if err := st.CreateMaterialChunks(ctx, a.materialID, []string{"Capitals of Europe"}); err != nil {
+		t.Fatalf("CreateMaterialChunks: %v", err)
+	}
+	if err := st.FailMaterialChunk(ctx, a.materialID, 0, "model error"); err != nil {
+		t.Fatalf("FailMaterialChunk: %v", err)
+	}
+	job := &store.IngestionJob{UserID: userID, Type: "TEXT", Content: "Capitals of Europe"}
+	if err := st.CreateIngestionJob(ctx, job); err != nil {
+		t.Fatalf("CreateIngestionJob: %v", err)
+	}
+	a.jobID = job.ID
+
+	settings := core.DefaultStudySettings()
+	settings.LeechThreshold = 1
+	if _, err := s.UpdateStudySettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateStudySettings: %v", err)
+	}
+	if _, err := s.CompleteReview(ctx, &learning.CompleteReviewRequest{FlashcardId: a.cardID, SessionId: "session"}); err != nil {
+		t.Fatalf("CompleteReview: %v", err)
+	}
+	if _, err := s.FailReview(ctx, &learning.FailReviewRequest{FlashcardId: a.cardID, SessionId: "session"}); err != nil {
+		t.Fatalf("FailReview: %v", err)
+	}
+
+	ai.AddDistractors(nil, errors.New("ai unavailable"))
+	quiz, err := s.GenerateQuiz(ctx, &learning.GenerateQuizRequest{MaterialId: a.materialID})
+	if err != nil {
+		t.Fatalf("GenerateQuiz: %v", err)
+	}
+	a.quizID, a.questionID = quiz.Id, quiz.Questions[0].Id
+	return a, ctx
+}
+
+// userContext creates a user and returns a context authenticated as them.
+func userContext(t *testing.T, st store.Store, name string) context.Context {
+	t.Helper()
+	user, err := st.CreateUser(context.Background(), name+"@example.com", name, "google-"+name, "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return context.WithValue(context.Background(), middleware.UserIDKey, user.Id)
+}
+
+func leaked(n int32, what string) error {
+	return fmt.Errorf("got %d of the other user's %s", n, what)
+}
+
+// jobStream is a WatchIngestionJob stream that discards what it is sent.
+type jobStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *jobStream) Context() context.Context { return s.ctx }
+
+func (s *jobStream) Send(*learning.IngestionJob) error { return nil }
@@ -0,0 +1,237 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// The IDs an operation is tried with, besides one the user owns.
+const (
+	missingID   = "00000000-0000-0000-0000-000000000000"
+	malformedID = "not-a-uuid"
+)
+
+// TestCrossUserAccess checks every operation on a user's material, flashcard,
+// quiz or ingestion job turns away other users with ErrPermissionDenied, and
+// IDs that don't exist, or aren't even UUIDs, with ErrNotFound.
+func TestCrossUserAccess(t *testing.T) {
+	ctx := context.Background()
+	card := func() []*learning.Flashcard {
+		return []*learning.Flashcard{{Question: "Q", Answer: "A"}}
+	}
+	later := time.Now().Add(24 * time.Hour)
+
+	materialOps := []struct {
+		name string
+		op   func(s Store, userID, materialID string) error
+	}{
+		{"AddFlashcards", func(s Store, userID, materialID string) error {
+			_, err := s.AddFlashcards(ctx, userID, materialID, card())
+			return err
+		}},
+		{"AddFlashcards in a transaction", func(s Store, userID, materialID string) error {
+			return s.WithTx(ctx, func(tx Store) error {
+				_, err := tx.AddFlashcards(ctx, userID, materialID, card())
+				return err
+			})
+		}},
+		{"GetDueFlashcards", func(s Store, userID, materialID string) error {
+			_, err := s.GetDueFlashcards(ctx, userID, materialID)
+			return err
+		}},
+		{"SoftDeleteMaterial", func(s Store, userID, materialID string) error {
+			return s.SoftDeleteMaterial(ctx, userID, materialID)
+		}},
+		{"GetMaterialContent", func(s Store, userID, materialID string) error {
+			_, _, _, err := s.GetMaterialContent(ctx, userID, materialID)
+			return err
+		}},
+		{"UpdateMaterialSummary", func(s Store, userID, materialID string) error {
+			return s.UpdateMaterialSummary(ctx, userID, materialID, "Summary")
+		}},
+		{"UpdateMaterialTitle", func(s Store, userID, materialID string) error {
+			return s.UpdateMaterialTitle(ctx, userID, materialID, "Title")
+		}},
+		{"GetMaterialChunks", func(s Store, userID, materialID string) error {
+			_, err := s.GetMaterialChunks(ctx, userID, materialID)
+			return err
+		}},
+		{"GetQuizCards", func(s Store, userID, materialID string) error {
+			_, err := s.GetQuizCards(ctx, userID, materialID, "", 10)
+			return err
+		}},
+	}
+
+	flashcardOps := []struct {
+		name string
+		op   func(s Store, userID, flashcardID string) error
+	}{
+		{"GetFlashcard", func(s Store, userID, flashcardID string) error {
+			_, err := s.GetFlashcard(ctx, userID, flashcardID)
+			return err
+		}},
+		{"UpdateFlashcardContent", func(s Store, userID, flashcardID string) error {
+			return s.UpdateFlashcardContent(ctx, userID, flashcardID, "Q2", "A2")
+		}},
+		{"SetFlashcardSuspended", func(s Store, userID, flashcardID string) error {
+			return s.SetFlashcardSuspended(ctx, userID, flashcardID, true)
+		}},
+		{"SetFlashcardBuriedUntil", func(s Store, userID, flashcardID string) error {
+			return s.SetFlashcardBuriedUntil(ctx, userID, flashcardID, &later)
+		}},
+		{"SetFlashcardFlag", func(s Store, userID, flashcardID string) error {
+			return s.SetFlashcardFlag(ctx, userID, flashcardID, 1)
+		}},
+		{"DeleteFlashcards", func(s Store, userID, flashcardID string) error {
+			_, err := s.DeleteFlashcards(ctx, userID, []string{flashcardID})
+			return err
+		}},
+		{"RecordReview", func(s Store, userID, flashcardID string) error {
+			schedule := FlashcardSchedule{Stage: 1, EaseFactor: 2.5, IntervalDays: 1, NextReviewAt: later, LastReviewedAt: time.Now()}
+			return s.RecordReview(ctx, schedule, &ReviewLog{
+				FlashcardID: flashcardID, UserID: userID, Grade: learning.ReviewGrade_REVIEW_GRADE_GOOD,
+				Scheduler: "fixed", NextStage: 1, NextDueAt: later, ReviewedAt: time.Now(),
+			})
+		}},
+	}
+
+	otherOps := []struct {
+		name   string
+		create func(t *testing.T, s Store, userID string) string
+		op     func(s Store, userID, id string) error
+	}{
+		{"GetQuizQuestions", func(t *testing.T, s Store, userID string) string {
+			materialID, cardIDs := newTestMaterial(t, s, userID, card()...)
+			quizID, err := s.CreateQuiz(ctx, userID, materialID, "", []*QuizQuestion{
+				{FlashcardID: cardIDs[0], Question: "Q", Choices: []string{"A", "B"}, CorrectIndex: 0},
+			})
+			if err != nil {
+				t.Fatalf("CreateQuiz: %v", err)
+			}
+			return quizID
+		}, func(s Store, userID, quizID string) error {
+			_, err := s.GetQuizQuestions(ctx, userID, quizID)
+			return err
+		}},
+		{"GetIngestionJob", func(t *testing.T, s Store, userID string) string {
+			job := &IngestionJob{UserID: userID, Type: "TEXT", Content: "Some content"}
+			if err := s.CreateIngestionJob(ctx, job); err != nil {
+				t.Fatalf("CreateIngestionJob: %v", err)
+			}
+			return job.ID
+		}, func(s Store, userID, jobID string) error {
+			_, err := s.GetIngestionJob(ctx, userID, jobID)
+			return err
+		}},
+	}
+
+	// check runs op as the owner, as someone else and on IDs that don't exist
+	check := func(t *testing.T, owner, other, id string, op func(userID, id string) error) {
+		t.Helper()
+		tests := []struct {
+			name   string
+			userID string
+			id     string
+			want   error
+		}{
+			{"other user", other, id, ErrPermissionDenied},
+			{"missing id", owner, missingID, ErrNotFound},
+			{"malformed id", owner, malformedID, ErrNotFound},
+			{"owner", owner, id, nil},
+		}
+		for _, tt := range tests {
+			err := op(tt.userID, tt.id)
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+			}
+		}
+	}
+
+	forEachStore(t, func(t *testing.T, s Store) {
+		owner, other := newTestUser(t, s), newTestUser(t, s)
+
+		for _, tt := range materialOps {
+			t.Run(tt.name, func(t *testing.T) {
+				materialID, _ := newTestMaterial(t, s, owner, card()...)
+				check(t, owner, other, materialID, func(userID, id string) error { return tt.op(s, userID, id) })
+			})
+		}
+		for _, tt := range flashcardOps {
+			t.Run(tt.name, func(t *testing.T) {
+				_, cardIDs := newTestMaterial(t, s, owner, card()...)
+				check(t, owner, other, cardIDs[0], func(userID, id string) error { return tt.op(s, userID, id) })
+			})
+		}
+		for _, tt := range otherOps {
+			t.Run(tt.name, func(t *testing.T) {
+				id := tt.create(t, s, owner)
+				check(t, owner, other, id, func(userID, id string) error { return tt.op(s, userID, id) })
+			})
+		}
+	})
+}
+
+// TestAddFlashcardsInTransactionKeepsItUsable checks a rejected AddFlashcards
+// leaves the transaction it ran in usable, so the caller can carry on or
+// report the error it got instead of a failed statement.
+func TestAddFlashcardsInTransactionKeepsItUsable(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		owner, other := newTestUser(t, s), newTestUser(t, s)
+		materialID, _ := newTestMaterial(t, s, owner)
+		foreignID, _ := newTestMaterial(t, s, other)
+
+		for _, id := range []string{malformedID, missingID, foreignID} {
+			err := s.WithTx(ctx, func(tx Store) error {
+				if _, err := tx.AddFlashcards(ctx, owner, id, []*learning.Flashcard{{Question: "Q", Answer: "A"}}); err == nil {
+					t.Errorf("AddFlashcards(%s) succeeded", id)
+				}
+				_, err := tx.AddFlashcards(ctx, owner, materialID, []*learning.Flashcard{{Question: "Q", Answer: "A"}})
+				return err
+			})
+			if err != nil {
+				t.Errorf("after AddFlashcards(%s): %v", id, err)
+			}
+		}
+
+		cards, err := s.GetDueFlashcards(ctx, owner, materialID)
+		if err != nil {
+			t.Fatalf("GetDueFlashcards: %v", err)
+		}
+		if len(cards) != 3 {
+			t.Errorf("got %d cards, want 3", len(cards))
+		}
+	})
+}
+
+// TestBuryFlashcardSiblingsIgnoresOtherUsers checks burying the siblings of a
+// card someone else owns leaves them alone.
+func TestBuryFlashcardSiblingsIgnoresOtherUsers(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		owner, other := newTestUser(t, s), newTestUser(t, s)
+		noteID := "11111111-1111-4111-8111-111111111111"
+		materialID, cardIDs := newTestMaterial(t, s, owner,
+			&learning.Flashcard{Question: "Q", Answer: "A", NoteId: noteID},
+			&learning.Flashcard{Question: "A", Answer: "Q", NoteId: noteID})
+
+		buried, err := s.BuryFlashcardSiblings(ctx, other, cardIDs[0], time.Now().Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("BuryFlashcardSiblings: %v", err)
+		}
+		if len(buried) != 0 {
+			t.Errorf("BuryFlashcardSiblings buried %v", buried)
+		}
+		cards, err := s.GetDueFlashcards(ctx, owner, materialID)
+		if err != nil {
+			t.Fatalf("GetDueFlashcards: %v", err)
+		}
+		if len(cards) != 2 {
+			t.Errorf("got %d due cards, want 2", len(cards))
+		}
+	})
+}
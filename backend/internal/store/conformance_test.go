@@ -0,0 +1,744 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/proto"
+)
+
+// The tests in this file pin down behaviour every Store implementation must
+// share, and run against each of them through forEachStore.
+
+func TestFlashcardLifecycle(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		materialID, ids := newTestMaterial(t, s, userID,
+			&learning.Flashcard{Question: "Q1", Answer: "A1"},
+			&learning.Flashcard{Question: "Q2", Answer: "A2"},
+			&learning.Flashcard{Question: "Q3", Answer: "A3"})
+
+		due := func() []string {
+			t.Helper()
+			cards, err := s.GetDueFlashcards(ctx, userID, materialID)
+			if err != nil {
+				t.Fatalf("GetDueFlashcards: %v", err)
+			}
+			var got []string
+			for _, card := range cards {
+				got = append(got, card.Id)
+			}
+			slices.Sort(got)
+			return got
+		}
+		sorted := func(ids ...string) []string {
+			ids = slices.Clone(ids)
+			slices.Sort(ids)
+			return ids
+		}
+
+		if got, want := due(), sorted(ids...); !slices.Equal(got, want) {
+			t.Errorf("due cards = %v, want %v", got, want)
+		}
+
+		if err := s.UpdateFlashcardContent(ctx, userID, ids[0], "Q1 edited", "A1 edited"); err != nil {
+			t.Fatalf("UpdateFlashcardContent: %v", err)
+		}
+		card, err := s.GetFlashcard(ctx, userID, ids[0])
+		if err != nil {
+			t.Fatalf("GetFlashcard: %v", err)
+		}
+		if card.Question != "Q1 edited" || card.Answer != "A1 edited" || card.MaterialId != materialID {
+			t.Errorf("GetFlashcard = %q/%q in %s, want the edited card in %s", card.Question, card.Answer, card.MaterialId, materialID)
+		}
+
+		if err := s.SetFlashcardSuspended(ctx, userID, ids[1], true); err != nil {
+			t.Fatalf("SetFlashcardSuspended: %v", err)
+		}
+		later := time.Now().Add(24 * time.Hour)
+		if err := s.SetFlashcardBuriedUntil(ctx, userID, ids[2], &later); err != nil {
+			t.Fatalf("SetFlashcardBuriedUntil: %v", err)
+		}
+		if got, want := due(), sorted(ids[0]); !slices.Equal(got, want) {
+			t.Errorf("due cards after suspending and burying = %v, want %v", got, want)
+		}
+
+		if err := s.SetFlashcardSuspended(ctx, userID, ids[1], false); err != nil {
+			t.Fatalf("SetFlashcardSuspended: %v", err)
+		}
+		if err := s.SetFlashcardBuriedUntil(ctx, userID, ids[2], nil); err != nil {
+			t.Fatalf("SetFlashcardBuriedUntil: %v", err)
+		}
+		if got, want := due(), sorted(ids...); !slices.Equal(got, want) {
+			t.Errorf("due cards after restoring = %v, want %v", got, want)
+		}
+
+		deleted, err := s.DeleteFlashcards(ctx, userID, ids[:2])
+		if err != nil {
+			t.Fatalf("DeleteFlashcards: %v", err)
+		}
+		if deleted != 2 {
+			t.Errorf("DeleteFlashcards = %d, want 2", deleted)
+		}
+		if _, err := s.GetFlashcard(ctx, userID, ids[0]); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetFlashcard of a deleted card: got %v, want %v", err, ErrNotFound)
+		}
+		if got, want := due(), sorted(ids[2]); !slices.Equal(got, want) {
+			t.Errorf("due cards after deleting = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestClozeEditReconcilesSiblings(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		text := "{{c1::Paris}} is the capital of {{c2::France}}"
+		noteID := NewUUID()
+		materialID, ids := newTestMaterial(t, s, userID,
+			&learning.Flashcard{Question: text, CardType: learning.CardType_CARD_TYPE_CLOZE, NoteId: noteID, ClozeIndex: 1},
+			&learning.Flashcard{Question: text, CardType: learning.CardType_CARD_TYPE_CLOZE, NoteId: noteID, ClozeIndex: 2},
+			&learning.Flashcard{Question: "Q", Answer: "A"})
+
+		// siblings returns the cloze number of each card of the note, and checks they all share its text
+		siblings := func(wantText string) map[int32]string {
+			t.Helper()
+			cards, err := s.GetDueFlashcards(ctx, userID, materialID)
+			if err != nil {
+				t.Fatalf("GetDueFlashcards: %v", err)
+			}
+			got := make(map[int32]string)
+			for _, card := range cards {
+				if card.CardType != learning.CardType_CARD_TYPE_CLOZE {
+					continue
+				}
+				if card.NoteId != noteID || card.Question != wantText {
+					t.Errorf("cloze card %s has note %s and text %q, want %s and %q", card.Id, card.NoteId, card.Question, noteID, wantText)
+				}
+				got[card.ClozeIndex] = card.Id
+			}
+			return got
+		}
+
+		edited := "{{c1::Paris}} is the capital of France, on the {{c3::Seine::river}}"
+		if err := s.UpdateFlashcardContent(ctx, userID, ids[0], edited, "Extra"); err != nil {
+			t.Fatalf("UpdateFlashcardContent: %v", err)
+		}
+		got := siblings(edited)
+		if len(got) != 2 || got[1] != ids[0] || got[3] == "" {
+			t.Errorf("cloze cards after edit = %v, want card %s for c1 and a new card for c3", got, ids[0])
+		}
+		if _, err := s.GetFlashcard(ctx, userID, ids[1]); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetFlashcard of the removed c2 card: got %v, want %v", err, ErrNotFound)
+		}
+
+		if err := s.UpdateFlashcardContent(ctx, userID, got[3], "No deletions", ""); !errors.Is(err, ErrNoClozeDeletions) {
+			t.Errorf("UpdateFlashcardContent without deletions: got %v, want %v", err, ErrNoClozeDeletions)
+		}
+		if after := siblings(edited); len(after) != 2 || after[1] != got[1] || after[3] != got[3] {
+			t.Errorf("cloze cards after a rejected edit = %v, want %v", after, got)
+		}
+
+		basic, err := s.GetFlashcard(ctx, userID, ids[2])
+		if err != nil {
+			t.Fatalf("GetFlashcard: %v", err)
+		}
+		if basic.Question != "Q" {
+			t.Errorf("basic card question = %q, want it untouched", basic.Question)
+		}
+	})
+}
+
+func TestMaterialsAndTags(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		first, _ := newTestMaterial(t, s, userID, &learning.Flashcard{Question: "Q", Answer: "A"})
+		second, _ := newTestMaterial(t, s, userID)
+
+		goID, err := s.CreateTag(ctx, userID, "go")
+		if err != nil {
+			t.Fatalf("CreateTag: %v", err)
+		}
+		again, err := s.CreateTag(ctx, userID, "go")
+		if err != nil {
+			t.Fatalf("CreateTag: %v", err)
+		}
+		if again != goID {
+			t.Errorf("CreateTag of an existing name = %s, want %s", again, goID)
+		}
+		dbID, err := s.CreateTag(ctx, userID, "databases")
+		if err != nil {
+			t.Fatalf("CreateTag: %v", err)
+		}
+		if err := s.AddMaterialTags(ctx, first, []string{goID, dbID}); err != nil {
+			t.Fatalf("AddMaterialTags: %v", err)
+		}
+
+		tags, err := s.GetTags(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetTags: %v", err)
+		}
+		if want := []string{"databases", "go"}; !slices.Equal(tags, want) {
+			t.Errorf("GetTags = %v, want %v", tags, want)
+		}
+		materialTags, err := s.GetMaterialTags(ctx, first)
+		if err != nil {
+			t.Fatalf("GetMaterialTags: %v", err)
+		}
+		slices.Sort(materialTags)
+		if want := []string{"databases", "go"}; !slices.Equal(materialTags, want) {
+			t.Errorf("GetMaterialTags = %v, want %v", materialTags, want)
+		}
+
+		// Newest first
+		materials, total, err := s.GetDueMaterials(ctx, userID, 1, 10)
+		if err != nil {
+			t.Fatalf("GetDueMaterials: %v", err)
+		}
+		if total != 2 || len(materials) != 2 || materials[0].Id != second || materials[1].Id != first {
+			t.Fatalf("GetDueMaterials = %d of %d, want %s then %s", len(materials), total, second, first)
+		}
+		if materials[1].DueCount != 1 {
+			t.Errorf("DueCount = %d, want 1", materials[1].DueCount)
+		}
+
+		if err := s.SoftDeleteMaterial(ctx, userID, first); err != nil {
+			t.Fatalf("SoftDeleteMaterial: %v", err)
+		}
+		materials, total, err = s.GetDueMaterials(ctx, userID, 1, 10)
+		if err != nil {
+			t.Fatalf("GetDueMaterials: %v", err)
+		}
+		if total != 1 || len(materials) != 1 || materials[0].Id != second {
+			t.Errorf("GetDueMaterials after deleting = %d of %d, want only %s", len(materials), total, second)
+		}
+	})
+}
+
+func TestReviewAndUndo(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		_, ids := newTestMaterial(t, s, userID, &learning.Flashcard{Question: "Q", Answer: "A"})
+		before, err := s.GetFlashcard(ctx, userID, ids[0])
+		if err != nil {
+			t.Fatalf("GetFlashcard: %v", err)
+		}
+
+		if _, _, err := s.UndoLastReview(ctx, userID, ""); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("UndoLastReview before any review: got %v, want %v", err, ErrNothingToUndo)
+		}
+
+		now := time.Now()
+		next := now.Add(72 * time.Hour)
+		schedule := FlashcardSchedule{Stage: 2, EaseFactor: 2.6, IntervalDays: 3, Repetitions: 1, NextReviewAt: next, LastReviewedAt: now}
+		entry := &ReviewLog{
+			FlashcardID: ids[0], UserID: userID, Grade: learning.ReviewGrade_REVIEW_GRADE_GOOD, Scheduler: "sm2",
+			PreviousStage: before.Stage, NextStage: 2, PreviousDueAt: before.NextReviewAt.AsTime(), NextDueAt: next,
+			ReviewedAt: now, SessionID: "session",
+			PreviousEaseFactor: before.EaseFactor,
+		}
+		if err := s.RecordReview(ctx, schedule, entry); err != nil {
+			t.Fatalf("RecordReview: %v", err)
+		}
+		if entry.ID == "" {
+			t.Error("RecordReview left the entry without an ID")
+		}
+
+		card, err := s.GetFlashcard(ctx, userID, ids[0])
+		if err != nil {
+			t.Fatalf("GetFlashcard: %v", err)
+		}
+		if card.Stage != 2 || card.IntervalDays != 3 || card.LastReviewedAt == nil {
+			t.Errorf("after review: stage %d, interval %d, reviewed at %v", card.Stage, card.IntervalDays, card.LastReviewedAt)
+		}
+		newCount, reviewCount, err := s.GetStudyCountsSince(ctx, userID, now.Add(-time.Minute))
+		if err != nil {
+			t.Fatalf("GetStudyCountsSince: %v", err)
+		}
+		if newCount != 1 || reviewCount != 0 {
+			t.Errorf("GetStudyCountsSince = %d new, %d reviews, want 1 and 0", newCount, reviewCount)
+		}
+
+		if _, _, err := s.UndoLastReview(ctx, userID, "other session"); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("UndoLastReview of another session: got %v, want %v", err, ErrNothingToUndo)
+		}
+		undone, remaining, err := s.UndoLastReview(ctx, userID, "session")
+		if err != nil {
+			t.Fatalf("UndoLastReview: %v", err)
+		}
+		if undone.FlashcardID != ids[0] || remaining != 0 {
+			t.Errorf("UndoLastReview = %s with %d remaining, want %s with 0", undone.FlashcardID, remaining, ids[0])
+		}
+
+		card, err = s.GetFlashcard(ctx, userID, ids[0])
+		if err != nil {
+			t.Fatalf("GetFlashcard: %v", err)
+		}
+		if card.Stage != before.Stage || card.IntervalDays != before.IntervalDays || card.LastReviewedAt != nil {
+			t.Errorf("after undo: stage %d, interval %d, reviewed at %v, want the card as before", card.Stage, card.IntervalDays, card.LastReviewedAt)
+		}
+		if _, _, err := s.UndoLastReview(ctx, userID, "session"); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("UndoLastReview twice: got %v, want %v", err, ErrNothingToUndo)
+		}
+	})
+}
+
+func TestReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		now := time.Now()
+
+		// material adds a material whose new cards come due after every card
+		// added before them, with the given cards reviewed and due offset from now
+		names := make(map[string]string)
+		material := func(newCards []string, reviewed map[string]time.Duration, clozeNote ...string) {
+			t.Helper()
+			var cards []*learning.Flashcard
+			var cardNames []string
+			for _, name := range newCards {
+				cards = append(cards, &learning.Flashcard{Question: name, Answer: name})
+				cardNames = append(cardNames, name)
+			}
+			for name := range reviewed {
+				cards = append(cards, &learning.Flashcard{Question: name, Answer: name})
+				cardNames = append(cardNames, name)
+			}
+			noteID := NewUUID()
+			for i, name := range clozeNote {
+				cards = append(cards, &learning.Flashcard{Question: "{{c1::x}} {{c2::y}}", CardType: learning.CardType_CARD_TYPE_CLOZE, NoteId: noteID, ClozeIndex: int32(i + 1)})
+				cardNames = append(cardNames, name)
+			}
+			_, ids := newTestMaterial(t, s, userID, cards...)
+			for i, id := range ids {
+				names[id] = cardNames[i]
+				offset, ok := reviewed[cardNames[i]]
+				if !ok {
+					continue
+				}
+				reviewedAt := now.Add(offset - 24*time.Hour)
+				schedule := FlashcardSchedule{Stage: 1, EaseFactor: 2.5, IntervalDays: 1, Repetitions: 1, NextReviewAt: now.Add(offset), LastReviewedAt: reviewedAt}
+				entry := &ReviewLog{FlashcardID: id, UserID: userID, Grade: learning.ReviewGrade_REVIEW_GRADE_GOOD, ReviewedAt: reviewedAt}
+				if err := s.RecordReview(ctx, schedule, entry); err != nil {
+					t.Fatalf("RecordReview: %v", err)
+				}
+			}
+			// Keep the new cards of later materials due later, even at millisecond precision
+			time.Sleep(5 * time.Millisecond)
+		}
+		material([]string{"a1"}, map[string]time.Duration{"a2": -2 * time.Hour, "a3": -time.Hour})
+		material([]string{"b2"}, map[string]time.Duration{"b1": -5 * time.Hour})
+		material([]string{"c1"}, map[string]time.Duration{"c2": 48 * time.Hour})
+		material(nil, nil, "d", "d")
+
+		// Materials take turns, each giving its earliest due card in each round,
+		// and the material with the earliest due card goes first. Only one card
+		// of a note is due, and cards due after DueBefore wait.
+		tests := []struct {
+			name      string
+			filter    ReviewQueueFilter
+			want      []string
+			wantTotal int32
+		}{
+			{
+				name:      "all due",
+				filter:    ReviewQueueFilter{Limit: 20, NewLimit: 20, ReviewLimit: 20},
+				want:      []string{"b1", "a2", "c1", "d", "b2", "a3", "a1"},
+				wantTotal: 7,
+			},
+			{
+				name:      "limit",
+				filter:    ReviewQueueFilter{Limit: 3, NewLimit: 20, ReviewLimit: 20},
+				want:      []string{"b1", "a2", "c1"},
+				wantTotal: 7,
+			},
+			{
+				name:      "new and review limits",
+				filter:    ReviewQueueFilter{Limit: 20, NewLimit: 2, ReviewLimit: 2},
+				want:      []string{"b1", "a2", "c1", "d"},
+				wantTotal: 7,
+			},
+			{
+				name:      "no new cards",
+				filter:    ReviewQueueFilter{Limit: 20, NewLimit: 0, ReviewLimit: 20},
+				want:      []string{"b1", "a2", "a3"},
+				wantTotal: 7,
+			},
+			{
+				name:      "no reviews",
+				filter:    ReviewQueueFilter{Limit: 20, NewLimit: 20, ReviewLimit: 0},
+				want:      []string{"c1", "d", "b2", "a1"},
+				wantTotal: 7,
+			},
+			{
+				name:      "due before",
+				filter:    ReviewQueueFilter{Limit: 20, NewLimit: 20, ReviewLimit: 20, DueBefore: now.Add(-90 * time.Minute)},
+				want:      []string{"b1", "a2"},
+				wantTotal: 2,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				filter := tt.filter
+				if filter.DueBefore.IsZero() {
+					filter.DueBefore = now.Add(time.Hour)
+				}
+				cards, total, err := s.GetReviewQueue(ctx, userID, filter)
+				if err != nil {
+					t.Fatalf("GetReviewQueue: %v", err)
+				}
+				var got []string
+				for _, card := range cards {
+					got = append(got, names[card.Id])
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("queue = %v, want %v", got, tt.want)
+				}
+				if total != tt.wantTotal {
+					t.Errorf("total due = %d, want %d", total, tt.wantTotal)
+				}
+			})
+		}
+	})
+}
+
+func TestStudySettingsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		settings, err := s.GetStudySettings(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetStudySettings: %v", err)
+		}
+		if settings != nil {
+			t.Errorf("GetStudySettings before saving = %v, want nil", settings)
+		}
+
+		want := &learning.StudySettings{
+			NewCardsPerDay: 5, MaxReviewsPerDay: 50, DayRolloverHour: 3, Timezone: "Asia/Kolkata",
+			LeechThreshold: 4, LeechAction: learning.LeechAction_LEECH_ACTION_SUSPEND,
+		}
+		for range 2 {
+			if err := s.UpdateStudySettings(ctx, userID, want); err != nil {
+				t.Fatalf("UpdateStudySettings: %v", err)
+			}
+		}
+		got, err := s.GetStudySettings(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetStudySettings: %v", err)
+		}
+		if !proto.Equal(got, want) {
+			t.Errorf("GetStudySettings = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestMaterialChunks(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		materialID, _ := newTestMaterial(t, s, userID)
+		if err := s.CreateMaterialChunks(ctx, materialID, []string{"one", "two", "three"}); err != nil {
+			t.Fatalf("CreateMaterialChunks: %v", err)
+		}
+
+		cards := []*learning.Flashcard{{Question: "Q1", Answer: "A1"}, {Question: "Q2", Answer: "A2"}}
+		for _, want := range []int32{2, 0} {
+			n, err := s.SaveChunkFlashcards(ctx, materialID, 0, cards)
+			if err != nil {
+				t.Fatalf("SaveChunkFlashcards: %v", err)
+			}
+			if n != want {
+				t.Errorf("SaveChunkFlashcards = %d, want %d", n, want)
+			}
+		}
+		if err := s.FailMaterialChunk(ctx, materialID, 1, "model error"); err != nil {
+			t.Fatalf("FailMaterialChunk: %v", err)
+		}
+
+		chunks, err := s.GetMaterialChunks(ctx, userID, materialID)
+		if err != nil {
+			t.Fatalf("GetMaterialChunks: %v", err)
+		}
+		want := []MaterialChunk{
+			{Index: 0, Content: "one", Status: ChunkDone, FlashcardsCreated: 2},
+			{Index: 1, Content: "two", Status: ChunkFailed, Error: "model error"},
+			{Index: 2, Content: "three", Status: ChunkPending},
+		}
+		if len(chunks) != len(want) {
+			t.Fatalf("GetMaterialChunks returned %d chunks, want %d", len(chunks), len(want))
+		}
+		for i := range want {
+			if *chunks[i] != want[i] {
+				t.Errorf("chunk %d = %+v, want %+v", i, *chunks[i], want[i])
+			}
+		}
+
+		due, err := s.GetDueFlashcards(ctx, userID, materialID)
+		if err != nil {
+			t.Fatalf("GetDueFlashcards: %v", err)
+		}
+		if len(due) != 2 {
+			t.Errorf("material has %d cards, want 2", len(due))
+		}
+	})
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		err := s.WithTx(ctx, func(tx Store) error {
+			materialID, err := tx.CreateMaterial(ctx, userID, "TEXT", "Content", "Title")
+			if err != nil {
+				return err
+			}
+			if _, err := tx.CreateFlashcards(ctx, materialID, []*learning.Flashcard{{Question: "Q", Answer: "A"}}); err != nil {
+				return err
+			}
+			// A nested transaction that succeeds is still rolled back with the outer one
+			if err := tx.WithTx(ctx, func(tx Store) error {
+				_, err := tx.CreateTag(ctx, userID, "rolled back")
+				return err
+			}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithTx: got %v, want %v", err, errAbort)
+		}
+
+		materials, total, err := s.GetDueMaterials(ctx, userID, 1, 10)
+		if err != nil {
+			t.Fatalf("GetDueMaterials: %v", err)
+		}
+		if total != 0 || len(materials) != 0 {
+			t.Errorf("GetDueMaterials = %d materials, want none", total)
+		}
+		tags, err := s.GetTags(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetTags: %v", err)
+		}
+		if len(tags) != 0 {
+			t.Errorf("GetTags = %v, want none", tags)
+		}
+	})
+}
+
+func TestUndoRestoresBuriedSiblings(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		noteID := "22222222-2222-4222-8222-" + randomHex(t)[:12]
+		materialID, ids := newTestMaterial(t, s, userID,
+			&learning.Flashcard{Question: "Q1", Answer: "A1", NoteId: noteID},
+			&learning.Flashcard{Question: "Q2", Answer: "A2", NoteId: noteID},
+			&learning.Flashcard{Question: "Q3", Answer: "A3", NoteId: noteID})
+		reviewed, sibling, buriedLonger := ids[0], ids[1], ids[2]
+
+		now := time.Now()
+		longer := now.Add(10 * 24 * time.Hour)
+		if err := s.SetFlashcardBuriedUntil(ctx, userID, buriedLonger, &longer); err != nil {
+			t.Fatalf("SetFlashcardBuriedUntil: %v", err)
+		}
+
+		tomorrow := now.Add(24 * time.Hour)
+		buried, err := s.BuryFlashcardSiblings(ctx, userID, reviewed, tomorrow)
+		if err != nil {
+			t.Fatalf("BuryFlashcardSiblings: %v", err)
+		}
+		if len(buried) != 1 || buried[0].FlashcardID != sibling || buried[0].PreviousBuriedUntil != nil {
+			t.Fatalf("BuryFlashcardSiblings = %+v, want only %s, previously unburied", buried, sibling)
+		}
+
+		before, err := s.GetFlashcard(ctx, userID, reviewed)
+		if err != nil {
+			t.Fatalf("GetFlashcard: %v", err)
+		}
+		schedule := FlashcardSchedule{Stage: 1, EaseFactor: 2.5, IntervalDays: 1, Repetitions: 1, NextReviewAt: tomorrow, LastReviewedAt: now}
+		entry := &ReviewLog{
+			FlashcardID: reviewed, UserID: userID, Grade: learning.ReviewGrade_REVIEW_GRADE_GOOD, Scheduler: "fixed",
+			PreviousStage: before.Stage, NextStage: 1, PreviousDueAt: before.NextReviewAt.AsTime(), NextDueAt: tomorrow,
+			ReviewedAt: now, PreviousEaseFactor: before.EaseFactor, BuriedSiblings: buried,
+		}
+		if err := s.RecordReview(ctx, schedule, entry); err != nil {
+			t.Fatalf("RecordReview: %v", err)
+		}
+		due, err := s.GetDueFlashcards(ctx, userID, materialID)
+		if err != nil {
+			t.Fatalf("GetDueFlashcards: %v", err)
+		}
+		if len(due) != 1 || due[0].Id != reviewed {
+			t.Errorf("%d cards active after the review, want only the reviewed one", len(due))
+		}
+
+		if _, _, err := s.UndoLastReview(ctx, userID, ""); err != nil {
+			t.Fatalf("UndoLastReview: %v", err)
+		}
+		due, err = s.GetDueFlashcards(ctx, userID, materialID)
+		if err != nil {
+			t.Fatalf("GetDueFlashcards: %v", err)
+		}
+		var got []string
+		for _, card := range due {
+			got = append(got, card.Id)
+		}
+		slices.Sort(got)
+		want := []string{reviewed, sibling}
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("active cards after undo = %v, want %v; the card buried for longer stays buried", got, want)
+		}
+	})
+}
+
+func TestIngestionJobLease(t *testing.T) {
+	ctx := context.Background()
+	expired := time.Now().Add(-time.Minute)
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		job := &IngestionJob{UserID: userID, Type: "TEXT", Content: "Some content", ImageData: "aW1hZ2U="}
+		if err := s.CreateIngestionJob(ctx, job); err != nil {
+			t.Fatalf("CreateIngestionJob: %v", err)
+		}
+
+		// The first claim's lease runs out, so the job is claimed again
+		stale, err := s.ClaimIngestionJob(ctx, expired, 5)
+		if err != nil || stale == nil {
+			t.Fatalf("ClaimIngestionJob: got %v, %v; want the job", stale, err)
+		}
+		current, err := s.ClaimIngestionJob(ctx, time.Now().Add(time.Minute), 5)
+		if err != nil || current == nil || current.Attempts != stale.Attempts+1 {
+			t.Fatalf("ClaimIngestionJob: got %+v, %v; want the job claimed again", current, err)
+		}
+
+		stale.Stage = learning.IngestionStage_INGESTION_STAGE_GENERATING
+		if err := s.UpdateIngestionJob(ctx, stale); !errors.Is(err, ErrIngestionLeaseLost) {
+			t.Errorf("UpdateIngestionJob with the first claim: got %v, want %v", err, ErrIngestionLeaseLost)
+		}
+		current.Stage = learning.IngestionStage_INGESTION_STAGE_GENERATING
+		if err := s.UpdateIngestionJob(ctx, current); err != nil {
+			t.Fatalf("UpdateIngestionJob: %v", err)
+		}
+		if content, imageData := jobInput(t, s, job.ID); content != job.Content || imageData != job.ImageData {
+			t.Errorf("unfinished job's input is %q, %q; want it kept", content, imageData)
+		}
+
+		current.Stage, current.Title = learning.IngestionStage_INGESTION_STAGE_SUCCEEDED, "Done"
+		if err := s.UpdateIngestionJob(ctx, current); err != nil {
+			t.Fatalf("UpdateIngestionJob: %v", err)
+		}
+		if content, imageData := jobInput(t, s, job.ID); content != "" || imageData != "" {
+			t.Errorf("finished job's input is %q, %q; want it cleared", content, imageData)
+		}
+		current.Title = "Changed"
+		if err := s.UpdateIngestionJob(ctx, current); !errors.Is(err, ErrIngestionLeaseLost) {
+			t.Errorf("UpdateIngestionJob of a finished job: got %v, want %v", err, ErrIngestionLeaseLost)
+		}
+		got, err := s.GetIngestionJob(ctx, userID, job.ID)
+		if err != nil {
+			t.Fatalf("GetIngestionJob: %v", err)
+		}
+		if got.Stage != learning.IngestionStage_INGESTION_STAGE_SUCCEEDED || got.Title != "Done" {
+			t.Errorf("job ended %s titled %q, want it succeeded titled %q", got.Stage, got.Title, "Done")
+		}
+
+		// A job interrupted too often is given up on, and its input dropped
+		abandoned := &IngestionJob{UserID: userID, Type: "TEXT", Content: "More content"}
+		if err := s.CreateIngestionJob(ctx, abandoned); err != nil {
+			t.Fatalf("CreateIngestionJob: %v", err)
+		}
+		if claimed, err := s.ClaimIngestionJob(ctx, expired, 1); err != nil || claimed == nil {
+			t.Fatalf("ClaimIngestionJob: got %v, %v; want the job", claimed, err)
+		}
+		if claimed, err := s.ClaimIngestionJob(ctx, expired, 1); err != nil || claimed != nil {
+			t.Fatalf("ClaimIngestionJob: got %v, %v; want no job", claimed, err)
+		}
+		if got, err := s.GetIngestionJob(ctx, userID, abandoned.ID); err != nil || got.Stage != learning.IngestionStage_INGESTION_STAGE_FAILED {
+			t.Errorf("GetIngestionJob: got %+v, %v; want it failed", got, err)
+		}
+		if content, _ := jobInput(t, s, abandoned.ID); content != "" {
+			t.Errorf("abandoned job's content is %q, want it cleared", content)
+		}
+	})
+}
+
+// jobInput returns the content and image data a job was queued with, which the
+// Store interface doesn't expose outside of ClaimIngestionJob.
+func jobInput(t *testing.T, s Store, id string) (content, imageData string) {
+	t.Helper()
+	ctx := context.Background()
+	var err error
+	switch s := s.(type) {
+	case *MemoryStore:
+		defer s.lock()()
+		j := s.state.data.jobs[id]
+		return j.Content, j.ImageData
+	case *SQLiteStore:
+		err = s.db.QueryRowContext(ctx, `SELECT content, image_data FROM ingestion_jobs WHERE id = $1`, id).Scan(&content, &imageData)
+	case *PostgresStore:
+		err = s.db.QueryRow(ctx, `SELECT content, image_data FROM ingestion_jobs WHERE id = $1`, id).Scan(&content, &imageData)
+	default:
+		t.Fatalf("no way to read job input from %T", s)
+	}
+	if err != nil {
+		t.Fatalf("read job input: %v", err)
+	}
+	return content, imageData
+}
+
+func TestConcurrentUndos(t *testing.T) {
+	ctx := context.Background()
+	forEachStore(t, func(t *testing.T, s Store) {
+		userID := newTestUser(t, s)
+		_, ids := newTestMaterial(t, s, userID, &learning.Flashcard{Question: "Q1", Answer: "A1"}, &learning.Flashcard{Question: "Q2", Answer: "A2"})
+		now := time.Now()
+		for i, id := range ids {
+			reviewedAt := now.Add(time.Duration(i-len(ids)) * time.Minute)
+			next := reviewedAt.Add(24 * time.Hour)
+			schedule := FlashcardSchedule{Stage: 1, IntervalDays: 1, NextReviewAt: next, LastReviewedAt: reviewedAt}
+			entry := &ReviewLog{
+				FlashcardID: id, UserID: userID, Grade: learning.ReviewGrade_REVIEW_GRADE_GOOD, Scheduler: "fixed",
+				NextStage: 1, NextDueAt: next, ReviewedAt: reviewedAt,
+			}
+			if err := s.RecordReview(ctx, schedule, entry); err != nil {
+				t.Fatalf("RecordReview: %v", err)
+			}
+		}
+
+		// Each undo takes the latest review not yet undone, even while another is in progress
+		var wg sync.WaitGroup
+		undone := make([]*ReviewLog, len(ids))
+		errs := make([]error, len(ids))
+		for i := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				undone[i], _, errs[i] = s.UndoLastReview(ctx, userID, "")
+			}()
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("UndoLastReview %d: %v", i+1, err)
+			}
+		}
+		if undone[0].ID == undone[1].ID {
+			t.Errorf("both undos reverted review %s, want one each", undone[0].ID)
+		}
+		if _, _, err := s.UndoLastReview(ctx, userID, ""); !errors.Is(err, ErrNothingToUndo) {
+			t.Errorf("UndoLastReview after undoing every review: got %v, want %v", err, ErrNothingToUndo)
+		}
+	})
+}
@@ -0,0 +1,1320 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/amityadav/landr/pkg/pb/auth"
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MemoryStore keeps everything in memory, for tests that exercise the core
+// without a database. It behaves like PostgresStore, including its access
+// errors. A transaction holds the store's lock until it finishes, so other
+// callers wait for it the way they would for a SQLite write.
+type MemoryStore struct {
+	state *memoryState
+	tx    bool // Set for the store handed to a WithTx callback, which already holds the lock
+}
+
+type memoryState struct {
+	mu   sync.Mutex
+	data *memoryData
+}
+
+// memoryData is every table. Rows are stored by value and their slices are
+// never modified in place, so copying the maps snapshots the data.
+type memoryData struct {
+	seq           int64 // Orders rows by creation, like created_at
+	users         map[string]memoryUser
+	materials     map[string]memoryMaterial
+	flashcards    map[string]memoryFlashcard
+	tags          map[string]memoryTag
+	materialTags  map[memoryMaterialTag]bool
+	reviewLogs    map[string]memoryReviewLog
+	fsrsParams    map[string]memoryFSRSParams
+	settings      map[string]memorySettings
+	quizzes       map[string]memoryQuiz
+	quizQuestions map[string][]QuizQuestion // By quiz ID, in order
+	quizAttempts  map[string]QuizAttempt
+	jobs          map[string]memoryJob
+	chunks        map[memoryChunkKey]MaterialChunk
+}
+
+type memoryUser struct {
+	id, email, name, googleID, picture string
+}
+
+type memoryMaterial struct {
+	id, userID, matType, content, title, summary string
+	deleted                                      bool
+	seq                                          int64
+}
+
+type memoryFlashcard struct {
+	id, materialID, question, answer string
+	stage                            int32
+	nextReviewAt                     time.Time
+	easeFactor                       float64
+	intervalDays, repetitions        int32
+	stability, difficulty            float64
+	lastReviewedAt                   *time.Time
+	suspended                        bool
+	buriedUntil                      *time.Time
+	flag, lapses                     int32
+	isLeech                          bool
+	cardType, noteID                 string
+	clozeIndex                       int32
+}
+
+type memoryTag struct {
+	id, userID, name string
+}
+
+type memoryMaterialTag struct {
+	materialID, tagID string
+}
+
+type memoryReviewLog struct {
+	ReviewLog
+	reverted bool
+}
+
+type memoryFSRSParams struct {
+	weights     []float64
+	reviewCount int32
+	optimizedAt time.Time
+}
+
+type memorySettings struct {
+	newCardsPerDay, maxReviewsPerDay, dayRolloverHour int32
+	timezone                                          string
+	leechThreshold                                    int32
+	leechAction                                       learning.LeechAction
+}
+
+type memoryQuiz struct {
+	userID, materialID, tag string
+}
+
+type memoryJob struct {
+	IngestionJob
+	seq int64
+}
+
+type memoryChunkKey struct {
+	materialID string
+	index      int32
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{state: &memoryState{data: &memoryData{
+		users:         make(map[string]memoryUser),
+		materials:     make(map[string]memoryMaterial),
+		flashcards:    make(map[string]memoryFlashcard),
+		tags:          make(map[string]memoryTag),
+		materialTags:  make(map[memoryMaterialTag]bool),
+		reviewLogs:    make(map[string]memoryReviewLog),
+		fsrsParams:    make(map[string]memoryFSRSParams),
+		settings:      make(map[string]memorySettings),
+		quizzes:       make(map[string]memoryQuiz),
+		quizQuestions: make(map[string][]QuizQuestion),
+		quizAttempts:  make(map[string]QuizAttempt),
+		jobs:          make(map[string]memoryJob),
+		chunks:        make(map[memoryChunkKey]MaterialChunk),
+	}}}
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		seq:           d.seq,
+		users:         maps.Clone(d.users),
+		materials:     maps.Clone(d.materials),
+		flashcards:    maps.Clone(d.flashcards),
+		tags:          maps.Clone(d.tags),
+		materialTags:  maps.Clone(d.materialTags),
+		reviewLogs:    maps.Clone(d.reviewLogs),
+		fsrsParams:    maps.Clone(d.fsrsParams),
+		settings:      maps.Clone(d.settings),
+		quizzes:       maps.Clone(d.quizzes),
+		quizQuestions: maps.Clone(d.quizQuestions),
+		quizAttempts:  maps.Clone(d.quizAttempts),
+		jobs:          maps.Clone(d.jobs),
+		chunks:        maps.Clone(d.chunks),
+	}
+}
+
+// lock takes the store's lock, unless this store is inside a transaction
+// that holds it, and returns the function that releases it.
+func (s *MemoryStore) lock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.state.mu.Lock()
+	return s.state.mu.Unlock
+}
+
+func (s *MemoryStore) Close() {}
+
+// WithTx calls fn with a store whose writes are kept if fn returns nil and
+// undone otherwise. A nested WithTx undoes only its own writes.
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	defer s.lock()()
+	saved := s.state.data.clone()
+	if err := fn(&MemoryStore{state: s.state, tx: true}); err != nil {
+		s.state.data = saved
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStore) CreateUser(ctx context.Context, email, name, googleID, picture string) (*auth.UserProfile, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	user := memoryUser{id: NewUUID(), email: email, name: name, googleID: googleID, picture: picture}
+	for _, u := range d.users {
+		if u.googleID == googleID {
+			user = u
+			user.name, user.picture = name, picture
+		}
+	}
+	for _, u := range d.users {
+		if u.email == email && u.id != user.id {
+			return nil, fmt.Errorf("failed to create/update user: email %s is taken", email)
+		}
+	}
+	d.users[user.id] = user
+	return user.proto(), nil
+}
+
+func (s *MemoryStore) GetUserByGoogleID(ctx context.Context, googleID string) (*auth.UserProfile, error) {
+	defer s.lock()()
+	for _, u := range s.state.data.users {
+		if u.googleID == googleID {
+			return u.proto(), nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get user: %w", ErrNotFound)
+}
+
+func (u memoryUser) proto() *auth.UserProfile {
+	return &auth.UserProfile{Id: u.id, Email: u.email, Name: u.name, Picture: u.picture}
+}
+
+func (s *MemoryStore) CreateMaterial(ctx context.Context, userID, matType, content, title string) (string, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	if _, ok := d.users[userID]; !ok {
+		return "", fmt.Errorf("failed to insert material: user %s: %w", userID, ErrNotFound)
+	}
+	d.seq++
+	m := memoryMaterial{id: NewUUID(), userID: userID, matType: matType, content: content, title: title, seq: d.seq}
+	d.materials[m.id] = m
+	return m.id, nil
+}
+
+func (s *MemoryStore) SoftDeleteMaterial(ctx context.Context, userID, materialID string) error {
+	defer s.lock()()
+	d := s.state.data
+
+	m, ok := d.materials[materialID]
+	if !ok || m.userID != userID || m.deleted {
+		return d.materialAccessError(userID, materialID)
+	}
+	m.deleted = true
+	d.materials[materialID] = m
+	return nil
+}
+
+func (s *MemoryStore) CreateTag(ctx context.Context, userID, name string) (string, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	for _, t := range d.tags {
+		if t.userID == userID && t.name == name {
+			return t.id, nil
+		}
+	}
+	t := memoryTag{id: NewUUID(), userID: userID, name: name}
+	d.tags[t.id] = t
+	return t.id, nil
+}
+
+func (s *MemoryStore) GetTags(ctx context.Context, userID string) ([]string, error) {
+	defer s.lock()()
+	var names []string
+	for _, t := range s.state.data.tags {
+		if t.userID == userID {
+			names = append(names, t.name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *MemoryStore) AddMaterialTags(ctx context.Context, materialID string, tagIDs []string) error {
+	defer s.lock()()
+	d := s.state.data
+
+	if _, ok := d.materials[materialID]; !ok {
+		return fmt.Errorf("failed to link tag: material %s: %w", materialID, ErrNotFound)
+	}
+	for _, id := range tagIDs {
+		if _, ok := d.tags[id]; !ok {
+			return fmt.Errorf("failed to link tag: tag %s: %w", id, ErrNotFound)
+		}
+	}
+	for _, id := range tagIDs {
+		d.materialTags[memoryMaterialTag{materialID: materialID, tagID: id}] = true
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetMaterialTags(ctx context.Context, materialID string) ([]string, error) {
+	defer s.lock()()
+	return s.state.data.tagsOf(materialID), nil
+}
+
+// tagsOf returns the names of a material's tags in order.
+func (d *memoryData) tagsOf(materialID string) []string {
+	names := []string{}
+	for link := range d.materialTags {
+		if link.materialID == materialID {
+			names = append(names, d.tags[link.tagID].name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateFlashcards adds cards to a material and returns their IDs in order.
+func (s *MemoryStore) CreateFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	defer s.lock()()
+	return s.state.data.insertFlashcards(materialID, cards)
+}
+
+func (d *memoryData) insertFlashcards(materialID string, cards []*learning.Flashcard) ([]string, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+	if _, ok := d.materials[materialID]; !ok {
+		return nil, fmt.Errorf("material %s: %w", materialID, ErrNotFound)
+	}
+
+	now := time.Now()
+	ids := make([]string, len(cards))
+	for i, card := range cards {
+		ids[i] = NewUUID()
+		d.flashcards[ids[i]] = memoryFlashcard{
+			id: ids[i], materialID: materialID, question: card.Question, answer: card.Answer, nextReviewAt: now,
+			easeFactor: 2.5, cardType: cardTypeToDB(card.CardType), noteID: card.NoteId, clozeIndex: card.ClozeIndex,
+		}
+	}
+	return ids, nil
+}
+
+// AddFlashcards adds cards to a material the user owns and returns their IDs in order.
+func (s *MemoryStore) AddFlashcards(ctx context.Context, userID, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	if err := d.checkMaterialOwner(userID, materialID); err != nil {
+		return nil, err
+	}
+	return d.insertFlashcards(materialID, cards)
+}
+
+// DeleteFlashcards permanently deletes the given cards, along with their review
+// history. Either every card is deleted or, if any of them is missing or owned
+// by someone else, none are.
+func (s *MemoryStore) DeleteFlashcards(ctx context.Context, userID string, ids []string) (int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	deleted := make(map[string]bool)
+	for _, id := range ids {
+		if _, _, ok := d.ownedFlashcard(userID, id); !ok {
+			return 0, d.flashcardAccessError(userID, id)
+		}
+		deleted[id] = true
+	}
+
+	d.deleteFlashcards(deleted)
+	return int32(len(deleted)), nil
+}
+
+// deleteFlashcards removes cards with their review history, and drops them
+// from the quizzes that asked about them.
+func (d *memoryData) deleteFlashcards(deleted map[string]bool) {
+	for id := range deleted {
+		delete(d.flashcards, id)
+	}
+	for id, l := range d.reviewLogs {
+		if deleted[l.FlashcardID] {
+			delete(d.reviewLogs, id)
+		}
+	}
+	for quizID, questions := range d.quizQuestions {
+		if slices.ContainsFunc(questions, func(q QuizQuestion) bool { return deleted[q.FlashcardID] }) {
+			questions = slices.Clone(questions)
+			for i := range questions {
+				if deleted[questions[i].FlashcardID] {
+					questions[i].FlashcardID = ""
+				}
+			}
+			d.quizQuestions[quizID] = questions
+		}
+	}
+}
+
+func (s *MemoryStore) GetFlashcard(ctx context.Context, userID, id string) (*learning.Flashcard, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	card, m, ok := d.ownedFlashcard(userID, id)
+	if !ok {
+		return nil, d.flashcardAccessError(userID, id)
+	}
+	return d.flashcardProto(card, m), nil
+}
+
+func (s *MemoryStore) GetDueFlashcards(ctx context.Context, userID, materialID string) ([]*learning.Flashcard, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	if err := d.checkMaterialOwner(userID, materialID); err != nil {
+		return nil, err
+	}
+	m := d.materials[materialID]
+	var cards []*learning.Flashcard
+	for _, card := range d.sortedFlashcards() {
+		if card.materialID == materialID && card.active() {
+			cards = append(cards, d.flashcardProto(card, m))
+		}
+	}
+	return cards, nil
+}
+
+func (s *MemoryStore) GetDueMaterials(ctx context.Context, userID string, page, pageSize int32) ([]*learning.MaterialSummary, int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	var materials []memoryMaterial
+	for _, m := range d.materials {
+		if m.userID == userID && !m.deleted {
+			materials = append(materials, m)
+		}
+	}
+	sort.Slice(materials, func(i, j int) bool { return materials[i].seq > materials[j].seq })
+
+	total := int32(len(materials))
+	start := min(max((page-1)*pageSize, 0), total)
+	end := min(start+max(pageSize, 0), total)
+
+	var summaries []*learning.MaterialSummary
+	for _, m := range materials[start:end] {
+		summary := &learning.MaterialSummary{Id: m.id, Title: m.title, Tags: d.tagsOf(m.id)}
+		for _, card := range d.flashcards {
+			if card.materialID == m.id && card.active() {
+				summary.DueCount++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, total, nil
+}
+
+// GetDueFlashcardsCount returns how many cards due before dueBefore have never
+// been reviewed (new) and how many are due for another review.
+func (s *MemoryStore) GetDueFlashcardsCount(ctx context.Context, userID string, dueBefore time.Time) (int32, int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	newNotes, reviewNotes := make(map[string]bool), make(map[string]bool)
+	for _, card := range d.flashcards {
+		m := d.materials[card.materialID]
+		if m.userID != userID || m.deleted || !card.active() || !card.nextReviewAt.Before(dueBefore) {
+			continue
+		}
+		if card.lastReviewedAt == nil {
+			newNotes[card.note()] = true
+		} else {
+			reviewNotes[card.note()] = true
+		}
+	}
+	return int32(len(newNotes)), int32(len(reviewNotes)), nil
+}
+
+// GetReviewQueue returns the user's due cards across all materials. Cards are
+// interleaved round-robin between materials (materials with the oldest due card
+// first) and ordered by due date within a material. Sibling cards of a note are
+// never queued together.
+func (s *MemoryStore) GetReviewQueue(ctx context.Context, userID string, filter ReviewQueueFilter) ([]*learning.Flashcard, int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	// Only the earliest due card of each note, so siblings never share a day
+	earliest := make(map[string]memoryFlashcard)
+	for _, card := range d.flashcards {
+		m := d.materials[card.materialID]
+		if m.userID != userID || m.deleted || !card.active() || !card.nextReviewAt.Before(filter.DueBefore) {
+			continue
+		}
+		if len(filter.MaterialIDs) > 0 && !slices.Contains(filter.MaterialIDs, m.id) {
+			continue
+		}
+		if len(filter.Tags) > 0 && !slices.ContainsFunc(d.tagsOf(m.id), func(t string) bool { return slices.Contains(filter.Tags, t) }) {
+			continue
+		}
+		if e, ok := earliest[card.note()]; !ok || dueBefore(card, e) {
+			earliest[card.note()] = card
+		}
+	}
+
+	type queued struct {
+		card      memoryFlashcard
+		rank      int
+		firstDue  time.Time
+		isNew     bool
+		kindRank  int
+		materialM memoryMaterial
+	}
+	byMaterial := make(map[string][]memoryFlashcard)
+	for _, card := range earliest {
+		byMaterial[card.materialID] = append(byMaterial[card.materialID], card)
+	}
+	var due []*queued
+	for _, cards := range byMaterial {
+		sort.Slice(cards, func(i, j int) bool { return dueBefore(cards[i], cards[j]) })
+		for i, card := range cards {
+			due = append(due, &queued{card: card, rank: i + 1, firstDue: cards[0].nextReviewAt,
+				isNew: card.lastReviewedAt == nil, materialM: d.materials[card.materialID]})
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := due[i], due[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if !a.firstDue.Equal(b.firstDue) {
+			return a.firstDue.Before(b.firstDue)
+		}
+		if a.card.materialID != b.card.materialID {
+			return a.card.materialID < b.card.materialID
+		}
+		return a.card.id < b.card.id
+	})
+
+	newRank, reviewRank := 0, 0
+	var cards []*learning.Flashcard
+	for _, q := range due {
+		if q.isNew {
+			newRank++
+			q.kindRank = newRank
+		} else {
+			reviewRank++
+			q.kindRank = reviewRank
+		}
+		if (q.isNew && q.kindRank > int(filter.NewLimit)) || (!q.isNew && q.kindRank > int(filter.ReviewLimit)) {
+			continue
+		}
+		if len(cards) < int(filter.Limit) {
+			cards = append(cards, d.flashcardProto(q.card, q.materialM))
+		}
+	}
+	return cards, int32(len(due)), nil
+}
+
+// UpdateFlashcardContent rewrites a flashcard, and its cloze siblings which
+// share the same text. A rewritten card starts over with no lapses and is no
+// longer a leech. Editing a cloze card adds a sibling for every new cloze
+// number and deletes the siblings whose deletion was removed.
+func (s *MemoryStore) UpdateFlashcardContent(ctx context.Context, userID, id, question, answer string) error {
+	defer s.lock()()
+	d := s.state.data
+
+	target, _, ok := d.ownedFlashcard(userID, id)
+	if !ok {
+		return d.flashcardAccessError(userID, id)
+	}
+	isSibling := func(card memoryFlashcard) bool {
+		return card.id == id || (target.noteID != "" && card.noteID == target.noteID)
+	}
+
+	var remove []string
+	var add []*learning.Flashcard
+	if cardTypeFromDB(target.cardType) == learning.CardType_CARD_TYPE_CLOZE {
+		if target.noteID == "" {
+			target.noteID = NewUUID()
+		}
+		siblings := make(map[string]int32)
+		for _, card := range d.flashcards {
+			if isSibling(card) {
+				siblings[card.id] = card.clozeIndex
+			}
+		}
+		var err error
+		if remove, add, err = clozeChanges(siblings, target.noteID, question, answer); err != nil {
+			return err
+		}
+	}
+
+	for _, card := range d.flashcards {
+		m := d.materials[card.materialID]
+		if m.userID != userID || m.deleted || !isSibling(card) {
+			continue
+		}
+		card.question, card.answer, card.lapses, card.isLeech = question, answer, 0, false
+		card.noteID = target.noteID
+		d.flashcards[card.id] = card
+	}
+	deleted := make(map[string]bool)
+	for _, id := range remove {
+		deleted[id] = true
+	}
+	d.deleteFlashcards(deleted)
+	if _, err := d.insertFlashcards(target.materialID, add); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetFlashcardSuspended suspends or unsuspends a flashcard.
+func (s *MemoryStore) SetFlashcardSuspended(ctx context.Context, userID, id string, suspended bool) error {
+	return s.updateFlashcard(userID, id, func(card *memoryFlashcard) { card.suspended = suspended })
+}
+
+// SetFlashcardBuriedUntil hides a flashcard from reviews until the given time,
+// or unburies it when until is nil.
+func (s *MemoryStore) SetFlashcardBuriedUntil(ctx context.Context, userID, id string, until *time.Time) error {
+	return s.updateFlashcard(userID, id, func(card *memoryFlashcard) { card.buriedUntil = until })
+}
+
+// BuryFlashcardSiblings buries the other cards sharing a note with the given
+// card until the given time, unless they are already buried for longer, and
+// returns the cards it buried with when they were buried until before.
+func (s *MemoryStore) BuryFlashcardSiblings(ctx context.Context, userID, id string, until time.Time) ([]BuriedSibling, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	target, ok := d.flashcards[id]
+	if !ok || target.noteID == "" {
+		return nil, nil
+	}
+	var buried []BuriedSibling
+	for _, card := range d.flashcards {
+		if card.noteID != target.noteID || card.id == id || d.materials[card.materialID].userID != userID {
+			continue
+		}
+		if card.buriedUntil == nil || card.buriedUntil.Before(until) {
+			buried = append(buried, BuriedSibling{FlashcardID: card.id, PreviousBuriedUntil: card.buriedUntil})
+			card.buriedUntil = &until
+			d.flashcards[card.id] = card
+		}
+	}
+	return buried, nil
+}
+
+func (s *MemoryStore) SetFlashcardFlag(ctx context.Context, userID, id string, flag int32) error {
+	return s.updateFlashcard(userID, id, func(card *memoryFlashcard) { card.flag = flag })
+}
+
+// updateFlashcard applies update to a flashcard the user owns.
+func (s *MemoryStore) updateFlashcard(userID, id string, update func(card *memoryFlashcard)) error {
+	defer s.lock()()
+	d := s.state.data
+
+	card, _, ok := d.ownedFlashcard(userID, id)
+	if !ok {
+		return d.flashcardAccessError(userID, id)
+	}
+	update(&card)
+	d.flashcards[id] = card
+	return nil
+}
+
+// GetLeeches returns the user's leech cards, most lapsed first, including
+// ones that have been suspended.
+func (s *MemoryStore) GetLeeches(ctx context.Context, userID string) ([]*learning.Flashcard, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	var leeches []memoryFlashcard
+	for _, card := range d.flashcards {
+		m := d.materials[card.materialID]
+		if card.isLeech && m.userID == userID && !m.deleted {
+			leeches = append(leeches, card)
+		}
+	}
+	sort.Slice(leeches, func(i, j int) bool {
+		if leeches[i].lapses != leeches[j].lapses {
+			return leeches[i].lapses > leeches[j].lapses
+		}
+		return leeches[i].id < leeches[j].id
+	})
+
+	var cards []*learning.Flashcard
+	for _, card := range leeches {
+		cards = append(cards, d.flashcardProto(card, d.materials[card.materialID]))
+	}
+	return cards, nil
+}
+
+// RecordReview reschedules a flashcard and appends the review to the log.
+func (s *MemoryStore) RecordReview(ctx context.Context, schedule FlashcardSchedule, entry *ReviewLog) error {
+	defer s.lock()()
+	d := s.state.data
+
+	card, ok := d.flashcards[entry.FlashcardID]
+	if !ok || d.materials[card.materialID].userID != entry.UserID {
+		return d.flashcardAccessError(entry.UserID, entry.FlashcardID)
+	}
+	lastReviewedAt := schedule.LastReviewedAt
+	card.stage, card.easeFactor, card.intervalDays, card.repetitions = schedule.Stage, schedule.EaseFactor, schedule.IntervalDays, schedule.Repetitions
+	card.stability, card.difficulty = schedule.Stability, schedule.Difficulty
+	card.nextReviewAt, card.lastReviewedAt = schedule.NextReviewAt, &lastReviewedAt
+	card.lapses, card.isLeech, card.suspended = schedule.Lapses, schedule.IsLeech, schedule.Suspended
+	d.flashcards[card.id] = card
+
+	entry.ID = NewUUID()
+	l := memoryReviewLog{ReviewLog: *entry}
+	l.BuriedSiblings = slices.Clone(entry.BuriedSiblings)
+	d.reviewLogs[entry.ID] = l
+	return nil
+}
+
+func (s *MemoryStore) GetReviewLogs(ctx context.Context, userID string) ([]*ReviewLog, error) {
+	defer s.lock()()
+
+	var logs []*ReviewLog
+	for _, l := range s.state.data.reviewLogs {
+		if l.UserID == userID && !l.reverted {
+			entry := l.ReviewLog
+			logs = append(logs, &entry)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].FlashcardID != logs[j].FlashcardID {
+			return logs[i].FlashcardID < logs[j].FlashcardID
+		}
+		return logs[i].ReviewedAt.Before(logs[j].ReviewedAt)
+	})
+	return logs, nil
+}
+
+func (s *MemoryStore) GetReviewHistory(ctx context.Context, userID string, filter ReviewHistoryFilter) ([]*learning.ReviewLogEntry, int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	var matching []memoryReviewLog
+	for _, l := range d.reviewLogs {
+		m := d.materials[d.flashcards[l.FlashcardID].materialID]
+		switch {
+		case l.UserID != userID,
+			filter.MaterialID != "" && m.id != filter.MaterialID,
+			filter.Tag != "" && !slices.Contains(d.tagsOf(m.id), filter.Tag),
+			!filter.From.IsZero() && l.ReviewedAt.Before(filter.From),
+			!filter.To.IsZero() && !l.ReviewedAt.Before(filter.To):
+			continue
+		}
+		matching = append(matching, l)
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if !matching[i].ReviewedAt.Equal(matching[j].ReviewedAt) {
+			return matching[i].ReviewedAt.After(matching[j].ReviewedAt)
+		}
+		return matching[i].ID < matching[j].ID
+	})
+
+	total := int32(len(matching))
+	start := min(max((filter.Page-1)*filter.PageSize, 0), total)
+	end := min(start+max(filter.PageSize, 0), total)
+
+	var entries []*learning.ReviewLogEntry
+	for _, l := range matching[start:end] {
+		card := d.flashcards[l.FlashcardID]
+		m := d.materials[card.materialID]
+		e := &learning.ReviewLogEntry{
+			Id: l.ID, FlashcardId: l.FlashcardID, MaterialId: m.id, MaterialTitle: m.title, Question: card.question,
+			Grade: l.Grade, Scheduler: l.Scheduler, PreviousStage: l.PreviousStage, NextStage: l.NextStage,
+			ElapsedDays: l.ElapsedDays, ReviewedAt: timestamppb.New(l.ReviewedAt), SessionId: l.SessionID, Reverted: l.reverted,
+		}
+		if !l.PreviousDueAt.IsZero() {
+			e.PreviousDueAt = timestamppb.New(l.PreviousDueAt)
+		}
+		if !l.NextDueAt.IsZero() {
+			e.NextDueAt = timestamppb.New(l.NextDueAt)
+		}
+		if l.ClientReviewedAt != nil {
+			e.ClientReviewedAt = timestamppb.New(*l.ClientReviewedAt)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, nil
+}
+
+// UndoLastReview reverts the user's most recent review (optionally limited to
+// one session), restoring the card's scheduling state from before that review.
+// It returns the reverted review and how many reviews can still be undone.
+func (s *MemoryStore) UndoLastReview(ctx context.Context, userID, sessionID string) (*ReviewLog, int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	inScope := func(l memoryReviewLog) bool {
+		return l.UserID == userID && !l.reverted && (sessionID == "" || l.SessionID == sessionID)
+	}
+	var last *memoryReviewLog
+	for _, l := range d.reviewLogs {
+		if inScope(l) && (last == nil || l.ReviewedAt.After(last.ReviewedAt)) {
+			last = &l
+		}
+	}
+	if last == nil {
+		return nil, 0, ErrNothingToUndo
+	}
+
+	// Refuse to undo if the card was reviewed again since, e.g. from another device
+	card, ok := d.flashcards[last.FlashcardID]
+	superseded := !ok || card.stage != last.NextStage || !card.nextReviewAt.Equal(last.NextDueAt)
+	for _, l := range d.reviewLogs {
+		if l.FlashcardID == last.FlashcardID && l.ID != last.ID && !l.reverted && l.ReviewedAt.After(last.ReviewedAt) {
+			superseded = true
+		}
+	}
+	if superseded {
+		return nil, 0, ErrReviewSuperseded
+	}
+
+	card.stage, card.easeFactor, card.intervalDays, card.repetitions = last.PreviousStage, last.PreviousEaseFactor, last.PreviousIntervalDays, last.PreviousRepetitions
+	card.stability, card.difficulty, card.lastReviewedAt = last.PreviousStability, last.PreviousDifficulty, last.PreviousLastReviewedAt
+	card.lapses, card.isLeech, card.suspended = last.PreviousLapses, last.PreviousIsLeech, last.PreviousSuspended
+	if !last.PreviousDueAt.IsZero() {
+		card.nextReviewAt = last.PreviousDueAt
+	}
+	d.flashcards[card.id] = card
+	for _, b := range last.BuriedSiblings {
+		if sibling, ok := d.flashcards[b.FlashcardID]; ok {
+			sibling.buriedUntil = b.PreviousBuriedUntil
+			d.flashcards[sibling.id] = sibling
+		}
+	}
+
+	last.reverted = true
+	d.reviewLogs[last.ID] = *last
+
+	var remaining int32
+	for _, l := range d.reviewLogs {
+		if inScope(l) {
+			remaining++
+		}
+	}
+	reverted := last.ReviewLog
+	return &reverted, remaining, nil
+}
+
+// GetFSRSParams returns the user's fitted FSRS weights, or nil if none have been fitted yet.
+func (s *MemoryStore) GetFSRSParams(ctx context.Context, userID string) ([]float64, error) {
+	defer s.lock()()
+	params, ok := s.state.data.fsrsParams[userID]
+	if !ok {
+		return nil, nil
+	}
+	return slices.Clone(params.weights), nil
+}
+
+func (s *MemoryStore) SaveFSRSParams(ctx context.Context, userID string, weights []float64, reviewCount int32) error {
+	defer s.lock()()
+	s.state.data.fsrsParams[userID] = memoryFSRSParams{weights: slices.Clone(weights), reviewCount: reviewCount, optimizedAt: time.Now()}
+	return nil
+}
+
+// GetUsersNeedingOptimization returns users with at least minNewReviews reviews
+// logged since their FSRS weights were last fitted.
+func (s *MemoryStore) GetUsersNeedingOptimization(ctx context.Context, minNewReviews int) ([]string, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	counts := make(map[string]int)
+	for _, l := range d.reviewLogs {
+		params, fitted := d.fsrsParams[l.UserID]
+		if !l.reverted && (!fitted || l.ReviewedAt.After(params.optimizedAt)) {
+			counts[l.UserID]++
+		}
+	}
+	var userIDs []string
+	for userID, n := range counts {
+		if n >= minNewReviews {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// GetStudySettings returns the user's study settings, or nil if they never saved any.
+func (s *MemoryStore) GetStudySettings(ctx context.Context, userID string) (*learning.StudySettings, error) {
+	defer s.lock()()
+	settings, ok := s.state.data.settings[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &learning.StudySettings{
+		NewCardsPerDay: settings.newCardsPerDay, MaxReviewsPerDay: settings.maxReviewsPerDay,
+		DayRolloverHour: settings.dayRolloverHour, Timezone: settings.timezone,
+		LeechThreshold: settings.leechThreshold, LeechAction: settings.leechAction,
+	}, nil
+}
+
+func (s *MemoryStore) UpdateStudySettings(ctx context.Context, userID string, settings *learning.StudySettings) error {
+	defer s.lock()()
+	s.state.data.settings[userID] = memorySettings{
+		newCardsPerDay: settings.NewCardsPerDay, maxReviewsPerDay: settings.MaxReviewsPerDay,
+		dayRolloverHour: settings.DayRolloverHour, timezone: settings.Timezone,
+		leechThreshold: settings.LeechThreshold, leechAction: settings.LeechAction,
+	}
+	return nil
+}
+
+// GetStudyCountsSince returns how many new cards the user started and how many
+// reviews of previously seen cards they did since the given time.
+func (s *MemoryStore) GetStudyCountsSince(ctx context.Context, userID string, since time.Time) (int32, int32, error) {
+	defer s.lock()()
+
+	var newStudied, reviewsDone int32
+	for _, l := range s.state.data.reviewLogs {
+		if l.UserID != userID || l.reverted || l.ReviewedAt.Before(since) {
+			continue
+		}
+		if l.PreviousLastReviewedAt == nil {
+			newStudied++
+		} else {
+			reviewsDone++
+		}
+	}
+	return newStudied, reviewsDone, nil
+}
+
+// GetQuizCards returns up to limit of the user's basic, unsuspended cards in
+// random order, from one material or from all materials with a tag.
+func (s *MemoryStore) GetQuizCards(ctx context.Context, userID, materialID, tag string, limit int32) ([]*learning.Flashcard, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	var cards []*learning.Flashcard
+	for _, card := range d.flashcards {
+		m := d.materials[card.materialID]
+		switch {
+		case m.userID != userID || m.deleted,
+			card.cardType != "BASIC" || card.suspended,
+			materialID != "" && m.id != materialID,
+			tag != "" && !slices.Contains(d.tagsOf(m.id), tag):
+			continue
+		}
+		cards = append(cards, &learning.Flashcard{Id: card.id, Question: card.question, Answer: card.answer, MaterialId: m.id, MaterialTitle: m.title})
+	}
+	rand.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	cards = cards[:min(len(cards), max(int(limit), 0))]
+
+	// No cards may also mean the material is missing or someone else's
+	if len(cards) == 0 && materialID != "" {
+		if err := d.checkMaterialOwner(userID, materialID); err != nil {
+			return nil, err
+		}
+	}
+	return cards, nil
+}
+
+// CreateQuiz saves a quiz and its questions, filling in the question IDs.
+func (s *MemoryStore) CreateQuiz(ctx context.Context, userID, materialID, tag string, questions []*QuizQuestion) (string, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	quizID := NewUUID()
+	d.quizzes[quizID] = memoryQuiz{userID: userID, materialID: materialID, tag: tag}
+	saved := make([]QuizQuestion, len(questions))
+	for i, q := range questions {
+		q.ID = NewUUID()
+		saved[i] = *q
+		saved[i].Choices = slices.Clone(q.Choices)
+	}
+	d.quizQuestions[quizID] = saved
+	return quizID, nil
+}
+
+// GetQuizQuestions returns a quiz's questions in order, with the correct answers.
+func (s *MemoryStore) GetQuizQuestions(ctx context.Context, userID, quizID string) ([]*QuizQuestion, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	quiz, ok := d.quizzes[quizID]
+	if !ok {
+		return nil, fmt.Errorf("quiz %s: %w", quizID, ErrNotFound)
+	}
+	if quiz.userID != userID {
+		return nil, fmt.Errorf("quiz %s: %w", quizID, ErrPermissionDenied)
+	}
+	var questions []*QuizQuestion
+	for _, q := range d.quizQuestions[quizID] {
+		q.Choices = slices.Clone(q.Choices)
+		questions = append(questions, &q)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("quiz %s: %w", quizID, ErrNotFound)
+	}
+	return questions, nil
+}
+
+// SaveQuizAttempt stores a scored quiz attempt and its answers, filling in its ID.
+func (s *MemoryStore) SaveQuizAttempt(ctx context.Context, attempt *QuizAttempt) error {
+	defer s.lock()()
+	d := s.state.data
+
+	if _, ok := d.quizzes[attempt.QuizID]; !ok {
+		return fmt.Errorf("failed to insert quiz attempt: quiz %s: %w", attempt.QuizID, ErrNotFound)
+	}
+	attempt.ID = NewUUID()
+	saved := *attempt
+	saved.Answers = slices.Clone(attempt.Answers)
+	d.quizAttempts[attempt.ID] = saved
+	return nil
+}
+
+// CreateIngestionJob queues a job, filling in its ID.
+func (s *MemoryStore) CreateIngestionJob(ctx context.Context, job *IngestionJob) error {
+	defer s.lock()()
+	d := s.state.data
+
+	if job.MaterialID != "" {
+		for _, j := range d.jobs {
+			if j.MaterialID == job.MaterialID && !j.finished() {
+				return fmt.Errorf("material %s: %w", job.MaterialID, ErrIngestionInProgress)
+			}
+		}
+	}
+	job.ID = NewUUID()
+	job.Stage = learning.IngestionStage_INGESTION_STAGE_QUEUED
+	job.UpdatedAt = time.Now()
+	d.seq++
+	d.jobs[job.ID] = memoryJob{IngestionJob: IngestionJob{
+		ID: job.ID, UserID: job.UserID, Type: job.Type, Content: job.Content, ImageData: job.ImageData,
+		AllowCloze: job.AllowCloze, GenerateReverse: job.GenerateReverse, Stage: job.Stage,
+		MaterialID: job.MaterialID, UpdatedAt: job.UpdatedAt,
+	}, seq: d.seq}
+	return nil
+}
+
+// GetIngestionJob returns a job's progress and result, without its input.
+func (s *MemoryStore) GetIngestionJob(ctx context.Context, userID, id string) (*IngestionJob, error) {
+	defer s.lock()()
+
+	j, ok := s.state.data.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("ingestion job %s: %w", id, ErrNotFound)
+	}
+	if j.UserID != userID {
+		return nil, fmt.Errorf("ingestion job %s: %w", id, ErrPermissionDenied)
+	}
+	return &IngestionJob{
+		ID: j.ID, UserID: j.UserID, Type: j.Type, Stage: j.Stage, Chunk: j.Chunk, TotalChunks: j.TotalChunks,
+		Attempts: j.Attempts, MaterialID: j.MaterialID, Title: j.Title, Tags: slices.Clone(j.Tags),
+		FlashcardsCreated: j.FlashcardsCreated, FailedChunks: slices.Clone(j.FailedChunks), Error: j.Error, UpdatedAt: j.UpdatedAt,
+	}, nil
+}
+
+// ClaimIngestionJob hands the oldest unfinished job nobody holds a lease on to
+// the caller until leaseUntil, or returns nil if there is none. Jobs that
+// have already been claimed maxAttempts times are failed instead.
+func (s *MemoryStore) ClaimIngestionJob(ctx context.Context, leaseUntil time.Time, maxAttempts int32) (*IngestionJob, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	now := time.Now()
+	var oldest *memoryJob
+	for id, j := range d.jobs {
+		if j.finished() || (!j.LeaseExpiresAt.IsZero() && !j.LeaseExpiresAt.Before(now)) {
+			continue
+		}
+		if !j.LeaseExpiresAt.IsZero() && j.Attempts >= maxAttempts {
+			j.Stage, j.Error, j.UpdatedAt = learning.IngestionStage_INGESTION_STAGE_FAILED, "processing was interrupted too many times", now
+			j.Content, j.ImageData = "", ""
+			d.jobs[id] = j
+			continue
+		}
+		if oldest == nil || j.seq < oldest.seq {
+			oldest = &j
+		}
+	}
+	if oldest == nil {
+		return nil, nil
+	}
+
+	oldest.Attempts++
+	oldest.LeaseExpiresAt, oldest.UpdatedAt = leaseUntil, now
+	d.jobs[oldest.ID] = *oldest
+	log.Printf("[Store.ClaimIngestionJob] Claimed job %s (attempt %d)", oldest.ID, oldest.Attempts)
+
+	return &IngestionJob{
+		ID: oldest.ID, UserID: oldest.UserID, Type: oldest.Type, Content: oldest.Content, ImageData: oldest.ImageData,
+		AllowCloze: oldest.AllowCloze, GenerateReverse: oldest.GenerateReverse, MaterialID: oldest.MaterialID,
+		Stage: oldest.Stage, Attempts: oldest.Attempts, LeaseExpiresAt: leaseUntil, UpdatedAt: now,
+	}, nil
+}
+
+// UpdateIngestionJob saves a job's progress, result and lease, as long as the
+// job is unfinished and hasn't been claimed again since job was claimed. A
+// finished job's input is dropped, since it is never processed again.
+func (s *MemoryStore) UpdateIngestionJob(ctx context.Context, job *IngestionJob) error {
+	defer s.lock()()
+	d := s.state.data
+
+	j, ok := d.jobs[job.ID]
+	if !ok || j.Attempts != job.Attempts || j.finished() {
+		return fmt.Errorf("ingestion job %s: %w", job.ID, ErrIngestionLeaseLost)
+	}
+	if job.MaterialID != "" {
+		if _, ok := d.materials[job.MaterialID]; !ok {
+			return fmt.Errorf("failed to update ingestion job: material %s: %w", job.MaterialID, ErrNotFound)
+		}
+	}
+	job.UpdatedAt = time.Now()
+	j.Stage, j.Chunk, j.TotalChunks, j.LeaseExpiresAt = job.Stage, job.Chunk, job.TotalChunks, job.LeaseExpiresAt
+	j.MaterialID, j.Title, j.Tags = job.MaterialID, job.Title, slices.Clone(job.Tags)
+	j.FlashcardsCreated, j.FailedChunks, j.Error, j.UpdatedAt = job.FlashcardsCreated, slices.Clone(job.FailedChunks), job.Error, job.UpdatedAt
+	if job.Finished() {
+		j.Content, j.ImageData = "", ""
+	}
+	d.jobs[job.ID] = j
+	return nil
+}
+
+func (j memoryJob) finished() bool {
+	return j.IngestionJob.Finished()
+}
+
+func (s *MemoryStore) GetMaterialContent(ctx context.Context, userID, materialID string) (string, string, string, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	if err := d.checkMaterialOwner(userID, materialID); err != nil {
+		return "", "", "", err
+	}
+	m := d.materials[materialID]
+	return m.content, m.summary, m.title, nil
+}
+
+func (s *MemoryStore) UpdateMaterialSummary(ctx context.Context, userID, materialID, summary string) error {
+	return s.updateMaterial(userID, materialID, func(m *memoryMaterial) { m.summary = summary })
+}
+
+// UpdateMaterialTitle renames a material the user owns.
+func (s *MemoryStore) UpdateMaterialTitle(ctx context.Context, userID, materialID, title string) error {
+	return s.updateMaterial(userID, materialID, func(m *memoryMaterial) { m.title = title })
+}
+
+// updateMaterial applies update to a material the user owns, even a deleted one.
+func (s *MemoryStore) updateMaterial(userID, materialID string, update func(m *memoryMaterial)) error {
+	defer s.lock()()
+	d := s.state.data
+
+	m, ok := d.materials[materialID]
+	if !ok || m.userID != userID {
+		return d.materialAccessError(userID, materialID)
+	}
+	update(&m)
+	d.materials[materialID] = m
+	return nil
+}
+
+// CreateMaterialChunks stores the sections a material's content was split
+// into, all pending generation.
+func (s *MemoryStore) CreateMaterialChunks(ctx context.Context, materialID string, chunks []string) error {
+	defer s.lock()()
+	d := s.state.data
+
+	if _, ok := d.materials[materialID]; !ok {
+		return fmt.Errorf("failed to insert material chunk: material %s: %w", materialID, ErrNotFound)
+	}
+	for i := range chunks {
+		if _, ok := d.chunks[memoryChunkKey{materialID, int32(i)}]; ok {
+			return fmt.Errorf("failed to insert material chunk: chunk %d already exists", i)
+		}
+	}
+	for i, content := range chunks {
+		d.chunks[memoryChunkKey{materialID, int32(i)}] = MaterialChunk{Index: int32(i), Content: content, Status: ChunkPending}
+	}
+	return nil
+}
+
+// GetMaterialChunks returns a material's chunks in order. Materials added
+// before chunks were recorded have none.
+func (s *MemoryStore) GetMaterialChunks(ctx context.Context, userID, materialID string) ([]*MaterialChunk, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	if err := d.checkMaterialOwner(userID, materialID); err != nil {
+		return nil, err
+	}
+	var chunks []*MaterialChunk
+	for key, c := range d.chunks {
+		if key.materialID == materialID {
+			chunks = append(chunks, &c)
+		}
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+	return chunks, nil
+}
+
+// SaveChunkFlashcards adds the cards generated from a chunk and marks the
+// chunk done, returning how many cards were added. Cards that repeat one
+// already in the material are skipped, and nothing is added for a chunk that
+// is already done.
+func (s *MemoryStore) SaveChunkFlashcards(ctx context.Context, materialID string, index int32, cards []*learning.Flashcard) (int32, error) {
+	defer s.lock()()
+	d := s.state.data
+
+	key := memoryChunkKey{materialID, index}
+	chunk, ok := d.chunks[key]
+	if !ok {
+		return 0, fmt.Errorf("material %s chunk %d: %w", materialID, index, ErrNotFound)
+	}
+	if chunk.Status == ChunkDone {
+		return 0, nil
+	}
+
+	// Leave out cards that repeat one already in the material or earlier in this chunk
+	seen := make(map[string]bool)
+	for _, card := range d.flashcards {
+		if card.materialID == materialID {
+			seen[flashcardKey(card.question, card.cardType, card.clozeIndex)] = true
+		}
+	}
+	var unique []*learning.Flashcard
+	for _, card := range cards {
+		key := flashcardKey(card.Question, cardTypeToDB(card.CardType), card.ClozeIndex)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, card)
+		}
+	}
+	if _, err := d.insertFlashcards(materialID, unique); err != nil {
+		return 0, err
+	}
+
+	chunk.Status, chunk.Error, chunk.FlashcardsCreated = ChunkDone, "", int32(len(unique))
+	d.chunks[key] = chunk
+	return chunk.FlashcardsCreated, nil
+}
+
+// FailMaterialChunk records why generating a chunk's cards failed.
+func (s *MemoryStore) FailMaterialChunk(ctx context.Context, materialID string, index int32, reason string) error {
+	defer s.lock()()
+	d := s.state.data
+
+	key := memoryChunkKey{materialID, index}
+	if chunk, ok := d.chunks[key]; ok && chunk.Status != ChunkDone {
+		chunk.Status, chunk.Error = ChunkFailed, reason
+		d.chunks[key] = chunk
+	}
+	return nil
+}
+
+// active reports whether the card is neither suspended nor buried, and so can
+// come up for review.
+func (c memoryFlashcard) active() bool {
+	return !c.suspended && (c.buriedUntil == nil || !c.buriedUntil.After(time.Now()))
+}
+
+// note identifies the note a card belongs to; a card without one is its own note.
+func (c memoryFlashcard) note() string {
+	if c.noteID != "" {
+		return c.noteID
+	}
+	return c.id
+}
+
+// dueBefore orders cards by due time, then ID.
+func dueBefore(a, b memoryFlashcard) bool {
+	if !a.nextReviewAt.Equal(b.nextReviewAt) {
+		return a.nextReviewAt.Before(b.nextReviewAt)
+	}
+	return a.id < b.id
+}
+
+// sortedFlashcards returns every card ordered by ID.
+func (d *memoryData) sortedFlashcards() []memoryFlashcard {
+	cards := slices.Collect(maps.Values(d.flashcards))
+	sort.Slice(cards, func(i, j int) bool { return cards[i].id < cards[j].id })
+	return cards
+}
+
+func (d *memoryData) flashcardProto(c memoryFlashcard, m memoryMaterial) *learning.Flashcard {
+	card := &learning.Flashcard{
+		Id: c.id, Question: c.question, Answer: c.answer, Stage: c.stage, NextReviewAt: timestamppb.New(c.nextReviewAt),
+		EaseFactor: c.easeFactor, IntervalDays: c.intervalDays, Repetitions: c.repetitions,
+		Stability: c.stability, Difficulty: c.difficulty, Flag: learning.FlashcardFlag(c.flag), Lapses: c.lapses, IsLeech: c.isLeech,
+		CardType: cardTypeFromDB(c.cardType), NoteId: c.noteID, ClozeIndex: c.clozeIndex,
+		MaterialTitle: m.title, MaterialId: m.id, Tags: d.tagsOf(m.id),
+	}
+	if c.lastReviewedAt != nil {
+		card.LastReviewedAt = timestamppb.New(*c.lastReviewedAt)
+	}
+	setFlashcardStatus(card, c.suspended, c.buriedUntil)
+	return card
+}
+
+// ownedFlashcard returns a card and its material if the user owns it and the
+// material has not been deleted.
+func (d *memoryData) ownedFlashcard(userID, id string) (memoryFlashcard, memoryMaterial, bool) {
+	card, ok := d.flashcards[id]
+	if !ok {
+		return memoryFlashcard{}, memoryMaterial{}, false
+	}
+	m := d.materials[card.materialID]
+	return card, m, m.userID == userID && !m.deleted
+}
+
+// flashcardAccessError explains why a flashcard could not be found for a user:
+// ErrPermissionDenied if it belongs to someone else, ErrNotFound otherwise.
+func (d *memoryData) flashcardAccessError(userID, id string) error {
+	if card, ok := d.flashcards[id]; ok {
+		if m := d.materials[card.materialID]; !m.deleted && m.userID != userID {
+			log.Printf("[Store] User %s attempted to access flashcard %s owned by %s", userID, id, m.userID)
+			return fmt.Errorf("flashcard %s: %w", id, ErrPermissionDenied)
+		}
+	}
+	return fmt.Errorf("flashcard %s: %w", id, ErrNotFound)
+}
+
+// materialAccessError explains why a material could not be found for a user:
+// ErrPermissionDenied if it belongs to someone else, ErrNotFound otherwise.
+func (d *memoryData) materialAccessError(userID, id string) error {
+	if err := d.checkMaterialOwner(userID, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("material %s: %w", id, ErrNotFound)
+}
+
+// checkMaterialOwner returns nil if the material exists and belongs to the user.
+func (d *memoryData) checkMaterialOwner(userID, id string) error {
+	m, ok := d.materials[id]
+	if !ok || m.deleted {
+		return fmt.Errorf("material %s: %w", id, ErrNotFound)
+	}
+	if m.userID != userID {
+		log.Printf("[Store] User %s attempted to access material %s owned by %s", userID, id, m.userID)
+		return fmt.Errorf("material %s: %w", id, ErrPermissionDenied)
+	}
+	return nil
+}
+
+var _ Store = (*MemoryStore)(nil)
@@ -2,32 +2,79 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/amityadav/landr/pkg/pb/auth"
 	"github.com/amityadav/landr/pkg/pb/learning"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// querier runs PostgresStore's queries: the connection pool, or the
+// transaction of a store handed to a WithTx callback.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
 type PostgresStore struct {
-	db *pgxpool.Pool
+	db   querier
+	pool *pgxpool.Pool // nil for a store inside a transaction
 }
 
 func NewPostgresStore(ctx context.Context, connString string) (*PostgresStore, error) {
-	db, err := pgxpool.New(ctx, connString)
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection string: %w", err)
+	}
+	return newPostgresStore(ctx, config)
+}
+
+func newPostgresStore(ctx context.Context, config *pgxpool.Config) (*PostgresStore, error) {
+	db, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create connection pool: %w", err)
 	}
 	if err := db.Ping(ctx); err != nil {
 		return nil, fmt.Errorf("unable to ping database: %w", err)
 	}
-	return &PostgresStore{db: db}, nil
+	return &PostgresStore{db: db, pool: db}, nil
 }
 
 func (s *PostgresStore) Close() {
-	s.db.Close()
+	if s.pool != nil {
+		s.pool.Close()
+	}
+}
+
+// WithTx calls fn with a store whose writes are committed together if fn
+// returns nil and rolled back otherwise. Methods that use a transaction of
+// their own run in a savepoint of this one, and so does a nested WithTx.
+func (s *PostgresStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&PostgresStore{db: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
 func (s *PostgresStore) CreateUser(ctx context.Context, email, name, googleID, picture string) (*auth.UserProfile, error) {
@@ -86,7 +133,7 @@ func (s *PostgresStore) SoftDeleteMaterial(ctx context.Context, userID, material
 		return fmt.Errorf("failed to delete material: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("material not found or already deleted")
+		return s.materialAccessError(ctx, userID, materialID)
 	}
 	log.Printf("[Store.SoftDeleteMaterial] Material soft deleted successfully")
 	return nil
@@ -124,12 +171,15 @@ func (s *PostgresStore) GetTags(ctx context.Context, userID string) ([]string, e
 	return tags, nil
 }
 
+// AddMaterialTags links tags to a material in a single round trip.
 func (s *PostgresStore) AddMaterialTags(ctx context.Context, materialID string, tagIDs []string) error {
+	query := `INSERT INTO material_tags (material_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	batch := &pgx.Batch{}
 	for _, tagID := range tagIDs {
-		query := `INSERT INTO material_tags (material_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
-		if _, err := s.db.Exec(ctx, query, materialID, tagID); err != nil {
-			return fmt.Errorf("failed to link tag: %w", err)
-		}
+		batch.Queue(query, materialID, tagID)
+	}
+	if err := s.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to link tag: %w", err)
 	}
 	return nil
 }
@@ -157,51 +207,169 @@ func (s *PostgresStore) GetMaterialTags(ctx context.Context, materialID string)
 	return tags, nil
 }
 
-func (s *PostgresStore) CreateFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) error {
+// CreateFlashcards adds cards to a material and returns their IDs in order.
+func (s *PostgresStore) CreateFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) ([]string, error) {
 	log.Printf("[Store.CreateFlashcards] Inserting %d flashcards for material: %s", len(cards), materialID)
+	ids, err := insertFlashcards(ctx, s.db, materialID, cards)
+	if err != nil {
+		log.Printf("[Store.CreateFlashcards] Insert failed: %v", err)
+		return nil, err
+	}
+	log.Printf("[Store.CreateFlashcards] All flashcards inserted successfully")
+	return ids, nil
+}
+
+// flashcardCopyColumns are the columns insertFlashcards fills in.
+var flashcardCopyColumns = []string{
+	"id", "material_id", "question", "answer", "stage", "next_review_at", "card_type", "note_id", "cloze_index",
+}
+
+// insertFlashcards adds new cards to a material with a single COPY and
+// returns their IDs in order. COPY cannot return generated values, so the
+// IDs are generated here.
+func insertFlashcards(ctx context.Context, q querier, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	if len(cards) == 0 {
+		return nil, nil
+	}
+	var material pgtype.UUID
+	if err := material.Scan(materialID); err != nil {
+		return nil, fmt.Errorf("material %s: %w", materialID, ErrNotFound)
+	}
+
+	now := time.Now()
+	ids := make([]string, len(cards))
+	rows := make([][]any, len(cards))
 	for i, card := range cards {
-		query := `
-            INSERT INTO flashcards (material_id, question, answer, stage, next_review_at)
-            VALUES ($1, $2, $3, $4, NOW());
-        `
-		_, err := s.db.Exec(ctx, query, materialID, card.Question, card.Answer, 0)
-		if err != nil {
-			log.Printf("[Store.CreateFlashcards] Failed to insert flashcard %d: %v", i, err)
-			return fmt.Errorf("failed to insert flashcard: %w", err)
+		var noteID pgtype.UUID
+		if card.NoteId != "" {
+			if err := noteID.Scan(card.NoteId); err != nil {
+				return nil, fmt.Errorf("invalid note id %q: %w", card.NoteId, err)
+			}
 		}
+		ids[i] = NewUUID()
+		rows[i] = []any{ids[i], material, card.Question, card.Answer, int32(0), now,
+			cardTypeToDB(card.CardType), noteID, card.ClozeIndex}
 	}
-	log.Printf("[Store.CreateFlashcards] All flashcards inserted successfully")
-	return nil
+
+	if _, err := q.CopyFrom(ctx, pgx.Identifier{"flashcards"}, flashcardCopyColumns, pgx.CopyFromRows(rows)); err != nil {
+		return nil, fmt.Errorf("failed to insert flashcards: %w", err)
+	}
+	return ids, nil
+}
+
+// AddFlashcards adds cards to a material the user owns and returns their IDs
+// in order. The cards are inserted with a single COPY, so either all of them
+// are added or none are.
+func (s *PostgresStore) AddFlashcards(ctx context.Context, userID, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	log.Printf("[Store.AddFlashcards] Inserting %d flashcards for material: %s, user: %s", len(cards), materialID, userID)
+
+	if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	ids, err := insertFlashcards(ctx, s.db, materialID, cards)
+	if err != nil {
+		log.Printf("[Store.AddFlashcards] Insert failed: %v", err)
+		return nil, err
+	}
+	log.Printf("[Store.AddFlashcards] Flashcards created: %v", ids)
+	return ids, nil
+}
+
+// DeleteFlashcards permanently deletes the given cards, along with their review
+// history. Either every card is deleted or, if any of them is missing or owned
+// by someone else, none are.
+func (s *PostgresStore) DeleteFlashcards(ctx context.Context, userID string, ids []string) (int32, error) {
+	log.Printf("[Store.DeleteFlashcards] Deleting %d flashcards for user: %s", len(ids), userID)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		DELETE FROM flashcards f
+		USING materials m
+		WHERE f.material_id = m.id AND f.id = ANY($1) AND m.user_id = $2
+		  AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+		RETURNING f.id;
+	`
+	rows, err := tx.Query(ctx, query, ids, userID)
+	if err != nil {
+		log.Printf("[Store.DeleteFlashcards] Delete failed: %v", err)
+		return 0, fmt.Errorf("failed to delete flashcards: %w", err)
+	}
+	deleted := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan flashcard id: %w", err)
+		}
+		deleted[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("[Store.DeleteFlashcards] Delete failed: %v", err)
+		if isInvalidID(err) {
+			return 0, fmt.Errorf("flashcard: %w", ErrNotFound)
+		}
+		return 0, fmt.Errorf("failed to delete flashcards: %w", err)
+	}
+
+	for _, id := range ids {
+		if !deleted[id] {
+			log.Printf("[Store.DeleteFlashcards] Flashcard %s could not be deleted, rolling back", id)
+			return 0, s.flashcardAccessError(ctx, userID, id)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit delete: %w", err)
+	}
+	log.Printf("[Store.DeleteFlashcards] Deleted %d flashcards", len(deleted))
+	return int32(len(deleted)), nil
 }
 
-func (s *PostgresStore) GetFlashcard(ctx context.Context, id string) (*learning.Flashcard, error) {
-	log.Printf("[Store.GetFlashcard] Querying flashcard: %s", id)
+func (s *PostgresStore) GetFlashcard(ctx context.Context, userID, id string) (*learning.Flashcard, error) {
+	log.Printf("[Store.GetFlashcard] Querying flashcard: %s for user: %s", id, userID)
 	query := `
-		SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, m.title, m.id
+		SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, f.ease_factor, f.interval_days, f.repetitions,
+		       f.stability, f.difficulty, f.last_reviewed_at, f.suspended, f.buried_until, f.flag, f.lapses, f.is_leech,
+		       f.card_type, COALESCE(f.note_id::text, ''), f.cloze_index, m.title, m.id, ` + materialTagsOf("m.id") + `
 		FROM flashcards f
 		JOIN materials m ON f.material_id = m.id
-		WHERE f.id = $1;
+		WHERE f.id = $1 AND m.user_id = $2 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL);
 	`
-	row := s.db.QueryRow(ctx, query, id)
+	row := s.db.QueryRow(ctx, query, id, userID)
 
 	var card learning.Flashcard
 	var title string
 	var matID string
 	var nextReviewAt time.Time
+	var lastReviewedAt, buriedUntil *time.Time
+	var suspended bool
+	var cardType string
 
-	if err := row.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &title, &matID); err != nil {
+	if err := row.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &card.EaseFactor, &card.IntervalDays, &card.Repetitions,
+		&card.Stability, &card.Difficulty, &lastReviewedAt, &suspended, &buriedUntil, &card.Flag, &card.Lapses, &card.IsLeech,
+		&cardType, &card.NoteId, &card.ClozeIndex, &title, &matID, &card.Tags); err != nil {
 		log.Printf("[Store.GetFlashcard] Query failed: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, s.flashcardAccessError(ctx, userID, id)
+		}
 		return nil, fmt.Errorf("failed to query flashcard: %w", err)
 	}
 
 	card.MaterialTitle = title
-
-	tags, err := s.GetMaterialTags(ctx, matID)
-	if err != nil {
-		log.Printf("[Store.GetFlashcard] Failed to get tags: %v", err)
-		tags = []string{}
+	card.MaterialId = matID
+	card.CardType = cardTypeFromDB(cardType)
+	card.NextReviewAt = timestamppb.New(nextReviewAt)
+	if lastReviewedAt != nil {
+		card.LastReviewedAt = timestamppb.New(*lastReviewedAt)
 	}
-	card.Tags = tags
+	setFlashcardStatus(&card, suspended, buriedUntil)
 
 	log.Printf("[Store.GetFlashcard] Found flashcard at stage %d", card.Stage)
 	return &card, nil
@@ -210,15 +378,20 @@ func (s *PostgresStore) GetFlashcard(ctx context.Context, id string) (*learning.
 func (s *PostgresStore) GetDueFlashcards(ctx context.Context, userID, materialID string) ([]*learning.Flashcard, error) {
 	log.Printf("[Store.GetDueFlashcards] Querying flashcards for userID: %s, materialID: %s", userID, materialID)
 	query := `
-        SELECT f.id, f.question, f.answer, f.stage, m.title, m.id
+        SELECT f.id, f.question, f.answer, f.stage, f.flag, f.card_type, COALESCE(f.note_id::text, ''), f.cloze_index,
+               m.title, m.id, ` + materialTagsOf("m.id") + `
         FROM flashcards f
         JOIN materials m ON f.material_id = m.id
         WHERE m.user_id = $1 AND m.id = $2 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+          AND ` + activeFlashcard + `
         ORDER BY f.id ASC;
     `
 	rows, err := s.db.Query(ctx, query, userID, materialID)
 	if err != nil {
 		log.Printf("[Store.GetDueFlashcards] Query failed: %v", err)
+		if isInvalidID(err) {
+			return nil, fmt.Errorf("material %s: %w", materialID, ErrNotFound)
+		}
 		return nil, fmt.Errorf("failed to query flashcards: %w", err)
 	}
 	defer rows.Close()
@@ -228,39 +401,195 @@ func (s *PostgresStore) GetDueFlashcards(ctx context.Context, userID, materialID
 		var card learning.Flashcard
 		var title string
 		var matID string
-		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &title, &matID); err != nil {
+		var cardType string
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &card.Flag, &cardType, &card.NoteId, &card.ClozeIndex,
+			&title, &matID, &card.Tags); err != nil {
 			log.Printf("[Store.GetDueFlashcards] Scan failed: %v", err)
 			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
 		}
 		card.MaterialTitle = title
+		card.MaterialId = matID
+		card.CardType = cardTypeFromDB(cardType)
+		flashcards = append(flashcards, &card)
+	}
 
-		tags, err := s.GetMaterialTags(ctx, matID)
-		if err != nil {
-			log.Printf("[Store.GetDueFlashcards] Failed to get tags: %v", err)
-			tags = []string{}
+	// No cards may also mean the material is missing or someone else's
+	if len(flashcards) == 0 {
+		if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+			return nil, err
 		}
-		card.Tags = tags
-
-		flashcards = append(flashcards, &card)
 	}
 
 	return flashcards, nil
 }
 
-func (s *PostgresStore) UpdateFlashcardContent(ctx context.Context, id, question, answer string) error {
-	log.Printf("[Store.UpdateFlashcardContent] Updating flashcard: %s", id)
+// GetReviewQueue returns the user's due cards across all materials. Cards are
+// interleaved round-robin between materials (materials with the oldest due card
+// first) and ordered by due date within a material, so the order is stable and
+// a session can be resumed from another device. Sibling cards of a note are
+// never queued together.
+func (s *PostgresStore) GetReviewQueue(ctx context.Context, userID string, filter ReviewQueueFilter) ([]*learning.Flashcard, int32, error) {
+	log.Printf("[Store.GetReviewQueue] Querying queue for userID: %s, filter: %+v", userID, filter)
 	query := `
-		UPDATE flashcards
-		SET question = $1, answer = $2, updated_at = NOW()
-		WHERE id = $3;
+		WITH matching AS (
+			SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, f.ease_factor, f.interval_days, f.repetitions,
+			       f.stability, f.difficulty, f.last_reviewed_at, f.flag, f.card_type, COALESCE(f.note_id::text, '') AS note_id,
+			       f.cloze_index, m.title, m.id AS material_id,
+			       ROW_NUMBER() OVER (PARTITION BY COALESCE(f.note_id, f.id) ORDER BY f.next_review_at, f.id) AS sibling_rank
+			FROM flashcards f
+			JOIN materials m ON f.material_id = m.id
+			WHERE m.user_id = $1 AND f.next_review_at < $7 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+			  AND ` + activeFlashcard + `
+			  AND (cardinality($2::uuid[]) = 0 OR m.id = ANY($2))
+			  AND (cardinality($3::text[]) = 0 OR EXISTS (
+			      SELECT 1 FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+			      WHERE mt.material_id = m.id AND t.name = ANY($3)))
+		),
+		due AS (
+			-- Only the earliest due card of each note, so siblings never share a day
+			SELECT *, last_reviewed_at IS NULL AS is_new,
+			       ROW_NUMBER() OVER (PARTITION BY material_id ORDER BY next_review_at, id) AS material_rank,
+			       MIN(next_review_at) OVER (PARTITION BY material_id) AS material_first_due
+			FROM matching
+			WHERE sibling_rank = 1
+		),
+		ranked AS (
+			SELECT *, COUNT(*) OVER () AS total_due,
+			       ROW_NUMBER() OVER (PARTITION BY is_new ORDER BY material_rank, material_first_due, material_id) AS kind_rank
+			FROM due
+		)
+		SELECT id, question, answer, stage, next_review_at, ease_factor, interval_days, repetitions,
+		       stability, difficulty, last_reviewed_at, flag, card_type, note_id, cloze_index, title, material_id, total_due,
+		       ` + materialTagsOf("ranked.material_id") + `
+		FROM ranked
+		WHERE (is_new AND kind_rank <= $5) OR (NOT is_new AND kind_rank <= $6)
+		ORDER BY material_rank, material_first_due, material_id
+		LIMIT $4;
 	`
-	result, err := s.db.Exec(ctx, query, question, answer, id)
+	materialIDs := filter.MaterialIDs
+	if materialIDs == nil {
+		materialIDs = []string{}
+	}
+	tags := filter.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	rows, err := s.db.Query(ctx, query, userID, materialIDs, tags, filter.Limit, filter.NewLimit, filter.ReviewLimit, filter.DueBefore)
+	if err != nil {
+		log.Printf("[Store.GetReviewQueue] Query failed: %v", err)
+		if isInvalidID(err) {
+			return nil, 0, fmt.Errorf("material: %w", ErrNotFound)
+		}
+		return nil, 0, fmt.Errorf("failed to query review queue: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*learning.Flashcard
+	var totalDue int32
+	for rows.Next() {
+		var card learning.Flashcard
+		var nextReviewAt time.Time
+		var lastReviewedAt *time.Time
+		var total int64
+		var cardType string
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &card.EaseFactor,
+			&card.IntervalDays, &card.Repetitions, &card.Stability, &card.Difficulty, &lastReviewedAt, &card.Flag,
+			&cardType, &card.NoteId, &card.ClozeIndex, &card.MaterialTitle, &card.MaterialId, &total, &card.Tags); err != nil {
+			log.Printf("[Store.GetReviewQueue] Scan failed: %v", err)
+			return nil, 0, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		totalDue = int32(total)
+		card.CardType = cardTypeFromDB(cardType)
+		card.NextReviewAt = timestamppb.New(nextReviewAt)
+		if lastReviewedAt != nil {
+			card.LastReviewedAt = timestamppb.New(*lastReviewedAt)
+		}
+
+		cards = append(cards, &card)
+	}
+
+	log.Printf("[Store.GetReviewQueue] Found %d cards (total due: %d)", len(cards), totalDue)
+	return cards, totalDue, nil
+}
+
+// UpdateFlashcardContent rewrites a flashcard, and its cloze siblings which
+// share the same text. A rewritten card starts over with no lapses and is no
+// longer a leech. Editing a cloze card adds a sibling for every new cloze
+// number and deletes the siblings whose deletion was removed.
+func (s *PostgresStore) UpdateFlashcardContent(ctx context.Context, userID, id, question, answer string) error {
+	log.Printf("[Store.UpdateFlashcardContent] Updating flashcard: %s for user: %s", id, userID)
+
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE flashcards f
+		SET question = $1, answer = $2, lapses = 0, is_leech = FALSE, updated_at = NOW()
+		FROM materials m
+		WHERE f.material_id = m.id AND m.user_id = $4 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+		  AND (f.id = $3 OR f.note_id = (SELECT note_id FROM flashcards WHERE id = $3))
+		RETURNING f.id, f.material_id, f.card_type, COALESCE(f.note_id::text, ''), f.cloze_index;
+	`
+	rows, err := tx.Query(ctx, query, question, answer, id, userID)
+	if err != nil && !isInvalidID(err) {
 		log.Printf("[Store.UpdateFlashcardContent] Update failed: %v", err)
 		return fmt.Errorf("failed to update flashcard content: %w", err)
 	}
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("flashcard not found")
+	var materialID, cardType, noteID string
+	siblings := make(map[string]int32)
+	if err == nil {
+		for rows.Next() {
+			var cardID, cardMaterialID, cardCardType, cardNoteID string
+			var clozeIndex int32
+			if err := rows.Scan(&cardID, &cardMaterialID, &cardCardType, &cardNoteID, &clozeIndex); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan flashcard: %w", err)
+			}
+			siblings[cardID] = clozeIndex
+			if cardID == id {
+				materialID, cardType, noteID = cardMaterialID, cardCardType, cardNoteID
+			}
+		}
+		rows.Close()
+		err = rows.Err()
+	}
+	if err != nil && !isInvalidID(err) {
+		log.Printf("[Store.UpdateFlashcardContent] Update failed: %v", err)
+		return fmt.Errorf("failed to update flashcard content: %w", err)
+	}
+	if err != nil || materialID == "" {
+		// A malformed ID aborts the transaction, so end it before looking the card up
+		tx.Rollback(ctx)
+		return s.flashcardAccessError(ctx, userID, id)
+	}
+
+	if cardTypeFromDB(cardType) == learning.CardType_CARD_TYPE_CLOZE {
+		if noteID == "" {
+			noteID = NewUUID()
+			if _, err := tx.Exec(ctx, `UPDATE flashcards SET note_id = $1 WHERE id = $2`, noteID, id); err != nil {
+				return fmt.Errorf("failed to set note id: %w", err)
+			}
+		}
+		remove, add, err := clozeChanges(siblings, noteID, question, answer)
+		if err != nil {
+			return err
+		}
+		if len(remove) > 0 {
+			if _, err := (&PostgresStore{db: tx}).DeleteFlashcards(ctx, userID, remove); err != nil {
+				return err
+			}
+		}
+		if _, err := insertFlashcards(ctx, tx, materialID, add); err != nil {
+			return err
+		}
+		log.Printf("[Store.UpdateFlashcardContent] Added %d and removed %d cloze siblings", len(add), len(remove))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit flashcard update: %w", err)
 	}
 	log.Printf("[Store.UpdateFlashcardContent] Flashcard content updated successfully")
 	return nil
@@ -271,9 +600,8 @@ func (s *PostgresStore) GetDueMaterials(ctx context.Context, userID string, page
 
 	// First, get the total count
 	countQuery := `
-		SELECT COUNT(DISTINCT m.id)
+		SELECT COUNT(*)
 		FROM materials m
-		JOIN flashcards f ON m.id = f.material_id
 		WHERE m.user_id = $1 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
 	`
 	var totalCount int32
@@ -286,9 +614,10 @@ func (s *PostgresStore) GetDueMaterials(ctx context.Context, userID string, page
 
 	// Get paginated results
 	query := `
-		SELECT m.id, m.title, COUNT(f.id) as due_count
+		SELECT m.id, m.title, COUNT(f.id) FILTER (WHERE ` + activeFlashcard + `) as due_count,
+		       ` + materialTagsOf("m.id") + `
 		FROM materials m
-		JOIN flashcards f ON m.id = f.material_id
+		LEFT JOIN flashcards f ON m.id = f.material_id
 		WHERE m.user_id = $1 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
 		GROUP BY m.id, m.title
 		ORDER BY m.created_at DESC
@@ -303,17 +632,9 @@ func (s *PostgresStore) GetDueMaterials(ctx context.Context, userID string, page
 	var materials []*learning.MaterialSummary
 	for rows.Next() {
 		var m learning.MaterialSummary
-		if err := rows.Scan(&m.Id, &m.Title, &m.DueCount); err != nil {
+		if err := rows.Scan(&m.Id, &m.Title, &m.DueCount, &m.Tags); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan material: %w", err)
 		}
-
-		tags, err := s.GetMaterialTags(ctx, m.Id)
-		if err != nil {
-			log.Printf("[Store.GetDueMaterials] Failed to get tags: %v", err)
-			tags = []string{}
-		}
-		m.Tags = tags
-
 		materials = append(materials, &m)
 	}
 
@@ -321,36 +642,735 @@ func (s *PostgresStore) GetDueMaterials(ctx context.Context, userID string, page
 	return materials, totalCount, nil
 }
 
-func (s *PostgresStore) GetDueFlashcardsCount(ctx context.Context, userID string) (int32, error) {
+// GetDueFlashcardsCount returns how many cards due before dueBefore have never
+// been reviewed (new) and how many are due for another review.
+func (s *PostgresStore) GetDueFlashcardsCount(ctx context.Context, userID string, dueBefore time.Time) (int32, int32, error) {
 	log.Printf("[Store.GetDueFlashcardsCount] Counting due flashcards for userID: %s", userID)
 	query := `
-		SELECT COUNT(f.id)
+		SELECT COUNT(DISTINCT COALESCE(f.note_id, f.id)) FILTER (WHERE f.last_reviewed_at IS NULL),
+		       COUNT(DISTINCT COALESCE(f.note_id, f.id)) FILTER (WHERE f.last_reviewed_at IS NOT NULL)
 		FROM flashcards f
 		JOIN materials m ON f.material_id = m.id
-		WHERE m.user_id = $1 AND f.next_review_at <= NOW() AND (m.is_deleted = FALSE OR m.is_deleted IS NULL);
+		WHERE m.user_id = $1 AND f.next_review_at < $2 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+		  AND ` + activeFlashcard + `;
 	`
-	var count int32
-	if err := s.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+	var newCount, reviewCount int32
+	if err := s.db.QueryRow(ctx, query, userID, dueBefore).Scan(&newCount, &reviewCount); err != nil {
 		log.Printf("[Store.GetDueFlashcardsCount] Query failed: %v", err)
-		return 0, fmt.Errorf("failed to count due flashcards: %w", err)
+		return 0, 0, fmt.Errorf("failed to count due flashcards: %w", err)
 	}
-	log.Printf("[Store.GetDueFlashcardsCount] Found %d due flashcards", count)
-	return count, nil
+	log.Printf("[Store.GetDueFlashcardsCount] Found %d new and %d review flashcards due", newCount, reviewCount)
+	return newCount, reviewCount, nil
 }
 
-func (s *PostgresStore) UpdateFlashcard(ctx context.Context, id string, stage int32, nextReviewAt time.Time) error {
-	log.Printf("[Store.UpdateFlashcard] Updating flashcard: %s, Stage: %d, NextReviewAt: %v", id, stage, nextReviewAt)
-	query := `
-        UPDATE flashcards
-        SET stage = $1, next_review_at = $2, updated_at = NOW()
-        WHERE id = $3;
+// RecordReview reschedules a flashcard and appends the review to the log in a single transaction.
+func (s *PostgresStore) RecordReview(ctx context.Context, schedule FlashcardSchedule, entry *ReviewLog) error {
+	log.Printf("[Store.RecordReview] Updating flashcard: %s, Stage: %d, Ease: %.2f, Interval: %d, NextReviewAt: %v",
+		entry.FlashcardID, schedule.Stage, schedule.EaseFactor, schedule.IntervalDays, schedule.NextReviewAt)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updateQuery := `
+        UPDATE flashcards f
+        SET stage = $1, ease_factor = $2, interval_days = $3, repetitions = $4, stability = $5, difficulty = $6,
+            next_review_at = $7, last_reviewed_at = $8, lapses = $11, is_leech = $12, suspended = $13, updated_at = NOW()
+        FROM materials m
+        WHERE f.material_id = m.id AND f.id = $9 AND m.user_id = $10;
     `
-	_, err := s.db.Exec(ctx, query, stage, nextReviewAt, id)
+	result, err := tx.Exec(ctx, updateQuery, schedule.Stage, schedule.EaseFactor, schedule.IntervalDays, schedule.Repetitions,
+		schedule.Stability, schedule.Difficulty, schedule.NextReviewAt, schedule.LastReviewedAt, entry.FlashcardID, entry.UserID,
+		schedule.Lapses, schedule.IsLeech, schedule.Suspended)
 	if err != nil {
-		log.Printf("[Store.UpdateFlashcard] Update failed: %v", err)
+		log.Printf("[Store.RecordReview] Update failed: %v", err)
 		return fmt.Errorf("failed to update flashcard: %w", err)
 	}
-	log.Printf("[Store.UpdateFlashcard] Flashcard updated successfully")
+	if result.RowsAffected() == 0 {
+		return s.flashcardAccessError(ctx, entry.UserID, entry.FlashcardID)
+	}
+
+	insertQuery := `
+		INSERT INTO review_logs (flashcard_id, user_id, grade, scheduler, previous_stage, next_stage,
+		                         previous_due_at, next_due_at, elapsed_days, reviewed_at, client_reviewed_at, session_id,
+		                         previous_ease_factor, previous_interval_days, previous_repetitions,
+		                         previous_stability, previous_difficulty, previous_last_reviewed_at,
+		                         previous_lapses, previous_is_leech, previous_suspended)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING id;
+	`
+	err = tx.QueryRow(ctx, insertQuery, entry.FlashcardID, entry.UserID, int16(entry.Grade), entry.Scheduler,
+		entry.PreviousStage, entry.NextStage, entry.PreviousDueAt, entry.NextDueAt, entry.ElapsedDays,
+		entry.ReviewedAt, entry.ClientReviewedAt, entry.SessionID,
+		entry.PreviousEaseFactor, entry.PreviousIntervalDays, entry.PreviousRepetitions,
+		entry.PreviousStability, entry.PreviousDifficulty, entry.PreviousLastReviewedAt,
+		entry.PreviousLapses, entry.PreviousIsLeech, entry.PreviousSuspended).Scan(&entry.ID)
+	if err != nil {
+		log.Printf("[Store.RecordReview] Review log insert failed: %v", err)
+		return fmt.Errorf("failed to insert review log: %w", err)
+	}
+
+	for _, b := range entry.BuriedSiblings {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO review_log_buried_siblings (review_log_id, flashcard_id, previous_buried_until)
+			VALUES ($1, $2, $3);
+		`, entry.ID, b.FlashcardID, b.PreviousBuriedUntil)
+		if err != nil {
+			log.Printf("[Store.RecordReview] Buried sibling insert failed: %v", err)
+			return fmt.Errorf("failed to record buried sibling: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit review: %w", err)
+	}
+	log.Printf("[Store.RecordReview] Review %s recorded successfully", entry.ID)
+	return nil
+}
+
+func (s *PostgresStore) GetReviewLogs(ctx context.Context, userID string) ([]*ReviewLog, error) {
+	log.Printf("[Store.GetReviewLogs] Querying review logs for userID: %s", userID)
+	query := `
+		SELECT id, flashcard_id, user_id, grade, scheduler, elapsed_days, reviewed_at
+		FROM review_logs
+		WHERE user_id = $1 AND reverted_at IS NULL
+		ORDER BY flashcard_id, reviewed_at;
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		log.Printf("[Store.GetReviewLogs] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query review logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*ReviewLog
+	for rows.Next() {
+		var l ReviewLog
+		var grade int16
+		if err := rows.Scan(&l.ID, &l.FlashcardID, &l.UserID, &grade, &l.Scheduler, &l.ElapsedDays, &l.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		l.Grade = learning.ReviewGrade(grade)
+		logs = append(logs, &l)
+	}
+	log.Printf("[Store.GetReviewLogs] Found %d review logs", len(logs))
+	return logs, nil
+}
+
+func (s *PostgresStore) GetReviewHistory(ctx context.Context, userID string, filter ReviewHistoryFilter) ([]*learning.ReviewLogEntry, int32, error) {
+	log.Printf("[Store.GetReviewHistory] Querying history for userID: %s, filter: %+v", userID, filter)
+
+	where := `
+		FROM review_logs rl
+		JOIN flashcards f ON rl.flashcard_id = f.id
+		JOIN materials m ON f.material_id = m.id
+		WHERE rl.user_id = $1
+		  AND ($2 = '' OR m.id::text = $2)
+		  AND ($3 = '' OR EXISTS (
+		      SELECT 1 FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+		      WHERE mt.material_id = m.id AND t.name = $3))
+		  AND ($4::timestamptz IS NULL OR rl.reviewed_at >= $4)
+		  AND ($5::timestamptz IS NULL OR rl.reviewed_at < $5)
+	`
+	var from, to *time.Time
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+	args := []any{userID, filter.MaterialID, filter.Tag, from, to}
+
+	var totalCount int32
+	if err := s.db.QueryRow(ctx, "SELECT COUNT(*) "+where, args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count review history: %w", err)
+	}
+
+	query := `
+		SELECT rl.id, rl.flashcard_id, m.id, m.title, f.question, rl.grade, rl.scheduler,
+		       rl.previous_stage, rl.next_stage, rl.previous_due_at, rl.next_due_at,
+		       rl.elapsed_days, rl.reviewed_at, rl.client_reviewed_at, COALESCE(rl.session_id, ''),
+		       rl.reverted_at IS NOT NULL
+	` + where + `
+		ORDER BY rl.reviewed_at DESC, rl.id
+		LIMIT $6 OFFSET $7;
+	`
+	offset := (filter.Page - 1) * filter.PageSize
+	rows, err := s.db.Query(ctx, query, append(args, filter.PageSize, offset)...)
+	if err != nil {
+		log.Printf("[Store.GetReviewHistory] Query failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to query review history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*learning.ReviewLogEntry
+	for rows.Next() {
+		var e learning.ReviewLogEntry
+		var grade int16
+		var previousDueAt, nextDueAt, clientReviewedAt *time.Time
+		var reviewedAt time.Time
+		if err := rows.Scan(&e.Id, &e.FlashcardId, &e.MaterialId, &e.MaterialTitle, &e.Question, &grade, &e.Scheduler,
+			&e.PreviousStage, &e.NextStage, &previousDueAt, &nextDueAt,
+			&e.ElapsedDays, &reviewedAt, &clientReviewedAt, &e.SessionId, &e.Reverted); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		e.Grade = learning.ReviewGrade(grade)
+		e.ReviewedAt = timestamppb.New(reviewedAt)
+		if previousDueAt != nil {
+			e.PreviousDueAt = timestamppb.New(*previousDueAt)
+		}
+		if nextDueAt != nil {
+			e.NextDueAt = timestamppb.New(*nextDueAt)
+		}
+		if clientReviewedAt != nil {
+			e.ClientReviewedAt = timestamppb.New(*clientReviewedAt)
+		}
+		entries = append(entries, &e)
+	}
+
+	log.Printf("[Store.GetReviewHistory] Found %d entries (total: %d)", len(entries), totalCount)
+	return entries, totalCount, nil
+}
+
+// UndoLastReview reverts the user's most recent review (optionally limited to
+// one session), restoring the card's scheduling state from before that review.
+// It returns the reverted review and how many reviews can still be undone.
+func (s *PostgresStore) UndoLastReview(ctx context.Context, userID, sessionID string) (*ReviewLog, int32, error) {
+	log.Printf("[Store.UndoLastReview] Undoing last review for userID: %s, sessionID: %s", userID, sessionID)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Take the user's undos one at a time. Locking only the latest review
+	// would leave a concurrent undo waiting on a row that is reverted by the
+	// time it gets it, so it would find nothing to undo; once this lock is
+	// held, the select below sees every undo committed before it.
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE`, userID); err != nil {
+		log.Printf("[Store.UndoLastReview] Lock failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to lock reviews: %w", err)
+	}
+
+	selectQuery := `
+		SELECT id, flashcard_id, user_id, grade, scheduler, previous_stage, next_stage, previous_due_at, next_due_at,
+		       reviewed_at, COALESCE(session_id, ''), previous_ease_factor, previous_interval_days,
+		       previous_repetitions, previous_stability, previous_difficulty, previous_last_reviewed_at,
+		       previous_lapses, previous_is_leech, previous_suspended
+		FROM review_logs
+		WHERE user_id = $1 AND reverted_at IS NULL AND ($2 = '' OR session_id = $2)
+		ORDER BY reviewed_at DESC
+		LIMIT 1
+		FOR UPDATE;
+	`
+	var l ReviewLog
+	var grade int16
+	var previousDueAt, nextDueAt *time.Time
+	err = tx.QueryRow(ctx, selectQuery, userID, sessionID).Scan(&l.ID, &l.FlashcardID, &l.UserID, &grade, &l.Scheduler,
+		&l.PreviousStage, &l.NextStage, &previousDueAt, &nextDueAt, &l.ReviewedAt, &l.SessionID,
+		&l.PreviousEaseFactor, &l.PreviousIntervalDays, &l.PreviousRepetitions,
+		&l.PreviousStability, &l.PreviousDifficulty, &l.PreviousLastReviewedAt,
+		&l.PreviousLapses, &l.PreviousIsLeech, &l.PreviousSuspended)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, ErrNothingToUndo
+	}
+	if err != nil {
+		log.Printf("[Store.UndoLastReview] Query failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to get last review: %w", err)
+	}
+	l.Grade = learning.ReviewGrade(grade)
+	if previousDueAt != nil {
+		l.PreviousDueAt = *previousDueAt
+	}
+	if nextDueAt != nil {
+		l.NextDueAt = *nextDueAt
+	}
+
+	// Refuse to undo if the card was reviewed again since, e.g. from another device
+	var superseded bool
+	supersededQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM review_logs
+			WHERE flashcard_id = $1 AND id <> $2 AND reverted_at IS NULL AND reviewed_at > $3
+		) OR NOT EXISTS (
+			SELECT 1 FROM flashcards
+			WHERE id = $1 AND stage = $4 AND next_review_at IS NOT DISTINCT FROM $5
+		);
+	`
+	if err := tx.QueryRow(ctx, supersededQuery, l.FlashcardID, l.ID, l.ReviewedAt, l.NextStage, nextDueAt).Scan(&superseded); err != nil {
+		return nil, 0, fmt.Errorf("failed to check for later reviews: %w", err)
+	}
+	if superseded {
+		log.Printf("[Store.UndoLastReview] Review %s was superseded", l.ID)
+		return nil, 0, ErrReviewSuperseded
+	}
+
+	restoreQuery := `
+		UPDATE flashcards
+		SET stage = $1, ease_factor = $2, interval_days = $3, repetitions = $4, stability = $5, difficulty = $6,
+		    next_review_at = $7, last_reviewed_at = $8, lapses = $10, is_leech = $11, suspended = $12, updated_at = NOW()
+		WHERE id = $9;
+	`
+	if _, err := tx.Exec(ctx, restoreQuery, l.PreviousStage, l.PreviousEaseFactor, l.PreviousIntervalDays, l.PreviousRepetitions,
+		l.PreviousStability, l.PreviousDifficulty, previousDueAt, l.PreviousLastReviewedAt, l.FlashcardID,
+		l.PreviousLapses, l.PreviousIsLeech, l.PreviousSuspended); err != nil {
+		log.Printf("[Store.UndoLastReview] Restore failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to restore flashcard: %w", err)
+	}
+
+	unburyQuery := `
+		UPDATE flashcards f
+		SET buried_until = b.previous_buried_until, updated_at = NOW()
+		FROM review_log_buried_siblings b
+		WHERE b.review_log_id = $1 AND f.id = b.flashcard_id;
+	`
+	if _, err := tx.Exec(ctx, unburyQuery, l.ID); err != nil {
+		log.Printf("[Store.UndoLastReview] Unbury failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to restore buried siblings: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE review_logs SET reverted_at = NOW() WHERE id = $1`, l.ID); err != nil {
+		return nil, 0, fmt.Errorf("failed to mark review reverted: %w", err)
+	}
+
+	var remaining int32
+	remainingQuery := `
+		SELECT COUNT(*) FROM review_logs
+		WHERE user_id = $1 AND reverted_at IS NULL AND ($2 = '' OR session_id = $2);
+	`
+	if err := tx.QueryRow(ctx, remainingQuery, userID, sessionID).Scan(&remaining); err != nil {
+		return nil, 0, fmt.Errorf("failed to count remaining reviews: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit undo: %w", err)
+	}
+	log.Printf("[Store.UndoLastReview] Review %s reverted, flashcard %s restored to stage %d", l.ID, l.FlashcardID, l.PreviousStage)
+	return &l, remaining, nil
+}
+
+// GetFSRSParams returns the user's fitted FSRS weights, or nil if none have been fitted yet.
+func (s *PostgresStore) GetFSRSParams(ctx context.Context, userID string) ([]float64, error) {
+	query := `SELECT weights FROM user_fsrs_params WHERE user_id = $1`
+	var weights []float64
+	err := s.db.QueryRow(ctx, query, userID).Scan(&weights)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fsrs params: %w", err)
+	}
+	return weights, nil
+}
+
+func (s *PostgresStore) SaveFSRSParams(ctx context.Context, userID string, weights []float64, reviewCount int32) error {
+	log.Printf("[Store.SaveFSRSParams] Saving %d weights for userID: %s", len(weights), userID)
+	query := `
+		INSERT INTO user_fsrs_params (user_id, weights, review_count, optimized_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET weights = EXCLUDED.weights, review_count = EXCLUDED.review_count, optimized_at = NOW();
+	`
+	if _, err := s.db.Exec(ctx, query, userID, weights, reviewCount); err != nil {
+		log.Printf("[Store.SaveFSRSParams] Save failed: %v", err)
+		return fmt.Errorf("failed to save fsrs params: %w", err)
+	}
+	return nil
+}
+
+// GetUsersNeedingOptimization returns users with at least minNewReviews reviews
+// logged since their FSRS weights were last fitted.
+func (s *PostgresStore) GetUsersNeedingOptimization(ctx context.Context, minNewReviews int) ([]string, error) {
+	query := `
+		SELECT rl.user_id
+		FROM review_logs rl
+		LEFT JOIN user_fsrs_params p ON p.user_id = rl.user_id
+		WHERE rl.reverted_at IS NULL AND (p.optimized_at IS NULL OR rl.reviewed_at > p.optimized_at)
+		GROUP BY rl.user_id
+		HAVING COUNT(*) >= $1;
+	`
+	rows, err := s.db.Query(ctx, query, minNewReviews)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users needing optimization: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, nil
+}
+
+// GetStudySettings returns the user's study settings, or nil if they never saved any.
+func (s *PostgresStore) GetStudySettings(ctx context.Context, userID string) (*learning.StudySettings, error) {
+	query := `
+		SELECT new_cards_per_day, max_reviews_per_day, day_rollover_hour, timezone, leech_threshold, leech_action
+		FROM user_settings
+		WHERE user_id = $1;
+	`
+	var settings learning.StudySettings
+	var leechAction string
+	err := s.db.QueryRow(ctx, query, userID).Scan(&settings.NewCardsPerDay, &settings.MaxReviewsPerDay, &settings.DayRolloverHour, &settings.Timezone,
+		&settings.LeechThreshold, &leechAction)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("[Store.GetStudySettings] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	settings.LeechAction = learning.LeechAction(learning.LeechAction_value["LEECH_ACTION_"+leechAction])
+	return &settings, nil
+}
+
+func (s *PostgresStore) UpdateStudySettings(ctx context.Context, userID string, settings *learning.StudySettings) error {
+	log.Printf("[Store.UpdateStudySettings] Saving settings for userID: %s: %+v", userID, settings)
+	query := `
+		INSERT INTO user_settings (user_id, new_cards_per_day, max_reviews_per_day, day_rollover_hour, timezone,
+		                           leech_threshold, leech_action)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+		SET new_cards_per_day = EXCLUDED.new_cards_per_day, max_reviews_per_day = EXCLUDED.max_reviews_per_day,
+		    day_rollover_hour = EXCLUDED.day_rollover_hour, timezone = EXCLUDED.timezone,
+		    leech_threshold = EXCLUDED.leech_threshold, leech_action = EXCLUDED.leech_action, updated_at = NOW();
+	`
+	leechAction := strings.TrimPrefix(settings.LeechAction.String(), "LEECH_ACTION_")
+	_, err := s.db.Exec(ctx, query, userID, settings.NewCardsPerDay, settings.MaxReviewsPerDay, settings.DayRolloverHour, settings.Timezone,
+		settings.LeechThreshold, leechAction)
+	if err != nil {
+		log.Printf("[Store.UpdateStudySettings] Save failed: %v", err)
+		return fmt.Errorf("failed to save study settings: %w", err)
+	}
+	return nil
+}
+
+// GetStudyCountsSince returns how many new cards the user started and how many
+// reviews of previously seen cards they did since the given time.
+func (s *PostgresStore) GetStudyCountsSince(ctx context.Context, userID string, since time.Time) (int32, int32, error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE previous_last_reviewed_at IS NULL),
+		       COUNT(*) FILTER (WHERE previous_last_reviewed_at IS NOT NULL)
+		FROM review_logs
+		WHERE user_id = $1 AND reviewed_at >= $2 AND reverted_at IS NULL;
+	`
+	var newStudied, reviewsDone int32
+	if err := s.db.QueryRow(ctx, query, userID, since).Scan(&newStudied, &reviewsDone); err != nil {
+		log.Printf("[Store.GetStudyCountsSince] Query failed: %v", err)
+		return 0, 0, fmt.Errorf("failed to count reviews: %w", err)
+	}
+	return newStudied, reviewsDone, nil
+}
+
+// GetQuizCards returns up to limit of the user's basic, unsuspended cards in
+// random order, from one material or from all materials with a tag.
+func (s *PostgresStore) GetQuizCards(ctx context.Context, userID, materialID, tag string, limit int32) ([]*learning.Flashcard, error) {
+	log.Printf("[Store.GetQuizCards] Querying cards for userID: %s, materialID: %s, tag: %s", userID, materialID, tag)
+	query := `
+		SELECT f.id, f.question, f.answer, m.id, m.title
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE m.user_id = $1 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+		  AND f.card_type = 'BASIC' AND f.suspended = FALSE
+		  AND ($2 = '' OR m.id::text = $2)
+		  AND ($3 = '' OR EXISTS (
+		      SELECT 1 FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+		      WHERE mt.material_id = m.id AND t.name = $3))
+		ORDER BY random()
+		LIMIT $4;
+	`
+	rows, err := s.db.Query(ctx, query, userID, materialID, tag, limit)
+	if err != nil {
+		log.Printf("[Store.GetQuizCards] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query quiz cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*learning.Flashcard
+	for rows.Next() {
+		var card learning.Flashcard
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.MaterialId, &card.MaterialTitle); err != nil {
+			log.Printf("[Store.GetQuizCards] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		cards = append(cards, &card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query quiz cards: %w", err)
+	}
+
+	// No cards may also mean the material is missing or someone else's
+	if len(cards) == 0 && materialID != "" {
+		if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("[Store.GetQuizCards] Found %d cards", len(cards))
+	return cards, nil
+}
+
+// CreateQuiz saves a quiz and its questions, filling in the question IDs.
+func (s *PostgresStore) CreateQuiz(ctx context.Context, userID, materialID, tag string, questions []*QuizQuestion) (string, error) {
+	log.Printf("[Store.CreateQuiz] Creating quiz with %d questions for userID: %s", len(questions), userID)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var quizID string
+	quizQuery := `
+		INSERT INTO quizzes (user_id, material_id, tag)
+		VALUES ($1, NULLIF($2, '')::uuid, NULLIF($3, ''))
+		RETURNING id;
+	`
+	if err := tx.QueryRow(ctx, quizQuery, userID, materialID, tag).Scan(&quizID); err != nil {
+		log.Printf("[Store.CreateQuiz] Quiz insert failed: %v", err)
+		return "", fmt.Errorf("failed to insert quiz: %w", err)
+	}
+
+	questionQuery := `
+		INSERT INTO quiz_questions (quiz_id, flashcard_id, position, question, choices, correct_index)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+	for i, q := range questions {
+		err := tx.QueryRow(ctx, questionQuery, quizID, q.FlashcardID, i, q.Question, q.Choices, q.CorrectIndex).Scan(&q.ID)
+		if err != nil {
+			log.Printf("[Store.CreateQuiz] Question %d insert failed: %v", i, err)
+			return "", fmt.Errorf("failed to insert quiz question: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit quiz: %w", err)
+	}
+	log.Printf("[Store.CreateQuiz] Quiz created with ID: %s", quizID)
+	return quizID, nil
+}
+
+// GetQuizQuestions returns a quiz's questions in order, with the correct answers.
+func (s *PostgresStore) GetQuizQuestions(ctx context.Context, userID, quizID string) ([]*QuizQuestion, error) {
+	log.Printf("[Store.GetQuizQuestions] Querying quiz: %s for user: %s", quizID, userID)
+	query := `
+		SELECT qq.id, COALESCE(qq.flashcard_id::text, ''), qq.question, qq.choices, qq.correct_index
+		FROM quiz_questions qq
+		JOIN quizzes q ON qq.quiz_id = q.id
+		WHERE q.id = $1 AND q.user_id = $2
+		ORDER BY qq.position;
+	`
+	rows, err := s.db.Query(ctx, query, quizID, userID)
+	if err != nil {
+		log.Printf("[Store.GetQuizQuestions] Query failed: %v", err)
+		if isInvalidID(err) {
+			return nil, fmt.Errorf("quiz %s: %w", quizID, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to query quiz: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*QuizQuestion
+	for rows.Next() {
+		var q QuizQuestion
+		if err := rows.Scan(&q.ID, &q.FlashcardID, &q.Question, &q.Choices, &q.CorrectIndex); err != nil {
+			log.Printf("[Store.GetQuizQuestions] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
+		}
+		questions = append(questions, &q)
+	}
+	if err := rows.Err(); err != nil {
+		if isInvalidID(err) {
+			return nil, fmt.Errorf("quiz %s: %w", quizID, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to query quiz: %w", err)
+	}
+
+	// A quiz always has questions, so none means it is missing or someone else's
+	if len(questions) == 0 {
+		if err := s.checkOwner(ctx, `SELECT user_id FROM quizzes WHERE id = $1`, userID, quizID, "quiz"); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("quiz %s: %w", quizID, ErrNotFound)
+	}
+	return questions, nil
+}
+
+// SaveQuizAttempt stores a scored quiz attempt and its answers, filling in its ID.
+func (s *PostgresStore) SaveQuizAttempt(ctx context.Context, attempt *QuizAttempt) error {
+	log.Printf("[Store.SaveQuizAttempt] Saving attempt for quiz: %s, score: %d/%d", attempt.QuizID, attempt.CorrectCount, attempt.TotalCount)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	attemptQuery := `
+		INSERT INTO quiz_attempts (quiz_id, user_id, correct_count, total_count, applied_to_schedule, submitted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+	err = tx.QueryRow(ctx, attemptQuery, attempt.QuizID, attempt.UserID, attempt.CorrectCount, attempt.TotalCount,
+		attempt.AppliedToSchedule, attempt.SubmittedAt).Scan(&attempt.ID)
+	if err != nil {
+		log.Printf("[Store.SaveQuizAttempt] Attempt insert failed: %v", err)
+		return fmt.Errorf("failed to insert quiz attempt: %w", err)
+	}
+
+	answerQuery := `
+		INSERT INTO quiz_attempt_answers (attempt_id, question_id, chosen_index, correct)
+		VALUES ($1, $2, $3, $4);
+	`
+	for _, a := range attempt.Answers {
+		if _, err := tx.Exec(ctx, answerQuery, attempt.ID, a.QuestionID, a.ChosenIndex, a.Correct); err != nil {
+			log.Printf("[Store.SaveQuizAttempt] Answer insert failed: %v", err)
+			return fmt.Errorf("failed to insert quiz answer: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit quiz attempt: %w", err)
+	}
+	log.Printf("[Store.SaveQuizAttempt] Attempt saved with ID: %s", attempt.ID)
+	return nil
+}
+
+// CreateIngestionJob queues a job, filling in its ID.
+func (s *PostgresStore) CreateIngestionJob(ctx context.Context, job *IngestionJob) error {
+	log.Printf("[Store.CreateIngestionJob] Queueing %s job for userID: %s", job.Type, job.UserID)
+	query := `
+		INSERT INTO ingestion_jobs (user_id, type, content, image_data, allow_cloze, generate_reverse, material_id)
+		SELECT $1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ingestion_jobs
+			WHERE material_id = NULLIF($7, '')::uuid AND stage NOT IN ('SUCCEEDED', 'FAILED')
+		)
+		RETURNING id, updated_at;
+	`
+	err := s.db.QueryRow(ctx, query, job.UserID, job.Type, job.Content, job.ImageData, job.AllowCloze, job.GenerateReverse,
+		job.MaterialID).Scan(&job.ID, &job.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("material %s: %w", job.MaterialID, ErrIngestionInProgress)
+	}
+	if err != nil {
+		log.Printf("[Store.CreateIngestionJob] Insert failed: %v", err)
+		return fmt.Errorf("failed to insert ingestion job: %w", err)
+	}
+	job.Stage = learning.IngestionStage_INGESTION_STAGE_QUEUED
+	log.Printf("[Store.CreateIngestionJob] Job queued with ID: %s", job.ID)
+	return nil
+}
+
+// GetIngestionJob returns a job's progress and result, without its input.
+func (s *PostgresStore) GetIngestionJob(ctx context.Context, userID, id string) (*IngestionJob, error) {
+	query := `
+		SELECT id, user_id, type, stage, chunk, total_chunks, attempts, COALESCE(material_id::text, ''),
+		       title, tags, flashcards_created, failed_chunks, error, updated_at
+		FROM ingestion_jobs
+		WHERE id = $1 AND user_id = $2;
+	`
+	var job IngestionJob
+	var stage string
+	err := s.db.QueryRow(ctx, query, id, userID).Scan(&job.ID, &job.UserID, &job.Type, &stage, &job.Chunk, &job.TotalChunks,
+		&job.Attempts, &job.MaterialID, &job.Title, &job.Tags, &job.FlashcardsCreated, &job.FailedChunks, &job.Error, &job.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		if err := s.checkOwner(ctx, `SELECT user_id FROM ingestion_jobs WHERE id = $1`, userID, id, "ingestion job"); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ingestion job %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("[Store.GetIngestionJob] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to get ingestion job: %w", err)
+	}
+	job.Stage = ingestionStageFromDB(stage)
+	return &job, nil
+}
+
+// ClaimIngestionJob hands the oldest unfinished job nobody holds a lease on to
+// the caller until leaseUntil, or returns nil if there is none. Jobs that
+// have already been claimed maxAttempts times are failed instead.
+func (s *PostgresStore) ClaimIngestionJob(ctx context.Context, leaseUntil time.Time, maxAttempts int32) (*IngestionJob, error) {
+	giveUpQuery := `
+		UPDATE ingestion_jobs
+		SET stage = 'FAILED', error = 'processing was interrupted too many times', content = '', image_data = '',
+		    updated_at = NOW()
+		WHERE stage NOT IN ('SUCCEEDED', 'FAILED') AND lease_expires_at < NOW() AND attempts >= $1;
+	`
+	if _, err := s.db.Exec(ctx, giveUpQuery, maxAttempts); err != nil {
+		log.Printf("[Store.ClaimIngestionJob] Failing abandoned jobs failed: %v", err)
+		return nil, fmt.Errorf("failed to fail abandoned ingestion jobs: %w", err)
+	}
+
+	query := `
+		UPDATE ingestion_jobs
+		SET attempts = attempts + 1, lease_expires_at = $1, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM ingestion_jobs
+			WHERE stage NOT IN ('SUCCEEDED', 'FAILED') AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, type, content, image_data, allow_cloze, generate_reverse, COALESCE(material_id::text, ''),
+		          stage, attempts, updated_at;
+	`
+	var job IngestionJob
+	var stage string
+	err := s.db.QueryRow(ctx, query, leaseUntil).Scan(&job.ID, &job.UserID, &job.Type, &job.Content, &job.ImageData,
+		&job.AllowCloze, &job.GenerateReverse, &job.MaterialID, &stage, &job.Attempts, &job.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("[Store.ClaimIngestionJob] Claim failed: %v", err)
+		return nil, fmt.Errorf("failed to claim ingestion job: %w", err)
+	}
+	job.Stage = ingestionStageFromDB(stage)
+	job.LeaseExpiresAt = leaseUntil
+	log.Printf("[Store.ClaimIngestionJob] Claimed job %s (attempt %d)", job.ID, job.Attempts)
+	return &job, nil
+}
+
+// UpdateIngestionJob saves a job's progress, result and lease, as long as the
+// job is unfinished and hasn't been claimed again since job was claimed. A
+// finished job's input is dropped, since it is never processed again.
+func (s *PostgresStore) UpdateIngestionJob(ctx context.Context, job *IngestionJob) error {
+	query := `
+		UPDATE ingestion_jobs
+		SET stage = $2, chunk = $3, total_chunks = $4, lease_expires_at = $5, material_id = NULLIF($6, '')::uuid,
+		    title = $7, tags = $8, flashcards_created = $9, failed_chunks = $10, error = $11, updated_at = NOW(),
+		    content = CASE WHEN $13 THEN '' ELSE content END,
+		    image_data = CASE WHEN $13 THEN '' ELSE image_data END
+		WHERE id = $1 AND attempts = $12 AND stage NOT IN ('SUCCEEDED', 'FAILED')
+		RETURNING updated_at;
+	`
+	tags, failedChunks := job.Tags, job.FailedChunks
+	if tags == nil {
+		tags = []string{}
+	}
+	if failedChunks == nil {
+		failedChunks = []int32{}
+	}
+	err := s.db.QueryRow(ctx, query, job.ID, ingestionStageToDB(job.Stage), job.Chunk, job.TotalChunks, job.LeaseExpiresAt,
+		job.MaterialID, job.Title, tags, job.FlashcardsCreated, failedChunks, job.Error, job.Attempts,
+		job.Finished()).Scan(&job.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("ingestion job %s: %w", job.ID, ErrIngestionLeaseLost)
+	}
+	if err != nil {
+		log.Printf("[Store.UpdateIngestionJob] Update failed: %v", err)
+		return fmt.Errorf("failed to update ingestion job: %w", err)
+	}
 	return nil
 }
 
@@ -359,30 +1379,443 @@ func (s *PostgresStore) GetMaterialContent(ctx context.Context, userID, material
 	query := `
 		SELECT content, COALESCE(summary, ''), title
 		FROM materials
-		WHERE id = $1 AND user_id = $2;
+		WHERE id = $1 AND user_id = $2 AND (is_deleted = FALSE OR is_deleted IS NULL);
 	`
 	var content, summary, title string
 	err := s.db.QueryRow(ctx, query, materialID, userID).Scan(&content, &summary, &title)
 	if err != nil {
 		log.Printf("[Store.GetMaterialContent] Query failed: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return "", "", "", s.materialAccessError(ctx, userID, materialID)
+		}
 		return "", "", "", fmt.Errorf("failed to get material content: %w", err)
 	}
 	log.Printf("[Store.GetMaterialContent] Found material, content length: %d, has summary: %v", len(content), summary != "")
 	return content, summary, title, nil
 }
 
-func (s *PostgresStore) UpdateMaterialSummary(ctx context.Context, materialID, summary string) error {
+func (s *PostgresStore) UpdateMaterialSummary(ctx context.Context, userID, materialID, summary string) error {
 	log.Printf("[Store.UpdateMaterialSummary] Updating summary for material: %s", materialID)
 	query := `
 		UPDATE materials
 		SET summary = $1, updated_at = NOW()
-		WHERE id = $2;
+		WHERE id = $2 AND user_id = $3;
 	`
-	_, err := s.db.Exec(ctx, query, summary, materialID)
-	if err != nil {
+	result, err := s.db.Exec(ctx, query, summary, materialID, userID)
+	if err != nil && !isInvalidID(err) {
 		log.Printf("[Store.UpdateMaterialSummary] Update failed: %v", err)
 		return fmt.Errorf("failed to update material summary: %w", err)
 	}
+	if err != nil || result.RowsAffected() == 0 {
+		return s.materialAccessError(ctx, userID, materialID)
+	}
 	log.Printf("[Store.UpdateMaterialSummary] Summary updated successfully")
 	return nil
 }
+
+// UpdateMaterialTitle renames a material the user owns.
+func (s *PostgresStore) UpdateMaterialTitle(ctx context.Context, userID, materialID, title string) error {
+	log.Printf("[Store.UpdateMaterialTitle] Updating title for material: %s", materialID)
+	query := `
+		UPDATE materials
+		SET title = $1, updated_at = NOW()
+		WHERE id = $2 AND user_id = $3;
+	`
+	result, err := s.db.Exec(ctx, query, title, materialID, userID)
+	if err != nil && !isInvalidID(err) {
+		log.Printf("[Store.UpdateMaterialTitle] Update failed: %v", err)
+		return fmt.Errorf("failed to update material title: %w", err)
+	}
+	if err != nil || result.RowsAffected() == 0 {
+		return s.materialAccessError(ctx, userID, materialID)
+	}
+	return nil
+}
+
+// CreateMaterialChunks stores the sections a material's content was split
+// into, all pending generation, with a single COPY.
+func (s *PostgresStore) CreateMaterialChunks(ctx context.Context, materialID string, chunks []string) error {
+	log.Printf("[Store.CreateMaterialChunks] Inserting %d chunks for material: %s", len(chunks), materialID)
+	if len(chunks) == 0 {
+		return nil
+	}
+	var material pgtype.UUID
+	if err := material.Scan(materialID); err != nil {
+		return fmt.Errorf("material %s: %w", materialID, ErrNotFound)
+	}
+
+	rows := make([][]any, len(chunks))
+	for i, content := range chunks {
+		rows[i] = []any{material, int32(i), content}
+	}
+	columns := []string{"material_id", "chunk_index", "content"}
+	if _, err := s.db.CopyFrom(ctx, pgx.Identifier{"material_chunks"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		log.Printf("[Store.CreateMaterialChunks] Insert failed: %v", err)
+		return fmt.Errorf("failed to insert material chunks: %w", err)
+	}
+	return nil
+}
+
+// GetMaterialChunks returns a material's chunks in order. Materials added
+// before chunks were recorded have none.
+func (s *PostgresStore) GetMaterialChunks(ctx context.Context, userID, materialID string) ([]*MaterialChunk, error) {
+	if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT chunk_index, content, status, error, flashcards_created
+		FROM material_chunks
+		WHERE material_id = $1
+		ORDER BY chunk_index;
+	`
+	rows, err := s.db.Query(ctx, query, materialID)
+	if err != nil {
+		log.Printf("[Store.GetMaterialChunks] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query material chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []*MaterialChunk
+	for rows.Next() {
+		var c MaterialChunk
+		if err := rows.Scan(&c.Index, &c.Content, &c.Status, &c.Error, &c.FlashcardsCreated); err != nil {
+			log.Printf("[Store.GetMaterialChunks] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan material chunk: %w", err)
+		}
+		chunks = append(chunks, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query material chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// SaveChunkFlashcards adds the cards generated from a chunk and marks the
+// chunk done in one transaction, returning how many cards were added. Cards
+// that repeat one already in the material are skipped, and nothing is added
+// for a chunk that is already done.
+func (s *PostgresStore) SaveChunkFlashcards(ctx context.Context, materialID string, index int32, cards []*learning.Flashcard) (int32, error) {
+	log.Printf("[Store.SaveChunkFlashcards] Saving %d flashcards for material: %s, chunk: %d", len(cards), materialID, index)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	lockQuery := `SELECT status FROM material_chunks WHERE material_id = $1 AND chunk_index = $2 FOR UPDATE`
+	err = tx.QueryRow(ctx, lockQuery, materialID, index).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("material %s chunk %d: %w", materialID, index, ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock material chunk: %w", err)
+	}
+	if status == ChunkDone {
+		log.Printf("[Store.SaveChunkFlashcards] Chunk %d already done, skipping", index)
+		return 0, nil
+	}
+
+	// Leave out cards that repeat one already in the material or earlier in this chunk
+	rows, err := tx.Query(ctx, `SELECT question, card_type, cloze_index FROM flashcards WHERE material_id = $1`, materialID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query material flashcards: %w", err)
+	}
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var question, cardType string
+		var clozeIndex int32
+		if err := rows.Scan(&question, &cardType, &clozeIndex); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan material flashcard: %w", err)
+		}
+		seen[flashcardKey(question, cardType, clozeIndex)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to query material flashcards: %w", err)
+	}
+
+	var unique []*learning.Flashcard
+	for _, card := range cards {
+		key := flashcardKey(card.Question, cardTypeToDB(card.CardType), card.ClozeIndex)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, card)
+		}
+	}
+	if _, err := insertFlashcards(ctx, tx, materialID, unique); err != nil {
+		log.Printf("[Store.SaveChunkFlashcards] Insert failed: %v", err)
+		return 0, err
+	}
+	created := int32(len(unique))
+
+	doneQuery := `
+		UPDATE material_chunks
+		SET status = 'DONE', error = '', flashcards_created = $3, updated_at = NOW()
+		WHERE material_id = $1 AND chunk_index = $2;
+	`
+	if _, err := tx.Exec(ctx, doneQuery, materialID, index, created); err != nil {
+		return 0, fmt.Errorf("failed to mark material chunk done: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit chunk flashcards: %w", err)
+	}
+	log.Printf("[Store.SaveChunkFlashcards] Added %d flashcards (%d duplicates skipped)", created, int32(len(cards))-created)
+	return created, nil
+}
+
+// FailMaterialChunk records why generating a chunk's cards failed.
+func (s *PostgresStore) FailMaterialChunk(ctx context.Context, materialID string, index int32, reason string) error {
+	query := `
+		UPDATE material_chunks
+		SET status = 'FAILED', error = $3, updated_at = NOW()
+		WHERE material_id = $1 AND chunk_index = $2 AND status <> 'DONE';
+	`
+	if _, err := s.db.Exec(ctx, query, materialID, index, reason); err != nil {
+		log.Printf("[Store.FailMaterialChunk] Update failed: %v", err)
+		return fmt.Errorf("failed to mark material chunk failed: %w", err)
+	}
+	return nil
+}
+
+// GetLeeches returns the user's leech cards, most lapsed first, including
+// ones that have been suspended.
+func (s *PostgresStore) GetLeeches(ctx context.Context, userID string) ([]*learning.Flashcard, error) {
+	log.Printf("[Store.GetLeeches] Querying leeches for userID: %s", userID)
+	query := `
+		SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, f.last_reviewed_at, f.suspended, f.buried_until,
+		       f.flag, f.lapses, f.is_leech, f.card_type, COALESCE(f.note_id::text, ''), f.cloze_index, m.title, m.id,
+		       ` + materialTagsOf("m.id") + `
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE m.user_id = $1 AND f.is_leech AND (m.is_deleted = FALSE OR m.is_deleted IS NULL)
+		ORDER BY f.lapses DESC, f.id;
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		log.Printf("[Store.GetLeeches] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query leeches: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*learning.Flashcard
+	for rows.Next() {
+		var card learning.Flashcard
+		var nextReviewAt time.Time
+		var lastReviewedAt, buriedUntil *time.Time
+		var suspended bool
+		var cardType string
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &lastReviewedAt, &suspended,
+			&buriedUntil, &card.Flag, &card.Lapses, &card.IsLeech, &cardType, &card.NoteId, &card.ClozeIndex,
+			&card.MaterialTitle, &card.MaterialId, &card.Tags); err != nil {
+			log.Printf("[Store.GetLeeches] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		card.CardType = cardTypeFromDB(cardType)
+		card.NextReviewAt = timestamppb.New(nextReviewAt)
+		if lastReviewedAt != nil {
+			card.LastReviewedAt = timestamppb.New(*lastReviewedAt)
+		}
+		setFlashcardStatus(&card, suspended, buriedUntil)
+
+		cards = append(cards, &card)
+	}
+
+	log.Printf("[Store.GetLeeches] Found %d leeches", len(cards))
+	return cards, nil
+}
+
+// SetFlashcardSuspended suspends or unsuspends a flashcard.
+func (s *PostgresStore) SetFlashcardSuspended(ctx context.Context, userID, id string, suspended bool) error {
+	log.Printf("[Store.SetFlashcardSuspended] Flashcard: %s, user: %s, suspended: %v", id, userID, suspended)
+	return s.updateFlashcardState(ctx, userID, id, "suspended = $1", suspended)
+}
+
+// SetFlashcardBuriedUntil hides a flashcard from reviews until the given time,
+// or unburies it when until is nil.
+func (s *PostgresStore) SetFlashcardBuriedUntil(ctx context.Context, userID, id string, until *time.Time) error {
+	log.Printf("[Store.SetFlashcardBuriedUntil] Flashcard: %s, user: %s, until: %v", id, userID, until)
+	return s.updateFlashcardState(ctx, userID, id, "buried_until = $1", until)
+}
+
+// BuryFlashcardSiblings buries the other cards sharing a note with the given
+// card until the given time, unless they are already buried for longer, and
+// returns the cards it buried with when they were buried until before.
+func (s *PostgresStore) BuryFlashcardSiblings(ctx context.Context, userID, id string, until time.Time) ([]BuriedSibling, error) {
+	log.Printf("[Store.BuryFlashcardSiblings] Burying siblings of flashcard: %s until %v", id, until)
+	// Joining the table to itself reads the rows as they were before the update
+	query := `
+		UPDATE flashcards f
+		SET buried_until = $1, updated_at = NOW()
+		FROM flashcards card, materials m, flashcards old
+		WHERE card.id = $2 AND f.note_id = card.note_id AND f.id <> card.id
+		  AND f.material_id = m.id AND m.user_id = $3
+		  AND old.id = f.id AND (old.buried_until IS NULL OR old.buried_until < $1)
+		RETURNING f.id, old.buried_until;
+	`
+	rows, err := s.db.Query(ctx, query, until, id, userID)
+	if err != nil {
+		log.Printf("[Store.BuryFlashcardSiblings] Update failed: %v", err)
+		return nil, fmt.Errorf("failed to bury siblings: %w", err)
+	}
+	defer rows.Close()
+	var buried []BuriedSibling
+	for rows.Next() {
+		var b BuriedSibling
+		if err := rows.Scan(&b.FlashcardID, &b.PreviousBuriedUntil); err != nil {
+			return nil, fmt.Errorf("failed to scan buried sibling: %w", err)
+		}
+		buried = append(buried, b)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[Store.BuryFlashcardSiblings] Update failed: %v", err)
+		return nil, fmt.Errorf("failed to bury siblings: %w", err)
+	}
+	log.Printf("[Store.BuryFlashcardSiblings] Buried %d siblings", len(buried))
+	return buried, nil
+}
+
+func (s *PostgresStore) SetFlashcardFlag(ctx context.Context, userID, id string, flag int32) error {
+	log.Printf("[Store.SetFlashcardFlag] Flashcard: %s, user: %s, flag: %d", id, userID, flag)
+	return s.updateFlashcardState(ctx, userID, id, "flag = $1", flag)
+}
+
+// updateFlashcardState applies a single-column SET clause (using $1 for value)
+// to a flashcard the user owns.
+func (s *PostgresStore) updateFlashcardState(ctx context.Context, userID, id, set string, value any) error {
+	query := `
+		UPDATE flashcards f
+		SET ` + set + `, updated_at = NOW()
+		FROM materials m
+		WHERE f.material_id = m.id AND f.id = $2 AND m.user_id = $3
+		  AND (m.is_deleted = FALSE OR m.is_deleted IS NULL);
+	`
+	result, err := s.db.Exec(ctx, query, value, id, userID)
+	if err != nil && !isInvalidID(err) {
+		log.Printf("[Store.updateFlashcardState] Update failed: %v", err)
+		return fmt.Errorf("failed to update flashcard: %w", err)
+	}
+	if err != nil || result.RowsAffected() == 0 {
+		return s.flashcardAccessError(ctx, userID, id)
+	}
+	return nil
+}
+
+// activeFlashcard matches flashcards (aliased f) that are neither suspended
+// nor buried, and so can come up for review.
+const activeFlashcard = `f.suspended = FALSE AND (f.buried_until IS NULL OR f.buried_until <= NOW())`
+
+// materialTagsOf selects the names of the tags of the material whose ID is in
+// column col as a text array, so listings get tags without a query per row.
+func materialTagsOf(col string) string {
+	return `COALESCE((
+		SELECT array_agg(t.name ORDER BY t.name)
+		FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+		WHERE mt.material_id = ` + col + `), '{}')`
+}
+
+// setFlashcardStatus derives a card's status from its suspended flag and burial time.
+func setFlashcardStatus(card *learning.Flashcard, suspended bool, buriedUntil *time.Time) {
+	switch {
+	case suspended:
+		card.Status = learning.FlashcardStatus_FLASHCARD_STATUS_SUSPENDED
+	case buriedUntil != nil && buriedUntil.After(time.Now()):
+		card.Status = learning.FlashcardStatus_FLASHCARD_STATUS_BURIED
+		card.BuriedUntil = timestamppb.New(*buriedUntil)
+	default:
+		card.Status = learning.FlashcardStatus_FLASHCARD_STATUS_ACTIVE
+	}
+}
+
+// flashcardAccessError explains why a flashcard could not be found for a user:
+// ErrPermissionDenied if it belongs to someone else, ErrNotFound otherwise.
+func (s *PostgresStore) flashcardAccessError(ctx context.Context, userID, id string) error {
+	query := `
+		SELECT m.user_id
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE f.id = $1 AND (m.is_deleted = FALSE OR m.is_deleted IS NULL);
+	`
+	if err := s.checkOwner(ctx, query, userID, id, "flashcard"); err != nil {
+		return err
+	}
+	return fmt.Errorf("flashcard %s: %w", id, ErrNotFound)
+}
+
+// materialAccessError explains why a material could not be found for a user:
+// ErrPermissionDenied if it belongs to someone else, ErrNotFound otherwise.
+func (s *PostgresStore) materialAccessError(ctx context.Context, userID, id string) error {
+	if err := s.checkMaterialOwner(ctx, userID, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("material %s: %w", id, ErrNotFound)
+}
+
+// checkMaterialOwner returns nil if the material exists and belongs to the user.
+func (s *PostgresStore) checkMaterialOwner(ctx context.Context, userID, id string) error {
+	query := `SELECT user_id FROM materials WHERE id = $1 AND (is_deleted = FALSE OR is_deleted IS NULL)`
+	return s.checkOwner(ctx, query, userID, id, "material")
+}
+
+// checkOwner looks up a record's owner with ownerQuery and returns nil if it
+// is userID, ErrPermissionDenied if it is someone else and ErrNotFound if the
+// record does not exist.
+func (s *PostgresStore) checkOwner(ctx context.Context, ownerQuery, userID, id, kind string) error {
+	// A malformed ID would fail the query, and with it any transaction this
+	// store is part of, so it is turned away before querying
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return fmt.Errorf("%s %s: %w", kind, id, ErrNotFound)
+	}
+
+	var ownerID string
+	err := s.db.QueryRow(ctx, ownerQuery, id).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return fmt.Errorf("%s %s: %w", kind, id, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", kind, err)
+	}
+	if ownerID != userID {
+		log.Printf("[Store] User %s attempted to access %s %s owned by %s", userID, kind, id, ownerID)
+		return fmt.Errorf("%s %s: %w", kind, id, ErrPermissionDenied)
+	}
+	return nil
+}
+
+// flashcardKey identifies cards that are duplicates of each other: the same
+// kind of card whose question starts with the same 50 characters, ignoring case.
+func flashcardKey(question, cardType string, clozeIndex int32) string {
+	q := []rune(strings.ToLower(strings.TrimSpace(question)))
+	if len(q) > 50 {
+		q = q[:50]
+	}
+	return fmt.Sprintf("%s/%d/%s", cardType, clozeIndex, string(q))
+}
+
+// cardTypeToDB returns how a card type is stored in flashcards.card_type.
+func cardTypeToDB(t learning.CardType) string {
+	return strings.TrimPrefix(t.String(), "CARD_TYPE_")
+}
+
+func cardTypeFromDB(s string) learning.CardType {
+	return learning.CardType(learning.CardType_value["CARD_TYPE_"+s])
+}
+
+// ingestionStageToDB returns how a stage is stored in ingestion_jobs.stage.
+func ingestionStageToDB(stage learning.IngestionStage) string {
+	return strings.TrimPrefix(stage.String(), "INGESTION_STAGE_")
+}
+
+func ingestionStageFromDB(s string) learning.IngestionStage {
+	return learning.IngestionStage(learning.IngestionStage_value["INGESTION_STAGE_"+s])
+}
+
+// isInvalidID reports whether err is Postgres rejecting a malformed UUID.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// BenchmarkCreateMaterialChunks measures storing the chunks of a long
+// material, on SQLite and, when TEST_DATABASE_URL is set, Postgres.
+func BenchmarkCreateMaterialChunks(b *testing.B) {
+	ctx := context.Background()
+	stores := map[string]func(b *testing.B) Store{
+		"sqlite":   func(b *testing.B) Store { return newTestSQLiteStore(b) },
+		"postgres": func(b *testing.B) Store { return newTestPostgresStore(b) },
+	}
+	for name, newStore := range stores {
+		for _, n := range []int{10, 100} {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				s := newStore(b)
+				userID := newTestUser(b, s)
+				chunks := make([]string, n)
+				for i := range chunks {
+					chunks[i] = strings.Repeat("Some content of a long material. ", 100)
+				}
+
+				b.ResetTimer()
+				for range b.N {
+					b.StopTimer()
+					materialID, _ := newTestMaterial(b, s, userID)
+					b.StartTimer()
+					if err := s.CreateMaterialChunks(ctx, materialID, chunks); err != nil {
+						b.Fatalf("CreateMaterialChunks: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestListingQueryCounts checks the listings make the same number of round
+// trips however many materials, cards and tags they return.
+func TestListingQueryCounts(t *testing.T) {
+	ctx := context.Background()
+	s, counter, err := NewCountingPostgresStore(ctx, migrateTestDatabase(t))
+	if err != nil {
+		t.Fatalf("NewCountingPostgresStore: %v", err)
+	}
+	t.Cleanup(s.Close)
+
+	// counts returns the round trips of each listing for a user with n
+	// materials of n tagged cards each.
+	counts := func(t *testing.T, n int) map[string]int64 {
+		t.Helper()
+		userID := newTestUser(t, s)
+		var materialID, cardID string
+		for i := range n {
+			var cards []*learning.Flashcard
+			for j := range n {
+				cards = append(cards, &learning.Flashcard{
+					Question: fmt.Sprintf("Q%d", j),
+					Answer:   fmt.Sprintf("A%d", j),
+				})
+			}
+			var ids []string
+			materialID, ids = newTestMaterial(t, s, userID, cards...)
+			cardID = ids[0]
+			tagID, err := s.CreateTag(ctx, userID, fmt.Sprintf("tag%d", i))
+			if err != nil {
+				t.Fatalf("CreateTag: %v", err)
+			}
+			if err := s.AddMaterialTags(ctx, materialID, []string{tagID}); err != nil {
+				t.Fatalf("AddMaterialTags: %v", err)
+			}
+		}
+
+		listings := map[string]func() error{
+			"GetDueMaterials": func() error {
+				_, _, err := s.GetDueMaterials(ctx, userID, 1, 50)
+				return err
+			},
+			"GetDueFlashcards": func() error {
+				_, err := s.GetDueFlashcards(ctx, userID, materialID)
+				return err
+			},
+			"GetFlashcard": func() error {
+				_, err := s.GetFlashcard(ctx, userID, cardID)
+				return err
+			},
+		}
+		got := make(map[string]int64)
+		for name, list := range listings {
+			got[name] = counter.Measure(func() {
+				if err := list(); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+			})
+		}
+		return got
+	}
+
+	few, many := counts(t, 1), counts(t, 10)
+	for name, n := range few {
+		if many[name] != n {
+			t.Errorf("%s made %d queries for 1 row and %d for 10, want the same", name, n, many[name])
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QueryCounter is a pgx tracer that counts the round trips a PostgresStore
+// makes: every query, batch and COPY counts once. It lets callers check that
+// a listing stays a constant number of queries however many rows it returns.
+type QueryCounter struct {
+	count atomic.Int64
+}
+
+// NewCountingPostgresStore connects like NewPostgresStore, counting the
+// store's queries with the returned QueryCounter.
+func NewCountingPostgresStore(ctx context.Context, connString string) (*PostgresStore, *QueryCounter, error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse connection string: %w", err)
+	}
+	counter := &QueryCounter{}
+	config.ConnConfig.Tracer = counter
+
+	s, err := newPostgresStore(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, counter, nil
+}
+
+// Count returns the number of round trips since the counter was created or last reset.
+func (c *QueryCounter) Count() int64 {
+	return c.count.Load()
+}
+
+// Reset sets the count back to zero.
+func (c *QueryCounter) Reset() {
+	c.count.Store(0)
+}
+
+// Measure runs fn and returns how many round trips it made. Measurements of
+// concurrent calls overlap.
+func (c *QueryCounter) Measure(fn func()) int64 {
+	before := c.Count()
+	fn()
+	return c.Count() - before
+}
+
+func (c *QueryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	c.count.Add(1)
+	return ctx
+}
+
+func (c *QueryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+func (c *QueryCounter) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	c.count.Add(1)
+	return ctx
+}
+
+func (c *QueryCounter) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+func (c *QueryCounter) TraceBatchEnd(context.Context, *pgx.Conn, pgx.TraceBatchEndData) {}
+
+func (c *QueryCounter) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceCopyFromStartData) context.Context {
+	c.count.Add(1)
+	return ctx
+}
+
+func (c *QueryCounter) TraceCopyFromEnd(context.Context, *pgx.Conn, pgx.TraceCopyFromEndData) {}
+
+var (
+	_ pgx.QueryTracer    = (*QueryCounter)(nil)
+	_ pgx.BatchTracer    = (*QueryCounter)(nil)
+	_ pgx.CopyFromTracer = (*QueryCounter)(nil)
+)
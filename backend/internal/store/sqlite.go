@@ -0,0 +1,1856 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amityadav/landr/db"
+	"github.com/amityadav/landr/internal/migrate"
+	"github.com/amityadav/landr/pkg/pb/auth"
+	"github.com/amityadav/landr/pkg/pb/learning"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver
+)
+
+// sqliteNow is the current time as stored in SQLite: Unix milliseconds.
+const sqliteNow = `CAST(unixepoch('subsec') * 1000 AS INTEGER)`
+
+// sqliteQuerier runs SQLiteStore's queries: the database, or the transaction
+// of a store handed to a WithTx callback.
+type sqliteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLiteStore keeps everything in a single SQLite file, for self-hosting
+// without a database server. IDs are UUID text, times Unix milliseconds and
+// arrays JSON.
+type SQLiteStore struct {
+	db   sqliteQuerier
+	conn *sql.DB // nil for a store inside a transaction
+	tx   *sql.Tx // nil outside a transaction
+}
+
+func NewSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	// Writers wait for each other instead of failing with SQLITE_BUSY, and
+	// transactions take the write lock up front so they can't deadlock
+	dsn := "file:" + path
+	if strings.Contains(path, "?") {
+		dsn += "&"
+	} else {
+		dsn += "?"
+	}
+	dsn += "_pragma=foreign_keys(1)&_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+	if path == ":memory:" || strings.Contains(path, "mode=memory") {
+		// Every connection would otherwise get a database of its own
+		conn.SetMaxOpenConns(1)
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+
+	s := &SQLiteStore{db: conn, conn: conn}
+	if err := s.setUpSchema(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// setUpSchema applies the migrations in db.SQLiteMigrations the database has
+// not had yet, each in a transaction of its own. The database's user_version
+// is the last one applied, 0 for a new database.
+func (s *SQLiteStore) setUpSchema(ctx context.Context) error {
+	migrations, err := migrate.Load(db.SQLiteMigrations, "sqlite")
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	if err := s.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("unable to read schema version: %w", err)
+	}
+	known := version == 0 || slices.ContainsFunc(migrations, func(m migrate.Migration) bool { return m.Version == version })
+	if !known {
+		return fmt.Errorf("database has unknown schema version %d, expected at most %d", version, migrations[len(migrations)-1].Version)
+	}
+
+	for _, mig := range migrations {
+		if mig.Version <= version {
+			continue
+		}
+		log.Printf("[Store] Applying SQLite migration %d_%s", mig.Version, mig.Name)
+		err := s.inTx(ctx, func(tx *SQLiteStore) error {
+			if _, err := tx.db.ExecContext(ctx, mig.Up); err != nil {
+				return fmt.Errorf("unable to apply migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			if _, err := tx.db.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, mig.Version)); err != nil {
+				return fmt.Errorf("unable to set schema version: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// WithTx calls fn with a store whose writes are committed together if fn
+// returns nil and rolled back otherwise. Methods that use a transaction of
+// their own run in a savepoint of this one, and so does a nested WithTx.
+func (s *SQLiteStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		return fn(tx)
+	})
+}
+
+// inTx runs fn in a transaction, or in a savepoint if the store is already
+// inside one.
+func (s *SQLiteStore) inTx(ctx context.Context, fn func(tx *SQLiteStore) error) error {
+	if s.tx != nil {
+		if _, err := s.tx.ExecContext(ctx, `SAVEPOINT nested`); err != nil {
+			return fmt.Errorf("failed to begin savepoint: %w", err)
+		}
+		if err := fn(s); err != nil {
+			s.tx.ExecContext(context.Background(), `ROLLBACK TO nested`)
+			s.tx.ExecContext(context.Background(), `RELEASE nested`)
+			return err
+		}
+		if _, err := s.tx.ExecContext(ctx, `RELEASE nested`); err != nil {
+			return fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		return nil
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&SQLiteStore{db: tx, tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CreateUser(ctx context.Context, email, name, googleID, picture string) (*auth.UserProfile, error) {
+	query := `
+		INSERT INTO users (id, email, name, google_id, picture, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, ` + sqliteNow + `, ` + sqliteNow + `)
+		ON CONFLICT (google_id) DO UPDATE
+		SET name = excluded.name, picture = excluded.picture, updated_at = excluded.updated_at
+		RETURNING id, email, name, picture;
+	`
+	row := s.db.QueryRowContext(ctx, query, NewUUID(), email, name, googleID, picture)
+	var user auth.UserProfile
+	if err := row.Scan(&user.Id, &user.Email, &user.Name, &user.Picture); err != nil {
+		return nil, fmt.Errorf("failed to create/update user: %w", err)
+	}
+	return &user, nil
+}
+
+func (s *SQLiteStore) GetUserByGoogleID(ctx context.Context, googleID string) (*auth.UserProfile, error) {
+	query := `SELECT id, email, name, picture FROM users WHERE google_id = $1`
+	row := s.db.QueryRowContext(ctx, query, googleID)
+	var user auth.UserProfile
+	if err := row.Scan(&user.Id, &user.Email, &user.Name, &user.Picture); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+func (s *SQLiteStore) CreateMaterial(ctx context.Context, userID, matType, content, title string) (string, error) {
+	log.Printf("[Store.CreateMaterial] Inserting material - UserID: %s, Type: %s, Title: %s", userID, matType, title)
+	query := `
+		INSERT INTO materials (id, user_id, type, content, title, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, ` + sqliteNow + `, ` + sqliteNow + `);
+	`
+	materialID := NewUUID()
+	if _, err := s.db.ExecContext(ctx, query, materialID, userID, matType, content, title); err != nil {
+		log.Printf("[Store.CreateMaterial] Insert failed: %v", err)
+		return "", fmt.Errorf("failed to insert material: %w", err)
+	}
+	log.Printf("[Store.CreateMaterial] Material created with ID: %s", materialID)
+	return materialID, nil
+}
+
+func (s *SQLiteStore) SoftDeleteMaterial(ctx context.Context, userID, materialID string) error {
+	log.Printf("[Store.SoftDeleteMaterial] Soft deleting material: %s for user: %s", materialID, userID)
+	query := `
+		UPDATE materials
+		SET is_deleted = 1, deleted_at = ` + sqliteNow + `, updated_at = ` + sqliteNow + `
+		WHERE id = $1 AND user_id = $2 AND (is_deleted = 0 OR is_deleted IS NULL);
+	`
+	result, err := s.db.ExecContext(ctx, query, materialID, userID)
+	if err != nil {
+		log.Printf("[Store.SoftDeleteMaterial] Delete failed: %v", err)
+		return fmt.Errorf("failed to delete material: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return s.materialAccessError(ctx, userID, materialID)
+	}
+	log.Printf("[Store.SoftDeleteMaterial] Material soft deleted successfully")
+	return nil
+}
+
+func (s *SQLiteStore) CreateTag(ctx context.Context, userID, name string) (string, error) {
+	query := `
+		INSERT INTO tags (id, user_id, name, created_at) VALUES ($1, $2, $3, ` + sqliteNow + `)
+		ON CONFLICT (user_id, name) DO UPDATE SET name = excluded.name
+		RETURNING id;
+	`
+	var id string
+	if err := s.db.QueryRowContext(ctx, query, NewUUID(), userID, name).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to create tag: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLiteStore) GetTags(ctx context.Context, userID string) ([]string, error) {
+	query := `SELECT name FROM tags WHERE user_id = $1 ORDER BY name`
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
+
+// AddMaterialTags links tags to a material in a single statement.
+func (s *SQLiteStore) AddMaterialTags(ctx context.Context, materialID string, tagIDs []string) error {
+	query := `
+		INSERT INTO material_tags (material_id, tag_id)
+		SELECT $1, value FROM json_each($2) WHERE true -- Needed before ON CONFLICT
+		ON CONFLICT DO NOTHING;
+	`
+	if _, err := s.db.ExecContext(ctx, query, materialID, jsonText(tagIDs)); err != nil {
+		return fmt.Errorf("failed to link tag: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetMaterialTags(ctx context.Context, materialID string) ([]string, error) {
+	query := `
+		SELECT t.name
+		FROM tags t
+		JOIN material_tags mt ON t.id = mt.tag_id
+		WHERE mt.material_id = $1
+	`
+	rows, err := s.db.QueryContext(ctx, query, materialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
+
+// CreateFlashcards adds cards to a material and returns their IDs in order.
+func (s *SQLiteStore) CreateFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	log.Printf("[Store.CreateFlashcards] Inserting %d flashcards for material: %s", len(cards), materialID)
+	var ids []string
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		var err error
+		ids, err = tx.insertFlashcards(ctx, materialID, cards)
+		return err
+	})
+	if err != nil {
+		log.Printf("[Store.CreateFlashcards] Insert failed: %v", err)
+		return nil, err
+	}
+	log.Printf("[Store.CreateFlashcards] All flashcards inserted successfully")
+	return ids, nil
+}
+
+// insertFlashcards adds new cards to a material and returns their IDs in
+// order. It should run in a transaction, which SQLite writes much faster.
+func (s *SQLiteStore) insertFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	query := `
+		INSERT INTO flashcards (id, material_id, question, answer, stage, next_review_at, card_type, note_id, cloze_index,
+		                        created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, NULLIF($7, ''), $8, $5, $5);
+	`
+	if len(cards) == 0 {
+		return nil, nil
+	}
+
+	now := toMillis(time.Now())
+	ids := make([]string, len(cards))
+	for i, card := range cards {
+		ids[i] = NewUUID()
+		_, err := s.db.ExecContext(ctx, query, ids[i], materialID, card.Question, card.Answer, now,
+			cardTypeToDB(card.CardType), card.NoteId, card.ClozeIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert flashcards: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// AddFlashcards adds cards to a material the user owns in one transaction and
+// returns their IDs in order.
+func (s *SQLiteStore) AddFlashcards(ctx context.Context, userID, materialID string, cards []*learning.Flashcard) ([]string, error) {
+	log.Printf("[Store.AddFlashcards] Inserting %d flashcards for material: %s, user: %s", len(cards), materialID, userID)
+	var ids []string
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		if err := tx.checkMaterialOwner(ctx, userID, materialID); err != nil {
+			return err
+		}
+		var err error
+		ids, err = tx.insertFlashcards(ctx, materialID, cards)
+		return err
+	})
+	if err != nil {
+		log.Printf("[Store.AddFlashcards] Insert failed: %v", err)
+		return nil, err
+	}
+	log.Printf("[Store.AddFlashcards] Flashcards created: %v", ids)
+	return ids, nil
+}
+
+// DeleteFlashcards permanently deletes the given cards, along with their review
+// history. Either every card is deleted or, if any of them is missing or owned
+// by someone else, none are.
+func (s *SQLiteStore) DeleteFlashcards(ctx context.Context, userID string, ids []string) (int32, error) {
+	log.Printf("[Store.DeleteFlashcards] Deleting %d flashcards for user: %s", len(ids), userID)
+	var deleted map[string]bool
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		query := `
+			DELETE FROM flashcards
+			WHERE id IN (SELECT value FROM json_each($1))
+			  AND material_id IN (
+			      SELECT id FROM materials WHERE user_id = $2 AND (is_deleted = 0 OR is_deleted IS NULL))
+			RETURNING id;
+		`
+		rows, err := tx.db.QueryContext(ctx, query, jsonText(ids), userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete flashcards: %w", err)
+		}
+		defer rows.Close()
+		deleted = make(map[string]bool)
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("failed to scan flashcard id: %w", err)
+			}
+			deleted[id] = true
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to delete flashcards: %w", err)
+		}
+		rows.Close()
+
+		for _, id := range ids {
+			if !deleted[id] {
+				log.Printf("[Store.DeleteFlashcards] Flashcard %s could not be deleted, rolling back", id)
+				return tx.flashcardAccessError(ctx, userID, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.DeleteFlashcards] Delete failed: %v", err)
+		return 0, err
+	}
+	log.Printf("[Store.DeleteFlashcards] Deleted %d flashcards", len(deleted))
+	return int32(len(deleted)), nil
+}
+
+func (s *SQLiteStore) GetFlashcard(ctx context.Context, userID, id string) (*learning.Flashcard, error) {
+	log.Printf("[Store.GetFlashcard] Querying flashcard: %s for user: %s", id, userID)
+	query := `
+		SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, f.ease_factor, f.interval_days, f.repetitions,
+		       f.stability, f.difficulty, f.last_reviewed_at, f.suspended, f.buried_until, f.flag, f.lapses, f.is_leech,
+		       f.card_type, COALESCE(f.note_id, ''), f.cloze_index, m.title, m.id, ` + sqliteMaterialTagsOf("m.id") + `
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE f.id = $1 AND m.user_id = $2 AND (m.is_deleted = 0 OR m.is_deleted IS NULL);
+	`
+	row := s.db.QueryRowContext(ctx, query, id, userID)
+
+	var card learning.Flashcard
+	var nextReviewAt int64
+	var lastReviewedAt, buriedUntil sql.NullInt64
+	var suspended bool
+	var cardType string
+
+	if err := row.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &card.EaseFactor, &card.IntervalDays, &card.Repetitions,
+		&card.Stability, &card.Difficulty, &lastReviewedAt, &suspended, &buriedUntil, &card.Flag, &card.Lapses, &card.IsLeech,
+		&cardType, &card.NoteId, &card.ClozeIndex, &card.MaterialTitle, &card.MaterialId, jsonArray(&card.Tags)); err != nil {
+		log.Printf("[Store.GetFlashcard] Query failed: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.flashcardAccessError(ctx, userID, id)
+		}
+		return nil, fmt.Errorf("failed to query flashcard: %w", err)
+	}
+
+	card.CardType = cardTypeFromDB(cardType)
+	card.NextReviewAt = timestamppb.New(fromMillis(nextReviewAt))
+	if t := fromNullMillis(lastReviewedAt); t != nil {
+		card.LastReviewedAt = timestamppb.New(*t)
+	}
+	setFlashcardStatus(&card, suspended, fromNullMillis(buriedUntil))
+
+	log.Printf("[Store.GetFlashcard] Found flashcard at stage %d", card.Stage)
+	return &card, nil
+}
+
+func (s *SQLiteStore) GetDueFlashcards(ctx context.Context, userID, materialID string) ([]*learning.Flashcard, error) {
+	log.Printf("[Store.GetDueFlashcards] Querying flashcards for userID: %s, materialID: %s", userID, materialID)
+	query := `
+		SELECT f.id, f.question, f.answer, f.stage, f.flag, f.card_type, COALESCE(f.note_id, ''), f.cloze_index,
+		       m.title, m.id, ` + sqliteMaterialTagsOf("m.id") + `
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE m.user_id = $1 AND m.id = $2 AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+		  AND ` + sqliteActiveFlashcard + `
+		ORDER BY f.id ASC;
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, materialID)
+	if err != nil {
+		log.Printf("[Store.GetDueFlashcards] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query flashcards: %w", err)
+	}
+	defer rows.Close()
+
+	var flashcards []*learning.Flashcard
+	for rows.Next() {
+		var card learning.Flashcard
+		var cardType string
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &card.Flag, &cardType, &card.NoteId, &card.ClozeIndex,
+			&card.MaterialTitle, &card.MaterialId, jsonArray(&card.Tags)); err != nil {
+			log.Printf("[Store.GetDueFlashcards] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		card.CardType = cardTypeFromDB(cardType)
+		flashcards = append(flashcards, &card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query flashcards: %w", err)
+	}
+
+	// No cards may also mean the material is missing or someone else's
+	if len(flashcards) == 0 {
+		if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+			return nil, err
+		}
+	}
+
+	return flashcards, nil
+}
+
+// GetReviewQueue returns the user's due cards across all materials. Cards are
+// interleaved round-robin between materials (materials with the oldest due card
+// first) and ordered by due date within a material. Sibling cards of a note are
+// never queued together.
+func (s *SQLiteStore) GetReviewQueue(ctx context.Context, userID string, filter ReviewQueueFilter) ([]*learning.Flashcard, int32, error) {
+	log.Printf("[Store.GetReviewQueue] Querying queue for userID: %s, filter: %+v", userID, filter)
+	query := `
+		WITH matching AS (
+			SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, f.ease_factor, f.interval_days, f.repetitions,
+			       f.stability, f.difficulty, f.last_reviewed_at, f.flag, f.card_type, COALESCE(f.note_id, '') AS note_id,
+			       f.cloze_index, m.title, m.id AS material_id,
+			       ROW_NUMBER() OVER (PARTITION BY COALESCE(f.note_id, f.id) ORDER BY f.next_review_at, f.id) AS sibling_rank
+			FROM flashcards f
+			JOIN materials m ON f.material_id = m.id
+			WHERE m.user_id = $1 AND f.next_review_at < $7 AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+			  AND ` + sqliteActiveFlashcard + `
+			  AND (json_array_length($2) = 0 OR m.id IN (SELECT value FROM json_each($2)))
+			  AND (json_array_length($3) = 0 OR EXISTS (
+			      SELECT 1 FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+			      WHERE mt.material_id = m.id AND t.name IN (SELECT value FROM json_each($3))))
+		),
+		due AS (
+			-- Only the earliest due card of each note, so siblings never share a day
+			SELECT *, last_reviewed_at IS NULL AS is_new,
+			       ROW_NUMBER() OVER (PARTITION BY material_id ORDER BY next_review_at, id) AS material_rank,
+			       MIN(next_review_at) OVER (PARTITION BY material_id) AS material_first_due
+			FROM matching
+			WHERE sibling_rank = 1
+		),
+		ranked AS (
+			SELECT *, COUNT(*) OVER () AS total_due,
+			       ROW_NUMBER() OVER (PARTITION BY is_new ORDER BY material_rank, material_first_due, material_id) AS kind_rank
+			FROM due
+		)
+		SELECT id, question, answer, stage, next_review_at, ease_factor, interval_days, repetitions,
+		       stability, difficulty, last_reviewed_at, flag, card_type, note_id, cloze_index, title, material_id, total_due,
+		       ` + sqliteMaterialTagsOf("ranked.material_id") + `
+		FROM ranked
+		WHERE (is_new AND kind_rank <= $5) OR (NOT is_new AND kind_rank <= $6)
+		ORDER BY material_rank, material_first_due, material_id
+		LIMIT $4;
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, jsonText(filter.MaterialIDs), jsonText(filter.Tags), filter.Limit,
+		filter.NewLimit, filter.ReviewLimit, toMillis(filter.DueBefore))
+	if err != nil {
+		log.Printf("[Store.GetReviewQueue] Query failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to query review queue: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*learning.Flashcard
+	var totalDue int32
+	for rows.Next() {
+		var card learning.Flashcard
+		var nextReviewAt int64
+		var lastReviewedAt sql.NullInt64
+		var cardType string
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &card.EaseFactor,
+			&card.IntervalDays, &card.Repetitions, &card.Stability, &card.Difficulty, &lastReviewedAt, &card.Flag,
+			&cardType, &card.NoteId, &card.ClozeIndex, &card.MaterialTitle, &card.MaterialId, &totalDue,
+			jsonArray(&card.Tags)); err != nil {
+			log.Printf("[Store.GetReviewQueue] Scan failed: %v", err)
+			return nil, 0, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		card.CardType = cardTypeFromDB(cardType)
+		card.NextReviewAt = timestamppb.New(fromMillis(nextReviewAt))
+		if t := fromNullMillis(lastReviewedAt); t != nil {
+			card.LastReviewedAt = timestamppb.New(*t)
+		}
+
+		cards = append(cards, &card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to query review queue: %w", err)
+	}
+
+	log.Printf("[Store.GetReviewQueue] Found %d cards (total due: %d)", len(cards), totalDue)
+	return cards, totalDue, nil
+}
+
+// UpdateFlashcardContent rewrites a flashcard, and its cloze siblings which
+// share the same text. A rewritten card starts over with no lapses and is no
+// longer a leech. Editing a cloze card adds a sibling for every new cloze
+// number and deletes the siblings whose deletion was removed.
+func (s *SQLiteStore) UpdateFlashcardContent(ctx context.Context, userID, id, question, answer string) error {
+	log.Printf("[Store.UpdateFlashcardContent] Updating flashcard: %s for user: %s", id, userID)
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		query := `
+			UPDATE flashcards
+			SET question = $1, answer = $2, lapses = 0, is_leech = 0, updated_at = ` + sqliteNow + `
+			WHERE material_id IN (
+			      SELECT id FROM materials WHERE user_id = $4 AND (is_deleted = 0 OR is_deleted IS NULL))
+			  AND (id = $3 OR note_id = (SELECT note_id FROM flashcards WHERE id = $3))
+			RETURNING id, material_id, card_type, COALESCE(note_id, ''), cloze_index;
+		`
+		rows, err := tx.db.QueryContext(ctx, query, question, answer, id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update flashcard content: %w", err)
+		}
+		defer rows.Close()
+		var materialID, cardType, noteID string
+		siblings := make(map[string]int32)
+		for rows.Next() {
+			var cardID, cardMaterialID, cardCardType, cardNoteID string
+			var clozeIndex int32
+			if err := rows.Scan(&cardID, &cardMaterialID, &cardCardType, &cardNoteID, &clozeIndex); err != nil {
+				return fmt.Errorf("failed to scan flashcard: %w", err)
+			}
+			siblings[cardID] = clozeIndex
+			if cardID == id {
+				materialID, cardType, noteID = cardMaterialID, cardCardType, cardNoteID
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to update flashcard content: %w", err)
+		}
+		rows.Close()
+		if materialID == "" {
+			return tx.flashcardAccessError(ctx, userID, id)
+		}
+
+		if cardTypeFromDB(cardType) != learning.CardType_CARD_TYPE_CLOZE {
+			return nil
+		}
+		if noteID == "" {
+			noteID = NewUUID()
+			if _, err := tx.db.ExecContext(ctx, `UPDATE flashcards SET note_id = $1 WHERE id = $2`, noteID, id); err != nil {
+				return fmt.Errorf("failed to set note id: %w", err)
+			}
+		}
+		remove, add, err := clozeChanges(siblings, noteID, question, answer)
+		if err != nil {
+			return err
+		}
+		if len(remove) > 0 {
+			if _, err := tx.DeleteFlashcards(ctx, userID, remove); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.insertFlashcards(ctx, materialID, add); err != nil {
+			return err
+		}
+		log.Printf("[Store.UpdateFlashcardContent] Added %d and removed %d cloze siblings", len(add), len(remove))
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.UpdateFlashcardContent] Update failed: %v", err)
+		return err
+	}
+	log.Printf("[Store.UpdateFlashcardContent] Flashcard content updated successfully")
+	return nil
+}
+
+func (s *SQLiteStore) GetDueMaterials(ctx context.Context, userID string, page, pageSize int32) ([]*learning.MaterialSummary, int32, error) {
+	log.Printf("[Store.GetDueMaterials] Querying materials for userID: %s, page: %d, pageSize: %d", userID, page, pageSize)
+
+	countQuery := `
+		SELECT COUNT(*)
+		FROM materials m
+		WHERE m.user_id = $1 AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+	`
+	var totalCount int32
+	if err := s.db.QueryRowContext(ctx, countQuery, userID).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count materials: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	query := `
+		SELECT m.id, m.title, COUNT(CASE WHEN ` + sqliteActiveFlashcard + ` THEN f.id END) AS due_count,
+		       ` + sqliteMaterialTagsOf("m.id") + `
+		FROM materials m
+		LEFT JOIN flashcards f ON m.id = f.material_id
+		WHERE m.user_id = $1 AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+		GROUP BY m.id, m.title
+		ORDER BY m.created_at DESC, m.rowid DESC -- Millisecond times tie for materials added together
+		LIMIT $2 OFFSET $3;
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query materials: %w", err)
+	}
+	defer rows.Close()
+
+	var materials []*learning.MaterialSummary
+	for rows.Next() {
+		var m learning.MaterialSummary
+		if err := rows.Scan(&m.Id, &m.Title, &m.DueCount, jsonArray(&m.Tags)); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan material: %w", err)
+		}
+		materials = append(materials, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to query materials: %w", err)
+	}
+
+	log.Printf("[Store.GetDueMaterials] Found %d materials (total: %d)", len(materials), totalCount)
+	return materials, totalCount, nil
+}
+
+// GetDueFlashcardsCount returns how many cards due before dueBefore have never
+// been reviewed (new) and how many are due for another review.
+func (s *SQLiteStore) GetDueFlashcardsCount(ctx context.Context, userID string, dueBefore time.Time) (int32, int32, error) {
+	log.Printf("[Store.GetDueFlashcardsCount] Counting due flashcards for userID: %s", userID)
+	query := `
+		SELECT COUNT(DISTINCT CASE WHEN f.last_reviewed_at IS NULL THEN COALESCE(f.note_id, f.id) END),
+		       COUNT(DISTINCT CASE WHEN f.last_reviewed_at IS NOT NULL THEN COALESCE(f.note_id, f.id) END)
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE m.user_id = $1 AND f.next_review_at < $2 AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+		  AND ` + sqliteActiveFlashcard + `;
+	`
+	var newCount, reviewCount int32
+	if err := s.db.QueryRowContext(ctx, query, userID, toMillis(dueBefore)).Scan(&newCount, &reviewCount); err != nil {
+		log.Printf("[Store.GetDueFlashcardsCount] Query failed: %v", err)
+		return 0, 0, fmt.Errorf("failed to count due flashcards: %w", err)
+	}
+	log.Printf("[Store.GetDueFlashcardsCount] Found %d new and %d review flashcards due", newCount, reviewCount)
+	return newCount, reviewCount, nil
+}
+
+// RecordReview reschedules a flashcard and appends the review to the log in a single transaction.
+func (s *SQLiteStore) RecordReview(ctx context.Context, schedule FlashcardSchedule, entry *ReviewLog) error {
+	log.Printf("[Store.RecordReview] Updating flashcard: %s, Stage: %d, Ease: %.2f, Interval: %d, NextReviewAt: %v",
+		entry.FlashcardID, schedule.Stage, schedule.EaseFactor, schedule.IntervalDays, schedule.NextReviewAt)
+
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		updateQuery := `
+			UPDATE flashcards
+			SET stage = $1, ease_factor = $2, interval_days = $3, repetitions = $4, stability = $5, difficulty = $6,
+			    next_review_at = $7, last_reviewed_at = $8, lapses = $11, is_leech = $12, suspended = $13,
+			    updated_at = ` + sqliteNow + `
+			WHERE id = $9 AND material_id IN (SELECT id FROM materials WHERE user_id = $10);
+		`
+		result, err := tx.db.ExecContext(ctx, updateQuery, schedule.Stage, schedule.EaseFactor, schedule.IntervalDays, schedule.Repetitions,
+			schedule.Stability, schedule.Difficulty, toMillis(schedule.NextReviewAt), toMillis(schedule.LastReviewedAt),
+			entry.FlashcardID, entry.UserID, schedule.Lapses, schedule.IsLeech, schedule.Suspended)
+		if err != nil {
+			return fmt.Errorf("failed to update flashcard: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return tx.flashcardAccessError(ctx, entry.UserID, entry.FlashcardID)
+		}
+
+		insertQuery := `
+			INSERT INTO review_logs (id, flashcard_id, user_id, grade, scheduler, previous_stage, next_stage,
+			                         previous_due_at, next_due_at, elapsed_days, reviewed_at, client_reviewed_at, session_id,
+			                         previous_ease_factor, previous_interval_days, previous_repetitions,
+			                         previous_stability, previous_difficulty, previous_last_reviewed_at,
+			                         previous_lapses, previous_is_leech, previous_suspended)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULLIF($13, ''), $14, $15, $16, $17, $18, $19, $20, $21, $22);
+		`
+		id := NewUUID()
+		_, err = tx.db.ExecContext(ctx, insertQuery, id, entry.FlashcardID, entry.UserID, int16(entry.Grade), entry.Scheduler,
+			entry.PreviousStage, entry.NextStage, nullableMillis(&entry.PreviousDueAt), nullableMillis(&entry.NextDueAt), entry.ElapsedDays,
+			toMillis(entry.ReviewedAt), nullableMillis(entry.ClientReviewedAt), entry.SessionID,
+			entry.PreviousEaseFactor, entry.PreviousIntervalDays, entry.PreviousRepetitions,
+			entry.PreviousStability, entry.PreviousDifficulty, nullableMillis(entry.PreviousLastReviewedAt),
+			entry.PreviousLapses, entry.PreviousIsLeech, entry.PreviousSuspended)
+		if err != nil {
+			return fmt.Errorf("failed to insert review log: %w", err)
+		}
+		entry.ID = id
+
+		for _, b := range entry.BuriedSiblings {
+			_, err := tx.db.ExecContext(ctx, `
+				INSERT INTO review_log_buried_siblings (review_log_id, flashcard_id, previous_buried_until)
+				VALUES ($1, $2, $3);
+			`, id, b.FlashcardID, nullableMillis(b.PreviousBuriedUntil))
+			if err != nil {
+				return fmt.Errorf("failed to record buried sibling: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.RecordReview] Failed: %v", err)
+		return err
+	}
+	log.Printf("[Store.RecordReview] Review %s recorded successfully", entry.ID)
+	return nil
+}
+
+func (s *SQLiteStore) GetReviewLogs(ctx context.Context, userID string) ([]*ReviewLog, error) {
+	log.Printf("[Store.GetReviewLogs] Querying review logs for userID: %s", userID)
+	query := `
+		SELECT id, flashcard_id, user_id, grade, scheduler, elapsed_days, reviewed_at
+		FROM review_logs
+		WHERE user_id = $1 AND reverted_at IS NULL
+		ORDER BY flashcard_id, reviewed_at;
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[Store.GetReviewLogs] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query review logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*ReviewLog
+	for rows.Next() {
+		var l ReviewLog
+		var grade int16
+		var reviewedAt int64
+		if err := rows.Scan(&l.ID, &l.FlashcardID, &l.UserID, &grade, &l.Scheduler, &l.ElapsedDays, &reviewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		l.Grade = learning.ReviewGrade(grade)
+		l.ReviewedAt = fromMillis(reviewedAt)
+		logs = append(logs, &l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query review logs: %w", err)
+	}
+	log.Printf("[Store.GetReviewLogs] Found %d review logs", len(logs))
+	return logs, nil
+}
+
+func (s *SQLiteStore) GetReviewHistory(ctx context.Context, userID string, filter ReviewHistoryFilter) ([]*learning.ReviewLogEntry, int32, error) {
+	log.Printf("[Store.GetReviewHistory] Querying history for userID: %s, filter: %+v", userID, filter)
+
+	where := `
+		FROM review_logs rl
+		JOIN flashcards f ON rl.flashcard_id = f.id
+		JOIN materials m ON f.material_id = m.id
+		WHERE rl.user_id = $1
+		  AND ($2 = '' OR m.id = $2)
+		  AND ($3 = '' OR EXISTS (
+		      SELECT 1 FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+		      WHERE mt.material_id = m.id AND t.name = $3))
+		  AND ($4 IS NULL OR rl.reviewed_at >= $4)
+		  AND ($5 IS NULL OR rl.reviewed_at < $5)
+	`
+	var from, to any
+	if !filter.From.IsZero() {
+		from = toMillis(filter.From)
+	}
+	if !filter.To.IsZero() {
+		to = toMillis(filter.To)
+	}
+	args := []any{userID, filter.MaterialID, filter.Tag, from, to}
+
+	var totalCount int32
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) "+where, args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count review history: %w", err)
+	}
+
+	query := `
+		SELECT rl.id, rl.flashcard_id, m.id, m.title, f.question, rl.grade, rl.scheduler,
+		       rl.previous_stage, rl.next_stage, rl.previous_due_at, rl.next_due_at,
+		       rl.elapsed_days, rl.reviewed_at, rl.client_reviewed_at, COALESCE(rl.session_id, ''),
+		       rl.reverted_at IS NOT NULL
+	` + where + `
+		ORDER BY rl.reviewed_at DESC, rl.id
+		LIMIT $6 OFFSET $7;
+	`
+	offset := (filter.Page - 1) * filter.PageSize
+	rows, err := s.db.QueryContext(ctx, query, append(args, filter.PageSize, offset)...)
+	if err != nil {
+		log.Printf("[Store.GetReviewHistory] Query failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to query review history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*learning.ReviewLogEntry
+	for rows.Next() {
+		var e learning.ReviewLogEntry
+		var grade int16
+		var previousDueAt, nextDueAt, clientReviewedAt sql.NullInt64
+		var reviewedAt int64
+		if err := rows.Scan(&e.Id, &e.FlashcardId, &e.MaterialId, &e.MaterialTitle, &e.Question, &grade, &e.Scheduler,
+			&e.PreviousStage, &e.NextStage, &previousDueAt, &nextDueAt,
+			&e.ElapsedDays, &reviewedAt, &clientReviewedAt, &e.SessionId, &e.Reverted); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan review log: %w", err)
+		}
+		e.Grade = learning.ReviewGrade(grade)
+		e.ReviewedAt = timestamppb.New(fromMillis(reviewedAt))
+		if t := fromNullMillis(previousDueAt); t != nil {
+			e.PreviousDueAt = timestamppb.New(*t)
+		}
+		if t := fromNullMillis(nextDueAt); t != nil {
+			e.NextDueAt = timestamppb.New(*t)
+		}
+		if t := fromNullMillis(clientReviewedAt); t != nil {
+			e.ClientReviewedAt = timestamppb.New(*t)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to query review history: %w", err)
+	}
+
+	log.Printf("[Store.GetReviewHistory] Found %d entries (total: %d)", len(entries), totalCount)
+	return entries, totalCount, nil
+}
+
+// UndoLastReview reverts the user's most recent review (optionally limited to
+// one session), restoring the card's scheduling state from before that review.
+// It returns the reverted review and how many reviews can still be undone.
+func (s *SQLiteStore) UndoLastReview(ctx context.Context, userID, sessionID string) (*ReviewLog, int32, error) {
+	log.Printf("[Store.UndoLastReview] Undoing last review for userID: %s, sessionID: %s", userID, sessionID)
+
+	var l ReviewLog
+	var remaining int32
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		selectQuery := `
+			SELECT id, flashcard_id, user_id, grade, scheduler, previous_stage, next_stage, previous_due_at, next_due_at,
+			       reviewed_at, COALESCE(session_id, ''), previous_ease_factor, previous_interval_days,
+			       previous_repetitions, previous_stability, previous_difficulty, previous_last_reviewed_at,
+			       previous_lapses, previous_is_leech, previous_suspended
+			FROM review_logs
+			WHERE user_id = $1 AND reverted_at IS NULL AND ($2 = '' OR session_id = $2)
+			ORDER BY reviewed_at DESC
+			LIMIT 1;
+		`
+		var grade int16
+		var reviewedAt int64
+		var previousDueAt, nextDueAt, previousLastReviewedAt sql.NullInt64
+		err := tx.db.QueryRowContext(ctx, selectQuery, userID, sessionID).Scan(&l.ID, &l.FlashcardID, &l.UserID, &grade, &l.Scheduler,
+			&l.PreviousStage, &l.NextStage, &previousDueAt, &nextDueAt, &reviewedAt, &l.SessionID,
+			&l.PreviousEaseFactor, &l.PreviousIntervalDays, &l.PreviousRepetitions,
+			&l.PreviousStability, &l.PreviousDifficulty, &previousLastReviewedAt,
+			&l.PreviousLapses, &l.PreviousIsLeech, &l.PreviousSuspended)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNothingToUndo
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get last review: %w", err)
+		}
+		l.Grade = learning.ReviewGrade(grade)
+		l.ReviewedAt = fromMillis(reviewedAt)
+		l.PreviousLastReviewedAt = fromNullMillis(previousLastReviewedAt)
+		if t := fromNullMillis(previousDueAt); t != nil {
+			l.PreviousDueAt = *t
+		}
+		if t := fromNullMillis(nextDueAt); t != nil {
+			l.NextDueAt = *t
+		}
+
+		// Refuse to undo if the card was reviewed again since, e.g. from another device
+		var superseded bool
+		supersededQuery := `
+			SELECT EXISTS (
+				SELECT 1 FROM review_logs
+				WHERE flashcard_id = $1 AND id <> $2 AND reverted_at IS NULL AND reviewed_at > $3
+			) OR NOT EXISTS (
+				SELECT 1 FROM flashcards
+				WHERE id = $1 AND stage = $4 AND next_review_at IS $5
+			);
+		`
+		if err := tx.db.QueryRowContext(ctx, supersededQuery, l.FlashcardID, l.ID, reviewedAt, l.NextStage, nextDueAt).Scan(&superseded); err != nil {
+			return fmt.Errorf("failed to check for later reviews: %w", err)
+		}
+		if superseded {
+			log.Printf("[Store.UndoLastReview] Review %s was superseded", l.ID)
+			return ErrReviewSuperseded
+		}
+
+		restoreQuery := `
+			UPDATE flashcards
+			SET stage = $1, ease_factor = $2, interval_days = $3, repetitions = $4, stability = $5, difficulty = $6,
+			    next_review_at = COALESCE($7, next_review_at), last_reviewed_at = $8, lapses = $10, is_leech = $11,
+			    suspended = $12, updated_at = ` + sqliteNow + `
+			WHERE id = $9;
+		`
+		if _, err := tx.db.ExecContext(ctx, restoreQuery, l.PreviousStage, l.PreviousEaseFactor, l.PreviousIntervalDays, l.PreviousRepetitions,
+			l.PreviousStability, l.PreviousDifficulty, previousDueAt, previousLastReviewedAt, l.FlashcardID,
+			l.PreviousLapses, l.PreviousIsLeech, l.PreviousSuspended); err != nil {
+			return fmt.Errorf("failed to restore flashcard: %w", err)
+		}
+
+		unburyQuery := `
+			UPDATE flashcards
+			SET buried_until = b.previous_buried_until, updated_at = ` + sqliteNow + `
+			FROM review_log_buried_siblings b
+			WHERE b.review_log_id = $1 AND flashcards.id = b.flashcard_id;
+		`
+		if _, err := tx.db.ExecContext(ctx, unburyQuery, l.ID); err != nil {
+			return fmt.Errorf("failed to restore buried siblings: %w", err)
+		}
+
+		if _, err := tx.db.ExecContext(ctx, `UPDATE review_logs SET reverted_at = `+sqliteNow+` WHERE id = $1`, l.ID); err != nil {
+			return fmt.Errorf("failed to mark review reverted: %w", err)
+		}
+
+		remainingQuery := `
+			SELECT COUNT(*) FROM review_logs
+			WHERE user_id = $1 AND reverted_at IS NULL AND ($2 = '' OR session_id = $2);
+		`
+		if err := tx.db.QueryRowContext(ctx, remainingQuery, userID, sessionID).Scan(&remaining); err != nil {
+			return fmt.Errorf("failed to count remaining reviews: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.UndoLastReview] Failed: %v", err)
+		return nil, 0, err
+	}
+	log.Printf("[Store.UndoLastReview] Review %s reverted, flashcard %s restored to stage %d", l.ID, l.FlashcardID, l.PreviousStage)
+	return &l, remaining, nil
+}
+
+// GetFSRSParams returns the user's fitted FSRS weights, or nil if none have been fitted yet.
+func (s *SQLiteStore) GetFSRSParams(ctx context.Context, userID string) ([]float64, error) {
+	query := `SELECT weights FROM user_fsrs_params WHERE user_id = $1`
+	var weights []float64
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(jsonArray(&weights))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fsrs params: %w", err)
+	}
+	return weights, nil
+}
+
+func (s *SQLiteStore) SaveFSRSParams(ctx context.Context, userID string, weights []float64, reviewCount int32) error {
+	log.Printf("[Store.SaveFSRSParams] Saving %d weights for userID: %s", len(weights), userID)
+	query := `
+		INSERT INTO user_fsrs_params (user_id, weights, review_count, optimized_at)
+		VALUES ($1, $2, $3, ` + sqliteNow + `)
+		ON CONFLICT (user_id) DO UPDATE
+		SET weights = excluded.weights, review_count = excluded.review_count, optimized_at = excluded.optimized_at;
+	`
+	if _, err := s.db.ExecContext(ctx, query, userID, jsonText(weights), reviewCount); err != nil {
+		log.Printf("[Store.SaveFSRSParams] Save failed: %v", err)
+		return fmt.Errorf("failed to save fsrs params: %w", err)
+	}
+	return nil
+}
+
+// GetUsersNeedingOptimization returns users with at least minNewReviews reviews
+// logged since their FSRS weights were last fitted.
+func (s *SQLiteStore) GetUsersNeedingOptimization(ctx context.Context, minNewReviews int) ([]string, error) {
+	query := `
+		SELECT rl.user_id
+		FROM review_logs rl
+		LEFT JOIN user_fsrs_params p ON p.user_id = rl.user_id
+		WHERE rl.reverted_at IS NULL AND (p.optimized_at IS NULL OR rl.reviewed_at > p.optimized_at)
+		GROUP BY rl.user_id
+		HAVING COUNT(*) >= $1;
+	`
+	rows, err := s.db.QueryContext(ctx, query, minNewReviews)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users needing optimization: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// GetStudySettings returns the user's study settings, or nil if they never saved any.
+func (s *SQLiteStore) GetStudySettings(ctx context.Context, userID string) (*learning.StudySettings, error) {
+	query := `
+		SELECT new_cards_per_day, max_reviews_per_day, day_rollover_hour, timezone, leech_threshold, leech_action
+		FROM user_settings
+		WHERE user_id = $1;
+	`
+	var settings learning.StudySettings
+	var leechAction string
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&settings.NewCardsPerDay, &settings.MaxReviewsPerDay, &settings.DayRolloverHour,
+		&settings.Timezone, &settings.LeechThreshold, &leechAction)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("[Store.GetStudySettings] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to get study settings: %w", err)
+	}
+	settings.LeechAction = learning.LeechAction(learning.LeechAction_value["LEECH_ACTION_"+leechAction])
+	return &settings, nil
+}
+
+func (s *SQLiteStore) UpdateStudySettings(ctx context.Context, userID string, settings *learning.StudySettings) error {
+	log.Printf("[Store.UpdateStudySettings] Saving settings for userID: %s: %+v", userID, settings)
+	query := `
+		INSERT INTO user_settings (user_id, new_cards_per_day, max_reviews_per_day, day_rollover_hour, timezone,
+		                           leech_threshold, leech_action, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, ` + sqliteNow + `, ` + sqliteNow + `)
+		ON CONFLICT (user_id) DO UPDATE
+		SET new_cards_per_day = excluded.new_cards_per_day, max_reviews_per_day = excluded.max_reviews_per_day,
+		    day_rollover_hour = excluded.day_rollover_hour, timezone = excluded.timezone,
+		    leech_threshold = excluded.leech_threshold, leech_action = excluded.leech_action, updated_at = excluded.updated_at;
+	`
+	leechAction := strings.TrimPrefix(settings.LeechAction.String(), "LEECH_ACTION_")
+	_, err := s.db.ExecContext(ctx, query, userID, settings.NewCardsPerDay, settings.MaxReviewsPerDay, settings.DayRolloverHour,
+		settings.Timezone, settings.LeechThreshold, leechAction)
+	if err != nil {
+		log.Printf("[Store.UpdateStudySettings] Save failed: %v", err)
+		return fmt.Errorf("failed to save study settings: %w", err)
+	}
+	return nil
+}
+
+// GetStudyCountsSince returns how many new cards the user started and how many
+// reviews of previously seen cards they did since the given time.
+func (s *SQLiteStore) GetStudyCountsSince(ctx context.Context, userID string, since time.Time) (int32, int32, error) {
+	query := `
+		SELECT COUNT(CASE WHEN previous_last_reviewed_at IS NULL THEN 1 END),
+		       COUNT(CASE WHEN previous_last_reviewed_at IS NOT NULL THEN 1 END)
+		FROM review_logs
+		WHERE user_id = $1 AND reviewed_at >= $2 AND reverted_at IS NULL;
+	`
+	var newStudied, reviewsDone int32
+	if err := s.db.QueryRowContext(ctx, query, userID, toMillis(since)).Scan(&newStudied, &reviewsDone); err != nil {
+		log.Printf("[Store.GetStudyCountsSince] Query failed: %v", err)
+		return 0, 0, fmt.Errorf("failed to count reviews: %w", err)
+	}
+	return newStudied, reviewsDone, nil
+}
+
+// GetQuizCards returns up to limit of the user's basic, unsuspended cards in
+// random order, from one material or from all materials with a tag.
+func (s *SQLiteStore) GetQuizCards(ctx context.Context, userID, materialID, tag string, limit int32) ([]*learning.Flashcard, error) {
+	log.Printf("[Store.GetQuizCards] Querying cards for userID: %s, materialID: %s, tag: %s", userID, materialID, tag)
+	query := `
+		SELECT f.id, f.question, f.answer, m.id, m.title
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE m.user_id = $1 AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+		  AND f.card_type = 'BASIC' AND f.suspended = 0
+		  AND ($2 = '' OR m.id = $2)
+		  AND ($3 = '' OR EXISTS (
+		      SELECT 1 FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+		      WHERE mt.material_id = m.id AND t.name = $3))
+		ORDER BY random()
+		LIMIT $4;
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID, materialID, tag, limit)
+	if err != nil {
+		log.Printf("[Store.GetQuizCards] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query quiz cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*learning.Flashcard
+	for rows.Next() {
+		var card learning.Flashcard
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.MaterialId, &card.MaterialTitle); err != nil {
+			log.Printf("[Store.GetQuizCards] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		cards = append(cards, &card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query quiz cards: %w", err)
+	}
+
+	// No cards may also mean the material is missing or someone else's
+	if len(cards) == 0 && materialID != "" {
+		if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("[Store.GetQuizCards] Found %d cards", len(cards))
+	return cards, nil
+}
+
+// CreateQuiz saves a quiz and its questions, filling in the question IDs.
+func (s *SQLiteStore) CreateQuiz(ctx context.Context, userID, materialID, tag string, questions []*QuizQuestion) (string, error) {
+	log.Printf("[Store.CreateQuiz] Creating quiz with %d questions for userID: %s", len(questions), userID)
+
+	quizID := NewUUID()
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		quizQuery := `
+			INSERT INTO quizzes (id, user_id, material_id, tag, created_at)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), ` + sqliteNow + `);
+		`
+		if _, err := tx.db.ExecContext(ctx, quizQuery, quizID, userID, materialID, tag); err != nil {
+			return fmt.Errorf("failed to insert quiz: %w", err)
+		}
+
+		questionQuery := `
+			INSERT INTO quiz_questions (id, quiz_id, flashcard_id, position, question, choices, correct_index)
+			VALUES ($1, $2, $3, $4, $5, $6, $7);
+		`
+		for i, q := range questions {
+			id := NewUUID()
+			_, err := tx.db.ExecContext(ctx, questionQuery, id, quizID, q.FlashcardID, i, q.Question, jsonText(q.Choices), q.CorrectIndex)
+			if err != nil {
+				return fmt.Errorf("failed to insert quiz question: %w", err)
+			}
+			q.ID = id
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.CreateQuiz] Insert failed: %v", err)
+		return "", err
+	}
+	log.Printf("[Store.CreateQuiz] Quiz created with ID: %s", quizID)
+	return quizID, nil
+}
+
+// GetQuizQuestions returns a quiz's questions in order, with the correct answers.
+func (s *SQLiteStore) GetQuizQuestions(ctx context.Context, userID, quizID string) ([]*QuizQuestion, error) {
+	log.Printf("[Store.GetQuizQuestions] Querying quiz: %s for user: %s", quizID, userID)
+	query := `
+		SELECT qq.id, COALESCE(qq.flashcard_id, ''), qq.question, qq.choices, qq.correct_index
+		FROM quiz_questions qq
+		JOIN quizzes q ON qq.quiz_id = q.id
+		WHERE q.id = $1 AND q.user_id = $2
+		ORDER BY qq.position;
+	`
+	rows, err := s.db.QueryContext(ctx, query, quizID, userID)
+	if err != nil {
+		log.Printf("[Store.GetQuizQuestions] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query quiz: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*QuizQuestion
+	for rows.Next() {
+		var q QuizQuestion
+		if err := rows.Scan(&q.ID, &q.FlashcardID, &q.Question, jsonArray(&q.Choices), &q.CorrectIndex); err != nil {
+			log.Printf("[Store.GetQuizQuestions] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan quiz question: %w", err)
+		}
+		questions = append(questions, &q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query quiz: %w", err)
+	}
+
+	// A quiz always has questions, so none means it is missing or someone else's
+	if len(questions) == 0 {
+		if err := s.checkOwner(ctx, `SELECT user_id FROM quizzes WHERE id = $1`, userID, quizID, "quiz"); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("quiz %s: %w", quizID, ErrNotFound)
+	}
+	return questions, nil
+}
+
+// SaveQuizAttempt stores a scored quiz attempt and its answers, filling in its ID.
+func (s *SQLiteStore) SaveQuizAttempt(ctx context.Context, attempt *QuizAttempt) error {
+	log.Printf("[Store.SaveQuizAttempt] Saving attempt for quiz: %s, score: %d/%d", attempt.QuizID, attempt.CorrectCount, attempt.TotalCount)
+
+	id := NewUUID()
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		attemptQuery := `
+			INSERT INTO quiz_attempts (id, quiz_id, user_id, correct_count, total_count, applied_to_schedule, submitted_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7);
+		`
+		_, err := tx.db.ExecContext(ctx, attemptQuery, id, attempt.QuizID, attempt.UserID, attempt.CorrectCount, attempt.TotalCount,
+			attempt.AppliedToSchedule, toMillis(attempt.SubmittedAt))
+		if err != nil {
+			return fmt.Errorf("failed to insert quiz attempt: %w", err)
+		}
+
+		answerQuery := `
+			INSERT INTO quiz_attempt_answers (attempt_id, question_id, chosen_index, correct)
+			VALUES ($1, $2, $3, $4);
+		`
+		for _, a := range attempt.Answers {
+			if _, err := tx.db.ExecContext(ctx, answerQuery, id, a.QuestionID, a.ChosenIndex, a.Correct); err != nil {
+				return fmt.Errorf("failed to insert quiz answer: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.SaveQuizAttempt] Insert failed: %v", err)
+		return err
+	}
+	attempt.ID = id
+	log.Printf("[Store.SaveQuizAttempt] Attempt saved with ID: %s", attempt.ID)
+	return nil
+}
+
+// CreateIngestionJob queues a job, filling in its ID.
+func (s *SQLiteStore) CreateIngestionJob(ctx context.Context, job *IngestionJob) error {
+	log.Printf("[Store.CreateIngestionJob] Queueing %s job for userID: %s", job.Type, job.UserID)
+	query := `
+		INSERT INTO ingestion_jobs (id, user_id, type, content, image_data, allow_cloze, generate_reverse, material_id,
+		                            created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), ` + sqliteNow + `, ` + sqliteNow + `
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ingestion_jobs
+			WHERE material_id = NULLIF($8, '') AND stage NOT IN ('SUCCEEDED', 'FAILED')
+		)
+		RETURNING updated_at;
+	`
+	id := NewUUID()
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx, query, id, job.UserID, job.Type, job.Content, job.ImageData, job.AllowCloze, job.GenerateReverse,
+		job.MaterialID).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("material %s: %w", job.MaterialID, ErrIngestionInProgress)
+	}
+	if err != nil {
+		log.Printf("[Store.CreateIngestionJob] Insert failed: %v", err)
+		return fmt.Errorf("failed to insert ingestion job: %w", err)
+	}
+	job.ID = id
+	job.UpdatedAt = fromMillis(updatedAt)
+	job.Stage = learning.IngestionStage_INGESTION_STAGE_QUEUED
+	log.Printf("[Store.CreateIngestionJob] Job queued with ID: %s", job.ID)
+	return nil
+}
+
+// GetIngestionJob returns a job's progress and result, without its input.
+func (s *SQLiteStore) GetIngestionJob(ctx context.Context, userID, id string) (*IngestionJob, error) {
+	query := `
+		SELECT id, user_id, type, stage, chunk, total_chunks, attempts, COALESCE(material_id, ''),
+		       title, tags, flashcards_created, failed_chunks, error, updated_at
+		FROM ingestion_jobs
+		WHERE id = $1 AND user_id = $2;
+	`
+	var job IngestionJob
+	var stage string
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx, query, id, userID).Scan(&job.ID, &job.UserID, &job.Type, &stage, &job.Chunk, &job.TotalChunks,
+		&job.Attempts, &job.MaterialID, &job.Title, jsonArray(&job.Tags), &job.FlashcardsCreated, jsonArray(&job.FailedChunks),
+		&job.Error, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := s.checkOwner(ctx, `SELECT user_id FROM ingestion_jobs WHERE id = $1`, userID, id, "ingestion job"); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ingestion job %s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("[Store.GetIngestionJob] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to get ingestion job: %w", err)
+	}
+	job.Stage = ingestionStageFromDB(stage)
+	job.UpdatedAt = fromMillis(updatedAt)
+	return &job, nil
+}
+
+// ClaimIngestionJob hands the oldest unfinished job nobody holds a lease on to
+// the caller until leaseUntil, or returns nil if there is none. Jobs that
+// have already been claimed maxAttempts times are failed instead.
+func (s *SQLiteStore) ClaimIngestionJob(ctx context.Context, leaseUntil time.Time, maxAttempts int32) (*IngestionJob, error) {
+	giveUpQuery := `
+		UPDATE ingestion_jobs
+		SET stage = 'FAILED', error = 'processing was interrupted too many times', content = '', image_data = '',
+		    updated_at = ` + sqliteNow + `
+		WHERE stage NOT IN ('SUCCEEDED', 'FAILED') AND lease_expires_at < ` + sqliteNow + ` AND attempts >= $1;
+	`
+	if _, err := s.db.ExecContext(ctx, giveUpQuery, maxAttempts); err != nil {
+		log.Printf("[Store.ClaimIngestionJob] Failing abandoned jobs failed: %v", err)
+		return nil, fmt.Errorf("failed to fail abandoned ingestion jobs: %w", err)
+	}
+
+	// A single statement, so no other worker can claim the job in between
+	query := `
+		UPDATE ingestion_jobs
+		SET attempts = attempts + 1, lease_expires_at = $1, updated_at = ` + sqliteNow + `
+		WHERE id = (
+			SELECT id FROM ingestion_jobs
+			WHERE stage NOT IN ('SUCCEEDED', 'FAILED') AND (lease_expires_at IS NULL OR lease_expires_at < ` + sqliteNow + `)
+			ORDER BY created_at
+			LIMIT 1
+		)
+		RETURNING id, user_id, type, content, image_data, allow_cloze, generate_reverse, COALESCE(material_id, ''),
+		          stage, attempts, updated_at;
+	`
+	var job IngestionJob
+	var stage string
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx, query, toMillis(leaseUntil)).Scan(&job.ID, &job.UserID, &job.Type, &job.Content, &job.ImageData,
+		&job.AllowCloze, &job.GenerateReverse, &job.MaterialID, &stage, &job.Attempts, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("[Store.ClaimIngestionJob] Claim failed: %v", err)
+		return nil, fmt.Errorf("failed to claim ingestion job: %w", err)
+	}
+	job.Stage = ingestionStageFromDB(stage)
+	job.UpdatedAt = fromMillis(updatedAt)
+	job.LeaseExpiresAt = leaseUntil
+	log.Printf("[Store.ClaimIngestionJob] Claimed job %s (attempt %d)", job.ID, job.Attempts)
+	return &job, nil
+}
+
+// UpdateIngestionJob saves a job's progress, result and lease, as long as the
+// job is unfinished and hasn't been claimed again since job was claimed. A
+// finished job's input is dropped, since it is never processed again.
+func (s *SQLiteStore) UpdateIngestionJob(ctx context.Context, job *IngestionJob) error {
+	query := `
+		UPDATE ingestion_jobs
+		SET stage = $2, chunk = $3, total_chunks = $4, lease_expires_at = $5, material_id = NULLIF($6, ''),
+		    title = $7, tags = $8, flashcards_created = $9, failed_chunks = $10, error = $11, updated_at = ` + sqliteNow + `,
+		    content = CASE WHEN $13 THEN '' ELSE content END,
+		    image_data = CASE WHEN $13 THEN '' ELSE image_data END
+		WHERE id = $1 AND attempts = $12 AND stage NOT IN ('SUCCEEDED', 'FAILED')
+		RETURNING updated_at;
+	`
+	var updatedAt int64
+	err := s.db.QueryRowContext(ctx, query, job.ID, ingestionStageToDB(job.Stage), job.Chunk, job.TotalChunks, toMillis(job.LeaseExpiresAt),
+		job.MaterialID, job.Title, jsonText(job.Tags), job.FlashcardsCreated, jsonText(job.FailedChunks), job.Error, job.Attempts,
+		job.Finished()).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("ingestion job %s: %w", job.ID, ErrIngestionLeaseLost)
+	}
+	if err != nil {
+		log.Printf("[Store.UpdateIngestionJob] Update failed: %v", err)
+		return fmt.Errorf("failed to update ingestion job: %w", err)
+	}
+	job.UpdatedAt = fromMillis(updatedAt)
+	return nil
+}
+
+func (s *SQLiteStore) GetMaterialContent(ctx context.Context, userID, materialID string) (string, string, string, error) {
+	log.Printf("[Store.GetMaterialContent] Fetching material: %s for user: %s", materialID, userID)
+	query := `
+		SELECT content, COALESCE(summary, ''), COALESCE(title, '')
+		FROM materials
+		WHERE id = $1 AND user_id = $2 AND (is_deleted = 0 OR is_deleted IS NULL);
+	`
+	var content, summary, title string
+	err := s.db.QueryRowContext(ctx, query, materialID, userID).Scan(&content, &summary, &title)
+	if err != nil {
+		log.Printf("[Store.GetMaterialContent] Query failed: %v", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", "", s.materialAccessError(ctx, userID, materialID)
+		}
+		return "", "", "", fmt.Errorf("failed to get material content: %w", err)
+	}
+	log.Printf("[Store.GetMaterialContent] Found material, content length: %d, has summary: %v", len(content), summary != "")
+	return content, summary, title, nil
+}
+
+func (s *SQLiteStore) UpdateMaterialSummary(ctx context.Context, userID, materialID, summary string) error {
+	log.Printf("[Store.UpdateMaterialSummary] Updating summary for material: %s", materialID)
+	query := `
+		UPDATE materials
+		SET summary = $1, updated_at = ` + sqliteNow + `
+		WHERE id = $2 AND user_id = $3;
+	`
+	result, err := s.db.ExecContext(ctx, query, summary, materialID, userID)
+	if err != nil {
+		log.Printf("[Store.UpdateMaterialSummary] Update failed: %v", err)
+		return fmt.Errorf("failed to update material summary: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return s.materialAccessError(ctx, userID, materialID)
+	}
+	log.Printf("[Store.UpdateMaterialSummary] Summary updated successfully")
+	return nil
+}
+
+// UpdateMaterialTitle renames a material the user owns.
+func (s *SQLiteStore) UpdateMaterialTitle(ctx context.Context, userID, materialID, title string) error {
+	log.Printf("[Store.UpdateMaterialTitle] Updating title for material: %s", materialID)
+	query := `
+		UPDATE materials
+		SET title = $1, updated_at = ` + sqliteNow + `
+		WHERE id = $2 AND user_id = $3;
+	`
+	result, err := s.db.ExecContext(ctx, query, title, materialID, userID)
+	if err != nil {
+		log.Printf("[Store.UpdateMaterialTitle] Update failed: %v", err)
+		return fmt.Errorf("failed to update material title: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return s.materialAccessError(ctx, userID, materialID)
+	}
+	return nil
+}
+
+// CreateMaterialChunks stores the sections a material's content was split
+// into, all pending generation.
+func (s *SQLiteStore) CreateMaterialChunks(ctx context.Context, materialID string, chunks []string) error {
+	log.Printf("[Store.CreateMaterialChunks] Inserting %d chunks for material: %s", len(chunks), materialID)
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		query := `
+			INSERT INTO material_chunks (material_id, chunk_index, content, updated_at)
+			VALUES ($1, $2, $3, ` + sqliteNow + `);
+		`
+		for i, content := range chunks {
+			if _, err := tx.db.ExecContext(ctx, query, materialID, i, content); err != nil {
+				log.Printf("[Store.CreateMaterialChunks] Failed to insert chunk %d: %v", i, err)
+				return fmt.Errorf("failed to insert material chunk: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetMaterialChunks returns a material's chunks in order. Materials added
+// before chunks were recorded have none.
+func (s *SQLiteStore) GetMaterialChunks(ctx context.Context, userID, materialID string) ([]*MaterialChunk, error) {
+	if err := s.checkMaterialOwner(ctx, userID, materialID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT chunk_index, content, status, error, flashcards_created
+		FROM material_chunks
+		WHERE material_id = $1
+		ORDER BY chunk_index;
+	`
+	rows, err := s.db.QueryContext(ctx, query, materialID)
+	if err != nil {
+		log.Printf("[Store.GetMaterialChunks] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query material chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []*MaterialChunk
+	for rows.Next() {
+		var c MaterialChunk
+		if err := rows.Scan(&c.Index, &c.Content, &c.Status, &c.Error, &c.FlashcardsCreated); err != nil {
+			log.Printf("[Store.GetMaterialChunks] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan material chunk: %w", err)
+		}
+		chunks = append(chunks, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query material chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// SaveChunkFlashcards adds the cards generated from a chunk and marks the
+// chunk done in one transaction, returning how many cards were added. Cards
+// that repeat one already in the material are skipped, and nothing is added
+// for a chunk that is already done.
+func (s *SQLiteStore) SaveChunkFlashcards(ctx context.Context, materialID string, index int32, cards []*learning.Flashcard) (int32, error) {
+	log.Printf("[Store.SaveChunkFlashcards] Saving %d flashcards for material: %s, chunk: %d", len(cards), materialID, index)
+	var created int32
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		var status string
+		statusQuery := `SELECT status FROM material_chunks WHERE material_id = $1 AND chunk_index = $2`
+		err := tx.db.QueryRowContext(ctx, statusQuery, materialID, index).Scan(&status)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("material %s chunk %d: %w", materialID, index, ErrNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get material chunk: %w", err)
+		}
+		if status == ChunkDone {
+			log.Printf("[Store.SaveChunkFlashcards] Chunk %d already done, skipping", index)
+			return nil
+		}
+
+		// Leave out cards that repeat one already in the material or earlier in this chunk
+		rows, err := tx.db.QueryContext(ctx, `SELECT question, card_type, cloze_index FROM flashcards WHERE material_id = $1`, materialID)
+		if err != nil {
+			return fmt.Errorf("failed to query material flashcards: %w", err)
+		}
+		seen := make(map[string]bool)
+		for rows.Next() {
+			var question, cardType string
+			var clozeIndex int32
+			if err := rows.Scan(&question, &cardType, &clozeIndex); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan material flashcard: %w", err)
+			}
+			seen[flashcardKey(question, cardType, clozeIndex)] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to query material flashcards: %w", err)
+		}
+
+		var unique []*learning.Flashcard
+		for _, card := range cards {
+			key := flashcardKey(card.Question, cardTypeToDB(card.CardType), card.ClozeIndex)
+			if !seen[key] {
+				seen[key] = true
+				unique = append(unique, card)
+			}
+		}
+		if _, err := tx.insertFlashcards(ctx, materialID, unique); err != nil {
+			return err
+		}
+		created = int32(len(unique))
+
+		doneQuery := `
+			UPDATE material_chunks
+			SET status = 'DONE', error = '', flashcards_created = $3, updated_at = ` + sqliteNow + `
+			WHERE material_id = $1 AND chunk_index = $2;
+		`
+		if _, err := tx.db.ExecContext(ctx, doneQuery, materialID, index, created); err != nil {
+			return fmt.Errorf("failed to mark material chunk done: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.SaveChunkFlashcards] Failed: %v", err)
+		return 0, err
+	}
+	log.Printf("[Store.SaveChunkFlashcards] Added %d flashcards (%d duplicates skipped)", created, int32(len(cards))-created)
+	return created, nil
+}
+
+// FailMaterialChunk records why generating a chunk's cards failed.
+func (s *SQLiteStore) FailMaterialChunk(ctx context.Context, materialID string, index int32, reason string) error {
+	query := `
+		UPDATE material_chunks
+		SET status = 'FAILED', error = $3, updated_at = ` + sqliteNow + `
+		WHERE material_id = $1 AND chunk_index = $2 AND status <> 'DONE';
+	`
+	if _, err := s.db.ExecContext(ctx, query, materialID, index, reason); err != nil {
+		log.Printf("[Store.FailMaterialChunk] Update failed: %v", err)
+		return fmt.Errorf("failed to mark material chunk failed: %w", err)
+	}
+	return nil
+}
+
+// GetLeeches returns the user's leech cards, most lapsed first, including
+// ones that have been suspended.
+func (s *SQLiteStore) GetLeeches(ctx context.Context, userID string) ([]*learning.Flashcard, error) {
+	log.Printf("[Store.GetLeeches] Querying leeches for userID: %s", userID)
+	query := `
+		SELECT f.id, f.question, f.answer, f.stage, f.next_review_at, f.last_reviewed_at, f.suspended, f.buried_until,
+		       f.flag, f.lapses, f.is_leech, f.card_type, COALESCE(f.note_id, ''), f.cloze_index, m.title, m.id,
+		       ` + sqliteMaterialTagsOf("m.id") + `
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE m.user_id = $1 AND f.is_leech AND (m.is_deleted = 0 OR m.is_deleted IS NULL)
+		ORDER BY f.lapses DESC, f.id;
+	`
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("[Store.GetLeeches] Query failed: %v", err)
+		return nil, fmt.Errorf("failed to query leeches: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*learning.Flashcard
+	for rows.Next() {
+		var card learning.Flashcard
+		var nextReviewAt int64
+		var lastReviewedAt, buriedUntil sql.NullInt64
+		var suspended bool
+		var cardType string
+		if err := rows.Scan(&card.Id, &card.Question, &card.Answer, &card.Stage, &nextReviewAt, &lastReviewedAt, &suspended,
+			&buriedUntil, &card.Flag, &card.Lapses, &card.IsLeech, &cardType, &card.NoteId, &card.ClozeIndex,
+			&card.MaterialTitle, &card.MaterialId, jsonArray(&card.Tags)); err != nil {
+			log.Printf("[Store.GetLeeches] Scan failed: %v", err)
+			return nil, fmt.Errorf("failed to scan flashcard: %w", err)
+		}
+		card.CardType = cardTypeFromDB(cardType)
+		card.NextReviewAt = timestamppb.New(fromMillis(nextReviewAt))
+		if t := fromNullMillis(lastReviewedAt); t != nil {
+			card.LastReviewedAt = timestamppb.New(*t)
+		}
+		setFlashcardStatus(&card, suspended, fromNullMillis(buriedUntil))
+
+		cards = append(cards, &card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query leeches: %w", err)
+	}
+
+	log.Printf("[Store.GetLeeches] Found %d leeches", len(cards))
+	return cards, nil
+}
+
+// SetFlashcardSuspended suspends or unsuspends a flashcard.
+func (s *SQLiteStore) SetFlashcardSuspended(ctx context.Context, userID, id string, suspended bool) error {
+	log.Printf("[Store.SetFlashcardSuspended] Flashcard: %s, user: %s, suspended: %v", id, userID, suspended)
+	return s.updateFlashcardState(ctx, userID, id, "suspended = $1", suspended)
+}
+
+// SetFlashcardBuriedUntil hides a flashcard from reviews until the given time,
+// or unburies it when until is nil.
+func (s *SQLiteStore) SetFlashcardBuriedUntil(ctx context.Context, userID, id string, until *time.Time) error {
+	log.Printf("[Store.SetFlashcardBuriedUntil] Flashcard: %s, user: %s, until: %v", id, userID, until)
+	return s.updateFlashcardState(ctx, userID, id, "buried_until = $1", nullableMillis(until))
+}
+
+// BuryFlashcardSiblings buries the other cards sharing a note with the given
+// card until the given time, unless they are already buried for longer, and
+// returns the cards it buried with when they were buried until before.
+func (s *SQLiteStore) BuryFlashcardSiblings(ctx context.Context, userID, id string, until time.Time) ([]BuriedSibling, error) {
+	log.Printf("[Store.BuryFlashcardSiblings] Burying siblings of flashcard: %s until %v", id, until)
+	var buried []BuriedSibling
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		selectQuery := `
+			SELECT id, buried_until FROM flashcards
+			WHERE note_id = (SELECT note_id FROM flashcards WHERE id = $2) AND id <> $2
+			  AND material_id IN (SELECT id FROM materials WHERE user_id = $3)
+			  AND (buried_until IS NULL OR buried_until < $1);
+		`
+		rows, err := tx.db.QueryContext(ctx, selectQuery, toMillis(until), id, userID)
+		if err != nil {
+			return fmt.Errorf("failed to query siblings: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var b BuriedSibling
+			var buriedUntil sql.NullInt64
+			if err := rows.Scan(&b.FlashcardID, &buriedUntil); err != nil {
+				return fmt.Errorf("failed to scan sibling: %w", err)
+			}
+			b.PreviousBuriedUntil = fromNullMillis(buriedUntil)
+			buried = append(buried, b)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to query siblings: %w", err)
+		}
+		rows.Close()
+
+		for _, b := range buried {
+			_, err := tx.db.ExecContext(ctx, `UPDATE flashcards SET buried_until = $1, updated_at = `+sqliteNow+` WHERE id = $2`,
+				toMillis(until), b.FlashcardID)
+			if err != nil {
+				return fmt.Errorf("failed to bury sibling: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[Store.BuryFlashcardSiblings] Update failed: %v", err)
+		return nil, err
+	}
+	log.Printf("[Store.BuryFlashcardSiblings] Buried %d siblings", len(buried))
+	return buried, nil
+}
+
+func (s *SQLiteStore) SetFlashcardFlag(ctx context.Context, userID, id string, flag int32) error {
+	log.Printf("[Store.SetFlashcardFlag] Flashcard: %s, user: %s, flag: %d", id, userID, flag)
+	return s.updateFlashcardState(ctx, userID, id, "flag = $1", flag)
+}
+
+// updateFlashcardState applies a single-column SET clause (using $1 for value)
+// to a flashcard the user owns.
+func (s *SQLiteStore) updateFlashcardState(ctx context.Context, userID, id, set string, value any) error {
+	query := `
+		UPDATE flashcards
+		SET ` + set + `, updated_at = ` + sqliteNow + `
+		WHERE id = $2 AND material_id IN (
+		      SELECT id FROM materials WHERE user_id = $3 AND (is_deleted = 0 OR is_deleted IS NULL));
+	`
+	result, err := s.db.ExecContext(ctx, query, value, id, userID)
+	if err != nil {
+		log.Printf("[Store.updateFlashcardState] Update failed: %v", err)
+		return fmt.Errorf("failed to update flashcard: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return s.flashcardAccessError(ctx, userID, id)
+	}
+	return nil
+}
+
+// sqliteActiveFlashcard matches flashcards (aliased f) that are neither
+// suspended nor buried, and so can come up for review.
+const sqliteActiveFlashcard = `f.suspended = 0 AND (f.buried_until IS NULL OR f.buried_until <= ` + sqliteNow + `)`
+
+// sqliteMaterialTagsOf selects the names of the tags of the material whose ID
+// is in column col as a JSON array, so listings get tags without a query per row.
+func sqliteMaterialTagsOf(col string) string {
+	return `(
+		SELECT json_group_array(name) FROM (
+			SELECT t.name
+			FROM material_tags mt JOIN tags t ON t.id = mt.tag_id
+			WHERE mt.material_id = ` + col + `
+			ORDER BY t.name))`
+}
+
+// flashcardAccessError explains why a flashcard could not be found for a user:
+// ErrPermissionDenied if it belongs to someone else, ErrNotFound otherwise.
+func (s *SQLiteStore) flashcardAccessError(ctx context.Context, userID, id string) error {
+	query := `
+		SELECT m.user_id
+		FROM flashcards f
+		JOIN materials m ON f.material_id = m.id
+		WHERE f.id = $1 AND (m.is_deleted = 0 OR m.is_deleted IS NULL);
+	`
+	if err := s.checkOwner(ctx, query, userID, id, "flashcard"); err != nil {
+		return err
+	}
+	return fmt.Errorf("flashcard %s: %w", id, ErrNotFound)
+}
+
+// materialAccessError explains why a material could not be found for a user:
+// ErrPermissionDenied if it belongs to someone else, ErrNotFound otherwise.
+func (s *SQLiteStore) materialAccessError(ctx context.Context, userID, id string) error {
+	if err := s.checkMaterialOwner(ctx, userID, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("material %s: %w", id, ErrNotFound)
+}
+
+// checkMaterialOwner returns nil if the material exists and belongs to the user.
+func (s *SQLiteStore) checkMaterialOwner(ctx context.Context, userID, id string) error {
+	query := `SELECT user_id FROM materials WHERE id = $1 AND (is_deleted = 0 OR is_deleted IS NULL)`
+	return s.checkOwner(ctx, query, userID, id, "material")
+}
+
+// checkOwner looks up a record's owner with ownerQuery and returns nil if it
+// is userID, ErrPermissionDenied if it is someone else and ErrNotFound if the
+// record does not exist.
+func (s *SQLiteStore) checkOwner(ctx context.Context, ownerQuery, userID, id, kind string) error {
+	var ownerID string
+	err := s.db.QueryRowContext(ctx, ownerQuery, id).Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %s: %w", kind, id, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", kind, err)
+	}
+	if ownerID != userID {
+		log.Printf("[Store] User %s attempted to access %s %s owned by %s", userID, kind, id, ownerID)
+		return fmt.Errorf("%s %s: %w", kind, id, ErrPermissionDenied)
+	}
+	return nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+// nullableMillis returns a time to store in a nullable column: NULL for a
+// nil or zero time.
+func nullableMillis(t *time.Time) any {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.UnixMilli()
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.UnixMilli(ms)
+}
+
+func fromNullMillis(ms sql.NullInt64) *time.Time {
+	if !ms.Valid {
+		return nil
+	}
+	t := time.UnixMilli(ms.Int64)
+	return &t
+}
+
+// jsonText encodes a slice for a JSON array column, with nil as an empty array.
+func jsonText[T any](v []T) string {
+	if v == nil {
+		return "[]"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// jsonScanner decodes a JSON array column into the slice it points to.
+type jsonScanner[T any] struct {
+	dest *[]T
+}
+
+func jsonArray[T any](dest *[]T) sql.Scanner {
+	return jsonScanner[T]{dest: dest}
+}
+
+func (j jsonScanner[T]) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*j.dest = nil
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into a JSON array", src)
+	}
+	return json.Unmarshal(b, j.dest)
+}
+
+var (
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*SQLiteStore)(nil)
+)
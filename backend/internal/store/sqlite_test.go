@@ -0,0 +1,141 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/amityadav/landr/db"
+	"github.com/amityadav/landr/internal/migrate"
+)
+
+// TestSQLiteMigrationsMatchPostgres checks every Postgres migration after the
+// SQLite baseline has a SQLite counterpart, so the two schemas can't drift.
+func TestSQLiteMigrationsMatchPostgres(t *testing.T) {
+	postgres, err := migrate.Load(db.Migrations, "migrations")
+	if err != nil {
+		t.Fatalf("load Postgres migrations: %v", err)
+	}
+	sqlite, err := migrate.Load(db.SQLiteMigrations, "sqlite")
+	if err != nil {
+		t.Fatalf("load SQLite migrations: %v", err)
+	}
+
+	baseline := sqlite[0].Version
+	var want []int64
+	for _, m := range postgres {
+		if m.Version >= baseline {
+			want = append(want, m.Version)
+		}
+	}
+	var got []int64
+	for _, m := range sqlite {
+		got = append(got, m.Version)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("SQLite migrations %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SQLite migrations %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSQLiteSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	migrations, err := migrate.Load(db.SQLiteMigrations, "sqlite")
+	if err != nil {
+		t.Fatalf("migrate.Load: %v", err)
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	tests := []struct {
+		name    string
+		version int64 // Set on the database before reopening it
+		wantErr bool
+	}{
+		{"up to date", latest, false},
+		{"unknown version", latest + 1000, true},
+		{"older than the baseline", migrations[0].Version - 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "landr.db")
+			s, err := NewSQLiteStore(ctx, path)
+			if err != nil {
+				t.Fatalf("NewSQLiteStore: %v", err)
+			}
+			if got := sqliteUserVersion(t, s.conn); got != latest {
+				t.Errorf("new database at version %d, want %d", got, latest)
+			}
+			if _, err := s.conn.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, tt.version)); err != nil {
+				t.Fatalf("set user_version: %v", err)
+			}
+			s.Close()
+
+			s, err = NewSQLiteStore(ctx, path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reopen: error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				s.Close()
+			}
+		})
+	}
+}
+
+func sqliteUserVersion(t *testing.T, conn *sql.DB) int64 {
+	t.Helper()
+	var version int64
+	if err := conn.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("read user_version: %v", err)
+	}
+	return version
+}
+
+// TestSQLiteUpgradesStepByStep checks a database left at an older version
+// gets the migrations after it applied, keeping its data.
+func TestSQLiteUpgradesStepByStep(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "landr.db")
+	s, err := NewSQLiteStore(ctx, path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	user, err := s.CreateUser(ctx, "old@example.com", "Old", "google-old", "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	// Take the database back to the baseline schema
+	for _, stmt := range []string{`DROP TABLE review_log_buried_siblings`, `PRAGMA user_version = 15`} {
+		if _, err := s.conn.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	s.Close()
+
+	s, err = NewSQLiteStore(ctx, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	migrations, err := migrate.Load(db.SQLiteMigrations, "sqlite")
+	if err != nil {
+		t.Fatalf("migrate.Load: %v", err)
+	}
+	if got, want := sqliteUserVersion(t, s.conn), migrations[len(migrations)-1].Version; got != want {
+		t.Errorf("upgraded database at version %d, want %d", got, want)
+	}
+	if _, err := s.conn.ExecContext(ctx, `SELECT COUNT(*) FROM review_log_buried_siblings`); err != nil {
+		t.Errorf("review_log_buried_siblings missing after upgrade: %v", err)
+	}
+	got, err := s.GetUserByGoogleID(ctx, "google-old")
+	if err != nil || got.Id != user.Id {
+		t.Errorf("GetUserByGoogleID after upgrade = %v, %v, want %s", got, err, user.Id)
+	}
+}
@@ -2,12 +2,187 @@ package store
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/amityadav/landr/internal/cloze"
 	"github.com/amityadav/landr/pkg/pb/auth"
 	"github.com/amityadav/landr/pkg/pb/learning"
 )
 
+// FlashcardSchedule is the spaced repetition state persisted for a flashcard after a review.
+type FlashcardSchedule struct {
+	Stage          int32
+	EaseFactor     float64
+	IntervalDays   int32
+	Repetitions    int32
+	Stability      float64
+	Difficulty     float64
+	NextReviewAt   time.Time
+	LastReviewedAt time.Time
+	Lapses         int32
+	IsLeech        bool
+	Suspended      bool
+}
+
+// ReviewLog records a single graded review of a flashcard.
+type ReviewLog struct {
+	ID               string
+	FlashcardID      string
+	UserID           string
+	Grade            learning.ReviewGrade
+	Scheduler        string
+	PreviousStage    int32
+	NextStage        int32
+	PreviousDueAt    time.Time
+	NextDueAt        time.Time
+	ElapsedDays      float64
+	ReviewedAt       time.Time
+	ClientReviewedAt *time.Time
+	SessionID        string
+
+	// Scheduling state before the review, restored by UndoLastReview
+	PreviousEaseFactor     float64
+	PreviousIntervalDays   int32
+	PreviousRepetitions    int32
+	PreviousStability      float64
+	PreviousDifficulty     float64
+	PreviousLastReviewedAt *time.Time
+	PreviousLapses         int32
+	PreviousIsLeech        bool
+	PreviousSuspended      bool
+
+	// Sibling cards the review buried, put back by UndoLastReview
+	BuriedSiblings []BuriedSibling
+}
+
+// BuriedSibling is a card buried because a card of the same note was
+// reviewed, and when it was buried until before (nil if it wasn't).
+type BuriedSibling struct {
+	FlashcardID         string
+	PreviousBuriedUntil *time.Time
+}
+
+// ReviewHistoryFilter narrows down a user's review history. Zero values are ignored.
+type ReviewHistoryFilter struct {
+	MaterialID string
+	Tag        string
+	From       time.Time
+	To         time.Time
+	Page       int32
+	PageSize   int32
+}
+
+var (
+	// ErrNotFound is returned when a record does not exist (or has been deleted).
+	ErrNotFound = errors.New("not found")
+	// ErrPermissionDenied is returned when a record exists but belongs to another user.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrNothingToUndo is returned when there is no review left to undo.
+	ErrNothingToUndo = errors.New("no review to undo")
+	// ErrReviewSuperseded is returned when a card was reviewed again after the review being undone.
+	ErrReviewSuperseded = errors.New("review was superseded by a later review")
+	// ErrIngestionInProgress is returned when a material already has an unfinished ingestion job.
+	ErrIngestionInProgress = errors.New("material is still being processed")
+	// ErrIngestionLeaseLost is returned when saving a job the worker no longer
+	// owns, because it finished or was claimed again after the lease ran out.
+	ErrIngestionLeaseLost = errors.New("ingestion job is no longer leased to this worker")
+	// ErrNoFailedChunks is returned when every chunk of a material already has its cards.
+	ErrNoFailedChunks = errors.New("no failed chunks to retry")
+	// ErrNoClozeDeletions is returned when a cloze card is edited to have no deletions left.
+	ErrNoClozeDeletions = errors.New("cloze text needs at least one {{c1::...}} deletion")
+)
+
+// ReviewQueueFilter narrows down the due cards in a review queue. Empty slices are ignored.
+type ReviewQueueFilter struct {
+	MaterialIDs []string
+	Tags        []string
+	Limit       int32
+	NewLimit    int32     // Maximum never-reviewed cards
+	ReviewLimit int32     // Maximum previously reviewed cards
+	DueBefore   time.Time // Cards due before this time are included
+}
+
+// QuizQuestion is a multiple-choice question built from a flashcard.
+type QuizQuestion struct {
+	ID           string
+	FlashcardID  string
+	Question     string
+	Choices      []string
+	CorrectIndex int32
+}
+
+// QuizAttempt is a user's scored submission of a quiz.
+type QuizAttempt struct {
+	ID                string
+	QuizID            string
+	UserID            string
+	Answers           []QuizAttemptAnswer
+	CorrectCount      int32
+	TotalCount        int32
+	AppliedToSchedule bool
+	SubmittedAt       time.Time
+}
+
+// QuizAttemptAnswer is the choice made for one question of a quiz attempt.
+type QuizAttemptAnswer struct {
+	QuestionID  string
+	ChosenIndex int32 // -1 if unanswered
+	Correct     bool
+}
+
+// IngestionJob is material queued to be turned into flashcards in the background,
+// with its progress and, once finished, its result.
+type IngestionJob struct {
+	ID              string
+	UserID          string
+	Type            string
+	Content         string
+	ImageData       string
+	AllowCloze      bool
+	GenerateReverse bool
+
+	Stage          learning.IngestionStage
+	Chunk          int32
+	TotalChunks    int32
+	Attempts       int32     // Also identifies the claim, so a worker can't save a job claimed again since
+	LeaseExpiresAt time.Time // The claiming worker owns the job until then
+
+	MaterialID        string // Set once the material is saved; a claimed job with one resumes its unfinished chunks
+	Title             string
+	Tags              []string
+	FlashcardsCreated int32
+	FailedChunks      []int32 // 1-based, as reported to clients
+	Error             string
+	UpdatedAt         time.Time
+}
+
+// Finished reports whether the job has reached a terminal stage.
+func (j *IngestionJob) Finished() bool {
+	return j.Stage == learning.IngestionStage_INGESTION_STAGE_SUCCEEDED || j.Stage == learning.IngestionStage_INGESTION_STAGE_FAILED
+}
+
+// Statuses of a MaterialChunk.
+const (
+	ChunkPending = "PENDING"
+	ChunkDone    = "DONE"
+	ChunkFailed  = "FAILED"
+)
+
+// MaterialChunk is one section of a material's content that flashcards are
+// generated from separately.
+type MaterialChunk struct {
+	Index             int32 // 0-based
+	Content           string
+	Status            string
+	Error             string
+	FlashcardsCreated int32
+}
+
 type Store interface {
 	// User
 	CreateUser(ctx context.Context, email, name, googleID, picture string) (*auth.UserProfile, error)
@@ -24,18 +199,123 @@ type Store interface {
 	GetMaterialTags(ctx context.Context, materialID string) ([]string, error)
 
 	// Flashcard
-	CreateFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) error
-	GetFlashcard(ctx context.Context, id string) (*learning.Flashcard, error)
+	CreateFlashcards(ctx context.Context, materialID string, cards []*learning.Flashcard) ([]string, error)
+	AddFlashcards(ctx context.Context, userID, materialID string, cards []*learning.Flashcard) ([]string, error)
+	DeleteFlashcards(ctx context.Context, userID string, ids []string) (int32, error)
+	GetFlashcard(ctx context.Context, userID, id string) (*learning.Flashcard, error)
 	GetDueFlashcards(ctx context.Context, userID, materialID string) ([]*learning.Flashcard, error)
 	GetDueMaterials(ctx context.Context, userID string, page, pageSize int32) ([]*learning.MaterialSummary, int32, error)
-	GetDueFlashcardsCount(ctx context.Context, userID string) (int32, error)
-	UpdateFlashcard(ctx context.Context, id string, stage int32, nextReviewAt time.Time) error
-	UpdateFlashcardContent(ctx context.Context, id, question, answer string) error
+	GetDueFlashcardsCount(ctx context.Context, userID string, dueBefore time.Time) (newCount int32, reviewCount int32, err error)
+	GetReviewQueue(ctx context.Context, userID string, filter ReviewQueueFilter) ([]*learning.Flashcard, int32, error)
+	UpdateFlashcardContent(ctx context.Context, userID, id, question, answer string) error
+	SetFlashcardSuspended(ctx context.Context, userID, id string, suspended bool) error
+	SetFlashcardBuriedUntil(ctx context.Context, userID, id string, until *time.Time) error
+	BuryFlashcardSiblings(ctx context.Context, userID, id string, until time.Time) ([]BuriedSibling, error)
+	SetFlashcardFlag(ctx context.Context, userID, id string, flag int32) error
+	GetLeeches(ctx context.Context, userID string) ([]*learning.Flashcard, error)
+
+	// Review Logs & Scheduling
+	RecordReview(ctx context.Context, schedule FlashcardSchedule, entry *ReviewLog) error
+	GetReviewLogs(ctx context.Context, userID string) ([]*ReviewLog, error)
+	GetReviewHistory(ctx context.Context, userID string, filter ReviewHistoryFilter) ([]*learning.ReviewLogEntry, int32, error)
+	UndoLastReview(ctx context.Context, userID, sessionID string) (*ReviewLog, int32, error)
+	GetFSRSParams(ctx context.Context, userID string) ([]float64, error)
+	SaveFSRSParams(ctx context.Context, userID string, weights []float64, reviewCount int32) error
+	GetUsersNeedingOptimization(ctx context.Context, minNewReviews int) ([]string, error)
+
+	// Study Settings
+	GetStudySettings(ctx context.Context, userID string) (*learning.StudySettings, error)
+	UpdateStudySettings(ctx context.Context, userID string, settings *learning.StudySettings) error
+	GetStudyCountsSince(ctx context.Context, userID string, since time.Time) (newStudied int32, reviewsDone int32, err error)
+
+	// Quizzes
+	GetQuizCards(ctx context.Context, userID, materialID, tag string, limit int32) ([]*learning.Flashcard, error)
+	CreateQuiz(ctx context.Context, userID, materialID, tag string, questions []*QuizQuestion) (string, error)
+	GetQuizQuestions(ctx context.Context, userID, quizID string) ([]*QuizQuestion, error)
+	SaveQuizAttempt(ctx context.Context, attempt *QuizAttempt) error
+
+	// Material Chunks
+	UpdateMaterialTitle(ctx context.Context, userID, materialID, title string) error
+	CreateMaterialChunks(ctx context.Context, materialID string, chunks []string) error
+	GetMaterialChunks(ctx context.Context, userID, materialID string) ([]*MaterialChunk, error)
+	SaveChunkFlashcards(ctx context.Context, materialID string, index int32, cards []*learning.Flashcard) (int32, error)
+	FailMaterialChunk(ctx context.Context, materialID string, index int32, reason string) error
+
+	// Ingestion Jobs
+	CreateIngestionJob(ctx context.Context, job *IngestionJob) error
+	GetIngestionJob(ctx context.Context, userID, id string) (*IngestionJob, error)
+	ClaimIngestionJob(ctx context.Context, leaseUntil time.Time, maxAttempts int32) (*IngestionJob, error)
+	UpdateIngestionJob(ctx context.Context, job *IngestionJob) error
 
 	// Material Summary
 	GetMaterialContent(ctx context.Context, userID, materialID string) (content string, summary string, title string, err error)
-	UpdateMaterialSummary(ctx context.Context, materialID, summary string) error
+	UpdateMaterialSummary(ctx context.Context, userID, materialID, summary string) error
 
 	// General
+	WithTx(ctx context.Context, fn func(tx Store) error) error
 	Close()
 }
+
+// Open connects to the store a database URL names by its scheme:
+// postgres:// or postgresql:// for Postgres, and sqlite:<path> or
+// sqlite://<path> for a SQLite file.
+func Open(ctx context.Context, dbURL string) (Store, error) {
+	if path, ok := SQLitePath(dbURL); ok {
+		return NewSQLiteStore(ctx, path)
+	}
+	if !strings.HasPrefix(dbURL, "postgres://") && !strings.HasPrefix(dbURL, "postgresql://") {
+		return nil, fmt.Errorf("unsupported database URL %q: expected postgres:// or sqlite:", dbURL)
+	}
+	return NewPostgresStore(ctx, dbURL)
+}
+
+// SQLitePath returns the file a sqlite: database URL names.
+func SQLitePath(dbURL string) (string, bool) {
+	for _, prefix := range []string{"sqlite://", "sqlite:"} {
+		if path, ok := strings.CutPrefix(dbURL, prefix); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// NewUUID returns a random (version 4) UUID, for the IDs the stores assign and
+// the note IDs grouping sibling cards.
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// clozeChanges compares the cloze numbers of a note's cards, keyed by card ID,
+// with the numbers its edited text uses. It returns the cards whose deletion
+// is gone and new sibling cards for the numbers that have none.
+func clozeChanges(siblings map[string]int32, noteID, question, answer string) (remove []string, add []*learning.Flashcard, err error) {
+	indices := cloze.Indices(question)
+	if len(indices) == 0 {
+		return nil, nil, ErrNoClozeDeletions
+	}
+	covered := make(map[int32]bool)
+	for id, index := range siblings {
+		if slices.Contains(indices, index) {
+			covered[index] = true
+		} else {
+			remove = append(remove, id)
+		}
+	}
+	slices.Sort(remove)
+	for _, index := range indices {
+		if !covered[index] {
+			add = append(add, &learning.Flashcard{
+				Question:   question,
+				Answer:     answer,
+				CardType:   learning.CardType_CARD_TYPE_CLOZE,
+				NoteId:     noteID,
+				ClozeIndex: index,
+			})
+		}
+	}
+	return remove, add, nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/amityadav/landr/db"
+	"github.com/amityadav/landr/internal/migrate"
+	"github.com/amityadav/landr/pkg/pb/learning"
+)
+
+// testDatabaseURL names the environment variable holding a Postgres database
+// to run the store tests against as well. The tests migrate it up and create
+// users of their own, so it needn't be empty.
+const testDatabaseURL = "TEST_DATABASE_URL"
+
+// forEachStore runs fn against a fresh MemoryStore and SQLite database, and
+// against Postgres when TEST_DATABASE_URL is set.
+func forEachStore(t *testing.T, fn func(t *testing.T, s Store)) {
+	t.Helper()
+	t.Run("memory", func(t *testing.T) {
+		fn(t, NewMemoryStore())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		fn(t, newTestSQLiteStore(t))
+	})
+	t.Run("postgres", func(t *testing.T) {
+		fn(t, newTestPostgresStore(t))
+	})
+}
+
+func newTestSQLiteStore(t testing.TB) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), "landr.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestPostgresStore(t testing.TB) *PostgresStore {
+	t.Helper()
+	dsn := migrateTestDatabase(t)
+	s, err := NewPostgresStore(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("NewPostgresStore: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// migrateTestDatabase migrates the database TEST_DATABASE_URL names up and
+// returns the URL, skipping the test when it isn't set.
+func migrateTestDatabase(t testing.TB) string {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseURL)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseURL)
+	}
+	ctx := context.Background()
+
+	migrations, err := migrate.Load(db.Migrations, "migrations")
+	if err != nil {
+		t.Fatalf("migrate.Load: %v", err)
+	}
+	m, err := migrate.New(ctx, dsn, migrations)
+	if err != nil {
+		t.Fatalf("migrate.New: %v", err)
+	}
+	defer m.Close(ctx)
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return dsn
+}
+
+// newTestUser creates a user no other test shares and returns its ID.
+func newTestUser(t testing.TB, s Store) string {
+	t.Helper()
+	suffix := randomHex(t)
+	user, err := s.CreateUser(context.Background(), suffix+"@example.com", "Test "+suffix, "google-"+suffix, "")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user.Id
+}
+
+// newTestMaterial creates a material of userID with the given cards and
+// returns the IDs of both.
+func newTestMaterial(t testing.TB, s Store, userID string, cards ...*learning.Flashcard) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	materialID, err := s.CreateMaterial(ctx, userID, "TEXT", "Some content", "Title")
+	if err != nil {
+		t.Fatalf("CreateMaterial: %v", err)
+	}
+	if len(cards) == 0 {
+		return materialID, nil
+	}
+	ids, err := s.CreateFlashcards(ctx, materialID, cards)
+	if err != nil {
+		t.Fatalf("CreateFlashcards: %v", err)
+	}
+	return materialID, ids
+}
+
+func randomHex(t testing.TB) string {
+	t.Helper()
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
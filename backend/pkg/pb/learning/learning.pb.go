@@ -23,14 +23,340 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How well the user recalled a flashcard during review.
+type ReviewGrade int32
+
+const (
+	ReviewGrade_REVIEW_GRADE_UNSPECIFIED ReviewGrade = 0
+	ReviewGrade_REVIEW_GRADE_AGAIN       ReviewGrade = 1
+	ReviewGrade_REVIEW_GRADE_HARD        ReviewGrade = 2
+	ReviewGrade_REVIEW_GRADE_GOOD        ReviewGrade = 3
+	ReviewGrade_REVIEW_GRADE_EASY        ReviewGrade = 4
+)
+
+// Enum value maps for ReviewGrade.
+var (
+	ReviewGrade_name = map[int32]string{
+		0: "REVIEW_GRADE_UNSPECIFIED",
+		1: "REVIEW_GRADE_AGAIN",
+		2: "REVIEW_GRADE_HARD",
+		3: "REVIEW_GRADE_GOOD",
+		4: "REVIEW_GRADE_EASY",
+	}
+	ReviewGrade_value = map[string]int32{
+		"REVIEW_GRADE_UNSPECIFIED": 0,
+		"REVIEW_GRADE_AGAIN":       1,
+		"REVIEW_GRADE_HARD":        2,
+		"REVIEW_GRADE_GOOD":        3,
+		"REVIEW_GRADE_EASY":        4,
+	}
+)
+
+func (x ReviewGrade) Enum() *ReviewGrade {
+	p := new(ReviewGrade)
+	*p = x
+	return p
+}
+
+func (x ReviewGrade) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReviewGrade) Descriptor() protoreflect.EnumDescriptor {
+	return file_backend_proto_learning_learning_proto_enumTypes[0].Descriptor()
+}
+
+func (ReviewGrade) Type() protoreflect.EnumType {
+	return &file_backend_proto_learning_learning_proto_enumTypes[0]
+}
+
+func (x ReviewGrade) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReviewGrade.Descriptor instead.
+func (ReviewGrade) EnumDescriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{0}
+}
+
+// Whether a flashcard takes part in reviews.
+type FlashcardStatus int32
+
+const (
+	FlashcardStatus_FLASHCARD_STATUS_ACTIVE    FlashcardStatus = 0
+	FlashcardStatus_FLASHCARD_STATUS_SUSPENDED FlashcardStatus = 1 // Left out of reviews until unsuspended
+	FlashcardStatus_FLASHCARD_STATUS_BURIED    FlashcardStatus = 2 // Left out of reviews until the next study day
+)
+
+// Enum value maps for FlashcardStatus.
+var (
+	FlashcardStatus_name = map[int32]string{
+		0: "FLASHCARD_STATUS_ACTIVE",
+		1: "FLASHCARD_STATUS_SUSPENDED",
+		2: "FLASHCARD_STATUS_BURIED",
+	}
+	FlashcardStatus_value = map[string]int32{
+		"FLASHCARD_STATUS_ACTIVE":    0,
+		"FLASHCARD_STATUS_SUSPENDED": 1,
+		"FLASHCARD_STATUS_BURIED":    2,
+	}
+)
+
+func (x FlashcardStatus) Enum() *FlashcardStatus {
+	p := new(FlashcardStatus)
+	*p = x
+	return p
+}
+
+func (x FlashcardStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FlashcardStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_backend_proto_learning_learning_proto_enumTypes[1].Descriptor()
+}
+
+func (FlashcardStatus) Type() protoreflect.EnumType {
+	return &file_backend_proto_learning_learning_proto_enumTypes[1]
+}
+
+func (x FlashcardStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FlashcardStatus.Descriptor instead.
+func (FlashcardStatus) EnumDescriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{1}
+}
+
+// Colored marker a user can put on a flashcard.
+type FlashcardFlag int32
+
+const (
+	FlashcardFlag_FLASHCARD_FLAG_NONE   FlashcardFlag = 0
+	FlashcardFlag_FLASHCARD_FLAG_RED    FlashcardFlag = 1
+	FlashcardFlag_FLASHCARD_FLAG_ORANGE FlashcardFlag = 2
+	FlashcardFlag_FLASHCARD_FLAG_GREEN  FlashcardFlag = 3
+	FlashcardFlag_FLASHCARD_FLAG_BLUE   FlashcardFlag = 4
+	FlashcardFlag_FLASHCARD_FLAG_PURPLE FlashcardFlag = 5
+)
+
+// Enum value maps for FlashcardFlag.
+var (
+	FlashcardFlag_name = map[int32]string{
+		0: "FLASHCARD_FLAG_NONE",
+		1: "FLASHCARD_FLAG_RED",
+		2: "FLASHCARD_FLAG_ORANGE",
+		3: "FLASHCARD_FLAG_GREEN",
+		4: "FLASHCARD_FLAG_BLUE",
+		5: "FLASHCARD_FLAG_PURPLE",
+	}
+	FlashcardFlag_value = map[string]int32{
+		"FLASHCARD_FLAG_NONE":   0,
+		"FLASHCARD_FLAG_RED":    1,
+		"FLASHCARD_FLAG_ORANGE": 2,
+		"FLASHCARD_FLAG_GREEN":  3,
+		"FLASHCARD_FLAG_BLUE":   4,
+		"FLASHCARD_FLAG_PURPLE": 5,
+	}
+)
+
+func (x FlashcardFlag) Enum() *FlashcardFlag {
+	p := new(FlashcardFlag)
+	*p = x
+	return p
+}
+
+func (x FlashcardFlag) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FlashcardFlag) Descriptor() protoreflect.EnumDescriptor {
+	return file_backend_proto_learning_learning_proto_enumTypes[2].Descriptor()
+}
+
+func (FlashcardFlag) Type() protoreflect.EnumType {
+	return &file_backend_proto_learning_learning_proto_enumTypes[2]
+}
+
+func (x FlashcardFlag) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FlashcardFlag.Descriptor instead.
+func (FlashcardFlag) EnumDescriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{2}
+}
+
+// Kind of flashcard.
+type CardType int32
+
+const (
+	CardType_CARD_TYPE_BASIC   CardType = 0 // Question and answer
+	CardType_CARD_TYPE_CLOZE   CardType = 1 // Text with {{c1::deletions}}, one card per cloze number
+	CardType_CARD_TYPE_REVERSE CardType = 2 // Asks for the question given the answer
+)
+
+// Enum value maps for CardType.
+var (
+	CardType_name = map[int32]string{
+		0: "CARD_TYPE_BASIC",
+		1: "CARD_TYPE_CLOZE",
+		2: "CARD_TYPE_REVERSE",
+	}
+	CardType_value = map[string]int32{
+		"CARD_TYPE_BASIC":   0,
+		"CARD_TYPE_CLOZE":   1,
+		"CARD_TYPE_REVERSE": 2,
+	}
+)
+
+func (x CardType) Enum() *CardType {
+	p := new(CardType)
+	*p = x
+	return p
+}
+
+func (x CardType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CardType) Descriptor() protoreflect.EnumDescriptor {
+	return file_backend_proto_learning_learning_proto_enumTypes[3].Descriptor()
+}
+
+func (CardType) Type() protoreflect.EnumType {
+	return &file_backend_proto_learning_learning_proto_enumTypes[3]
+}
+
+func (x CardType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CardType.Descriptor instead.
+func (CardType) EnumDescriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{3}
+}
+
+// What happens to a card once it lapses often enough to become a leech.
+type LeechAction int32
+
+const (
+	LeechAction_LEECH_ACTION_TAG     LeechAction = 0 // Only mark the card as a leech
+	LeechAction_LEECH_ACTION_SUSPEND LeechAction = 1 // Mark the card as a leech and suspend it
+)
+
+// Enum value maps for LeechAction.
+var (
+	LeechAction_name = map[int32]string{
+		0: "LEECH_ACTION_TAG",
+		1: "LEECH_ACTION_SUSPEND",
+	}
+	LeechAction_value = map[string]int32{
+		"LEECH_ACTION_TAG":     0,
+		"LEECH_ACTION_SUSPEND": 1,
+	}
+)
+
+func (x LeechAction) Enum() *LeechAction {
+	p := new(LeechAction)
+	*p = x
+	return p
+}
+
+func (x LeechAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LeechAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_backend_proto_learning_learning_proto_enumTypes[4].Descriptor()
+}
+
+func (LeechAction) Type() protoreflect.EnumType {
+	return &file_backend_proto_learning_learning_proto_enumTypes[4]
+}
+
+func (x LeechAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LeechAction.Descriptor instead.
+func (LeechAction) EnumDescriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{4}
+}
+
+// Step an ingestion job has reached.
+type IngestionStage int32
+
+const (
+	IngestionStage_INGESTION_STAGE_QUEUED     IngestionStage = 0 // Waiting for a worker
+	IngestionStage_INGESTION_STAGE_FETCHING   IngestionStage = 1 // Scraping the link or downloading the transcript
+	IngestionStage_INGESTION_STAGE_EXTRACTING IngestionStage = 2 // Reading text from the image
+	IngestionStage_INGESTION_STAGE_GENERATING IngestionStage = 3 // Writing flashcards, chunk by chunk for long material
+	IngestionStage_INGESTION_STAGE_SAVING     IngestionStage = 4 // Storing the material and its cards
+	IngestionStage_INGESTION_STAGE_SUCCEEDED  IngestionStage = 5
+	IngestionStage_INGESTION_STAGE_FAILED     IngestionStage = 6
+)
+
+// Enum value maps for IngestionStage.
+var (
+	IngestionStage_name = map[int32]string{
+		0: "INGESTION_STAGE_QUEUED",
+		1: "INGESTION_STAGE_FETCHING",
+		2: "INGESTION_STAGE_EXTRACTING",
+		3: "INGESTION_STAGE_GENERATING",
+		4: "INGESTION_STAGE_SAVING",
+		5: "INGESTION_STAGE_SUCCEEDED",
+		6: "INGESTION_STAGE_FAILED",
+	}
+	IngestionStage_value = map[string]int32{
+		"INGESTION_STAGE_QUEUED":     0,
+		"INGESTION_STAGE_FETCHING":   1,
+		"INGESTION_STAGE_EXTRACTING": 2,
+		"INGESTION_STAGE_GENERATING": 3,
+		"INGESTION_STAGE_SAVING":     4,
+		"INGESTION_STAGE_SUCCEEDED":  5,
+		"INGESTION_STAGE_FAILED":     6,
+	}
+)
+
+func (x IngestionStage) Enum() *IngestionStage {
+	p := new(IngestionStage)
+	*p = x
+	return p
+}
+
+func (x IngestionStage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IngestionStage) Descriptor() protoreflect.EnumDescriptor {
+	return file_backend_proto_learning_learning_proto_enumTypes[5].Descriptor()
+}
+
+func (IngestionStage) Type() protoreflect.EnumType {
+	return &file_backend_proto_learning_learning_proto_enumTypes[5]
+}
+
+func (x IngestionStage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IngestionStage.Descriptor instead.
+func (IngestionStage) EnumDescriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{5}
+}
+
 type AddMaterialRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "TEXT", "LINK", "IMAGE", or "YOUTUBE"
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
-	ExistingTags  []string               `protobuf:"bytes,3,rep,name=existing_tags,json=existingTags,proto3" json:"existing_tags,omitempty"`
-	ImageData     string                 `protobuf:"bytes,4,opt,name=image_data,json=imageData,proto3" json:"image_data,omitempty"` // Base64 encoded image for IMAGE type
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Type            string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "TEXT", "LINK", "IMAGE", or "YOUTUBE" ("MANUAL" decks are made by CreateFlashcard)
+	Content         string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	ExistingTags    []string               `protobuf:"bytes,3,rep,name=existing_tags,json=existingTags,proto3" json:"existing_tags,omitempty"`
+	ImageData       string                 `protobuf:"bytes,4,opt,name=image_data,json=imageData,proto3" json:"image_data,omitempty"`                    // Base64 encoded image for IMAGE type
+	AllowCloze      bool                   `protobuf:"varint,5,opt,name=allow_cloze,json=allowCloze,proto3" json:"allow_cloze,omitempty"`                // Let the AI write cloze cards for definition-heavy material
+	GenerateReverse bool                   `protobuf:"varint,6,opt,name=generate_reverse,json=generateReverse,proto3" json:"generate_reverse,omitempty"` // Also study each question/answer pair in reverse, e.g. for vocabulary
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *AddMaterialRequest) Reset() {
@@ -91,12 +417,31 @@ func (x *AddMaterialRequest) GetImageData() string {
 	return ""
 }
 
+func (x *AddMaterialRequest) GetAllowCloze() bool {
+	if x != nil {
+		return x.AllowCloze
+	}
+	return false
+}
+
+func (x *AddMaterialRequest) GetGenerateReverse() bool {
+	if x != nil {
+		return x.GenerateReverse
+	}
+	return false
+}
+
+// AddMaterial only queues the material and returns job_id; the other fields
+// are filled in on the result of the finished IngestionJob.
 type AddMaterialResponse struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	MaterialId        string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
 	FlashcardsCreated int32                  `protobuf:"varint,2,opt,name=flashcards_created,json=flashcardsCreated,proto3" json:"flashcards_created,omitempty"`
 	Title             string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
 	Tags              []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	JobId             string                 `protobuf:"bytes,5,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	TotalChunks       int32                  `protobuf:"varint,6,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`           // Sections the content was split into for generation
+	FailedChunks      []int32                `protobuf:"varint,7,rep,packed,name=failed_chunks,json=failedChunks,proto3" json:"failed_chunks,omitempty"` // Sections (1-based) that produced no cards; see RetryFailedChunks
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -159,27 +504,52 @@ func (x *AddMaterialResponse) GetTags() []string {
 	return nil
 }
 
-type DeleteMaterialRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	MaterialId    string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *AddMaterialResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
 }
 
-func (x *DeleteMaterialRequest) Reset() {
-	*x = DeleteMaterialRequest{}
+func (x *AddMaterialResponse) GetTotalChunks() int32 {
+	if x != nil {
+		return x.TotalChunks
+	}
+	return 0
+}
+
+func (x *AddMaterialResponse) GetFailedChunks() []int32 {
+	if x != nil {
+		return x.FailedChunks
+	}
+	return nil
+}
+
+// Queues another ingestion job for the chunks of a material that failed or
+// never finished; chunks that already have cards are left alone.
+type RetryFailedChunksRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MaterialId      string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
+	AllowCloze      bool                   `protobuf:"varint,2,opt,name=allow_cloze,json=allowCloze,proto3" json:"allow_cloze,omitempty"`
+	GenerateReverse bool                   `protobuf:"varint,3,opt,name=generate_reverse,json=generateReverse,proto3" json:"generate_reverse,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RetryFailedChunksRequest) Reset() {
+	*x = RetryFailedChunksRequest{}
 	mi := &file_backend_proto_learning_learning_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteMaterialRequest) String() string {
+func (x *RetryFailedChunksRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteMaterialRequest) ProtoMessage() {}
+func (*RetryFailedChunksRequest) ProtoMessage() {}
 
-func (x *DeleteMaterialRequest) ProtoReflect() protoreflect.Message {
+func (x *RetryFailedChunksRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_backend_proto_learning_learning_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -191,42 +561,53 @@ func (x *DeleteMaterialRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteMaterialRequest.ProtoReflect.Descriptor instead.
-func (*DeleteMaterialRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RetryFailedChunksRequest.ProtoReflect.Descriptor instead.
+func (*RetryFailedChunksRequest) Descriptor() ([]byte, []int) {
 	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *DeleteMaterialRequest) GetMaterialId() string {
+func (x *RetryFailedChunksRequest) GetMaterialId() string {
 	if x != nil {
 		return x.MaterialId
 	}
 	return ""
 }
 
-type MaterialSummary struct {
+func (x *RetryFailedChunksRequest) GetAllowCloze() bool {
+	if x != nil {
+		return x.AllowCloze
+	}
+	return false
+}
+
+func (x *RetryFailedChunksRequest) GetGenerateReverse() bool {
+	if x != nil {
+		return x.GenerateReverse
+	}
+	return false
+}
+
+type WatchIngestionJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	DueCount      int32                  `protobuf:"varint,3,opt,name=due_count,json=dueCount,proto3" json:"due_count,omitempty"`
-	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MaterialSummary) Reset() {
-	*x = MaterialSummary{}
+func (x *WatchIngestionJobRequest) Reset() {
+	*x = WatchIngestionJobRequest{}
 	mi := &file_backend_proto_learning_learning_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MaterialSummary) String() string {
+func (x *WatchIngestionJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MaterialSummary) ProtoMessage() {}
+func (*WatchIngestionJobRequest) ProtoMessage() {}
 
-func (x *MaterialSummary) ProtoReflect() protoreflect.Message {
+func (x *WatchIngestionJobRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_backend_proto_learning_learning_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -238,61 +619,46 @@ func (x *MaterialSummary) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MaterialSummary.ProtoReflect.Descriptor instead.
-func (*MaterialSummary) Descriptor() ([]byte, []int) {
+// Deprecated: Use WatchIngestionJobRequest.ProtoReflect.Descriptor instead.
+func (*WatchIngestionJobRequest) Descriptor() ([]byte, []int) {
 	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *MaterialSummary) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *MaterialSummary) GetTitle() string {
+func (x *WatchIngestionJobRequest) GetJobId() string {
 	if x != nil {
-		return x.Title
+		return x.JobId
 	}
 	return ""
 }
 
-func (x *MaterialSummary) GetDueCount() int32 {
-	if x != nil {
-		return x.DueCount
-	}
-	return 0
-}
-
-func (x *MaterialSummary) GetTags() []string {
-	if x != nil {
-		return x.Tags
-	}
-	return nil
-}
-
-type GetDueMaterialsRequest struct {
+// Progress of a material being turned into flashcards in the background.
+type IngestionJob struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Stage         IngestionStage         `protobuf:"varint,2,opt,name=stage,proto3,enum=learning.IngestionStage" json:"stage,omitempty"`
+	Chunk         int32                  `protobuf:"varint,3,opt,name=chunk,proto3" json:"chunk,omitempty"` // Chunk being generated (1-based) while GENERATING
+	TotalChunks   int32                  `protobuf:"varint,4,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`
+	Result        *AddMaterialResponse   `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"` // Set once the job has finished, and for a FAILED job once its material was saved
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`   // Set once the job has FAILED
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetDueMaterialsRequest) Reset() {
-	*x = GetDueMaterialsRequest{}
+func (x *IngestionJob) Reset() {
+	*x = IngestionJob{}
 	mi := &file_backend_proto_learning_learning_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetDueMaterialsRequest) String() string {
+func (x *IngestionJob) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetDueMaterialsRequest) ProtoMessage() {}
+func (*IngestionJob) ProtoMessage() {}
 
-func (x *GetDueMaterialsRequest) ProtoReflect() protoreflect.Message {
+func (x *IngestionJob) ProtoReflect() protoreflect.Message {
 	mi := &file_backend_proto_learning_learning_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -304,39 +670,238 @@ func (x *GetDueMaterialsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetDueMaterialsRequest.ProtoReflect.Descriptor instead.
-func (*GetDueMaterialsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use IngestionJob.ProtoReflect.Descriptor instead.
+func (*IngestionJob) Descriptor() ([]byte, []int) {
 	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *GetDueMaterialsRequest) GetPage() int32 {
+func (x *IngestionJob) GetId() string {
 	if x != nil {
-		return x.Page
+		return x.Id
+	}
+	return ""
+}
+
+func (x *IngestionJob) GetStage() IngestionStage {
+	if x != nil {
+		return x.Stage
+	}
+	return IngestionStage_INGESTION_STAGE_QUEUED
+}
+
+func (x *IngestionJob) GetChunk() int32 {
+	if x != nil {
+		return x.Chunk
 	}
 	return 0
 }
 
-func (x *GetDueMaterialsRequest) GetPageSize() int32 {
+func (x *IngestionJob) GetTotalChunks() int32 {
 	if x != nil {
-		return x.PageSize
+		return x.TotalChunks
 	}
 	return 0
 }
 
-type GetDueMaterialsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Materials     []*MaterialSummary     `protobuf:"bytes,1,rep,name=materials,proto3" json:"materials,omitempty"`
-	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
-	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	TotalPages    int32                  `protobuf:"varint,5,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *IngestionJob) GetResult() *AddMaterialResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *IngestionJob) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *IngestionJob) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type DeleteMaterialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaterialId    string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMaterialRequest) Reset() {
+	*x = DeleteMaterialRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMaterialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMaterialRequest) ProtoMessage() {}
+
+func (x *DeleteMaterialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMaterialRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMaterialRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteMaterialRequest) GetMaterialId() string {
+	if x != nil {
+		return x.MaterialId
+	}
+	return ""
+}
+
+type MaterialSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	DueCount      int32                  `protobuf:"varint,3,opt,name=due_count,json=dueCount,proto3" json:"due_count,omitempty"`
+	Tags          []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaterialSummary) Reset() {
+	*x = MaterialSummary{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaterialSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaterialSummary) ProtoMessage() {}
+
+func (x *MaterialSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaterialSummary.ProtoReflect.Descriptor instead.
+func (*MaterialSummary) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MaterialSummary) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MaterialSummary) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *MaterialSummary) GetDueCount() int32 {
+	if x != nil {
+		return x.DueCount
+	}
+	return 0
+}
+
+func (x *MaterialSummary) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type GetDueMaterialsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDueMaterialsRequest) Reset() {
+	*x = GetDueMaterialsRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDueMaterialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDueMaterialsRequest) ProtoMessage() {}
+
+func (x *GetDueMaterialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDueMaterialsRequest.ProtoReflect.Descriptor instead.
+func (*GetDueMaterialsRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetDueMaterialsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetDueMaterialsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GetDueMaterialsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Materials     []*MaterialSummary     `protobuf:"bytes,1,rep,name=materials,proto3" json:"materials,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	TotalPages    int32                  `protobuf:"varint,5,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetDueMaterialsResponse) Reset() {
 	*x = GetDueMaterialsResponse{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[5]
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -348,7 +913,7 @@ func (x *GetDueMaterialsResponse) String() string {
 func (*GetDueMaterialsResponse) ProtoMessage() {}
 
 func (x *GetDueMaterialsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[5]
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -361,7 +926,7 @@ func (x *GetDueMaterialsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDueMaterialsResponse.ProtoReflect.Descriptor instead.
 func (*GetDueMaterialsResponse) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{5}
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *GetDueMaterialsResponse) GetMaterials() []*MaterialSummary {
@@ -408,7 +973,7 @@ type GetDueFlashcardsRequest struct {
 
 func (x *GetDueFlashcardsRequest) Reset() {
 	*x = GetDueFlashcardsRequest{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[6]
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -420,7 +985,7 @@ func (x *GetDueFlashcardsRequest) String() string {
 func (*GetDueFlashcardsRequest) ProtoMessage() {}
 
 func (x *GetDueFlashcardsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[6]
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -433,7 +998,7 @@ func (x *GetDueFlashcardsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDueFlashcardsRequest.ProtoReflect.Descriptor instead.
 func (*GetDueFlashcardsRequest) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{6}
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *GetDueFlashcardsRequest) GetMaterialId() string {
@@ -444,21 +1009,38 @@ func (x *GetDueFlashcardsRequest) GetMaterialId() string {
 }
 
 type Flashcard struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Question      string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
-	Answer        string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
-	Stage         int32                  `protobuf:"varint,4,opt,name=stage,proto3" json:"stage,omitempty"`
-	NextReviewAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=next_review_at,json=nextReviewAt,proto3" json:"next_review_at,omitempty"`
-	MaterialTitle string                 `protobuf:"bytes,6,opt,name=material_title,json=materialTitle,proto3" json:"material_title,omitempty"`
-	Tags          []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Question       string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	Answer         string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	Stage          int32                  `protobuf:"varint,4,opt,name=stage,proto3" json:"stage,omitempty"`
+	NextReviewAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=next_review_at,json=nextReviewAt,proto3" json:"next_review_at,omitempty"`
+	MaterialTitle  string                 `protobuf:"bytes,6,opt,name=material_title,json=materialTitle,proto3" json:"material_title,omitempty"`
+	Tags           []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	EaseFactor     float64                `protobuf:"fixed64,8,opt,name=ease_factor,json=easeFactor,proto3" json:"ease_factor,omitempty"`
+	IntervalDays   int32                  `protobuf:"varint,9,opt,name=interval_days,json=intervalDays,proto3" json:"interval_days,omitempty"`
+	Repetitions    int32                  `protobuf:"varint,10,opt,name=repetitions,proto3" json:"repetitions,omitempty"`
+	Stability      float64                `protobuf:"fixed64,11,opt,name=stability,proto3" json:"stability,omitempty"`
+	Difficulty     float64                `protobuf:"fixed64,12,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	LastReviewedAt *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_reviewed_at,json=lastReviewedAt,proto3" json:"last_reviewed_at,omitempty"`
+	MaterialId     string                 `protobuf:"bytes,14,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
+	Status         FlashcardStatus        `protobuf:"varint,15,opt,name=status,proto3,enum=learning.FlashcardStatus" json:"status,omitempty"`
+	Flag           FlashcardFlag          `protobuf:"varint,16,opt,name=flag,proto3,enum=learning.FlashcardFlag" json:"flag,omitempty"`
+	BuriedUntil    *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=buried_until,json=buriedUntil,proto3" json:"buried_until,omitempty"` // Set while the card is buried
+	Lapses         int32                  `protobuf:"varint,18,opt,name=lapses,proto3" json:"lapses,omitempty"`                             // Times the card was forgotten after being learned
+	IsLeech        bool                   `protobuf:"varint,19,opt,name=is_leech,json=isLeech,proto3" json:"is_leech,omitempty"`
+	CardType       CardType               `protobuf:"varint,20,opt,name=card_type,json=cardType,proto3,enum=learning.CardType" json:"card_type,omitempty"`
+	NoteId         string                 `protobuf:"bytes,21,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`              // Shared by sibling cards: cloze deletions of one text, or both directions of a pair
+	ClozeIndex     int32                  `protobuf:"varint,22,opt,name=cloze_index,json=clozeIndex,proto3" json:"cloze_index,omitempty"` // Cloze number this card asks about
+	Front          string                 `protobuf:"bytes,23,opt,name=front,proto3" json:"front,omitempty"`                              // Rendered text to show before revealing the answer
+	Back           string                 `protobuf:"bytes,24,opt,name=back,proto3" json:"back,omitempty"`                                // Rendered text to show after revealing the answer
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Flashcard) Reset() {
 	*x = Flashcard{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[7]
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -470,7 +1052,7 @@ func (x *Flashcard) String() string {
 func (*Flashcard) ProtoMessage() {}
 
 func (x *Flashcard) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[7]
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +1065,7 @@ func (x *Flashcard) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Flashcard.ProtoReflect.Descriptor instead.
 func (*Flashcard) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{7}
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Flashcard) GetId() string {
@@ -528,35 +1110,2096 @@ func (x *Flashcard) GetMaterialTitle() string {
 	return ""
 }
 
-func (x *Flashcard) GetTags() []string {
+func (x *Flashcard) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Flashcard) GetEaseFactor() float64 {
+	if x != nil {
+		return x.EaseFactor
+	}
+	return 0
+}
+
+func (x *Flashcard) GetIntervalDays() int32 {
+	if x != nil {
+		return x.IntervalDays
+	}
+	return 0
+}
+
+func (x *Flashcard) GetRepetitions() int32 {
+	if x != nil {
+		return x.Repetitions
+	}
+	return 0
+}
+
+func (x *Flashcard) GetStability() float64 {
+	if x != nil {
+		return x.Stability
+	}
+	return 0
+}
+
+func (x *Flashcard) GetDifficulty() float64 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+func (x *Flashcard) GetLastReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastReviewedAt
+	}
+	return nil
+}
+
+func (x *Flashcard) GetMaterialId() string {
+	if x != nil {
+		return x.MaterialId
+	}
+	return ""
+}
+
+func (x *Flashcard) GetStatus() FlashcardStatus {
+	if x != nil {
+		return x.Status
+	}
+	return FlashcardStatus_FLASHCARD_STATUS_ACTIVE
+}
+
+func (x *Flashcard) GetFlag() FlashcardFlag {
+	if x != nil {
+		return x.Flag
+	}
+	return FlashcardFlag_FLASHCARD_FLAG_NONE
+}
+
+func (x *Flashcard) GetBuriedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BuriedUntil
+	}
+	return nil
+}
+
+func (x *Flashcard) GetLapses() int32 {
+	if x != nil {
+		return x.Lapses
+	}
+	return 0
+}
+
+func (x *Flashcard) GetIsLeech() bool {
+	if x != nil {
+		return x.IsLeech
+	}
+	return false
+}
+
+func (x *Flashcard) GetCardType() CardType {
+	if x != nil {
+		return x.CardType
+	}
+	return CardType_CARD_TYPE_BASIC
+}
+
+func (x *Flashcard) GetNoteId() string {
+	if x != nil {
+		return x.NoteId
+	}
+	return ""
+}
+
+func (x *Flashcard) GetClozeIndex() int32 {
+	if x != nil {
+		return x.ClozeIndex
+	}
+	return 0
+}
+
+func (x *Flashcard) GetFront() string {
+	if x != nil {
+		return x.Front
+	}
+	return ""
+}
+
+func (x *Flashcard) GetBack() string {
+	if x != nil {
+		return x.Back
+	}
+	return ""
+}
+
+type FlashcardList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flashcards    []*Flashcard           `protobuf:"bytes,1,rep,name=flashcards,proto3" json:"flashcards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlashcardList) Reset() {
+	*x = FlashcardList{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlashcardList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlashcardList) ProtoMessage() {}
+
+func (x *FlashcardList) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlashcardList.ProtoReflect.Descriptor instead.
+func (*FlashcardList) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FlashcardList) GetFlashcards() []*Flashcard {
+	if x != nil {
+		return x.Flashcards
+	}
+	return nil
+}
+
+type CompleteReviewRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId      string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	ClientReviewedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=client_reviewed_at,json=clientReviewedAt,proto3" json:"client_reviewed_at,omitempty"` // When the review happened on the client
+	SessionId        string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                        // Client review session, used to scope UndoReview
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CompleteReviewRequest) Reset() {
+	*x = CompleteReviewRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteReviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteReviewRequest) ProtoMessage() {}
+
+func (x *CompleteReviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteReviewRequest.ProtoReflect.Descriptor instead.
+func (*CompleteReviewRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CompleteReviewRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *CompleteReviewRequest) GetClientReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClientReviewedAt
+	}
+	return nil
+}
+
+func (x *CompleteReviewRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type FailReviewRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId      string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	ClientReviewedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=client_reviewed_at,json=clientReviewedAt,proto3" json:"client_reviewed_at,omitempty"` // When the review happened on the client
+	SessionId        string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                        // Client review session, used to scope UndoReview
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *FailReviewRequest) Reset() {
+	*x = FailReviewRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailReviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailReviewRequest) ProtoMessage() {}
+
+func (x *FailReviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailReviewRequest.ProtoReflect.Descriptor instead.
+func (*FailReviewRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FailReviewRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *FailReviewRequest) GetClientReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClientReviewedAt
+	}
+	return nil
+}
+
+func (x *FailReviewRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GetAllTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllTagsResponse) Reset() {
+	*x = GetAllTagsResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllTagsResponse) ProtoMessage() {}
+
+func (x *GetAllTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllTagsResponse.ProtoReflect.Descriptor instead.
+func (*GetAllTagsResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetAllTagsResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type NotificationStatusResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	DueFlashcardsCount int32                  `protobuf:"varint,1,opt,name=due_flashcards_count,json=dueFlashcardsCount,proto3" json:"due_flashcards_count,omitempty"` // Cards left to study today, within the daily limits
+	HasDueMaterials    bool                   `protobuf:"varint,2,opt,name=has_due_materials,json=hasDueMaterials,proto3" json:"has_due_materials,omitempty"`
+	NewCardsCount      int32                  `protobuf:"varint,3,opt,name=new_cards_count,json=newCardsCount,proto3" json:"new_cards_count,omitempty"` // New cards due by the end of the user's local study day
+	ReviewsCount       int32                  `protobuf:"varint,4,opt,name=reviews_count,json=reviewsCount,proto3" json:"reviews_count,omitempty"`      // Reviews due by the end of the user's local study day
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *NotificationStatusResponse) Reset() {
+	*x = NotificationStatusResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationStatusResponse) ProtoMessage() {}
+
+func (x *NotificationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationStatusResponse.ProtoReflect.Descriptor instead.
+func (*NotificationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *NotificationStatusResponse) GetDueFlashcardsCount() int32 {
+	if x != nil {
+		return x.DueFlashcardsCount
+	}
+	return 0
+}
+
+func (x *NotificationStatusResponse) GetHasDueMaterials() bool {
+	if x != nil {
+		return x.HasDueMaterials
+	}
+	return false
+}
+
+func (x *NotificationStatusResponse) GetNewCardsCount() int32 {
+	if x != nil {
+		return x.NewCardsCount
+	}
+	return 0
+}
+
+func (x *NotificationStatusResponse) GetReviewsCount() int32 {
+	if x != nil {
+		return x.ReviewsCount
+	}
+	return 0
+}
+
+type GetMaterialSummaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaterialId    string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMaterialSummaryRequest) Reset() {
+	*x = GetMaterialSummaryRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMaterialSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMaterialSummaryRequest) ProtoMessage() {}
+
+func (x *GetMaterialSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMaterialSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetMaterialSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetMaterialSummaryRequest) GetMaterialId() string {
+	if x != nil {
+		return x.MaterialId
+	}
+	return ""
+}
+
+type GetMaterialSummaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Summary       string                 `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMaterialSummaryResponse) Reset() {
+	*x = GetMaterialSummaryResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMaterialSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMaterialSummaryResponse) ProtoMessage() {}
+
+func (x *GetMaterialSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMaterialSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetMaterialSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetMaterialSummaryResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *GetMaterialSummaryResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+type UpdateFlashcardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Question      string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	Answer        string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFlashcardRequest) Reset() {
+	*x = UpdateFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFlashcardRequest) ProtoMessage() {}
+
+func (x *UpdateFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *UpdateFlashcardRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *UpdateFlashcardRequest) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+type ReviewFlashcardRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId      string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Grade            ReviewGrade            `protobuf:"varint,2,opt,name=grade,proto3,enum=learning.ReviewGrade" json:"grade,omitempty"`
+	ClientReviewedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=client_reviewed_at,json=clientReviewedAt,proto3" json:"client_reviewed_at,omitempty"` // When the review happened on the client
+	SessionId        string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`                        // Client review session, used to scope UndoReview
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ReviewFlashcardRequest) Reset() {
+	*x = ReviewFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewFlashcardRequest) ProtoMessage() {}
+
+func (x *ReviewFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*ReviewFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ReviewFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *ReviewFlashcardRequest) GetGrade() ReviewGrade {
+	if x != nil {
+		return x.Grade
+	}
+	return ReviewGrade_REVIEW_GRADE_UNSPECIFIED
+}
+
+func (x *ReviewFlashcardRequest) GetClientReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClientReviewedAt
+	}
+	return nil
+}
+
+func (x *ReviewFlashcardRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GradeTypedAnswerRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId      string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	TypedAnswer      string                 `protobuf:"bytes,2,opt,name=typed_answer,json=typedAnswer,proto3" json:"typed_answer,omitempty"`
+	RecordReview     bool                   `protobuf:"varint,3,opt,name=record_review,json=recordReview,proto3" json:"record_review,omitempty"` // Also review the card with the suggested grade
+	ClientReviewedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=client_reviewed_at,json=clientReviewedAt,proto3" json:"client_reviewed_at,omitempty"`
+	SessionId        string                 `protobuf:"bytes,5,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GradeTypedAnswerRequest) Reset() {
+	*x = GradeTypedAnswerRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GradeTypedAnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GradeTypedAnswerRequest) ProtoMessage() {}
+
+func (x *GradeTypedAnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GradeTypedAnswerRequest.ProtoReflect.Descriptor instead.
+func (*GradeTypedAnswerRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GradeTypedAnswerRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *GradeTypedAnswerRequest) GetTypedAnswer() string {
+	if x != nil {
+		return x.TypedAnswer
+	}
+	return ""
+}
+
+func (x *GradeTypedAnswerRequest) GetRecordReview() bool {
+	if x != nil {
+		return x.RecordReview
+	}
+	return false
+}
+
+func (x *GradeTypedAnswerRequest) GetClientReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClientReviewedAt
+	}
+	return nil
+}
+
+func (x *GradeTypedAnswerRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GradeTypedAnswerResponse struct {
+	state          protoimpl.MessageState   `protogen:"open.v1"`
+	Score          float64                  `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"` // 0 (wrong) to 1 (fully correct)
+	SuggestedGrade ReviewGrade              `protobuf:"varint,2,opt,name=suggested_grade,json=suggestedGrade,proto3,enum=learning.ReviewGrade" json:"suggested_grade,omitempty"`
+	Explanation    string                   `protobuf:"bytes,3,opt,name=explanation,proto3" json:"explanation,omitempty"` // What the typed answer missed or got wrong
+	Review         *ReviewFlashcardResponse `protobuf:"bytes,4,opt,name=review,proto3" json:"review,omitempty"`           // Set when the review was recorded
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GradeTypedAnswerResponse) Reset() {
+	*x = GradeTypedAnswerResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GradeTypedAnswerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GradeTypedAnswerResponse) ProtoMessage() {}
+
+func (x *GradeTypedAnswerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GradeTypedAnswerResponse.ProtoReflect.Descriptor instead.
+func (*GradeTypedAnswerResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GradeTypedAnswerResponse) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *GradeTypedAnswerResponse) GetSuggestedGrade() ReviewGrade {
+	if x != nil {
+		return x.SuggestedGrade
+	}
+	return ReviewGrade_REVIEW_GRADE_UNSPECIFIED
+}
+
+func (x *GradeTypedAnswerResponse) GetExplanation() string {
+	if x != nil {
+		return x.Explanation
+	}
+	return ""
+}
+
+func (x *GradeTypedAnswerResponse) GetReview() *ReviewFlashcardResponse {
+	if x != nil {
+		return x.Review
+	}
+	return nil
+}
+
+type ReviewFlashcardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         int32                  `protobuf:"varint,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	NextReviewAt  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=next_review_at,json=nextReviewAt,proto3" json:"next_review_at,omitempty"`
+	IntervalDays  int32                  `protobuf:"varint,3,opt,name=interval_days,json=intervalDays,proto3" json:"interval_days,omitempty"`
+	EaseFactor    float64                `protobuf:"fixed64,4,opt,name=ease_factor,json=easeFactor,proto3" json:"ease_factor,omitempty"`
+	Repetitions   int32                  `protobuf:"varint,5,opt,name=repetitions,proto3" json:"repetitions,omitempty"`
+	Stability     float64                `protobuf:"fixed64,6,opt,name=stability,proto3" json:"stability,omitempty"`
+	Difficulty    float64                `protobuf:"fixed64,7,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewFlashcardResponse) Reset() {
+	*x = ReviewFlashcardResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewFlashcardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewFlashcardResponse) ProtoMessage() {}
+
+func (x *ReviewFlashcardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewFlashcardResponse.ProtoReflect.Descriptor instead.
+func (*ReviewFlashcardResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReviewFlashcardResponse) GetStage() int32 {
+	if x != nil {
+		return x.Stage
+	}
+	return 0
+}
+
+func (x *ReviewFlashcardResponse) GetNextReviewAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextReviewAt
+	}
+	return nil
+}
+
+func (x *ReviewFlashcardResponse) GetIntervalDays() int32 {
+	if x != nil {
+		return x.IntervalDays
+	}
+	return 0
+}
+
+func (x *ReviewFlashcardResponse) GetEaseFactor() float64 {
+	if x != nil {
+		return x.EaseFactor
+	}
+	return 0
+}
+
+func (x *ReviewFlashcardResponse) GetRepetitions() int32 {
+	if x != nil {
+		return x.Repetitions
+	}
+	return 0
+}
+
+func (x *ReviewFlashcardResponse) GetStability() float64 {
+	if x != nil {
+		return x.Stability
+	}
+	return 0
+}
+
+func (x *ReviewFlashcardResponse) GetDifficulty() float64 {
+	if x != nil {
+		return x.Difficulty
+	}
+	return 0
+}
+
+type OptimizeScheduleResponse struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Weights                 []float64              `protobuf:"fixed64,1,rep,packed,name=weights,proto3" json:"weights,omitempty"` // Fitted FSRS weights
+	ReviewCount             int32                  `protobuf:"varint,2,opt,name=review_count,json=reviewCount,proto3" json:"review_count,omitempty"`
+	LogLoss                 float64                `protobuf:"fixed64,3,opt,name=log_loss,json=logLoss,proto3" json:"log_loss,omitempty"`
+	DesiredRetention        float64                `protobuf:"fixed64,4,opt,name=desired_retention,json=desiredRetention,proto3" json:"desired_retention,omitempty"`
+	PredictedRetentionFsrs  float64                `protobuf:"fixed64,5,opt,name=predicted_retention_fsrs,json=predictedRetentionFsrs,proto3" json:"predicted_retention_fsrs,omitempty"`
+	PredictedRetentionFixed float64                `protobuf:"fixed64,6,opt,name=predicted_retention_fixed,json=predictedRetentionFixed,proto3" json:"predicted_retention_fixed,omitempty"`
+	FixedIntervalsDays      []int32                `protobuf:"varint,7,rep,packed,name=fixed_intervals_days,json=fixedIntervalsDays,proto3" json:"fixed_intervals_days,omitempty"` // The fixed [1,3,7,15,30] schedule
+	FsrsIntervalsDays       []int32                `protobuf:"varint,8,rep,packed,name=fsrs_intervals_days,json=fsrsIntervalsDays,proto3" json:"fsrs_intervals_days,omitempty"`    // Intervals FSRS schedules for a card always answered "Good"
+	UsedDefaultWeights      bool                   `protobuf:"varint,9,opt,name=used_default_weights,json=usedDefaultWeights,proto3" json:"used_default_weights,omitempty"`        // True when there is not enough review history to fit weights
+	OptimizedAt             *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=optimized_at,json=optimizedAt,proto3" json:"optimized_at,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *OptimizeScheduleResponse) Reset() {
+	*x = OptimizeScheduleResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizeScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeScheduleResponse) ProtoMessage() {}
+
+func (x *OptimizeScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeScheduleResponse.ProtoReflect.Descriptor instead.
+func (*OptimizeScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *OptimizeScheduleResponse) GetWeights() []float64 {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+func (x *OptimizeScheduleResponse) GetReviewCount() int32 {
+	if x != nil {
+		return x.ReviewCount
+	}
+	return 0
+}
+
+func (x *OptimizeScheduleResponse) GetLogLoss() float64 {
+	if x != nil {
+		return x.LogLoss
+	}
+	return 0
+}
+
+func (x *OptimizeScheduleResponse) GetDesiredRetention() float64 {
+	if x != nil {
+		return x.DesiredRetention
+	}
+	return 0
+}
+
+func (x *OptimizeScheduleResponse) GetPredictedRetentionFsrs() float64 {
+	if x != nil {
+		return x.PredictedRetentionFsrs
+	}
+	return 0
+}
+
+func (x *OptimizeScheduleResponse) GetPredictedRetentionFixed() float64 {
+	if x != nil {
+		return x.PredictedRetentionFixed
+	}
+	return 0
+}
+
+func (x *OptimizeScheduleResponse) GetFixedIntervalsDays() []int32 {
+	if x != nil {
+		return x.FixedIntervalsDays
+	}
+	return nil
+}
+
+func (x *OptimizeScheduleResponse) GetFsrsIntervalsDays() []int32 {
+	if x != nil {
+		return x.FsrsIntervalsDays
+	}
+	return nil
+}
+
+func (x *OptimizeScheduleResponse) GetUsedDefaultWeights() bool {
+	if x != nil {
+		return x.UsedDefaultWeights
+	}
+	return false
+}
+
+func (x *OptimizeScheduleResponse) GetOptimizedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OptimizedAt
+	}
+	return nil
+}
+
+type GetReviewHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaterialId    string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"` // Optional filter
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`                                 // Optional filter
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`                               // Optional, inclusive
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`                                   // Optional, exclusive
+	Page          int32                  `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReviewHistoryRequest) Reset() {
+	*x = GetReviewHistoryRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReviewHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReviewHistoryRequest) ProtoMessage() {}
+
+func (x *GetReviewHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReviewHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetReviewHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetReviewHistoryRequest) GetMaterialId() string {
+	if x != nil {
+		return x.MaterialId
+	}
+	return ""
+}
+
+func (x *GetReviewHistoryRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *GetReviewHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetReviewHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetReviewHistoryRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetReviewHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ReviewLogEntry struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FlashcardId      string                 `protobuf:"bytes,2,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	MaterialId       string                 `protobuf:"bytes,3,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
+	MaterialTitle    string                 `protobuf:"bytes,4,opt,name=material_title,json=materialTitle,proto3" json:"material_title,omitempty"`
+	Question         string                 `protobuf:"bytes,5,opt,name=question,proto3" json:"question,omitempty"`
+	Grade            ReviewGrade            `protobuf:"varint,6,opt,name=grade,proto3,enum=learning.ReviewGrade" json:"grade,omitempty"`
+	Scheduler        string                 `protobuf:"bytes,7,opt,name=scheduler,proto3" json:"scheduler,omitempty"`
+	PreviousStage    int32                  `protobuf:"varint,8,opt,name=previous_stage,json=previousStage,proto3" json:"previous_stage,omitempty"`
+	NextStage        int32                  `protobuf:"varint,9,opt,name=next_stage,json=nextStage,proto3" json:"next_stage,omitempty"`
+	PreviousDueAt    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=previous_due_at,json=previousDueAt,proto3" json:"previous_due_at,omitempty"`
+	NextDueAt        *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=next_due_at,json=nextDueAt,proto3" json:"next_due_at,omitempty"`
+	ElapsedDays      float64                `protobuf:"fixed64,12,opt,name=elapsed_days,json=elapsedDays,proto3" json:"elapsed_days,omitempty"` // Days since the card's previous review
+	ReviewedAt       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=reviewed_at,json=reviewedAt,proto3" json:"reviewed_at,omitempty"`
+	ClientReviewedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=client_reviewed_at,json=clientReviewedAt,proto3" json:"client_reviewed_at,omitempty"`
+	SessionId        string                 `protobuf:"bytes,15,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Reverted         bool                   `protobuf:"varint,16,opt,name=reverted,proto3" json:"reverted,omitempty"` // The review was undone with UndoReview
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ReviewLogEntry) Reset() {
+	*x = ReviewLogEntry{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewLogEntry) ProtoMessage() {}
+
+func (x *ReviewLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewLogEntry.ProtoReflect.Descriptor instead.
+func (*ReviewLogEntry) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ReviewLogEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetMaterialId() string {
+	if x != nil {
+		return x.MaterialId
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetMaterialTitle() string {
+	if x != nil {
+		return x.MaterialTitle
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetGrade() ReviewGrade {
+	if x != nil {
+		return x.Grade
+	}
+	return ReviewGrade_REVIEW_GRADE_UNSPECIFIED
+}
+
+func (x *ReviewLogEntry) GetScheduler() string {
+	if x != nil {
+		return x.Scheduler
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetPreviousStage() int32 {
+	if x != nil {
+		return x.PreviousStage
+	}
+	return 0
+}
+
+func (x *ReviewLogEntry) GetNextStage() int32 {
+	if x != nil {
+		return x.NextStage
+	}
+	return 0
+}
+
+func (x *ReviewLogEntry) GetPreviousDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PreviousDueAt
+	}
+	return nil
+}
+
+func (x *ReviewLogEntry) GetNextDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextDueAt
+	}
+	return nil
+}
+
+func (x *ReviewLogEntry) GetElapsedDays() float64 {
+	if x != nil {
+		return x.ElapsedDays
+	}
+	return 0
+}
+
+func (x *ReviewLogEntry) GetReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReviewedAt
+	}
+	return nil
+}
+
+func (x *ReviewLogEntry) GetClientReviewedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClientReviewedAt
+	}
+	return nil
+}
+
+func (x *ReviewLogEntry) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ReviewLogEntry) GetReverted() bool {
+	if x != nil {
+		return x.Reverted
+	}
+	return false
+}
+
+type GetReviewHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*ReviewLogEntry      `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	TotalPages    int32                  `protobuf:"varint,5,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReviewHistoryResponse) Reset() {
+	*x = GetReviewHistoryResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReviewHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReviewHistoryResponse) ProtoMessage() {}
+
+func (x *GetReviewHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReviewHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetReviewHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetReviewHistoryResponse) GetEntries() []*ReviewLogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *GetReviewHistoryResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *GetReviewHistoryResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetReviewHistoryResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetReviewHistoryResponse) GetTotalPages() int32 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+type UndoReviewRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // Optional, undo only reviews from this session
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndoReviewRequest) Reset() {
+	*x = UndoReviewRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndoReviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoReviewRequest) ProtoMessage() {}
+
+func (x *UndoReviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndoReviewRequest.ProtoReflect.Descriptor instead.
+func (*UndoReviewRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UndoReviewRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type UndoReviewResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ReviewId             string                 `protobuf:"bytes,1,opt,name=review_id,json=reviewId,proto3" json:"review_id,omitempty"`
+	FlashcardId          string                 `protobuf:"bytes,2,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	RestoredStage        int32                  `protobuf:"varint,3,opt,name=restored_stage,json=restoredStage,proto3" json:"restored_stage,omitempty"`
+	RestoredNextReviewAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=restored_next_review_at,json=restoredNextReviewAt,proto3" json:"restored_next_review_at,omitempty"`
+	RemainingUndos       int32                  `protobuf:"varint,5,opt,name=remaining_undos,json=remainingUndos,proto3" json:"remaining_undos,omitempty"` // Reviews in the session that can still be undone
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *UndoReviewResponse) Reset() {
+	*x = UndoReviewResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndoReviewResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoReviewResponse) ProtoMessage() {}
+
+func (x *UndoReviewResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndoReviewResponse.ProtoReflect.Descriptor instead.
+func (*UndoReviewResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UndoReviewResponse) GetReviewId() string {
+	if x != nil {
+		return x.ReviewId
+	}
+	return ""
+}
+
+func (x *UndoReviewResponse) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *UndoReviewResponse) GetRestoredStage() int32 {
+	if x != nil {
+		return x.RestoredStage
+	}
+	return 0
+}
+
+func (x *UndoReviewResponse) GetRestoredNextReviewAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RestoredNextReviewAt
+	}
+	return nil
+}
+
+func (x *UndoReviewResponse) GetRemainingUndos() int32 {
+	if x != nil {
+		return x.RemainingUndos
+	}
+	return 0
+}
+
+type GetReviewQueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaterialIds   []string               `protobuf:"bytes,1,rep,name=material_ids,json=materialIds,proto3" json:"material_ids,omitempty"` // Optional, only cards from these materials
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`                                  // Optional, only cards from materials with any of these tags
+	MaxCards      int32                  `protobuf:"varint,3,opt,name=max_cards,json=maxCards,proto3" json:"max_cards,omitempty"`         // Maximum cards to return, defaults to 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReviewQueueRequest) Reset() {
+	*x = GetReviewQueueRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReviewQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReviewQueueRequest) ProtoMessage() {}
+
+func (x *GetReviewQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReviewQueueRequest.ProtoReflect.Descriptor instead.
+func (*GetReviewQueueRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetReviewQueueRequest) GetMaterialIds() []string {
+	if x != nil {
+		return x.MaterialIds
+	}
+	return nil
+}
+
+func (x *GetReviewQueueRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *GetReviewQueueRequest) GetMaxCards() int32 {
+	if x != nil {
+		return x.MaxCards
+	}
+	return 0
+}
+
+type GetReviewQueueResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Flashcards        []*Flashcard           `protobuf:"bytes,1,rep,name=flashcards,proto3" json:"flashcards,omitempty"`                                           // Due cards interleaved across materials in a stable order
+	TotalDue          int32                  `protobuf:"varint,2,opt,name=total_due,json=totalDue,proto3" json:"total_due,omitempty"`                              // All due cards matching the filters, before any limits are applied
+	NewCardsRemaining int32                  `protobuf:"varint,3,opt,name=new_cards_remaining,json=newCardsRemaining,proto3" json:"new_cards_remaining,omitempty"` // New cards the user may still start today
+	ReviewsRemaining  int32                  `protobuf:"varint,4,opt,name=reviews_remaining,json=reviewsRemaining,proto3" json:"reviews_remaining,omitempty"`      // Reviews the user may still do today
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetReviewQueueResponse) Reset() {
+	*x = GetReviewQueueResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReviewQueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReviewQueueResponse) ProtoMessage() {}
+
+func (x *GetReviewQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReviewQueueResponse.ProtoReflect.Descriptor instead.
+func (*GetReviewQueueResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetReviewQueueResponse) GetFlashcards() []*Flashcard {
+	if x != nil {
+		return x.Flashcards
+	}
+	return nil
+}
+
+func (x *GetReviewQueueResponse) GetTotalDue() int32 {
+	if x != nil {
+		return x.TotalDue
+	}
+	return 0
+}
+
+func (x *GetReviewQueueResponse) GetNewCardsRemaining() int32 {
+	if x != nil {
+		return x.NewCardsRemaining
+	}
+	return 0
+}
+
+func (x *GetReviewQueueResponse) GetReviewsRemaining() int32 {
+	if x != nil {
+		return x.ReviewsRemaining
+	}
+	return 0
+}
+
+type StudySettings struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	NewCardsPerDay   int32                  `protobuf:"varint,1,opt,name=new_cards_per_day,json=newCardsPerDay,proto3" json:"new_cards_per_day,omitempty"`
+	MaxReviewsPerDay int32                  `protobuf:"varint,2,opt,name=max_reviews_per_day,json=maxReviewsPerDay,proto3" json:"max_reviews_per_day,omitempty"`
+	DayRolloverHour  int32                  `protobuf:"varint,3,opt,name=day_rollover_hour,json=dayRolloverHour,proto3" json:"day_rollover_hour,omitempty"` // Local hour (0-23) at which a new study day starts
+	Timezone         string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`                                         // IANA name, e.g. "Asia/Kolkata"
+	LeechThreshold   int32                  `protobuf:"varint,5,opt,name=leech_threshold,json=leechThreshold,proto3" json:"leech_threshold,omitempty"`      // Lapses before a card becomes a leech, at least 1
+	LeechAction      LeechAction            `protobuf:"varint,6,opt,name=leech_action,json=leechAction,proto3,enum=learning.LeechAction" json:"leech_action,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StudySettings) Reset() {
+	*x = StudySettings{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StudySettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StudySettings) ProtoMessage() {}
+
+func (x *StudySettings) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StudySettings.ProtoReflect.Descriptor instead.
+func (*StudySettings) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *StudySettings) GetNewCardsPerDay() int32 {
+	if x != nil {
+		return x.NewCardsPerDay
+	}
+	return 0
+}
+
+func (x *StudySettings) GetMaxReviewsPerDay() int32 {
+	if x != nil {
+		return x.MaxReviewsPerDay
+	}
+	return 0
+}
+
+func (x *StudySettings) GetDayRolloverHour() int32 {
+	if x != nil {
+		return x.DayRolloverHour
+	}
+	return 0
+}
+
+func (x *StudySettings) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *StudySettings) GetLeechThreshold() int32 {
+	if x != nil {
+		return x.LeechThreshold
+	}
+	return 0
+}
+
+func (x *StudySettings) GetLeechAction() LeechAction {
+	if x != nil {
+		return x.LeechAction
+	}
+	return LeechAction_LEECH_ACTION_TAG
+}
+
+type SuspendFlashcardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Restore       bool                   `protobuf:"varint,2,opt,name=restore,proto3" json:"restore,omitempty"` // Unsuspend the card instead
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuspendFlashcardRequest) Reset() {
+	*x = SuspendFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuspendFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuspendFlashcardRequest) ProtoMessage() {}
+
+func (x *SuspendFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuspendFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*SuspendFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SuspendFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *SuspendFlashcardRequest) GetRestore() bool {
+	if x != nil {
+		return x.Restore
+	}
+	return false
+}
+
+type BuryFlashcardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Restore       bool                   `protobuf:"varint,2,opt,name=restore,proto3" json:"restore,omitempty"` // Unbury the card instead
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BuryFlashcardRequest) Reset() {
+	*x = BuryFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BuryFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuryFlashcardRequest) ProtoMessage() {}
+
+func (x *BuryFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuryFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*BuryFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *BuryFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *BuryFlashcardRequest) GetRestore() bool {
+	if x != nil {
+		return x.Restore
+	}
+	return false
+}
+
+type FlagFlashcardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Flag          FlashcardFlag          `protobuf:"varint,2,opt,name=flag,proto3,enum=learning.FlashcardFlag" json:"flag,omitempty"` // FLASHCARD_FLAG_NONE clears the flag
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlagFlashcardRequest) Reset() {
+	*x = FlagFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlagFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlagFlashcardRequest) ProtoMessage() {}
+
+func (x *FlagFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlagFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*FlagFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FlagFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+func (x *FlagFlashcardRequest) GetFlag() FlashcardFlag {
+	if x != nil {
+		return x.Flag
+	}
+	return FlashcardFlag_FLASHCARD_FLAG_NONE
+}
+
+type RewriteFlashcardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewriteFlashcardRequest) Reset() {
+	*x = RewriteFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewriteFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewriteFlashcardRequest) ProtoMessage() {}
+
+func (x *RewriteFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewriteFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*RewriteFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RewriteFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+// A suggested clearer version of a card; save it with UpdateFlashcard.
+type RewriteFlashcardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Question      string                 `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	Answer        string                 `protobuf:"bytes,2,opt,name=answer,proto3" json:"answer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewriteFlashcardResponse) Reset() {
+	*x = RewriteFlashcardResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewriteFlashcardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewriteFlashcardResponse) ProtoMessage() {}
+
+func (x *RewriteFlashcardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewriteFlashcardResponse.ProtoReflect.Descriptor instead.
+func (*RewriteFlashcardResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RewriteFlashcardResponse) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *RewriteFlashcardResponse) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+// Creating a cloze card creates one sibling per cloze number; the first is returned.
+type CreateFlashcardRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MaterialId      string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"` // Material to add the card to; empty starts a new manual deck
+	Question        string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
+	Answer          string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	DeckTitle       string                 `protobuf:"bytes,4,opt,name=deck_title,json=deckTitle,proto3" json:"deck_title,omitempty"`                      // Title of the new deck when material_id is empty
+	Tags            []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`                                                 // Tags of the new deck when material_id is empty
+	CardType        CardType               `protobuf:"varint,6,opt,name=card_type,json=cardType,proto3,enum=learning.CardType" json:"card_type,omitempty"` // For cloze cards question holds the cloze text and answer any extra notes
+	GenerateReverse bool                   `protobuf:"varint,7,opt,name=generate_reverse,json=generateReverse,proto3" json:"generate_reverse,omitempty"`   // Also add a reverse sibling of a basic card
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateFlashcardRequest) Reset() {
+	*x = CreateFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFlashcardRequest) ProtoMessage() {}
+
+func (x *CreateFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*CreateFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *CreateFlashcardRequest) GetMaterialId() string {
+	if x != nil {
+		return x.MaterialId
+	}
+	return ""
+}
+
+func (x *CreateFlashcardRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *CreateFlashcardRequest) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *CreateFlashcardRequest) GetDeckTitle() string {
+	if x != nil {
+		return x.DeckTitle
+	}
+	return ""
+}
+
+func (x *CreateFlashcardRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateFlashcardRequest) GetCardType() CardType {
+	if x != nil {
+		return x.CardType
+	}
+	return CardType_CARD_TYPE_BASIC
+}
+
+func (x *CreateFlashcardRequest) GetGenerateReverse() bool {
+	if x != nil {
+		return x.GenerateReverse
+	}
+	return false
+}
+
+type DeleteFlashcardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFlashcardRequest) Reset() {
+	*x = DeleteFlashcardRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFlashcardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFlashcardRequest) ProtoMessage() {}
+
+func (x *DeleteFlashcardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFlashcardRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFlashcardRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *DeleteFlashcardRequest) GetFlashcardId() string {
+	if x != nil {
+		return x.FlashcardId
+	}
+	return ""
+}
+
+type BulkDeleteFlashcardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlashcardIds  []string               `protobuf:"bytes,1,rep,name=flashcard_ids,json=flashcardIds,proto3" json:"flashcard_ids,omitempty"` // All are deleted, or none if any is missing or not the user's
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkDeleteFlashcardsRequest) Reset() {
+	*x = BulkDeleteFlashcardsRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkDeleteFlashcardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkDeleteFlashcardsRequest) ProtoMessage() {}
+
+func (x *BulkDeleteFlashcardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkDeleteFlashcardsRequest.ProtoReflect.Descriptor instead.
+func (*BulkDeleteFlashcardsRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *BulkDeleteFlashcardsRequest) GetFlashcardIds() []string {
 	if x != nil {
-		return x.Tags
+		return x.FlashcardIds
 	}
 	return nil
 }
 
-type FlashcardList struct {
+type BulkDeleteFlashcardsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Flashcards    []*Flashcard           `protobuf:"bytes,1,rep,name=flashcards,proto3" json:"flashcards,omitempty"`
+	DeletedCount  int32                  `protobuf:"varint,1,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FlashcardList) Reset() {
-	*x = FlashcardList{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[8]
+func (x *BulkDeleteFlashcardsResponse) Reset() {
+	*x = BulkDeleteFlashcardsResponse{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FlashcardList) String() string {
+func (x *BulkDeleteFlashcardsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FlashcardList) ProtoMessage() {}
+func (*BulkDeleteFlashcardsResponse) ProtoMessage() {}
 
-func (x *FlashcardList) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[8]
+func (x *BulkDeleteFlashcardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -567,40 +3210,42 @@ func (x *FlashcardList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FlashcardList.ProtoReflect.Descriptor instead.
-func (*FlashcardList) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use BulkDeleteFlashcardsResponse.ProtoReflect.Descriptor instead.
+func (*BulkDeleteFlashcardsResponse) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *FlashcardList) GetFlashcards() []*Flashcard {
+func (x *BulkDeleteFlashcardsResponse) GetDeletedCount() int32 {
 	if x != nil {
-		return x.Flashcards
+		return x.DeletedCount
 	}
-	return nil
+	return 0
 }
 
-type CompleteReviewRequest struct {
+type GenerateQuizRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	MaterialId    string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`           // Quiz on this material's cards
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`                                           // Or on the cards of all materials with this tag
+	QuestionCount int32                  `protobuf:"varint,3,opt,name=question_count,json=questionCount,proto3" json:"question_count,omitempty"` // Defaults to 10
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CompleteReviewRequest) Reset() {
-	*x = CompleteReviewRequest{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[9]
+func (x *GenerateQuizRequest) Reset() {
+	*x = GenerateQuizRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CompleteReviewRequest) String() string {
+func (x *GenerateQuizRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CompleteReviewRequest) ProtoMessage() {}
+func (*GenerateQuizRequest) ProtoMessage() {}
 
-func (x *CompleteReviewRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[9]
+func (x *GenerateQuizRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -611,40 +3256,57 @@ func (x *CompleteReviewRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CompleteReviewRequest.ProtoReflect.Descriptor instead.
-func (*CompleteReviewRequest) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use GenerateQuizRequest.ProtoReflect.Descriptor instead.
+func (*GenerateQuizRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *CompleteReviewRequest) GetFlashcardId() string {
+func (x *GenerateQuizRequest) GetMaterialId() string {
 	if x != nil {
-		return x.FlashcardId
+		return x.MaterialId
 	}
 	return ""
 }
 
-type FailReviewRequest struct {
+func (x *GenerateQuizRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *GenerateQuizRequest) GetQuestionCount() int32 {
+	if x != nil {
+		return x.QuestionCount
+	}
+	return 0
+}
+
+type QuizQuestion struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FlashcardId   string                 `protobuf:"bytes,2,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Question      string                 `protobuf:"bytes,3,opt,name=question,proto3" json:"question,omitempty"`
+	Choices       []string               `protobuf:"bytes,4,rep,name=choices,proto3" json:"choices,omitempty"` // One correct answer among generated distractors
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FailReviewRequest) Reset() {
-	*x = FailReviewRequest{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[10]
+func (x *QuizQuestion) Reset() {
+	*x = QuizQuestion{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FailReviewRequest) String() string {
+func (x *QuizQuestion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FailReviewRequest) ProtoMessage() {}
+func (*QuizQuestion) ProtoMessage() {}
 
-func (x *FailReviewRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[10]
+func (x *QuizQuestion) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -655,40 +3317,62 @@ func (x *FailReviewRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FailReviewRequest.ProtoReflect.Descriptor instead.
-func (*FailReviewRequest) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use QuizQuestion.ProtoReflect.Descriptor instead.
+func (*QuizQuestion) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *FailReviewRequest) GetFlashcardId() string {
+func (x *QuizQuestion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QuizQuestion) GetFlashcardId() string {
 	if x != nil {
 		return x.FlashcardId
 	}
 	return ""
 }
 
-type GetAllTagsResponse struct {
+func (x *QuizQuestion) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *QuizQuestion) GetChoices() []string {
+	if x != nil {
+		return x.Choices
+	}
+	return nil
+}
+
+type Quiz struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Questions     []*QuizQuestion        `protobuf:"bytes,2,rep,name=questions,proto3" json:"questions,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAllTagsResponse) Reset() {
-	*x = GetAllTagsResponse{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[11]
+func (x *Quiz) Reset() {
+	*x = Quiz{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAllTagsResponse) String() string {
+func (x *Quiz) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAllTagsResponse) ProtoMessage() {}
+func (*Quiz) ProtoMessage() {}
 
-func (x *GetAllTagsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[11]
+func (x *Quiz) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -699,41 +3383,48 @@ func (x *GetAllTagsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAllTagsResponse.ProtoReflect.Descriptor instead.
-func (*GetAllTagsResponse) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use Quiz.ProtoReflect.Descriptor instead.
+func (*Quiz) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *GetAllTagsResponse) GetTags() []string {
+func (x *Quiz) GetId() string {
 	if x != nil {
-		return x.Tags
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Quiz) GetQuestions() []*QuizQuestion {
+	if x != nil {
+		return x.Questions
 	}
 	return nil
 }
 
-type NotificationStatusResponse struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	DueFlashcardsCount int32                  `protobuf:"varint,1,opt,name=due_flashcards_count,json=dueFlashcardsCount,proto3" json:"due_flashcards_count,omitempty"`
-	HasDueMaterials    bool                   `protobuf:"varint,2,opt,name=has_due_materials,json=hasDueMaterials,proto3" json:"has_due_materials,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+type QuizAnswer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuestionId    string                 `protobuf:"bytes,1,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	ChoiceIndex   int32                  `protobuf:"varint,2,opt,name=choice_index,json=choiceIndex,proto3" json:"choice_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NotificationStatusResponse) Reset() {
-	*x = NotificationStatusResponse{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[12]
+func (x *QuizAnswer) Reset() {
+	*x = QuizAnswer{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NotificationStatusResponse) String() string {
+func (x *QuizAnswer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NotificationStatusResponse) ProtoMessage() {}
+func (*QuizAnswer) ProtoMessage() {}
 
-func (x *NotificationStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[12]
+func (x *QuizAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -744,47 +3435,49 @@ func (x *NotificationStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NotificationStatusResponse.ProtoReflect.Descriptor instead.
-func (*NotificationStatusResponse) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use QuizAnswer.ProtoReflect.Descriptor instead.
+func (*QuizAnswer) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *NotificationStatusResponse) GetDueFlashcardsCount() int32 {
+func (x *QuizAnswer) GetQuestionId() string {
 	if x != nil {
-		return x.DueFlashcardsCount
+		return x.QuestionId
 	}
-	return 0
+	return ""
 }
 
-func (x *NotificationStatusResponse) GetHasDueMaterials() bool {
+func (x *QuizAnswer) GetChoiceIndex() int32 {
 	if x != nil {
-		return x.HasDueMaterials
+		return x.ChoiceIndex
 	}
-	return false
+	return 0
 }
 
-type GetMaterialSummaryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	MaterialId    string                 `protobuf:"bytes,1,opt,name=material_id,json=materialId,proto3" json:"material_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type SubmitQuizAnswersRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	QuizId          string                 `protobuf:"bytes,1,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	Answers         []*QuizAnswer          `protobuf:"bytes,2,rep,name=answers,proto3" json:"answers,omitempty"`                                           // Unanswered questions count as wrong
+	ApplyToSchedule bool                   `protobuf:"varint,3,opt,name=apply_to_schedule,json=applyToSchedule,proto3" json:"apply_to_schedule,omitempty"` // Review the cards that are due today with the outcomes
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *GetMaterialSummaryRequest) Reset() {
-	*x = GetMaterialSummaryRequest{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[13]
+func (x *SubmitQuizAnswersRequest) Reset() {
+	*x = SubmitQuizAnswersRequest{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetMaterialSummaryRequest) String() string {
+func (x *SubmitQuizAnswersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetMaterialSummaryRequest) ProtoMessage() {}
+func (*SubmitQuizAnswersRequest) ProtoMessage() {}
 
-func (x *GetMaterialSummaryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[13]
+func (x *SubmitQuizAnswersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -795,41 +3488,58 @@ func (x *GetMaterialSummaryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetMaterialSummaryRequest.ProtoReflect.Descriptor instead.
-func (*GetMaterialSummaryRequest) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use SubmitQuizAnswersRequest.ProtoReflect.Descriptor instead.
+func (*SubmitQuizAnswersRequest) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *GetMaterialSummaryRequest) GetMaterialId() string {
+func (x *SubmitQuizAnswersRequest) GetQuizId() string {
 	if x != nil {
-		return x.MaterialId
+		return x.QuizId
 	}
 	return ""
 }
 
-type GetMaterialSummaryResponse struct {
+func (x *SubmitQuizAnswersRequest) GetAnswers() []*QuizAnswer {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+func (x *SubmitQuizAnswersRequest) GetApplyToSchedule() bool {
+	if x != nil {
+		return x.ApplyToSchedule
+	}
+	return false
+}
+
+type QuizQuestionResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Summary       string                 `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	QuestionId    string                 `protobuf:"bytes,1,opt,name=question_id,json=questionId,proto3" json:"question_id,omitempty"`
+	FlashcardId   string                 `protobuf:"bytes,2,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
+	Correct       bool                   `protobuf:"varint,3,opt,name=correct,proto3" json:"correct,omitempty"`
+	CorrectIndex  int32                  `protobuf:"varint,4,opt,name=correct_index,json=correctIndex,proto3" json:"correct_index,omitempty"`
+	ChosenIndex   int32                  `protobuf:"varint,5,opt,name=chosen_index,json=chosenIndex,proto3" json:"chosen_index,omitempty"` // -1 if unanswered
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetMaterialSummaryResponse) Reset() {
-	*x = GetMaterialSummaryResponse{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[14]
+func (x *QuizQuestionResult) Reset() {
+	*x = QuizQuestionResult{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetMaterialSummaryResponse) String() string {
+func (x *QuizQuestionResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetMaterialSummaryResponse) ProtoMessage() {}
+func (*QuizQuestionResult) ProtoMessage() {}
 
-func (x *GetMaterialSummaryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[14]
+func (x *QuizQuestionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -840,49 +3550,74 @@ func (x *GetMaterialSummaryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetMaterialSummaryResponse.ProtoReflect.Descriptor instead.
-func (*GetMaterialSummaryResponse) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use QuizQuestionResult.ProtoReflect.Descriptor instead.
+func (*QuizQuestionResult) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *GetMaterialSummaryResponse) GetSummary() string {
+func (x *QuizQuestionResult) GetQuestionId() string {
 	if x != nil {
-		return x.Summary
+		return x.QuestionId
 	}
 	return ""
 }
 
-func (x *GetMaterialSummaryResponse) GetTitle() string {
+func (x *QuizQuestionResult) GetFlashcardId() string {
 	if x != nil {
-		return x.Title
+		return x.FlashcardId
 	}
 	return ""
 }
 
-type UpdateFlashcardRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	FlashcardId   string                 `protobuf:"bytes,1,opt,name=flashcard_id,json=flashcardId,proto3" json:"flashcard_id,omitempty"`
-	Question      string                 `protobuf:"bytes,2,opt,name=question,proto3" json:"question,omitempty"`
-	Answer        string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *QuizQuestionResult) GetCorrect() bool {
+	if x != nil {
+		return x.Correct
+	}
+	return false
 }
 
-func (x *UpdateFlashcardRequest) Reset() {
-	*x = UpdateFlashcardRequest{}
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[15]
+func (x *QuizQuestionResult) GetCorrectIndex() int32 {
+	if x != nil {
+		return x.CorrectIndex
+	}
+	return 0
+}
+
+func (x *QuizQuestionResult) GetChosenIndex() int32 {
+	if x != nil {
+		return x.ChosenIndex
+	}
+	return 0
+}
+
+type QuizResult struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AttemptId        string                 `protobuf:"bytes,1,opt,name=attempt_id,json=attemptId,proto3" json:"attempt_id,omitempty"`
+	QuizId           string                 `protobuf:"bytes,2,opt,name=quiz_id,json=quizId,proto3" json:"quiz_id,omitempty"`
+	CorrectCount     int32                  `protobuf:"varint,3,opt,name=correct_count,json=correctCount,proto3" json:"correct_count,omitempty"`
+	TotalCount       int32                  `protobuf:"varint,4,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Results          []*QuizQuestionResult  `protobuf:"bytes,5,rep,name=results,proto3" json:"results,omitempty"`
+	CardsRescheduled int32                  `protobuf:"varint,6,opt,name=cards_rescheduled,json=cardsRescheduled,proto3" json:"cards_rescheduled,omitempty"`
+	SubmittedAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=submitted_at,json=submittedAt,proto3" json:"submitted_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *QuizResult) Reset() {
+	*x = QuizResult{}
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateFlashcardRequest) String() string {
+func (x *QuizResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateFlashcardRequest) ProtoMessage() {}
+func (*QuizResult) ProtoMessage() {}
 
-func (x *UpdateFlashcardRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_backend_proto_learning_learning_proto_msgTypes[15]
+func (x *QuizResult) ProtoReflect() protoreflect.Message {
+	mi := &file_backend_proto_learning_learning_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -893,49 +3628,100 @@ func (x *UpdateFlashcardRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateFlashcardRequest.ProtoReflect.Descriptor instead.
-func (*UpdateFlashcardRequest) Descriptor() ([]byte, []int) {
-	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use QuizResult.ProtoReflect.Descriptor instead.
+func (*QuizResult) Descriptor() ([]byte, []int) {
+	return file_backend_proto_learning_learning_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *UpdateFlashcardRequest) GetFlashcardId() string {
+func (x *QuizResult) GetAttemptId() string {
 	if x != nil {
-		return x.FlashcardId
+		return x.AttemptId
 	}
 	return ""
 }
 
-func (x *UpdateFlashcardRequest) GetQuestion() string {
+func (x *QuizResult) GetQuizId() string {
 	if x != nil {
-		return x.Question
+		return x.QuizId
 	}
 	return ""
 }
 
-func (x *UpdateFlashcardRequest) GetAnswer() string {
+func (x *QuizResult) GetCorrectCount() int32 {
 	if x != nil {
-		return x.Answer
+		return x.CorrectCount
 	}
-	return ""
+	return 0
+}
+
+func (x *QuizResult) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *QuizResult) GetResults() []*QuizQuestionResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *QuizResult) GetCardsRescheduled() int32 {
+	if x != nil {
+		return x.CardsRescheduled
+	}
+	return 0
+}
+
+func (x *QuizResult) GetSubmittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SubmittedAt
+	}
+	return nil
 }
 
 var File_backend_proto_learning_learning_proto protoreflect.FileDescriptor
 
 const file_backend_proto_learning_learning_proto_rawDesc = "" +
 	"\n" +
-	"%backend/proto/learning/learning.proto\x12\blearning\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\x86\x01\n" +
+	"%backend/proto/learning/learning.proto\x12\blearning\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xd2\x01\n" +
 	"\x12AddMaterialRequest\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\x12#\n" +
 	"\rexisting_tags\x18\x03 \x03(\tR\fexistingTags\x12\x1d\n" +
 	"\n" +
-	"image_data\x18\x04 \x01(\tR\timageData\"\x8f\x01\n" +
+	"image_data\x18\x04 \x01(\tR\timageData\x12\x1f\n" +
+	"\vallow_cloze\x18\x05 \x01(\bR\n" +
+	"allowCloze\x12)\n" +
+	"\x10generate_reverse\x18\x06 \x01(\bR\x0fgenerateReverse\"\xee\x01\n" +
 	"\x13AddMaterialResponse\x12\x1f\n" +
 	"\vmaterial_id\x18\x01 \x01(\tR\n" +
 	"materialId\x12-\n" +
 	"\x12flashcards_created\x18\x02 \x01(\x05R\x11flashcardsCreated\x12\x14\n" +
 	"\x05title\x18\x03 \x01(\tR\x05title\x12\x12\n" +
-	"\x04tags\x18\x04 \x03(\tR\x04tags\"8\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12\x15\n" +
+	"\x06job_id\x18\x05 \x01(\tR\x05jobId\x12!\n" +
+	"\ftotal_chunks\x18\x06 \x01(\x05R\vtotalChunks\x12#\n" +
+	"\rfailed_chunks\x18\a \x03(\x05R\ffailedChunks\"\x87\x01\n" +
+	"\x18RetryFailedChunksRequest\x12\x1f\n" +
+	"\vmaterial_id\x18\x01 \x01(\tR\n" +
+	"materialId\x12\x1f\n" +
+	"\vallow_cloze\x18\x02 \x01(\bR\n" +
+	"allowCloze\x12)\n" +
+	"\x10generate_reverse\x18\x03 \x01(\bR\x0fgenerateReverse\"1\n" +
+	"\x18WatchIngestionJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x8f\x02\n" +
+	"\fIngestionJob\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12.\n" +
+	"\x05stage\x18\x02 \x01(\x0e2\x18.learning.IngestionStageR\x05stage\x12\x14\n" +
+	"\x05chunk\x18\x03 \x01(\x05R\x05chunk\x12!\n" +
+	"\ftotal_chunks\x18\x04 \x01(\x05R\vtotalChunks\x125\n" +
+	"\x06result\x18\x05 \x01(\v2\x1d.learning.AddMaterialResponseR\x06result\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"8\n" +
 	"\x15DeleteMaterialRequest\x12\x1f\n" +
 	"\vmaterial_id\x18\x01 \x01(\tR\n" +
 	"materialId\"h\n" +
@@ -957,7 +3743,7 @@ const file_backend_proto_learning_learning_proto_rawDesc = "" +
 	"totalPages\":\n" +
 	"\x17GetDueFlashcardsRequest\x12\x1f\n" +
 	"\vmaterial_id\x18\x01 \x01(\tR\n" +
-	"materialId\"\xe2\x01\n" +
+	"materialId\"\xd6\x06\n" +
 	"\tFlashcard\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
 	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x16\n" +
@@ -965,20 +3751,51 @@ const file_backend_proto_learning_learning_proto_rawDesc = "" +
 	"\x05stage\x18\x04 \x01(\x05R\x05stage\x12@\n" +
 	"\x0enext_review_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fnextReviewAt\x12%\n" +
 	"\x0ematerial_title\x18\x06 \x01(\tR\rmaterialTitle\x12\x12\n" +
-	"\x04tags\x18\a \x03(\tR\x04tags\"D\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12\x1f\n" +
+	"\vease_factor\x18\b \x01(\x01R\n" +
+	"easeFactor\x12#\n" +
+	"\rinterval_days\x18\t \x01(\x05R\fintervalDays\x12 \n" +
+	"\vrepetitions\x18\n" +
+	" \x01(\x05R\vrepetitions\x12\x1c\n" +
+	"\tstability\x18\v \x01(\x01R\tstability\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\f \x01(\x01R\n" +
+	"difficulty\x12D\n" +
+	"\x10last_reviewed_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\x0elastReviewedAt\x12\x1f\n" +
+	"\vmaterial_id\x18\x0e \x01(\tR\n" +
+	"materialId\x121\n" +
+	"\x06status\x18\x0f \x01(\x0e2\x19.learning.FlashcardStatusR\x06status\x12+\n" +
+	"\x04flag\x18\x10 \x01(\x0e2\x17.learning.FlashcardFlagR\x04flag\x12=\n" +
+	"\fburied_until\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\vburiedUntil\x12\x16\n" +
+	"\x06lapses\x18\x12 \x01(\x05R\x06lapses\x12\x19\n" +
+	"\bis_leech\x18\x13 \x01(\bR\aisLeech\x12/\n" +
+	"\tcard_type\x18\x14 \x01(\x0e2\x12.learning.CardTypeR\bcardType\x12\x17\n" +
+	"\anote_id\x18\x15 \x01(\tR\x06noteId\x12\x1f\n" +
+	"\vcloze_index\x18\x16 \x01(\x05R\n" +
+	"clozeIndex\x12\x14\n" +
+	"\x05front\x18\x17 \x01(\tR\x05front\x12\x12\n" +
+	"\x04back\x18\x18 \x01(\tR\x04back\"D\n" +
 	"\rFlashcardList\x123\n" +
 	"\n" +
 	"flashcards\x18\x01 \x03(\v2\x13.learning.FlashcardR\n" +
-	"flashcards\":\n" +
+	"flashcards\"\xa3\x01\n" +
 	"\x15CompleteReviewRequest\x12!\n" +
-	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\"6\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12H\n" +
+	"\x12client_reviewed_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x10clientReviewedAt\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\"\x9f\x01\n" +
 	"\x11FailReviewRequest\x12!\n" +
-	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\"(\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12H\n" +
+	"\x12client_reviewed_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x10clientReviewedAt\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\"(\n" +
 	"\x12GetAllTagsResponse\x12\x12\n" +
-	"\x04tags\x18\x01 \x03(\tR\x04tags\"z\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\"\xc7\x01\n" +
 	"\x1aNotificationStatusResponse\x120\n" +
 	"\x14due_flashcards_count\x18\x01 \x01(\x05R\x12dueFlashcardsCount\x12*\n" +
-	"\x11has_due_materials\x18\x02 \x01(\bR\x0fhasDueMaterials\"<\n" +
+	"\x11has_due_materials\x18\x02 \x01(\bR\x0fhasDueMaterials\x12&\n" +
+	"\x0fnew_cards_count\x18\x03 \x01(\x05R\rnewCardsCount\x12#\n" +
+	"\rreviews_count\x18\x04 \x01(\x05R\freviewsCount\"<\n" +
 	"\x19GetMaterialSummaryRequest\x12\x1f\n" +
 	"\vmaterial_id\x18\x01 \x01(\tR\n" +
 	"materialId\"L\n" +
@@ -988,7 +3805,215 @@ const file_backend_proto_learning_learning_proto_rawDesc = "" +
 	"\x16UpdateFlashcardRequest\x12!\n" +
 	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12\x1a\n" +
 	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x16\n" +
-	"\x06answer\x18\x03 \x01(\tR\x06answer2\xa7\x06\n" +
+	"\x06answer\x18\x03 \x01(\tR\x06answer\"\xd1\x01\n" +
+	"\x16ReviewFlashcardRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12+\n" +
+	"\x05grade\x18\x02 \x01(\x0e2\x15.learning.ReviewGradeR\x05grade\x12H\n" +
+	"\x12client_reviewed_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x10clientReviewedAt\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\"\xed\x01\n" +
+	"\x17GradeTypedAnswerRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12!\n" +
+	"\ftyped_answer\x18\x02 \x01(\tR\vtypedAnswer\x12#\n" +
+	"\rrecord_review\x18\x03 \x01(\bR\frecordReview\x12H\n" +
+	"\x12client_reviewed_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x10clientReviewedAt\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x05 \x01(\tR\tsessionId\"\xcd\x01\n" +
+	"\x18GradeTypedAnswerResponse\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x01R\x05score\x12>\n" +
+	"\x0fsuggested_grade\x18\x02 \x01(\x0e2\x15.learning.ReviewGradeR\x0esuggestedGrade\x12 \n" +
+	"\vexplanation\x18\x03 \x01(\tR\vexplanation\x129\n" +
+	"\x06review\x18\x04 \x01(\v2!.learning.ReviewFlashcardResponseR\x06review\"\x97\x02\n" +
+	"\x17ReviewFlashcardResponse\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\x05R\x05stage\x12@\n" +
+	"\x0enext_review_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\fnextReviewAt\x12#\n" +
+	"\rinterval_days\x18\x03 \x01(\x05R\fintervalDays\x12\x1f\n" +
+	"\vease_factor\x18\x04 \x01(\x01R\n" +
+	"easeFactor\x12 \n" +
+	"\vrepetitions\x18\x05 \x01(\x05R\vrepetitions\x12\x1c\n" +
+	"\tstability\x18\x06 \x01(\x01R\tstability\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\a \x01(\x01R\n" +
+	"difficulty\"\xe8\x03\n" +
+	"\x18OptimizeScheduleResponse\x12\x18\n" +
+	"\aweights\x18\x01 \x03(\x01R\aweights\x12!\n" +
+	"\freview_count\x18\x02 \x01(\x05R\vreviewCount\x12\x19\n" +
+	"\blog_loss\x18\x03 \x01(\x01R\alogLoss\x12+\n" +
+	"\x11desired_retention\x18\x04 \x01(\x01R\x10desiredRetention\x128\n" +
+	"\x18predicted_retention_fsrs\x18\x05 \x01(\x01R\x16predictedRetentionFsrs\x12:\n" +
+	"\x19predicted_retention_fixed\x18\x06 \x01(\x01R\x17predictedRetentionFixed\x120\n" +
+	"\x14fixed_intervals_days\x18\a \x03(\x05R\x12fixedIntervalsDays\x12.\n" +
+	"\x13fsrs_intervals_days\x18\b \x03(\x05R\x11fsrsIntervalsDays\x120\n" +
+	"\x14used_default_weights\x18\t \x01(\bR\x12usedDefaultWeights\x12=\n" +
+	"\foptimized_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\voptimizedAt\"\xd9\x01\n" +
+	"\x17GetReviewHistoryRequest\x12\x1f\n" +
+	"\vmaterial_id\x18\x01 \x01(\tR\n" +
+	"materialId\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x12\n" +
+	"\x04page\x18\x05 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\"\x9d\x05\n" +
+	"\x0eReviewLogEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fflashcard_id\x18\x02 \x01(\tR\vflashcardId\x12\x1f\n" +
+	"\vmaterial_id\x18\x03 \x01(\tR\n" +
+	"materialId\x12%\n" +
+	"\x0ematerial_title\x18\x04 \x01(\tR\rmaterialTitle\x12\x1a\n" +
+	"\bquestion\x18\x05 \x01(\tR\bquestion\x12+\n" +
+	"\x05grade\x18\x06 \x01(\x0e2\x15.learning.ReviewGradeR\x05grade\x12\x1c\n" +
+	"\tscheduler\x18\a \x01(\tR\tscheduler\x12%\n" +
+	"\x0eprevious_stage\x18\b \x01(\x05R\rpreviousStage\x12\x1d\n" +
+	"\n" +
+	"next_stage\x18\t \x01(\x05R\tnextStage\x12B\n" +
+	"\x0fprevious_due_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\rpreviousDueAt\x12:\n" +
+	"\vnext_due_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tnextDueAt\x12!\n" +
+	"\felapsed_days\x18\f \x01(\x01R\velapsedDays\x12;\n" +
+	"\vreviewed_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"reviewedAt\x12H\n" +
+	"\x12client_reviewed_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\x10clientReviewedAt\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x0f \x01(\tR\tsessionId\x12\x1a\n" +
+	"\breverted\x18\x10 \x01(\bR\breverted\"\xc1\x01\n" +
+	"\x18GetReviewHistoryResponse\x122\n" +
+	"\aentries\x18\x01 \x03(\v2\x18.learning.ReviewLogEntryR\aentries\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1f\n" +
+	"\vtotal_pages\x18\x05 \x01(\x05R\n" +
+	"totalPages\"2\n" +
+	"\x11UndoReviewRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\xf7\x01\n" +
+	"\x12UndoReviewResponse\x12\x1b\n" +
+	"\treview_id\x18\x01 \x01(\tR\breviewId\x12!\n" +
+	"\fflashcard_id\x18\x02 \x01(\tR\vflashcardId\x12%\n" +
+	"\x0erestored_stage\x18\x03 \x01(\x05R\rrestoredStage\x12Q\n" +
+	"\x17restored_next_review_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x14restoredNextReviewAt\x12'\n" +
+	"\x0fremaining_undos\x18\x05 \x01(\x05R\x0eremainingUndos\"k\n" +
+	"\x15GetReviewQueueRequest\x12!\n" +
+	"\fmaterial_ids\x18\x01 \x03(\tR\vmaterialIds\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12\x1b\n" +
+	"\tmax_cards\x18\x03 \x01(\x05R\bmaxCards\"\xc7\x01\n" +
+	"\x16GetReviewQueueResponse\x123\n" +
+	"\n" +
+	"flashcards\x18\x01 \x03(\v2\x13.learning.FlashcardR\n" +
+	"flashcards\x12\x1b\n" +
+	"\ttotal_due\x18\x02 \x01(\x05R\btotalDue\x12.\n" +
+	"\x13new_cards_remaining\x18\x03 \x01(\x05R\x11newCardsRemaining\x12+\n" +
+	"\x11reviews_remaining\x18\x04 \x01(\x05R\x10reviewsRemaining\"\x94\x02\n" +
+	"\rStudySettings\x12)\n" +
+	"\x11new_cards_per_day\x18\x01 \x01(\x05R\x0enewCardsPerDay\x12-\n" +
+	"\x13max_reviews_per_day\x18\x02 \x01(\x05R\x10maxReviewsPerDay\x12*\n" +
+	"\x11day_rollover_hour\x18\x03 \x01(\x05R\x0fdayRolloverHour\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12'\n" +
+	"\x0fleech_threshold\x18\x05 \x01(\x05R\x0eleechThreshold\x128\n" +
+	"\fleech_action\x18\x06 \x01(\x0e2\x15.learning.LeechActionR\vleechAction\"V\n" +
+	"\x17SuspendFlashcardRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12\x18\n" +
+	"\arestore\x18\x02 \x01(\bR\arestore\"S\n" +
+	"\x14BuryFlashcardRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12\x18\n" +
+	"\arestore\x18\x02 \x01(\bR\arestore\"f\n" +
+	"\x14FlagFlashcardRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\x12+\n" +
+	"\x04flag\x18\x02 \x01(\x0e2\x17.learning.FlashcardFlagR\x04flag\"<\n" +
+	"\x17RewriteFlashcardRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\"N\n" +
+	"\x18RewriteFlashcardResponse\x12\x1a\n" +
+	"\bquestion\x18\x01 \x01(\tR\bquestion\x12\x16\n" +
+	"\x06answer\x18\x02 \x01(\tR\x06answer\"\xfc\x01\n" +
+	"\x16CreateFlashcardRequest\x12\x1f\n" +
+	"\vmaterial_id\x18\x01 \x01(\tR\n" +
+	"materialId\x12\x1a\n" +
+	"\bquestion\x18\x02 \x01(\tR\bquestion\x12\x16\n" +
+	"\x06answer\x18\x03 \x01(\tR\x06answer\x12\x1d\n" +
+	"\n" +
+	"deck_title\x18\x04 \x01(\tR\tdeckTitle\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12/\n" +
+	"\tcard_type\x18\x06 \x01(\x0e2\x12.learning.CardTypeR\bcardType\x12)\n" +
+	"\x10generate_reverse\x18\a \x01(\bR\x0fgenerateReverse\";\n" +
+	"\x16DeleteFlashcardRequest\x12!\n" +
+	"\fflashcard_id\x18\x01 \x01(\tR\vflashcardId\"B\n" +
+	"\x1bBulkDeleteFlashcardsRequest\x12#\n" +
+	"\rflashcard_ids\x18\x01 \x03(\tR\fflashcardIds\"C\n" +
+	"\x1cBulkDeleteFlashcardsResponse\x12#\n" +
+	"\rdeleted_count\x18\x01 \x01(\x05R\fdeletedCount\"o\n" +
+	"\x13GenerateQuizRequest\x12\x1f\n" +
+	"\vmaterial_id\x18\x01 \x01(\tR\n" +
+	"materialId\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12%\n" +
+	"\x0equestion_count\x18\x03 \x01(\x05R\rquestionCount\"w\n" +
+	"\fQuizQuestion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fflashcard_id\x18\x02 \x01(\tR\vflashcardId\x12\x1a\n" +
+	"\bquestion\x18\x03 \x01(\tR\bquestion\x12\x18\n" +
+	"\achoices\x18\x04 \x03(\tR\achoices\"L\n" +
+	"\x04Quiz\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x124\n" +
+	"\tquestions\x18\x02 \x03(\v2\x16.learning.QuizQuestionR\tquestions\"P\n" +
+	"\n" +
+	"QuizAnswer\x12\x1f\n" +
+	"\vquestion_id\x18\x01 \x01(\tR\n" +
+	"questionId\x12!\n" +
+	"\fchoice_index\x18\x02 \x01(\x05R\vchoiceIndex\"\x8f\x01\n" +
+	"\x18SubmitQuizAnswersRequest\x12\x17\n" +
+	"\aquiz_id\x18\x01 \x01(\tR\x06quizId\x12.\n" +
+	"\aanswers\x18\x02 \x03(\v2\x14.learning.QuizAnswerR\aanswers\x12*\n" +
+	"\x11apply_to_schedule\x18\x03 \x01(\bR\x0fapplyToSchedule\"\xba\x01\n" +
+	"\x12QuizQuestionResult\x12\x1f\n" +
+	"\vquestion_id\x18\x01 \x01(\tR\n" +
+	"questionId\x12!\n" +
+	"\fflashcard_id\x18\x02 \x01(\tR\vflashcardId\x12\x18\n" +
+	"\acorrect\x18\x03 \x01(\bR\acorrect\x12#\n" +
+	"\rcorrect_index\x18\x04 \x01(\x05R\fcorrectIndex\x12!\n" +
+	"\fchosen_index\x18\x05 \x01(\x05R\vchosenIndex\"\xae\x02\n" +
+	"\n" +
+	"QuizResult\x12\x1d\n" +
+	"\n" +
+	"attempt_id\x18\x01 \x01(\tR\tattemptId\x12\x17\n" +
+	"\aquiz_id\x18\x02 \x01(\tR\x06quizId\x12#\n" +
+	"\rcorrect_count\x18\x03 \x01(\x05R\fcorrectCount\x12\x1f\n" +
+	"\vtotal_count\x18\x04 \x01(\x05R\n" +
+	"totalCount\x126\n" +
+	"\aresults\x18\x05 \x03(\v2\x1c.learning.QuizQuestionResultR\aresults\x12+\n" +
+	"\x11cards_rescheduled\x18\x06 \x01(\x05R\x10cardsRescheduled\x12=\n" +
+	"\fsubmitted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vsubmittedAt*\x88\x01\n" +
+	"\vReviewGrade\x12\x1c\n" +
+	"\x18REVIEW_GRADE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12REVIEW_GRADE_AGAIN\x10\x01\x12\x15\n" +
+	"\x11REVIEW_GRADE_HARD\x10\x02\x12\x15\n" +
+	"\x11REVIEW_GRADE_GOOD\x10\x03\x12\x15\n" +
+	"\x11REVIEW_GRADE_EASY\x10\x04*k\n" +
+	"\x0fFlashcardStatus\x12\x1b\n" +
+	"\x17FLASHCARD_STATUS_ACTIVE\x10\x00\x12\x1e\n" +
+	"\x1aFLASHCARD_STATUS_SUSPENDED\x10\x01\x12\x1b\n" +
+	"\x17FLASHCARD_STATUS_BURIED\x10\x02*\xa9\x01\n" +
+	"\rFlashcardFlag\x12\x17\n" +
+	"\x13FLASHCARD_FLAG_NONE\x10\x00\x12\x16\n" +
+	"\x12FLASHCARD_FLAG_RED\x10\x01\x12\x19\n" +
+	"\x15FLASHCARD_FLAG_ORANGE\x10\x02\x12\x18\n" +
+	"\x14FLASHCARD_FLAG_GREEN\x10\x03\x12\x17\n" +
+	"\x13FLASHCARD_FLAG_BLUE\x10\x04\x12\x19\n" +
+	"\x15FLASHCARD_FLAG_PURPLE\x10\x05*K\n" +
+	"\bCardType\x12\x13\n" +
+	"\x0fCARD_TYPE_BASIC\x10\x00\x12\x13\n" +
+	"\x0fCARD_TYPE_CLOZE\x10\x01\x12\x15\n" +
+	"\x11CARD_TYPE_REVERSE\x10\x02*=\n" +
+	"\vLeechAction\x12\x14\n" +
+	"\x10LEECH_ACTION_TAG\x10\x00\x12\x18\n" +
+	"\x14LEECH_ACTION_SUSPEND\x10\x01*\xe1\x01\n" +
+	"\x0eIngestionStage\x12\x1a\n" +
+	"\x16INGESTION_STAGE_QUEUED\x10\x00\x12\x1c\n" +
+	"\x18INGESTION_STAGE_FETCHING\x10\x01\x12\x1e\n" +
+	"\x1aINGESTION_STAGE_EXTRACTING\x10\x02\x12\x1e\n" +
+	"\x1aINGESTION_STAGE_GENERATING\x10\x03\x12\x1a\n" +
+	"\x16INGESTION_STAGE_SAVING\x10\x04\x12\x1d\n" +
+	"\x19INGESTION_STAGE_SUCCEEDED\x10\x05\x12\x1a\n" +
+	"\x16INGESTION_STAGE_FAILED\x10\x062\xe3\x12\n" +
 	"\x0fLearningService\x12J\n" +
 	"\vAddMaterial\x12\x1c.learning.AddMaterialRequest\x1a\x1d.learning.AddMaterialResponse\x12I\n" +
 	"\x0eDeleteMaterial\x12\x1f.learning.DeleteMaterialRequest\x1a\x16.google.protobuf.Empty\x12V\n" +
@@ -1001,7 +4026,29 @@ const file_backend_proto_learning_learning_proto_rawDesc = "" +
 	"GetAllTags\x12\x16.google.protobuf.Empty\x1a\x1c.learning.GetAllTagsResponse\x12U\n" +
 	"\x15GetNotificationStatus\x12\x16.google.protobuf.Empty\x1a$.learning.NotificationStatusResponse\x12_\n" +
 	"\x12GetMaterialSummary\x12#.learning.GetMaterialSummaryRequest\x1a$.learning.GetMaterialSummaryResponse\x12K\n" +
-	"\x0fUpdateFlashcard\x12 .learning.UpdateFlashcardRequest\x1a\x16.google.protobuf.EmptyB,Z*github.com/amityadav/landr/pkg/pb/learningb\x06proto3"
+	"\x0fUpdateFlashcard\x12 .learning.UpdateFlashcardRequest\x1a\x16.google.protobuf.Empty\x12V\n" +
+	"\x0fReviewFlashcard\x12 .learning.ReviewFlashcardRequest\x1a!.learning.ReviewFlashcardResponse\x12N\n" +
+	"\x10OptimizeSchedule\x12\x16.google.protobuf.Empty\x1a\".learning.OptimizeScheduleResponse\x12Y\n" +
+	"\x10GetReviewHistory\x12!.learning.GetReviewHistoryRequest\x1a\".learning.GetReviewHistoryResponse\x12G\n" +
+	"\n" +
+	"UndoReview\x12\x1b.learning.UndoReviewRequest\x1a\x1c.learning.UndoReviewResponse\x12S\n" +
+	"\x0eGetReviewQueue\x12\x1f.learning.GetReviewQueueRequest\x1a .learning.GetReviewQueueResponse\x12C\n" +
+	"\x10GetStudySettings\x12\x16.google.protobuf.Empty\x1a\x17.learning.StudySettings\x12G\n" +
+	"\x13UpdateStudySettings\x12\x17.learning.StudySettings\x1a\x17.learning.StudySettings\x12M\n" +
+	"\x10SuspendFlashcard\x12!.learning.SuspendFlashcardRequest\x1a\x16.google.protobuf.Empty\x12G\n" +
+	"\rBuryFlashcard\x12\x1e.learning.BuryFlashcardRequest\x1a\x16.google.protobuf.Empty\x12G\n" +
+	"\rFlagFlashcard\x12\x1e.learning.FlagFlashcardRequest\x1a\x16.google.protobuf.Empty\x12=\n" +
+	"\n" +
+	"GetLeeches\x12\x16.google.protobuf.Empty\x1a\x17.learning.FlashcardList\x12Y\n" +
+	"\x10RewriteFlashcard\x12!.learning.RewriteFlashcardRequest\x1a\".learning.RewriteFlashcardResponse\x12H\n" +
+	"\x0fCreateFlashcard\x12 .learning.CreateFlashcardRequest\x1a\x13.learning.Flashcard\x12K\n" +
+	"\x0fDeleteFlashcard\x12 .learning.DeleteFlashcardRequest\x1a\x16.google.protobuf.Empty\x12e\n" +
+	"\x14BulkDeleteFlashcards\x12%.learning.BulkDeleteFlashcardsRequest\x1a&.learning.BulkDeleteFlashcardsResponse\x12=\n" +
+	"\fGenerateQuiz\x12\x1d.learning.GenerateQuizRequest\x1a\x0e.learning.Quiz\x12M\n" +
+	"\x11SubmitQuizAnswers\x12\".learning.SubmitQuizAnswersRequest\x1a\x14.learning.QuizResult\x12Y\n" +
+	"\x10GradeTypedAnswer\x12!.learning.GradeTypedAnswerRequest\x1a\".learning.GradeTypedAnswerResponse\x12Q\n" +
+	"\x11WatchIngestionJob\x12\".learning.WatchIngestionJobRequest\x1a\x16.learning.IngestionJob0\x01\x12V\n" +
+	"\x11RetryFailedChunks\x12\".learning.RetryFailedChunksRequest\x1a\x1d.learning.AddMaterialResponseB,Z*github.com/amityadav/landr/pkg/pb/learningb\x06proto3"
 
 var (
 	file_backend_proto_learning_learning_proto_rawDescOnce sync.Once
@@ -1015,56 +4062,169 @@ func file_backend_proto_learning_learning_proto_rawDescGZIP() []byte {
 	return file_backend_proto_learning_learning_proto_rawDescData
 }
 
-var file_backend_proto_learning_learning_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_backend_proto_learning_learning_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_backend_proto_learning_learning_proto_msgTypes = make([]protoimpl.MessageInfo, 48)
 var file_backend_proto_learning_learning_proto_goTypes = []any{
-	(*AddMaterialRequest)(nil),         // 0: learning.AddMaterialRequest
-	(*AddMaterialResponse)(nil),        // 1: learning.AddMaterialResponse
-	(*DeleteMaterialRequest)(nil),      // 2: learning.DeleteMaterialRequest
-	(*MaterialSummary)(nil),            // 3: learning.MaterialSummary
-	(*GetDueMaterialsRequest)(nil),     // 4: learning.GetDueMaterialsRequest
-	(*GetDueMaterialsResponse)(nil),    // 5: learning.GetDueMaterialsResponse
-	(*GetDueFlashcardsRequest)(nil),    // 6: learning.GetDueFlashcardsRequest
-	(*Flashcard)(nil),                  // 7: learning.Flashcard
-	(*FlashcardList)(nil),              // 8: learning.FlashcardList
-	(*CompleteReviewRequest)(nil),      // 9: learning.CompleteReviewRequest
-	(*FailReviewRequest)(nil),          // 10: learning.FailReviewRequest
-	(*GetAllTagsResponse)(nil),         // 11: learning.GetAllTagsResponse
-	(*NotificationStatusResponse)(nil), // 12: learning.NotificationStatusResponse
-	(*GetMaterialSummaryRequest)(nil),  // 13: learning.GetMaterialSummaryRequest
-	(*GetMaterialSummaryResponse)(nil), // 14: learning.GetMaterialSummaryResponse
-	(*UpdateFlashcardRequest)(nil),     // 15: learning.UpdateFlashcardRequest
-	(*timestamppb.Timestamp)(nil),      // 16: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),              // 17: google.protobuf.Empty
+	(ReviewGrade)(0),                     // 0: learning.ReviewGrade
+	(FlashcardStatus)(0),                 // 1: learning.FlashcardStatus
+	(FlashcardFlag)(0),                   // 2: learning.FlashcardFlag
+	(CardType)(0),                        // 3: learning.CardType
+	(LeechAction)(0),                     // 4: learning.LeechAction
+	(IngestionStage)(0),                  // 5: learning.IngestionStage
+	(*AddMaterialRequest)(nil),           // 6: learning.AddMaterialRequest
+	(*AddMaterialResponse)(nil),          // 7: learning.AddMaterialResponse
+	(*RetryFailedChunksRequest)(nil),     // 8: learning.RetryFailedChunksRequest
+	(*WatchIngestionJobRequest)(nil),     // 9: learning.WatchIngestionJobRequest
+	(*IngestionJob)(nil),                 // 10: learning.IngestionJob
+	(*DeleteMaterialRequest)(nil),        // 11: learning.DeleteMaterialRequest
+	(*MaterialSummary)(nil),              // 12: learning.MaterialSummary
+	(*GetDueMaterialsRequest)(nil),       // 13: learning.GetDueMaterialsRequest
+	(*GetDueMaterialsResponse)(nil),      // 14: learning.GetDueMaterialsResponse
+	(*GetDueFlashcardsRequest)(nil),      // 15: learning.GetDueFlashcardsRequest
+	(*Flashcard)(nil),                    // 16: learning.Flashcard
+	(*FlashcardList)(nil),                // 17: learning.FlashcardList
+	(*CompleteReviewRequest)(nil),        // 18: learning.CompleteReviewRequest
+	(*FailReviewRequest)(nil),            // 19: learning.FailReviewRequest
+	(*GetAllTagsResponse)(nil),           // 20: learning.GetAllTagsResponse
+	(*NotificationStatusResponse)(nil),   // 21: learning.NotificationStatusResponse
+	(*GetMaterialSummaryRequest)(nil),    // 22: learning.GetMaterialSummaryRequest
+	(*GetMaterialSummaryResponse)(nil),   // 23: learning.GetMaterialSummaryResponse
+	(*UpdateFlashcardRequest)(nil),       // 24: learning.UpdateFlashcardRequest
+	(*ReviewFlashcardRequest)(nil),       // 25: learning.ReviewFlashcardRequest
+	(*GradeTypedAnswerRequest)(nil),      // 26: learning.GradeTypedAnswerRequest
+	(*GradeTypedAnswerResponse)(nil),     // 27: learning.GradeTypedAnswerResponse
+	(*ReviewFlashcardResponse)(nil),      // 28: learning.ReviewFlashcardResponse
+	(*OptimizeScheduleResponse)(nil),     // 29: learning.OptimizeScheduleResponse
+	(*GetReviewHistoryRequest)(nil),      // 30: learning.GetReviewHistoryRequest
+	(*ReviewLogEntry)(nil),               // 31: learning.ReviewLogEntry
+	(*GetReviewHistoryResponse)(nil),     // 32: learning.GetReviewHistoryResponse
+	(*UndoReviewRequest)(nil),            // 33: learning.UndoReviewRequest
+	(*UndoReviewResponse)(nil),           // 34: learning.UndoReviewResponse
+	(*GetReviewQueueRequest)(nil),        // 35: learning.GetReviewQueueRequest
+	(*GetReviewQueueResponse)(nil),       // 36: learning.GetReviewQueueResponse
+	(*StudySettings)(nil),                // 37: learning.StudySettings
+	(*SuspendFlashcardRequest)(nil),      // 38: learning.SuspendFlashcardRequest
+	(*BuryFlashcardRequest)(nil),         // 39: learning.BuryFlashcardRequest
+	(*FlagFlashcardRequest)(nil),         // 40: learning.FlagFlashcardRequest
+	(*RewriteFlashcardRequest)(nil),      // 41: learning.RewriteFlashcardRequest
+	(*RewriteFlashcardResponse)(nil),     // 42: learning.RewriteFlashcardResponse
+	(*CreateFlashcardRequest)(nil),       // 43: learning.CreateFlashcardRequest
+	(*DeleteFlashcardRequest)(nil),       // 44: learning.DeleteFlashcardRequest
+	(*BulkDeleteFlashcardsRequest)(nil),  // 45: learning.BulkDeleteFlashcardsRequest
+	(*BulkDeleteFlashcardsResponse)(nil), // 46: learning.BulkDeleteFlashcardsResponse
+	(*GenerateQuizRequest)(nil),          // 47: learning.GenerateQuizRequest
+	(*QuizQuestion)(nil),                 // 48: learning.QuizQuestion
+	(*Quiz)(nil),                         // 49: learning.Quiz
+	(*QuizAnswer)(nil),                   // 50: learning.QuizAnswer
+	(*SubmitQuizAnswersRequest)(nil),     // 51: learning.SubmitQuizAnswersRequest
+	(*QuizQuestionResult)(nil),           // 52: learning.QuizQuestionResult
+	(*QuizResult)(nil),                   // 53: learning.QuizResult
+	(*timestamppb.Timestamp)(nil),        // 54: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),                // 55: google.protobuf.Empty
 }
 var file_backend_proto_learning_learning_proto_depIdxs = []int32{
-	3,  // 0: learning.GetDueMaterialsResponse.materials:type_name -> learning.MaterialSummary
-	16, // 1: learning.Flashcard.next_review_at:type_name -> google.protobuf.Timestamp
-	7,  // 2: learning.FlashcardList.flashcards:type_name -> learning.Flashcard
-	0,  // 3: learning.LearningService.AddMaterial:input_type -> learning.AddMaterialRequest
-	2,  // 4: learning.LearningService.DeleteMaterial:input_type -> learning.DeleteMaterialRequest
-	4,  // 5: learning.LearningService.GetDueMaterials:input_type -> learning.GetDueMaterialsRequest
-	6,  // 6: learning.LearningService.GetDueFlashcards:input_type -> learning.GetDueFlashcardsRequest
-	9,  // 7: learning.LearningService.CompleteReview:input_type -> learning.CompleteReviewRequest
-	10, // 8: learning.LearningService.FailReview:input_type -> learning.FailReviewRequest
-	17, // 9: learning.LearningService.GetAllTags:input_type -> google.protobuf.Empty
-	17, // 10: learning.LearningService.GetNotificationStatus:input_type -> google.protobuf.Empty
-	13, // 11: learning.LearningService.GetMaterialSummary:input_type -> learning.GetMaterialSummaryRequest
-	15, // 12: learning.LearningService.UpdateFlashcard:input_type -> learning.UpdateFlashcardRequest
-	1,  // 13: learning.LearningService.AddMaterial:output_type -> learning.AddMaterialResponse
-	17, // 14: learning.LearningService.DeleteMaterial:output_type -> google.protobuf.Empty
-	5,  // 15: learning.LearningService.GetDueMaterials:output_type -> learning.GetDueMaterialsResponse
-	8,  // 16: learning.LearningService.GetDueFlashcards:output_type -> learning.FlashcardList
-	17, // 17: learning.LearningService.CompleteReview:output_type -> google.protobuf.Empty
-	17, // 18: learning.LearningService.FailReview:output_type -> google.protobuf.Empty
-	11, // 19: learning.LearningService.GetAllTags:output_type -> learning.GetAllTagsResponse
-	12, // 20: learning.LearningService.GetNotificationStatus:output_type -> learning.NotificationStatusResponse
-	14, // 21: learning.LearningService.GetMaterialSummary:output_type -> learning.GetMaterialSummaryResponse
-	17, // 22: learning.LearningService.UpdateFlashcard:output_type -> google.protobuf.Empty
-	13, // [13:23] is the sub-list for method output_type
-	3,  // [3:13] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	5,  // 0: learning.IngestionJob.stage:type_name -> learning.IngestionStage
+	7,  // 1: learning.IngestionJob.result:type_name -> learning.AddMaterialResponse
+	54, // 2: learning.IngestionJob.updated_at:type_name -> google.protobuf.Timestamp
+	12, // 3: learning.GetDueMaterialsResponse.materials:type_name -> learning.MaterialSummary
+	54, // 4: learning.Flashcard.next_review_at:type_name -> google.protobuf.Timestamp
+	54, // 5: learning.Flashcard.last_reviewed_at:type_name -> google.protobuf.Timestamp
+	1,  // 6: learning.Flashcard.status:type_name -> learning.FlashcardStatus
+	2,  // 7: learning.Flashcard.flag:type_name -> learning.FlashcardFlag
+	54, // 8: learning.Flashcard.buried_until:type_name -> google.protobuf.Timestamp
+	3,  // 9: learning.Flashcard.card_type:type_name -> learning.CardType
+	16, // 10: learning.FlashcardList.flashcards:type_name -> learning.Flashcard
+	54, // 11: learning.CompleteReviewRequest.client_reviewed_at:type_name -> google.protobuf.Timestamp
+	54, // 12: learning.FailReviewRequest.client_reviewed_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: learning.ReviewFlashcardRequest.grade:type_name -> learning.ReviewGrade
+	54, // 14: learning.ReviewFlashcardRequest.client_reviewed_at:type_name -> google.protobuf.Timestamp
+	54, // 15: learning.GradeTypedAnswerRequest.client_reviewed_at:type_name -> google.protobuf.Timestamp
+	0,  // 16: learning.GradeTypedAnswerResponse.suggested_grade:type_name -> learning.ReviewGrade
+	28, // 17: learning.GradeTypedAnswerResponse.review:type_name -> learning.ReviewFlashcardResponse
+	54, // 18: learning.ReviewFlashcardResponse.next_review_at:type_name -> google.protobuf.Timestamp
+	54, // 19: learning.OptimizeScheduleResponse.optimized_at:type_name -> google.protobuf.Timestamp
+	54, // 20: learning.GetReviewHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	54, // 21: learning.GetReviewHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 22: learning.ReviewLogEntry.grade:type_name -> learning.ReviewGrade
+	54, // 23: learning.ReviewLogEntry.previous_due_at:type_name -> google.protobuf.Timestamp
+	54, // 24: learning.ReviewLogEntry.next_due_at:type_name -> google.protobuf.Timestamp
+	54, // 25: learning.ReviewLogEntry.reviewed_at:type_name -> google.protobuf.Timestamp
+	54, // 26: learning.ReviewLogEntry.client_reviewed_at:type_name -> google.protobuf.Timestamp
+	31, // 27: learning.GetReviewHistoryResponse.entries:type_name -> learning.ReviewLogEntry
+	54, // 28: learning.UndoReviewResponse.restored_next_review_at:type_name -> google.protobuf.Timestamp
+	16, // 29: learning.GetReviewQueueResponse.flashcards:type_name -> learning.Flashcard
+	4,  // 30: learning.StudySettings.leech_action:type_name -> learning.LeechAction
+	2,  // 31: learning.FlagFlashcardRequest.flag:type_name -> learning.FlashcardFlag
+	3,  // 32: learning.CreateFlashcardRequest.card_type:type_name -> learning.CardType
+	48, // 33: learning.Quiz.questions:type_name -> learning.QuizQuestion
+	50, // 34: learning.SubmitQuizAnswersRequest.answers:type_name -> learning.QuizAnswer
+	52, // 35: learning.QuizResult.results:type_name -> learning.QuizQuestionResult
+	54, // 36: learning.QuizResult.submitted_at:type_name -> google.protobuf.Timestamp
+	6,  // 37: learning.LearningService.AddMaterial:input_type -> learning.AddMaterialRequest
+	11, // 38: learning.LearningService.DeleteMaterial:input_type -> learning.DeleteMaterialRequest
+	13, // 39: learning.LearningService.GetDueMaterials:input_type -> learning.GetDueMaterialsRequest
+	15, // 40: learning.LearningService.GetDueFlashcards:input_type -> learning.GetDueFlashcardsRequest
+	18, // 41: learning.LearningService.CompleteReview:input_type -> learning.CompleteReviewRequest
+	19, // 42: learning.LearningService.FailReview:input_type -> learning.FailReviewRequest
+	55, // 43: learning.LearningService.GetAllTags:input_type -> google.protobuf.Empty
+	55, // 44: learning.LearningService.GetNotificationStatus:input_type -> google.protobuf.Empty
+	22, // 45: learning.LearningService.GetMaterialSummary:input_type -> learning.GetMaterialSummaryRequest
+	24, // 46: learning.LearningService.UpdateFlashcard:input_type -> learning.UpdateFlashcardRequest
+	25, // 47: learning.LearningService.ReviewFlashcard:input_type -> learning.ReviewFlashcardRequest
+	55, // 48: learning.LearningService.OptimizeSchedule:input_type -> google.protobuf.Empty
+	30, // 49: learning.LearningService.GetReviewHistory:input_type -> learning.GetReviewHistoryRequest
+	33, // 50: learning.LearningService.UndoReview:input_type -> learning.UndoReviewRequest
+	35, // 51: learning.LearningService.GetReviewQueue:input_type -> learning.GetReviewQueueRequest
+	55, // 52: learning.LearningService.GetStudySettings:input_type -> google.protobuf.Empty
+	37, // 53: learning.LearningService.UpdateStudySettings:input_type -> learning.StudySettings
+	38, // 54: learning.LearningService.SuspendFlashcard:input_type -> learning.SuspendFlashcardRequest
+	39, // 55: learning.LearningService.BuryFlashcard:input_type -> learning.BuryFlashcardRequest
+	40, // 56: learning.LearningService.FlagFlashcard:input_type -> learning.FlagFlashcardRequest
+	55, // 57: learning.LearningService.GetLeeches:input_type -> google.protobuf.Empty
+	41, // 58: learning.LearningService.RewriteFlashcard:input_type -> learning.RewriteFlashcardRequest
+	43, // 59: learning.LearningService.CreateFlashcard:input_type -> learning.CreateFlashcardRequest
+	44, // 60: learning.LearningService.DeleteFlashcard:input_type -> learning.DeleteFlashcardRequest
+	45, // 61: learning.LearningService.BulkDeleteFlashcards:input_type -> learning.BulkDeleteFlashcardsRequest
+	47, // 62: learning.LearningService.GenerateQuiz:input_type -> learning.GenerateQuizRequest
+	51, // 63: learning.LearningService.SubmitQuizAnswers:input_type -> learning.SubmitQuizAnswersRequest
+	26, // 64: learning.LearningService.GradeTypedAnswer:input_type -> learning.GradeTypedAnswerRequest
+	9,  // 65: learning.LearningService.WatchIngestionJob:input_type -> learning.WatchIngestionJobRequest
+	8,  // 66: learning.LearningService.RetryFailedChunks:input_type -> learning.RetryFailedChunksRequest
+	7,  // 67: learning.LearningService.AddMaterial:output_type -> learning.AddMaterialResponse
+	55, // 68: learning.LearningService.DeleteMaterial:output_type -> google.protobuf.Empty
+	14, // 69: learning.LearningService.GetDueMaterials:output_type -> learning.GetDueMaterialsResponse
+	17, // 70: learning.LearningService.GetDueFlashcards:output_type -> learning.FlashcardList
+	55, // 71: learning.LearningService.CompleteReview:output_type -> google.protobuf.Empty
+	55, // 72: learning.LearningService.FailReview:output_type -> google.protobuf.Empty
+	20, // 73: learning.LearningService.GetAllTags:output_type -> learning.GetAllTagsResponse
+	21, // 74: learning.LearningService.GetNotificationStatus:output_type -> learning.NotificationStatusResponse
+	23, // 75: learning.LearningService.GetMaterialSummary:output_type -> learning.GetMaterialSummaryResponse
+	55, // 76: learning.LearningService.UpdateFlashcard:output_type -> google.protobuf.Empty
+	28, // 77: learning.LearningService.ReviewFlashcard:output_type -> learning.ReviewFlashcardResponse
+	29, // 78: learning.LearningService.OptimizeSchedule:output_type -> learning.OptimizeScheduleResponse
+	32, // 79: learning.LearningService.GetReviewHistory:output_type -> learning.GetReviewHistoryResponse
+	34, // 80: learning.LearningService.UndoReview:output_type -> learning.UndoReviewResponse
+	36, // 81: learning.LearningService.GetReviewQueue:output_type -> learning.GetReviewQueueResponse
+	37, // 82: learning.LearningService.GetStudySettings:output_type -> learning.StudySettings
+	37, // 83: learning.LearningService.UpdateStudySettings:output_type -> learning.StudySettings
+	55, // 84: learning.LearningService.SuspendFlashcard:output_type -> google.protobuf.Empty
+	55, // 85: learning.LearningService.BuryFlashcard:output_type -> google.protobuf.Empty
+	55, // 86: learning.LearningService.FlagFlashcard:output_type -> google.protobuf.Empty
+	17, // 87: learning.LearningService.GetLeeches:output_type -> learning.FlashcardList
+	42, // 88: learning.LearningService.RewriteFlashcard:output_type -> learning.RewriteFlashcardResponse
+	16, // 89: learning.LearningService.CreateFlashcard:output_type -> learning.Flashcard
+	55, // 90: learning.LearningService.DeleteFlashcard:output_type -> google.protobuf.Empty
+	46, // 91: learning.LearningService.BulkDeleteFlashcards:output_type -> learning.BulkDeleteFlashcardsResponse
+	49, // 92: learning.LearningService.GenerateQuiz:output_type -> learning.Quiz
+	53, // 93: learning.LearningService.SubmitQuizAnswers:output_type -> learning.QuizResult
+	27, // 94: learning.LearningService.GradeTypedAnswer:output_type -> learning.GradeTypedAnswerResponse
+	10, // 95: learning.LearningService.WatchIngestionJob:output_type -> learning.IngestionJob
+	7,  // 96: learning.LearningService.RetryFailedChunks:output_type -> learning.AddMaterialResponse
+	67, // [67:97] is the sub-list for method output_type
+	37, // [37:67] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_backend_proto_learning_learning_proto_init() }
@@ -1077,13 +4237,14 @@ func file_backend_proto_learning_learning_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_backend_proto_learning_learning_proto_rawDesc), len(file_backend_proto_learning_learning_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      6,
+			NumMessages:   48,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_backend_proto_learning_learning_proto_goTypes,
 		DependencyIndexes: file_backend_proto_learning_learning_proto_depIdxs,
+		EnumInfos:         file_backend_proto_learning_learning_proto_enumTypes,
 		MessageInfos:      file_backend_proto_learning_learning_proto_msgTypes,
 	}.Build()
 	File_backend_proto_learning_learning_proto = out.File
@@ -30,6 +30,26 @@ const (
 	LearningService_GetNotificationStatus_FullMethodName = "/learning.LearningService/GetNotificationStatus"
 	LearningService_GetMaterialSummary_FullMethodName    = "/learning.LearningService/GetMaterialSummary"
 	LearningService_UpdateFlashcard_FullMethodName       = "/learning.LearningService/UpdateFlashcard"
+	LearningService_ReviewFlashcard_FullMethodName       = "/learning.LearningService/ReviewFlashcard"
+	LearningService_OptimizeSchedule_FullMethodName      = "/learning.LearningService/OptimizeSchedule"
+	LearningService_GetReviewHistory_FullMethodName      = "/learning.LearningService/GetReviewHistory"
+	LearningService_UndoReview_FullMethodName            = "/learning.LearningService/UndoReview"
+	LearningService_GetReviewQueue_FullMethodName        = "/learning.LearningService/GetReviewQueue"
+	LearningService_GetStudySettings_FullMethodName      = "/learning.LearningService/GetStudySettings"
+	LearningService_UpdateStudySettings_FullMethodName   = "/learning.LearningService/UpdateStudySettings"
+	LearningService_SuspendFlashcard_FullMethodName      = "/learning.LearningService/SuspendFlashcard"
+	LearningService_BuryFlashcard_FullMethodName         = "/learning.LearningService/BuryFlashcard"
+	LearningService_FlagFlashcard_FullMethodName         = "/learning.LearningService/FlagFlashcard"
+	LearningService_GetLeeches_FullMethodName            = "/learning.LearningService/GetLeeches"
+	LearningService_RewriteFlashcard_FullMethodName      = "/learning.LearningService/RewriteFlashcard"
+	LearningService_CreateFlashcard_FullMethodName       = "/learning.LearningService/CreateFlashcard"
+	LearningService_DeleteFlashcard_FullMethodName       = "/learning.LearningService/DeleteFlashcard"
+	LearningService_BulkDeleteFlashcards_FullMethodName  = "/learning.LearningService/BulkDeleteFlashcards"
+	LearningService_GenerateQuiz_FullMethodName          = "/learning.LearningService/GenerateQuiz"
+	LearningService_SubmitQuizAnswers_FullMethodName     = "/learning.LearningService/SubmitQuizAnswers"
+	LearningService_GradeTypedAnswer_FullMethodName      = "/learning.LearningService/GradeTypedAnswer"
+	LearningService_WatchIngestionJob_FullMethodName     = "/learning.LearningService/WatchIngestionJob"
+	LearningService_RetryFailedChunks_FullMethodName     = "/learning.LearningService/RetryFailedChunks"
 )
 
 // LearningServiceClient is the client API for LearningService service.
@@ -46,6 +66,26 @@ type LearningServiceClient interface {
 	GetNotificationStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NotificationStatusResponse, error)
 	GetMaterialSummary(ctx context.Context, in *GetMaterialSummaryRequest, opts ...grpc.CallOption) (*GetMaterialSummaryResponse, error)
 	UpdateFlashcard(ctx context.Context, in *UpdateFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ReviewFlashcard(ctx context.Context, in *ReviewFlashcardRequest, opts ...grpc.CallOption) (*ReviewFlashcardResponse, error)
+	OptimizeSchedule(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*OptimizeScheduleResponse, error)
+	GetReviewHistory(ctx context.Context, in *GetReviewHistoryRequest, opts ...grpc.CallOption) (*GetReviewHistoryResponse, error)
+	UndoReview(ctx context.Context, in *UndoReviewRequest, opts ...grpc.CallOption) (*UndoReviewResponse, error)
+	GetReviewQueue(ctx context.Context, in *GetReviewQueueRequest, opts ...grpc.CallOption) (*GetReviewQueueResponse, error)
+	GetStudySettings(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StudySettings, error)
+	UpdateStudySettings(ctx context.Context, in *StudySettings, opts ...grpc.CallOption) (*StudySettings, error)
+	SuspendFlashcard(ctx context.Context, in *SuspendFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	BuryFlashcard(ctx context.Context, in *BuryFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	FlagFlashcard(ctx context.Context, in *FlagFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetLeeches(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FlashcardList, error)
+	RewriteFlashcard(ctx context.Context, in *RewriteFlashcardRequest, opts ...grpc.CallOption) (*RewriteFlashcardResponse, error)
+	CreateFlashcard(ctx context.Context, in *CreateFlashcardRequest, opts ...grpc.CallOption) (*Flashcard, error)
+	DeleteFlashcard(ctx context.Context, in *DeleteFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	BulkDeleteFlashcards(ctx context.Context, in *BulkDeleteFlashcardsRequest, opts ...grpc.CallOption) (*BulkDeleteFlashcardsResponse, error)
+	GenerateQuiz(ctx context.Context, in *GenerateQuizRequest, opts ...grpc.CallOption) (*Quiz, error)
+	SubmitQuizAnswers(ctx context.Context, in *SubmitQuizAnswersRequest, opts ...grpc.CallOption) (*QuizResult, error)
+	GradeTypedAnswer(ctx context.Context, in *GradeTypedAnswerRequest, opts ...grpc.CallOption) (*GradeTypedAnswerResponse, error)
+	WatchIngestionJob(ctx context.Context, in *WatchIngestionJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IngestionJob], error)
+	RetryFailedChunks(ctx context.Context, in *RetryFailedChunksRequest, opts ...grpc.CallOption) (*AddMaterialResponse, error)
 }
 
 type learningServiceClient struct {
@@ -156,6 +196,215 @@ func (c *learningServiceClient) UpdateFlashcard(ctx context.Context, in *UpdateF
 	return out, nil
 }
 
+func (c *learningServiceClient) ReviewFlashcard(ctx context.Context, in *ReviewFlashcardRequest, opts ...grpc.CallOption) (*ReviewFlashcardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReviewFlashcardResponse)
+	err := c.cc.Invoke(ctx, LearningService_ReviewFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) OptimizeSchedule(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*OptimizeScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OptimizeScheduleResponse)
+	err := c.cc.Invoke(ctx, LearningService_OptimizeSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) GetReviewHistory(ctx context.Context, in *GetReviewHistoryRequest, opts ...grpc.CallOption) (*GetReviewHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReviewHistoryResponse)
+	err := c.cc.Invoke(ctx, LearningService_GetReviewHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) UndoReview(ctx context.Context, in *UndoReviewRequest, opts ...grpc.CallOption) (*UndoReviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UndoReviewResponse)
+	err := c.cc.Invoke(ctx, LearningService_UndoReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) GetReviewQueue(ctx context.Context, in *GetReviewQueueRequest, opts ...grpc.CallOption) (*GetReviewQueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReviewQueueResponse)
+	err := c.cc.Invoke(ctx, LearningService_GetReviewQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) GetStudySettings(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StudySettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StudySettings)
+	err := c.cc.Invoke(ctx, LearningService_GetStudySettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) UpdateStudySettings(ctx context.Context, in *StudySettings, opts ...grpc.CallOption) (*StudySettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StudySettings)
+	err := c.cc.Invoke(ctx, LearningService_UpdateStudySettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) SuspendFlashcard(ctx context.Context, in *SuspendFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, LearningService_SuspendFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) BuryFlashcard(ctx context.Context, in *BuryFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, LearningService_BuryFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) FlagFlashcard(ctx context.Context, in *FlagFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, LearningService_FlagFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) GetLeeches(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FlashcardList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlashcardList)
+	err := c.cc.Invoke(ctx, LearningService_GetLeeches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) RewriteFlashcard(ctx context.Context, in *RewriteFlashcardRequest, opts ...grpc.CallOption) (*RewriteFlashcardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RewriteFlashcardResponse)
+	err := c.cc.Invoke(ctx, LearningService_RewriteFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) CreateFlashcard(ctx context.Context, in *CreateFlashcardRequest, opts ...grpc.CallOption) (*Flashcard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Flashcard)
+	err := c.cc.Invoke(ctx, LearningService_CreateFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) DeleteFlashcard(ctx context.Context, in *DeleteFlashcardRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, LearningService_DeleteFlashcard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) BulkDeleteFlashcards(ctx context.Context, in *BulkDeleteFlashcardsRequest, opts ...grpc.CallOption) (*BulkDeleteFlashcardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkDeleteFlashcardsResponse)
+	err := c.cc.Invoke(ctx, LearningService_BulkDeleteFlashcards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) GenerateQuiz(ctx context.Context, in *GenerateQuizRequest, opts ...grpc.CallOption) (*Quiz, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quiz)
+	err := c.cc.Invoke(ctx, LearningService_GenerateQuiz_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) SubmitQuizAnswers(ctx context.Context, in *SubmitQuizAnswersRequest, opts ...grpc.CallOption) (*QuizResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuizResult)
+	err := c.cc.Invoke(ctx, LearningService_SubmitQuizAnswers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) GradeTypedAnswer(ctx context.Context, in *GradeTypedAnswerRequest, opts ...grpc.CallOption) (*GradeTypedAnswerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GradeTypedAnswerResponse)
+	err := c.cc.Invoke(ctx, LearningService_GradeTypedAnswer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *learningServiceClient) WatchIngestionJob(ctx context.Context, in *WatchIngestionJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[IngestionJob], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LearningService_ServiceDesc.Streams[0], LearningService_WatchIngestionJob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchIngestionJobRequest, IngestionJob]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LearningService_WatchIngestionJobClient = grpc.ServerStreamingClient[IngestionJob]
+
+func (c *learningServiceClient) RetryFailedChunks(ctx context.Context, in *RetryFailedChunksRequest, opts ...grpc.CallOption) (*AddMaterialResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddMaterialResponse)
+	err := c.cc.Invoke(ctx, LearningService_RetryFailedChunks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LearningServiceServer is the server API for LearningService service.
 // All implementations must embed UnimplementedLearningServiceServer
 // for forward compatibility.
@@ -170,6 +419,26 @@ type LearningServiceServer interface {
 	GetNotificationStatus(context.Context, *emptypb.Empty) (*NotificationStatusResponse, error)
 	GetMaterialSummary(context.Context, *GetMaterialSummaryRequest) (*GetMaterialSummaryResponse, error)
 	UpdateFlashcard(context.Context, *UpdateFlashcardRequest) (*emptypb.Empty, error)
+	ReviewFlashcard(context.Context, *ReviewFlashcardRequest) (*ReviewFlashcardResponse, error)
+	OptimizeSchedule(context.Context, *emptypb.Empty) (*OptimizeScheduleResponse, error)
+	GetReviewHistory(context.Context, *GetReviewHistoryRequest) (*GetReviewHistoryResponse, error)
+	UndoReview(context.Context, *UndoReviewRequest) (*UndoReviewResponse, error)
+	GetReviewQueue(context.Context, *GetReviewQueueRequest) (*GetReviewQueueResponse, error)
+	GetStudySettings(context.Context, *emptypb.Empty) (*StudySettings, error)
+	UpdateStudySettings(context.Context, *StudySettings) (*StudySettings, error)
+	SuspendFlashcard(context.Context, *SuspendFlashcardRequest) (*emptypb.Empty, error)
+	BuryFlashcard(context.Context, *BuryFlashcardRequest) (*emptypb.Empty, error)
+	FlagFlashcard(context.Context, *FlagFlashcardRequest) (*emptypb.Empty, error)
+	GetLeeches(context.Context, *emptypb.Empty) (*FlashcardList, error)
+	RewriteFlashcard(context.Context, *RewriteFlashcardRequest) (*RewriteFlashcardResponse, error)
+	CreateFlashcard(context.Context, *CreateFlashcardRequest) (*Flashcard, error)
+	DeleteFlashcard(context.Context, *DeleteFlashcardRequest) (*emptypb.Empty, error)
+	BulkDeleteFlashcards(context.Context, *BulkDeleteFlashcardsRequest) (*BulkDeleteFlashcardsResponse, error)
+	GenerateQuiz(context.Context, *GenerateQuizRequest) (*Quiz, error)
+	SubmitQuizAnswers(context.Context, *SubmitQuizAnswersRequest) (*QuizResult, error)
+	GradeTypedAnswer(context.Context, *GradeTypedAnswerRequest) (*GradeTypedAnswerResponse, error)
+	WatchIngestionJob(*WatchIngestionJobRequest, grpc.ServerStreamingServer[IngestionJob]) error
+	RetryFailedChunks(context.Context, *RetryFailedChunksRequest) (*AddMaterialResponse, error)
 	mustEmbedUnimplementedLearningServiceServer()
 }
 
@@ -210,6 +479,66 @@ func (UnimplementedLearningServiceServer) GetMaterialSummary(context.Context, *G
 func (UnimplementedLearningServiceServer) UpdateFlashcard(context.Context, *UpdateFlashcardRequest) (*emptypb.Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpdateFlashcard not implemented")
 }
+func (UnimplementedLearningServiceServer) ReviewFlashcard(context.Context, *ReviewFlashcardRequest) (*ReviewFlashcardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReviewFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) OptimizeSchedule(context.Context, *emptypb.Empty) (*OptimizeScheduleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method OptimizeSchedule not implemented")
+}
+func (UnimplementedLearningServiceServer) GetReviewHistory(context.Context, *GetReviewHistoryRequest) (*GetReviewHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetReviewHistory not implemented")
+}
+func (UnimplementedLearningServiceServer) UndoReview(context.Context, *UndoReviewRequest) (*UndoReviewResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UndoReview not implemented")
+}
+func (UnimplementedLearningServiceServer) GetReviewQueue(context.Context, *GetReviewQueueRequest) (*GetReviewQueueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetReviewQueue not implemented")
+}
+func (UnimplementedLearningServiceServer) GetStudySettings(context.Context, *emptypb.Empty) (*StudySettings, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStudySettings not implemented")
+}
+func (UnimplementedLearningServiceServer) UpdateStudySettings(context.Context, *StudySettings) (*StudySettings, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateStudySettings not implemented")
+}
+func (UnimplementedLearningServiceServer) SuspendFlashcard(context.Context, *SuspendFlashcardRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method SuspendFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) BuryFlashcard(context.Context, *BuryFlashcardRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method BuryFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) FlagFlashcard(context.Context, *FlagFlashcardRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method FlagFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) GetLeeches(context.Context, *emptypb.Empty) (*FlashcardList, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLeeches not implemented")
+}
+func (UnimplementedLearningServiceServer) RewriteFlashcard(context.Context, *RewriteFlashcardRequest) (*RewriteFlashcardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RewriteFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) CreateFlashcard(context.Context, *CreateFlashcardRequest) (*Flashcard, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) DeleteFlashcard(context.Context, *DeleteFlashcardRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteFlashcard not implemented")
+}
+func (UnimplementedLearningServiceServer) BulkDeleteFlashcards(context.Context, *BulkDeleteFlashcardsRequest) (*BulkDeleteFlashcardsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BulkDeleteFlashcards not implemented")
+}
+func (UnimplementedLearningServiceServer) GenerateQuiz(context.Context, *GenerateQuizRequest) (*Quiz, error) {
+	return nil, status.Error(codes.Unimplemented, "method GenerateQuiz not implemented")
+}
+func (UnimplementedLearningServiceServer) SubmitQuizAnswers(context.Context, *SubmitQuizAnswersRequest) (*QuizResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitQuizAnswers not implemented")
+}
+func (UnimplementedLearningServiceServer) GradeTypedAnswer(context.Context, *GradeTypedAnswerRequest) (*GradeTypedAnswerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GradeTypedAnswer not implemented")
+}
+func (UnimplementedLearningServiceServer) WatchIngestionJob(*WatchIngestionJobRequest, grpc.ServerStreamingServer[IngestionJob]) error {
+	return status.Error(codes.Unimplemented, "method WatchIngestionJob not implemented")
+}
+func (UnimplementedLearningServiceServer) RetryFailedChunks(context.Context, *RetryFailedChunksRequest) (*AddMaterialResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RetryFailedChunks not implemented")
+}
 func (UnimplementedLearningServiceServer) mustEmbedUnimplementedLearningServiceServer() {}
 func (UnimplementedLearningServiceServer) testEmbeddedByValue()                         {}
 
@@ -411,6 +740,359 @@ func _LearningService_UpdateFlashcard_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LearningService_ReviewFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).ReviewFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_ReviewFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).ReviewFlashcard(ctx, req.(*ReviewFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_OptimizeSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).OptimizeSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_OptimizeSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).OptimizeSchedule(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_GetReviewHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReviewHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).GetReviewHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_GetReviewHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).GetReviewHistory(ctx, req.(*GetReviewHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_UndoReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndoReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).UndoReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_UndoReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).UndoReview(ctx, req.(*UndoReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_GetReviewQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReviewQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).GetReviewQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_GetReviewQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).GetReviewQueue(ctx, req.(*GetReviewQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_GetStudySettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).GetStudySettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_GetStudySettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).GetStudySettings(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_UpdateStudySettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StudySettings)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).UpdateStudySettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_UpdateStudySettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).UpdateStudySettings(ctx, req.(*StudySettings))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_SuspendFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuspendFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).SuspendFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_SuspendFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).SuspendFlashcard(ctx, req.(*SuspendFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_BuryFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuryFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).BuryFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_BuryFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).BuryFlashcard(ctx, req.(*BuryFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_FlagFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlagFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).FlagFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_FlagFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).FlagFlashcard(ctx, req.(*FlagFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_GetLeeches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).GetLeeches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_GetLeeches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).GetLeeches(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_RewriteFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RewriteFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).RewriteFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_RewriteFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).RewriteFlashcard(ctx, req.(*RewriteFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_CreateFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).CreateFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_CreateFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).CreateFlashcard(ctx, req.(*CreateFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_DeleteFlashcard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFlashcardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).DeleteFlashcard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_DeleteFlashcard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).DeleteFlashcard(ctx, req.(*DeleteFlashcardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_BulkDeleteFlashcards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkDeleteFlashcardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).BulkDeleteFlashcards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_BulkDeleteFlashcards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).BulkDeleteFlashcards(ctx, req.(*BulkDeleteFlashcardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_GenerateQuiz_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateQuizRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).GenerateQuiz(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_GenerateQuiz_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).GenerateQuiz(ctx, req.(*GenerateQuizRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_SubmitQuizAnswers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitQuizAnswersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).SubmitQuizAnswers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_SubmitQuizAnswers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).SubmitQuizAnswers(ctx, req.(*SubmitQuizAnswersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_GradeTypedAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GradeTypedAnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).GradeTypedAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_GradeTypedAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).GradeTypedAnswer(ctx, req.(*GradeTypedAnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LearningService_WatchIngestionJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchIngestionJobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LearningServiceServer).WatchIngestionJob(m, &grpc.GenericServerStream[WatchIngestionJobRequest, IngestionJob]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LearningService_WatchIngestionJobServer = grpc.ServerStreamingServer[IngestionJob]
+
+func _LearningService_RetryFailedChunks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryFailedChunksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LearningServiceServer).RetryFailedChunks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LearningService_RetryFailedChunks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LearningServiceServer).RetryFailedChunks(ctx, req.(*RetryFailedChunksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LearningService_ServiceDesc is the grpc.ServiceDesc for LearningService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -458,7 +1140,89 @@ var LearningService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateFlashcard",
 			Handler:    _LearningService_UpdateFlashcard_Handler,
 		},
+		{
+			MethodName: "ReviewFlashcard",
+			Handler:    _LearningService_ReviewFlashcard_Handler,
+		},
+		{
+			MethodName: "OptimizeSchedule",
+			Handler:    _LearningService_OptimizeSchedule_Handler,
+		},
+		{
+			MethodName: "GetReviewHistory",
+			Handler:    _LearningService_GetReviewHistory_Handler,
+		},
+		{
+			MethodName: "UndoReview",
+			Handler:    _LearningService_UndoReview_Handler,
+		},
+		{
+			MethodName: "GetReviewQueue",
+			Handler:    _LearningService_GetReviewQueue_Handler,
+		},
+		{
+			MethodName: "GetStudySettings",
+			Handler:    _LearningService_GetStudySettings_Handler,
+		},
+		{
+			MethodName: "UpdateStudySettings",
+			Handler:    _LearningService_UpdateStudySettings_Handler,
+		},
+		{
+			MethodName: "SuspendFlashcard",
+			Handler:    _LearningService_SuspendFlashcard_Handler,
+		},
+		{
+			MethodName: "BuryFlashcard",
+			Handler:    _LearningService_BuryFlashcard_Handler,
+		},
+		{
+			MethodName: "FlagFlashcard",
+			Handler:    _LearningService_FlagFlashcard_Handler,
+		},
+		{
+			MethodName: "GetLeeches",
+			Handler:    _LearningService_GetLeeches_Handler,
+		},
+		{
+			MethodName: "RewriteFlashcard",
+			Handler:    _LearningService_RewriteFlashcard_Handler,
+		},
+		{
+			MethodName: "CreateFlashcard",
+			Handler:    _LearningService_CreateFlashcard_Handler,
+		},
+		{
+			MethodName: "DeleteFlashcard",
+			Handler:    _LearningService_DeleteFlashcard_Handler,
+		},
+		{
+			MethodName: "BulkDeleteFlashcards",
+			Handler:    _LearningService_BulkDeleteFlashcards_Handler,
+		},
+		{
+			MethodName: "GenerateQuiz",
+			Handler:    _LearningService_GenerateQuiz_Handler,
+		},
+		{
+			MethodName: "SubmitQuizAnswers",
+			Handler:    _LearningService_SubmitQuizAnswers_Handler,
+		},
+		{
+			MethodName: "GradeTypedAnswer",
+			Handler:    _LearningService_GradeTypedAnswer_Handler,
+		},
+		{
+			MethodName: "RetryFailedChunks",
+			Handler:    _LearningService_RetryFailedChunks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchIngestionJob",
+			Handler:       _LearningService_WatchIngestionJob_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "backend/proto/learning/learning.proto",
 }
@@ -12,6 +12,74 @@ import { Timestamp } from "../../google/protobuf/timestamp";
 
 export const protobufPackage = "learning";
 
+export enum IngestionStage {
+  /** INGESTION_STAGE_QUEUED - Waiting for a worker */
+  INGESTION_STAGE_QUEUED = 0,
+  /** INGESTION_STAGE_FETCHING - Scraping the link or downloading the transcript */
+  INGESTION_STAGE_FETCHING = 1,
+  /** INGESTION_STAGE_EXTRACTING - Reading text from the image */
+  INGESTION_STAGE_EXTRACTING = 2,
+  /** INGESTION_STAGE_GENERATING - Writing flashcards, chunk by chunk for long material */
+  INGESTION_STAGE_GENERATING = 3,
+  /** INGESTION_STAGE_SAVING - Storing the material and its cards */
+  INGESTION_STAGE_SAVING = 4,
+  INGESTION_STAGE_SUCCEEDED = 5,
+  INGESTION_STAGE_FAILED = 6,
+  UNRECOGNIZED = -1,
+}
+
+export function ingestionStageFromJSON(object: any): IngestionStage {
+  switch (object) {
+    case 0:
+    case "INGESTION_STAGE_QUEUED":
+      return IngestionStage.INGESTION_STAGE_QUEUED;
+    case 1:
+    case "INGESTION_STAGE_FETCHING":
+      return IngestionStage.INGESTION_STAGE_FETCHING;
+    case 2:
+    case "INGESTION_STAGE_EXTRACTING":
+      return IngestionStage.INGESTION_STAGE_EXTRACTING;
+    case 3:
+    case "INGESTION_STAGE_GENERATING":
+      return IngestionStage.INGESTION_STAGE_GENERATING;
+    case 4:
+    case "INGESTION_STAGE_SAVING":
+      return IngestionStage.INGESTION_STAGE_SAVING;
+    case 5:
+    case "INGESTION_STAGE_SUCCEEDED":
+      return IngestionStage.INGESTION_STAGE_SUCCEEDED;
+    case 6:
+    case "INGESTION_STAGE_FAILED":
+      return IngestionStage.INGESTION_STAGE_FAILED;
+    case -1:
+    case "UNRECOGNIZED":
+    default:
+      return IngestionStage.UNRECOGNIZED;
+  }
+}
+
+export function ingestionStageToJSON(object: IngestionStage): string {
+  switch (object) {
+    case IngestionStage.INGESTION_STAGE_QUEUED:
+      return "INGESTION_STAGE_QUEUED";
+    case IngestionStage.INGESTION_STAGE_FETCHING:
+      return "INGESTION_STAGE_FETCHING";
+    case IngestionStage.INGESTION_STAGE_EXTRACTING:
+      return "INGESTION_STAGE_EXTRACTING";
+    case IngestionStage.INGESTION_STAGE_GENERATING:
+      return "INGESTION_STAGE_GENERATING";
+    case IngestionStage.INGESTION_STAGE_SAVING:
+      return "INGESTION_STAGE_SAVING";
+    case IngestionStage.INGESTION_STAGE_SUCCEEDED:
+      return "INGESTION_STAGE_SUCCEEDED";
+    case IngestionStage.INGESTION_STAGE_FAILED:
+      return "INGESTION_STAGE_FAILED";
+    case IngestionStage.UNRECOGNIZED:
+    default:
+      return "UNRECOGNIZED";
+  }
+}
+
 export interface AddMaterialRequest {
   /** "TEXT", "LINK", "IMAGE", or "YOUTUBE" */
   type: string;
@@ -21,11 +89,40 @@ export interface AddMaterialRequest {
   imageData: string;
 }
 
+/**
+ * AddMaterial only queues the material and returns job_id; the other fields
+ * are filled in on the result of the finished IngestionJob.
+ */
 export interface AddMaterialResponse {
   materialId: string;
   flashcardsCreated: number;
   title: string;
   tags: string[];
+  jobId: string;
+  /** Sections the content was split into for generation */
+  totalChunks: number;
+  /** Sections (1-based) that produced no cards; see RetryFailedChunks */
+  failedChunks: number[];
+}
+
+export interface WatchIngestionJobRequest {
+  jobId: string;
+}
+
+/** Progress of a material being turned into flashcards in the background. */
+export interface IngestionJob {
+  id: string;
+  stage: IngestionStage;
+  /** Chunk being generated (1-based) while GENERATING */
+  chunk: number;
+  totalChunks: number;
+  /** Set once the job has finished, and for a FAILED job once its material was saved */
+  result:
+    | AddMaterialResponse
+    | undefined;
+  /** Set once the job has FAILED */
+  error: string;
+  updatedAt: Date | undefined;
 }
 
 export interface DeleteMaterialRequest {
@@ -213,7 +310,7 @@ export const AddMaterialRequest: MessageFns<AddMaterialRequest> = {
 };
 
 function createBaseAddMaterialResponse(): AddMaterialResponse {
-  return { materialId: "", flashcardsCreated: 0, title: "", tags: [] };
+  return { materialId: "", flashcardsCreated: 0, title: "", tags: [], jobId: "", totalChunks: 0, failedChunks: [] };
 }
 
 export const AddMaterialResponse: MessageFns<AddMaterialResponse> = {
@@ -230,6 +327,17 @@ export const AddMaterialResponse: MessageFns<AddMaterialResponse> = {
     for (const v of message.tags) {
       writer.uint32(34).string(v!);
     }
+    if (message.jobId !== "") {
+      writer.uint32(42).string(message.jobId);
+    }
+    if (message.totalChunks !== 0) {
+      writer.uint32(48).int32(message.totalChunks);
+    }
+    writer.uint32(58).fork();
+    for (const v of message.failedChunks) {
+      writer.int32(v);
+    }
+    writer.join();
     return writer;
   },
 
@@ -272,6 +380,40 @@ export const AddMaterialResponse: MessageFns<AddMaterialResponse> = {
           message.tags.push(reader.string());
           continue;
         }
+        case 5: {
+          if (tag !== 42) {
+            break;
+          }
+
+          message.jobId = reader.string();
+          continue;
+        }
+        case 6: {
+          if (tag !== 48) {
+            break;
+          }
+
+          message.totalChunks = reader.int32();
+          continue;
+        }
+        case 7: {
+          if (tag === 56) {
+            message.failedChunks.push(reader.int32());
+
+            continue;
+          }
+
+          if (tag === 58) {
+            const end2 = reader.uint32() + reader.pos;
+            while (reader.pos < end2) {
+              message.failedChunks.push(reader.int32());
+            }
+
+            continue;
+          }
+
+          break;
+        }
       }
       if ((tag & 7) === 4 || tag === 0) {
         break;
@@ -287,6 +429,11 @@ export const AddMaterialResponse: MessageFns<AddMaterialResponse> = {
       flashcardsCreated: isSet(object.flashcardsCreated) ? globalThis.Number(object.flashcardsCreated) : 0,
       title: isSet(object.title) ? globalThis.String(object.title) : "",
       tags: globalThis.Array.isArray(object?.tags) ? object.tags.map((e: any) => globalThis.String(e)) : [],
+      jobId: isSet(object.jobId) ? globalThis.String(object.jobId) : "",
+      totalChunks: isSet(object.totalChunks) ? globalThis.Number(object.totalChunks) : 0,
+      failedChunks: globalThis.Array.isArray(object?.failedChunks)
+        ? object.failedChunks.map((e: any) => globalThis.Number(e))
+        : [],
     };
   },
 
@@ -304,6 +451,15 @@ export const AddMaterialResponse: MessageFns<AddMaterialResponse> = {
     if (message.tags?.length) {
       obj.tags = message.tags;
     }
+    if (message.jobId !== "") {
+      obj.jobId = message.jobId;
+    }
+    if (message.totalChunks !== 0) {
+      obj.totalChunks = Math.round(message.totalChunks);
+    }
+    if (message.failedChunks?.length) {
+      obj.failedChunks = message.failedChunks.map((e) => Math.round(e));
+    }
     return obj;
   },
 
@@ -316,6 +472,225 @@ export const AddMaterialResponse: MessageFns<AddMaterialResponse> = {
     message.flashcardsCreated = object.flashcardsCreated ?? 0;
     message.title = object.title ?? "";
     message.tags = object.tags?.map((e) => e) || [];
+    message.jobId = object.jobId ?? "";
+    message.totalChunks = object.totalChunks ?? 0;
+    message.failedChunks = object.failedChunks?.map((e) => e) || [];
+    return message;
+  },
+};
+
+function createBaseWatchIngestionJobRequest(): WatchIngestionJobRequest {
+  return { jobId: "" };
+}
+
+export const WatchIngestionJobRequest: MessageFns<WatchIngestionJobRequest> = {
+  encode(message: WatchIngestionJobRequest, writer: BinaryWriter = new BinaryWriter()): BinaryWriter {
+    if (message.jobId !== "") {
+      writer.uint32(10).string(message.jobId);
+    }
+    return writer;
+  },
+
+  decode(input: BinaryReader | Uint8Array, length?: number): WatchIngestionJobRequest {
+    const reader = input instanceof BinaryReader ? input : new BinaryReader(input);
+    const end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseWatchIngestionJobRequest();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1: {
+          if (tag !== 10) {
+            break;
+          }
+
+          message.jobId = reader.string();
+          continue;
+        }
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skip(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): WatchIngestionJobRequest {
+    return { jobId: isSet(object.jobId) ? globalThis.String(object.jobId) : "" };
+  },
+
+  toJSON(message: WatchIngestionJobRequest): unknown {
+    const obj: any = {};
+    if (message.jobId !== "") {
+      obj.jobId = message.jobId;
+    }
+    return obj;
+  },
+
+  create(base?: DeepPartial<WatchIngestionJobRequest>): WatchIngestionJobRequest {
+    return WatchIngestionJobRequest.fromPartial(base ?? {});
+  },
+  fromPartial(object: DeepPartial<WatchIngestionJobRequest>): WatchIngestionJobRequest {
+    const message = createBaseWatchIngestionJobRequest();
+    message.jobId = object.jobId ?? "";
+    return message;
+  },
+};
+
+function createBaseIngestionJob(): IngestionJob {
+  return { id: "", stage: 0, chunk: 0, totalChunks: 0, result: undefined, error: "", updatedAt: undefined };
+}
+
+export const IngestionJob: MessageFns<IngestionJob> = {
+  encode(message: IngestionJob, writer: BinaryWriter = new BinaryWriter()): BinaryWriter {
+    if (message.id !== "") {
+      writer.uint32(10).string(message.id);
+    }
+    if (message.stage !== 0) {
+      writer.uint32(16).int32(message.stage);
+    }
+    if (message.chunk !== 0) {
+      writer.uint32(24).int32(message.chunk);
+    }
+    if (message.totalChunks !== 0) {
+      writer.uint32(32).int32(message.totalChunks);
+    }
+    if (message.result !== undefined) {
+      AddMaterialResponse.encode(message.result, writer.uint32(42).fork()).join();
+    }
+    if (message.error !== "") {
+      writer.uint32(50).string(message.error);
+    }
+    if (message.updatedAt !== undefined) {
+      Timestamp.encode(toTimestamp(message.updatedAt), writer.uint32(58).fork()).join();
+    }
+    return writer;
+  },
+
+  decode(input: BinaryReader | Uint8Array, length?: number): IngestionJob {
+    const reader = input instanceof BinaryReader ? input : new BinaryReader(input);
+    const end = length === undefined ? reader.len : reader.pos + length;
+    const message = createBaseIngestionJob();
+    while (reader.pos < end) {
+      const tag = reader.uint32();
+      switch (tag >>> 3) {
+        case 1: {
+          if (tag !== 10) {
+            break;
+          }
+
+          message.id = reader.string();
+          continue;
+        }
+        case 2: {
+          if (tag !== 16) {
+            break;
+          }
+
+          message.stage = reader.int32() as any;
+          continue;
+        }
+        case 3: {
+          if (tag !== 24) {
+            break;
+          }
+
+          message.chunk = reader.int32();
+          continue;
+        }
+        case 4: {
+          if (tag !== 32) {
+            break;
+          }
+
+          message.totalChunks = reader.int32();
+          continue;
+        }
+        case 5: {
+          if (tag !== 42) {
+            break;
+          }
+
+          message.result = AddMaterialResponse.decode(reader, reader.uint32());
+          continue;
+        }
+        case 6: {
+          if (tag !== 50) {
+            break;
+          }
+
+          message.error = reader.string();
+          continue;
+        }
+        case 7: {
+          if (tag !== 58) {
+            break;
+          }
+
+          message.updatedAt = fromTimestamp(Timestamp.decode(reader, reader.uint32()));
+          continue;
+        }
+      }
+      if ((tag & 7) === 4 || tag === 0) {
+        break;
+      }
+      reader.skip(tag & 7);
+    }
+    return message;
+  },
+
+  fromJSON(object: any): IngestionJob {
+    return {
+      id: isSet(object.id) ? globalThis.String(object.id) : "",
+      stage: isSet(object.stage) ? ingestionStageFromJSON(object.stage) : 0,
+      chunk: isSet(object.chunk) ? globalThis.Number(object.chunk) : 0,
+      totalChunks: isSet(object.totalChunks) ? globalThis.Number(object.totalChunks) : 0,
+      result: isSet(object.result) ? AddMaterialResponse.fromJSON(object.result) : undefined,
+      error: isSet(object.error) ? globalThis.String(object.error) : "",
+      updatedAt: isSet(object.updatedAt) ? fromJsonTimestamp(object.updatedAt) : undefined,
+    };
+  },
+
+  toJSON(message: IngestionJob): unknown {
+    const obj: any = {};
+    if (message.id !== "") {
+      obj.id = message.id;
+    }
+    if (message.stage !== 0) {
+      obj.stage = ingestionStageToJSON(message.stage);
+    }
+    if (message.chunk !== 0) {
+      obj.chunk = Math.round(message.chunk);
+    }
+    if (message.totalChunks !== 0) {
+      obj.totalChunks = Math.round(message.totalChunks);
+    }
+    if (message.result !== undefined) {
+      obj.result = AddMaterialResponse.toJSON(message.result);
+    }
+    if (message.error !== "") {
+      obj.error = message.error;
+    }
+    if (message.updatedAt !== undefined) {
+      obj.updatedAt = message.updatedAt.toISOString();
+    }
+    return obj;
+  },
+
+  create(base?: DeepPartial<IngestionJob>): IngestionJob {
+    return IngestionJob.fromPartial(base ?? {});
+  },
+  fromPartial(object: DeepPartial<IngestionJob>): IngestionJob {
+    const message = createBaseIngestionJob();
+    message.id = object.id ?? "";
+    message.stage = object.stage ?? 0;
+    message.chunk = object.chunk ?? 0;
+    message.totalChunks = object.totalChunks ?? 0;
+    message.result = (object.result !== undefined && object.result !== null)
+      ? AddMaterialResponse.fromPartial(object.result)
+      : undefined;
+    message.error = object.error ?? "";
+    message.updatedAt = object.updatedAt ?? undefined;
     return message;
   },
 };
@@ -1466,6 +1841,10 @@ export interface LearningServiceImplementation<CallContextExt = {}> {
     context: CallContext & CallContextExt,
   ): Promise<DeepPartial<GetMaterialSummaryResponse>>;
   updateFlashcard(request: UpdateFlashcardRequest, context: CallContext & CallContextExt): Promise<DeepPartial<Empty>>;
+  watchIngestionJob(
+    request: WatchIngestionJobRequest,
+    context: CallContext & CallContextExt,
+  ): ServerStreamingMethodResult<DeepPartial<IngestionJob>>;
 }
 
 export interface LearningServiceClient<CallOptionsExt = {}> {
@@ -1494,6 +1873,10 @@ export interface LearningServiceClient<CallOptionsExt = {}> {
     options?: CallOptions & CallOptionsExt,
   ): Promise<GetMaterialSummaryResponse>;
   updateFlashcard(request: DeepPartial<UpdateFlashcardRequest>, options?: CallOptions & CallOptionsExt): Promise<Empty>;
+  watchIngestionJob(
+    request: DeepPartial<WatchIngestionJobRequest>,
+    options?: CallOptions & CallOptionsExt,
+  ): AsyncIterable<IngestionJob>;
 }
 
 type Builtin = Date | Function | Uint8Array | string | number | boolean | undefined;
@@ -1530,6 +1913,8 @@ function isSet(value: any): boolean {
   return value !== null && value !== undefined;
 }
 
+export type ServerStreamingMethodResult<Response> = { [Symbol.asyncIterator](): AsyncIterator<Response, void> };
+
 export interface MessageFns<T> {
   encode(message: T, writer?: BinaryWriter): BinaryWriter;
   decode(input: BinaryReader | Uint8Array, length?: number): T;
@@ -3,12 +3,33 @@ import { View, Text, StyleSheet, TextInput, TouchableOpacity, ScrollView, Activi
 import { useMutation, useQueryClient } from '@tanstack/react-query';
 import { useNavigation } from '../navigation/ManualRouter';
 import { learningClient } from '../services/api';
+import { IngestionJob, IngestionStage } from '../../proto/backend/proto/learning/learning';
 import { AppHeader } from '../components/AppHeader';
 import { useTheme, ThemeColors } from '../utils/theme';
 import * as ImagePickerUtil from '../utils/imagePicker';
 
 type MaterialType = 'TEXT' | 'LINK' | 'IMAGE' | 'YOUTUBE';
 
+// Describes what a running ingestion job is doing, for the loading indicator.
+const describeJob = (job: IngestionJob): string => {
+    switch (job.stage) {
+        case IngestionStage.INGESTION_STAGE_QUEUED:
+            return 'Waiting to start...';
+        case IngestionStage.INGESTION_STAGE_FETCHING:
+            return 'Fetching content...';
+        case IngestionStage.INGESTION_STAGE_EXTRACTING:
+            return 'Extracting text...';
+        case IngestionStage.INGESTION_STAGE_GENERATING:
+            return job.totalChunks > 1
+                ? `Generating flashcards (${job.chunk}/${job.totalChunks})...`
+                : 'Generating flashcards...';
+        case IngestionStage.INGESTION_STAGE_SAVING:
+            return 'Saving flashcards...';
+        default:
+            return 'Generating flashcards...';
+    }
+};
+
 export const AddMaterialScreen = () => {
     const navigation = useNavigation();
     const queryClient = useQueryClient();
@@ -17,15 +38,31 @@ export const AddMaterialScreen = () => {
     const [type, setType] = useState<MaterialType>('TEXT');
     const [imageData, setImageData] = useState<string | null>(null);
     const [imagePreview, setImagePreview] = useState<string | null>(null);
+    const [progress, setProgress] = useState<string | null>(null);
 
     const mutation = useMutation({
         mutationFn: async () => {
             console.log('[ADD_MATERIAL] Submitting material, type:', type);
-            return await learningClient.addMaterial({
+            setProgress(null);
+            const { jobId } = await learningClient.addMaterial({
                 type,
                 content: type === 'IMAGE' ? '' : content,
                 imageData: type === 'IMAGE' ? imageData || '' : '',
             });
+
+            // The material is processed in the background; follow the job until it finishes
+            let job: IngestionJob | undefined;
+            for await (const update of learningClient.watchIngestionJob({ jobId })) {
+                job = update;
+                setProgress(describeJob(job));
+            }
+            if (job?.stage === IngestionStage.INGESTION_STAGE_FAILED) {
+                throw new Error(job.error);
+            }
+            if (job?.stage !== IngestionStage.INGESTION_STAGE_SUCCEEDED || !job.result) {
+                throw new Error(`Job ${jobId} ended before finishing`);
+            }
+            return job.result;
         },
         onSuccess: (data) => {
             console.log('[ADD_MATERIAL] Success! Created flashcards:', data.flashcardsCreated);
@@ -177,7 +214,7 @@ export const AddMaterialScreen = () => {
                         <View style={styles.loadingContainer}>
                             <ActivityIndicator color={colors.textInverse} />
                             <Text style={styles.loadingText}>
-                                {type === 'IMAGE' ? 'Extracting text & generating...' : 'Generating flashcards...'}
+                                {progress ?? (type === 'IMAGE' ? 'Extracting text & generating...' : 'Generating flashcards...')}
                             </Text>
                         </View>
                     ) : (
@@ -9,7 +9,8 @@ import {
     GetDueFlashcardsRequest,
     GetAllTagsResponse,
     NotificationStatusResponse,
-    GetMaterialSummaryRequest, GetMaterialSummaryResponse
+    GetMaterialSummaryRequest, GetMaterialSummaryResponse,
+    WatchIngestionJobRequest, IngestionJob
 } from '../../proto/backend/proto/learning/learning';
 import { Empty } from '../../proto/backend/google/protobuf/empty';
 
@@ -112,6 +113,14 @@ export const LearningServiceDefinition = {
             responseStream: false,
             options: {},
         },
+        watchIngestionJob: {
+            name: 'WatchIngestionJob',
+            requestType: WatchIngestionJobRequest,
+            requestStream: false,
+            responseType: IngestionJob,
+            responseStream: true,
+            options: {},
+        },
     },
 } as const;
 
@@ -13,7 +13,8 @@ import {
     GetDueMaterialsRequest, GetDueMaterialsResponse,
     GetDueFlashcardsRequest, FlashcardList,
     CompleteReviewRequest, FailReviewRequest, UpdateFlashcardRequest, GetAllTagsResponse, NotificationStatusResponse,
-    GetMaterialSummaryRequest, GetMaterialSummaryResponse
+    GetMaterialSummaryRequest, GetMaterialSummaryResponse,
+    WatchIngestionJobRequest, IngestionJob
 } from '../../proto/backend/proto/learning/learning';
 import { Empty } from '../../proto/backend/google/protobuf/empty';
 import { API_URL } from '../utils/config';
@@ -41,8 +42,8 @@ function encodeGrpcWebFrame(data: Uint8Array): Uint8Array {
 }
 
 // gRPC-Web frame decoding
-function decodeGrpcWebResponse(response: Uint8Array): { data: Uint8Array | null; status: number; message: string } {
-    let dataFrame: Uint8Array | null = null;
+function decodeGrpcWebResponse(response: Uint8Array): { frames: Uint8Array[]; status: number; message: string } {
+    const dataFrames: Uint8Array[] = [];
     let grpcStatus = 0;
     let grpcMessage = '';
     let offset = 0;
@@ -64,7 +65,7 @@ function decodeGrpcWebResponse(response: Uint8Array): { data: Uint8Array | null;
 
         if (flag === 0) {
             // Data frame
-            dataFrame = frameData;
+            dataFrames.push(frameData);
         } else if (flag === 128) {
             // Trailer frame
             const trailerText = new TextDecoder().decode(frameData);
@@ -83,7 +84,7 @@ function decodeGrpcWebResponse(response: Uint8Array): { data: Uint8Array | null;
         }
     }
 
-    return { data: dataFrame, status: grpcStatus, message: grpcMessage };
+    return { frames: dataFrames, status: grpcStatus, message: grpcMessage };
 }
 
 // Error class
@@ -101,6 +102,21 @@ async function grpcRequest<TRequest, TResponse>(
     encode: (req: TRequest) => Uint8Array,
     decode: (data: Uint8Array) => TResponse,
 ): Promise<TResponse> {
+    const responses = await grpcStreamRequest(path, request, encode, decode);
+    if (responses.length === 0) {
+        throw new GrpcError(path, 2, 'No data in response');
+    }
+    return responses[responses.length - 1];
+}
+
+// Server-streaming gRPC request function. XMLHttpRequest only hands over the
+// body once the stream has ended, so every message arrives at the end.
+async function grpcStreamRequest<TRequest, TResponse>(
+    path: string,
+    request: TRequest,
+    encode: (req: TRequest) => Uint8Array,
+    decode: (data: Uint8Array) => TResponse,
+): Promise<TResponse[]> {
     const token = await getToken();
     const url = `${API_URL}${path}`;
     const requestData = encodeGrpcWebFrame(encode(request));
@@ -126,19 +142,14 @@ async function grpcRequest<TRequest, TResponse>(
                 }
 
                 const responseBytes = new Uint8Array(xhr.response as ArrayBuffer);
-                const { data, status, message } = decodeGrpcWebResponse(responseBytes);
+                const { frames, status, message } = decodeGrpcWebResponse(responseBytes);
 
                 if (status !== 0) {
                     reject(new GrpcError(path, status, message || `gRPC error: ${status}`));
                     return;
                 }
 
-                if (!data) {
-                    reject(new GrpcError(path, 2, 'No data in response'));
-                    return;
-                }
-
-                resolve(decode(data));
+                resolve(frames.map(decode));
             } catch (e) {
                 reject(new GrpcError(path, 2, `Decode error: ${e}`));
             }
@@ -267,4 +278,14 @@ export const learningClient = {
             (data) => GetMaterialSummaryResponse.decode(data),
         );
     },
+
+    async *watchIngestionJob(request: Partial<WatchIngestionJobRequest>): AsyncIterable<IngestionJob> {
+        const req = WatchIngestionJobRequest.fromPartial(request);
+        yield* await grpcStreamRequest(
+            '/learning.LearningService/WatchIngestionJob',
+            req,
+            (r) => WatchIngestionJobRequest.encode(r).finish(),
+            (data) => IngestionJob.decode(data),
+        );
+    },
 };